@@ -0,0 +1,321 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/ims"
+)
+
+// AlertRule is a rule from `alerts.rules`. The query returns one row
+// per matching item, with the item name in the first column and an
+// optional detail message in the second.
+type AlertRule struct {
+	Enable   *bool  `mapstructure:"enable,omitempty"`
+	Severity string `mapstructure:"severity,omitempty"`
+	Cycles   int    `mapstructure:"cycles,omitempty"`
+	Subject  string `mapstructure:"subject,omitempty"`
+	Query    string `mapstructure:"query,omitempty"`
+	History  bool   `mapstructure:"history,omitempty"`
+}
+
+// alert is the state of one item matched by an alert rule
+type alert struct {
+	rule      string
+	item      string
+	severity  string
+	detail    string
+	subject   string
+	cycles    int
+	firstSeen time.Time
+	raised    time.Time
+}
+
+// processAlerts evaluates the alert rules against the reporting tables,
+// which must have been updated, and raises any new alerts. An item must
+// match a rule for the configured number of consecutive cycles before
+// an alert is raised, and it is then not raised again while it still
+// matches, unless `alerts.repeat-after` is set and that much time has
+// passed. Items that no longer match, including those of rules that
+// are disabled or skipped, are cleared. Rules with `history` set use the
+// coverage history tables and are skipped unless `gdna.history.enable`
+// is set.
+//
+// Alerts are raised as incidents through the IMS gateway when
+// `alerts.ims.type` is set, otherwise they are only recorded for the
+// `coverage-alerts` report. When a raised alert clears the incident is
+// updated with `alerts.ims.clear-fields` to resolve it. An alert that
+// fails to reach the IMS gateway is tried again the next cycle, and a
+// cleared alert is kept, with zero cycles, until it is resolved.
+//
+// The alert state is kept in `db.alerts` and updated in a transaction
+// of its own, so that errors do not affect reports.
+func processAlerts(ctx context.Context, cf *config.Config, db *sql.DB) (err error) {
+	if !config.Get[bool](cf, cf.Join("alerts", "enable")) {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error("cannot BEGIN transaction", slog.Any("error", err))
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	repeat := config.Get[time.Duration](cf, cf.Join("alerts", "repeat-after"))
+	history := config.Get[bool](cf, cf.Join("gdna", "history", "enable"))
+
+	var alerts, raise []*alert
+
+	for _, name := range slices.Sorted(maps.Keys(config.Get[map[string]any](cf, cf.Join("alerts", "rules")))) {
+		var rule AlertRule
+		if err = cf.UnmarshalKey(config.Join("alerts", "rules", name), &rule, config.NoExpand()); err != nil {
+			log.Error("skipping alert rule due to configuration format incorrect", slog.Any("error", err), slog.String("rule", name))
+			continue
+		}
+		if rule.Enable != nil && !*rule.Enable {
+			continue
+		}
+		if rule.History && !history {
+			log.Debug("skipping alert rule as history is not enabled", slog.String("rule", name))
+			continue
+		}
+
+		var state map[string]*alert
+		if state, err = alertState(ctx, cf, tx, name); err != nil {
+			return
+		}
+
+		query := config.Expand[string](cf, rule.Query)
+		var matches [][]string
+		if matches, err = queryToTable(ctx, tx, nil, query); err != nil {
+			log.Error("alert rule query failed", slog.Any("error", err), slog.String("rule", name), slog.String("query", query))
+			return
+		}
+
+		seen := make(map[string]bool)
+		for _, row := range matches[1:] {
+			// only the first row for each item is used
+			if seen[row[0]] {
+				continue
+			}
+			seen[row[0]] = true
+
+			a := &alert{
+				rule:      name,
+				item:      row[0],
+				severity:  rule.Severity,
+				cycles:    1,
+				firstSeen: now,
+			}
+			if len(row) > 1 {
+				a.detail = row[1]
+			}
+			if s, ok := state[a.item]; ok {
+				a.cycles = s.cycles + 1
+				a.firstSeen = s.firstSeen
+				a.raised = s.raised
+			}
+			alerts = append(alerts, a)
+
+			if a.cycles < max(rule.Cycles, 1) {
+				continue
+			}
+			if a.raised.IsZero() || (repeat > 0 && now.Sub(a.raised) >= repeat) {
+				a.subject = config.Expand[string](cf, rule.Subject, alertLookupTable(cf, a))
+				raise = append(raise, a)
+			}
+		}
+	}
+
+	for _, a := range raise {
+		if err := sendAlert(ctx, cf, a, false); err != nil {
+			log.Error("raising alert", slog.Any("error", err), slog.String("rule", a.rule), slog.String("item", a.item))
+			continue
+		}
+		log.Info("alert raised", slog.String("rule", a.rule), slog.String("item", a.item), slog.String("severity", a.severity), slog.String("subject", a.subject))
+		a.raised = now
+	}
+
+	for _, a := range alerts {
+		if err = updateAlert(ctx, cf, tx, a, now); err != nil {
+			return
+		}
+	}
+
+	// alerts not seen this cycle have cleared. resolve the incidents of
+	// those that were raised and keep any that fail, with zero cycles,
+	// so that they are tried again.
+	cleared, err := clearedAlerts(ctx, cf, tx, now)
+	if err != nil {
+		return
+	}
+	for _, a := range cleared {
+		if !a.raised.IsZero() {
+			a.subject = config.Expand[string](cf, config.Get[string](cf, config.Join("alerts", "rules", a.rule, "subject"), config.NoExpand()), alertLookupTable(cf, a))
+			if err := sendAlert(ctx, cf, a, true); err != nil {
+				log.Error("clearing alert", slog.Any("error", err), slog.String("rule", a.rule), slog.String("item", a.item))
+				a.cycles = 0
+				if err = updateAlert(ctx, cf, tx, a, now); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		log.Info("alert cleared", slog.String("rule", a.rule), slog.String("item", a.item))
+	}
+
+	if err = execSQL(ctx, cf, tx, config.Join("db", "alerts"), "prune", nil, sql.Named("now", now)); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+// updateAlert records the alert a as seen at now
+func updateAlert(ctx context.Context, cf *config.Config, tx *sql.Tx, a *alert, now time.Time) error {
+	return execSQL(ctx, cf, tx, config.Join("db", "alerts"), "insert", nil,
+		sql.Named("rule", a.rule),
+		sql.Named("item", a.item),
+		sql.Named("severity", a.severity),
+		sql.Named("detail", a.detail),
+		sql.Named("cycles", a.cycles),
+		sql.Named("firstSeen", a.firstSeen),
+		sql.Named("lastSeen", now),
+		sql.Named("raised", sql.NullTime{Time: a.raised, Valid: !a.raised.IsZero()}),
+	)
+}
+
+// clearedAlerts returns the alerts, for any rule, last seen before now
+func clearedAlerts(ctx context.Context, cf *config.Config, tx *sql.Tx, now time.Time) (cleared []*alert, err error) {
+	rows, err := tx.QueryContext(ctx, config.Get[string](cf, cf.Join("db", "alerts", "cleared")), sql.Named("now", now))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raised sql.NullTime
+		a := &alert{}
+		if err = rows.Scan(&a.rule, &a.item, &a.severity, &a.detail, &a.cycles, &a.firstSeen, &raised); err != nil {
+			return
+		}
+		a.raised = raised.Time
+		cleared = append(cleared, a)
+	}
+	err = rows.Err()
+	return
+}
+
+// alertState returns the current alerts for rule, indexed by item
+func alertState(ctx context.Context, cf *config.Config, tx *sql.Tx, rule string) (state map[string]*alert, err error) {
+	state = make(map[string]*alert)
+
+	rows, err := tx.QueryContext(ctx, config.Get[string](cf, cf.Join("db", "alerts", "select")), sql.Named("rule", rule))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raised sql.NullTime
+		a := &alert{rule: rule}
+		if err = rows.Scan(&a.item, &a.cycles, &a.firstSeen, &raised); err != nil {
+			return
+		}
+		a.raised = raised.Time
+		state[a.item] = a
+	}
+	err = rows.Err()
+	return
+}
+
+// alertLookupTable returns the values that can be used in the alert
+// rule `subject` and the `alerts.ims.fields`
+func alertLookupTable(cf *config.Config, a *alert) config.ExpandOption {
+	return config.LookupTable(map[string]string{
+		"rule":      a.rule,
+		"item":      a.item,
+		"severity":  a.severity,
+		"detail":    a.detail,
+		"subject":   a.subject,
+		"cycles":    strconv.Itoa(a.cycles),
+		"firstSeen": a.firstSeen.Format(time.RFC3339),
+		"site":      config.Get[string](cf, cf.Join("gdna", "site-name")),
+	})
+}
+
+// sendAlert sends the alert a to the first IMS gateway that accepts
+// it. The incident fields are from `alerts.ims.fields`, with those from
+// `alerts.ims.clear-fields` set over them if cleared is true. The
+// correlation ID is from the site name, rule and item, so that the IMS
+// gateway updates any existing incident for the same alert.
+func sendAlert(ctx context.Context, cf *config.Config, a *alert, cleared bool) (err error) {
+	imsType := config.Get[string](cf, cf.Join("alerts", "ims", "type"))
+	if imsType == "" {
+		return
+	}
+
+	lookup := alertLookupTable(cf, a)
+	incident := make(ims.Values)
+	for field, value := range config.Get[map[string]any](cf, cf.Join("alerts", "ims", "fields"), config.NoExpand()) {
+		incident[field] = config.Expand[string](cf, fmt.Sprint(value), lookup)
+	}
+	if cleared {
+		for field, value := range config.Get[map[string]any](cf, cf.Join("alerts", "ims", "clear-fields"), config.NoExpand()) {
+			incident[field] = config.Expand[string](cf, fmt.Sprint(value), lookup)
+		}
+	}
+	if _, ok := incident[ims.SNOW_CORRELATION_FIELD]; !ok {
+		incident[ims.SNOW_CORRELATION_FIELD] = ims.CorrelationID(config.Get[string](cf, cf.Join("gdna", "site-name")) + "\x00" + a.rule + "\x00" + a.item)
+	}
+
+	// only ServiceNow uses a table in the path
+	var endpoint string
+	if imsType == "snow" {
+		endpoint = config.Get[string](cf, cf.Join("alerts", "ims", "snow-table"))
+	}
+
+	err = errors.New("no IMS gateway URLs configured")
+	for r := range ims.Connect(cf.Sub(cf.Join("alerts", "ims")), imsType) {
+		var result map[string]any
+		if _, err = r.Post(ctx, endpoint, incident, &result); err != nil {
+			if ue, ok := errors.AsType[*url.Error](err); ok {
+				log.Warn("connection error to IMS gateway, trying next endpoint (if any)", slog.Any("error", ue.Unwrap()), slog.Any("url", r.BaseURL))
+			} else {
+				log.Warn("error from IMS gateway", slog.Any("error", err), slog.Any("url", r.BaseURL))
+			}
+			continue
+		}
+		log.Debug("IMS gateway response", slog.Any("result", result))
+		return
+	}
+	return
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/ims"
+)
+
+// fakeIMS is an IMS gateway that records the incidents posted to it
+// and fails requests while failing is set
+type fakeIMS struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	incidents []ims.Values
+	paths     []string
+	failing   bool
+}
+
+func newFakeIMS(t *testing.T) *fakeIMS {
+	f := &fakeIMS{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var incident ims.Values
+		if err := json.NewDecoder(r.Body).Decode(&incident); err != nil {
+			t.Errorf("decoding incident: %v", err)
+		}
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.incidents = append(f.incidents, incident)
+		f.paths = append(f.paths, r.URL.Path)
+		if f.failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"action":"Updated"}`))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeIMS) setFailing(failing bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failing = failing
+}
+
+// take returns and forgets the incidents received so far
+func (f *fakeIMS) take() (incidents []ims.Values) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	incidents, f.incidents = f.incidents, nil
+	return
+}
+
+// alertsConfig returns a configuration with alerts sent to f, a single
+// `test` rule and the built-in rules disabled, and a database with the
+// test data loaded and an empty test_matches table for rules to use
+func alertsConfig(t *testing.T, f *fakeIMS, rule map[string]any) (context.Context, *config.Config, *sql.DB) {
+	ctx := context.Background()
+	cf := testConfig(t, "sqlite", map[string]any{
+		"gdna.site-name":                        "site1",
+		"alerts.enable":                         true,
+		"alerts.ims.type":                       "snow",
+		"alerts.ims.url":                        []string{f.URL},
+		"alerts.rules.test":                     rule,
+		"alerts.rules.server-lost-l1.enable":    false,
+		"alerts.rules.server-lost-l2.enable":    false,
+		"alerts.rules.gateway-no-probes.enable": false,
+	})
+	db := openTestDB(t, ctx, cf)
+	loadTestData(t, ctx, cf, db)
+	if _, err := db.Exec(`CREATE TABLE test_matches (item TEXT, detail TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, cf, db
+}
+
+// alertCycles returns the cycles and raised state of the test rule
+// alert for item, with cycles -1 if there is none
+func alertCycles(t *testing.T, db *sql.DB, item string) (cycles int, raised bool) {
+	t.Helper()
+	var r sql.NullTime
+	err := db.QueryRow(`SELECT cycles, raised FROM alerts WHERE rule = 'test' AND item = ?`, item).Scan(&cycles, &r)
+	if err == sql.ErrNoRows {
+		return -1, false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cycles, r.Valid
+}
+
+func TestProcessAlerts(t *testing.T) {
+	f := newFakeIMS(t)
+	ctx, cf, db := alertsConfig(t, f, map[string]any{
+		"severity": "warning",
+		"cycles":   2,
+		"subject":  "${site}: ${item} matched",
+		"query":    "SELECT item, detail FROM test_matches",
+	})
+	process := func() {
+		t.Helper()
+		if err := processAlerts(ctx, cf, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO test_matches VALUES ('a', 'detail a')`); err != nil {
+		t.Fatal(err)
+	}
+
+	// pending until matched for the number of cycles
+	process()
+	if n := len(f.take()); n != 0 {
+		t.Fatalf("%d incidents sent on first cycle, want 0", n)
+	}
+	if c, r := alertCycles(t, db, "a"); c != 1 || r {
+		t.Fatalf("after first cycle cycles, raised = %d, %v, want 1, false", c, r)
+	}
+
+	process()
+	incidents := f.take()
+	if len(incidents) != 1 {
+		t.Fatalf("%d incidents sent on second cycle, want 1", len(incidents))
+	}
+	raised := incidents[0]
+	correlation := ims.CorrelationID("site1\x00test\x00a")
+	for field, want := range map[string]string{
+		"__incident_subject":       "site1: a matched",
+		"__itrs_severity":          "warning",
+		"__itrs_entity":            "a",
+		ims.SNOW_CORRELATION_FIELD: correlation,
+	} {
+		if raised[field] != want {
+			t.Errorf("raised incident %s = %q, want %q", field, raised[field], want)
+		}
+	}
+	if _, ok := raised[ims.INCIDENT_UPDATE_ONLY]; ok {
+		t.Errorf("raised incident has %s set", ims.INCIDENT_UPDATE_ONLY)
+	}
+	if f.paths[0] != "/snow/incident" {
+		t.Errorf("incident sent to %q, want /snow/incident", f.paths[0])
+	}
+
+	// not raised again while it still matches
+	process()
+	if n := len(f.take()); n != 0 {
+		t.Fatalf("%d incidents sent while still matching, want 0", n)
+	}
+
+	// cleared, but kept with zero cycles while the IMS gateway fails
+	if _, err := db.Exec(`DELETE FROM test_matches`); err != nil {
+		t.Fatal(err)
+	}
+	f.setFailing(true)
+	time.Sleep(time.Second)
+	process()
+	if n := len(f.take()); n != 1 {
+		t.Fatalf("%d clear attempts, want 1", n)
+	}
+	if c, r := alertCycles(t, db, "a"); c != 0 || !r {
+		t.Fatalf("after failed clear cycles, raised = %d, %v, want 0, true", c, r)
+	}
+
+	// and resolved with the same correlation ID once it succeeds
+	f.setFailing(false)
+	time.Sleep(time.Second)
+	process()
+	incidents = f.take()
+	if len(incidents) != 1 {
+		t.Fatalf("%d incidents sent on clear, want 1", len(incidents))
+	}
+	for field, want := range map[string]string{
+		"__incident_subject":       "site1: cleared: site1: a matched",
+		"__itrs_severity":          "ok",
+		ims.INCIDENT_UPDATE_ONLY:   "true",
+		ims.SNOW_CORRELATION_FIELD: correlation,
+	} {
+		if incidents[0][field] != want {
+			t.Errorf("clear incident %s = %q, want %q", field, incidents[0][field], want)
+		}
+	}
+	if c, _ := alertCycles(t, db, "a"); c != -1 {
+		t.Errorf("alert still recorded after clearing, cycles %d", c)
+	}
+}
+
+func TestProcessAlertsHistory(t *testing.T) {
+	f := newFakeIMS(t)
+	ctx, cf, db := alertsConfig(t, f, map[string]any{
+		"severity": "critical",
+		"history":  true,
+		"subject":  "${item}",
+		"query":    "SELECT 'h'",
+	})
+
+	if err := processAlerts(ctx, cf, db); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(f.take()); n != 1 {
+		t.Fatalf("%d incidents sent with history enabled, want 1", n)
+	}
+
+	// with history disabled the rule is skipped and its alert cleared
+	config.Set(cf, "gdna.history.enable", false)
+	time.Sleep(time.Second)
+	if err := processAlerts(ctx, cf, db); err != nil {
+		t.Fatal(err)
+	}
+	incidents := f.take()
+	if len(incidents) != 1 || incidents[0]["__itrs_severity"] != "ok" {
+		t.Fatalf("incidents sent with history disabled = %v, want one clear", incidents)
+	}
+	if c, _ := alertCycles(t, db, "h"); c != -1 {
+		t.Errorf("alert still recorded with history disabled, cycles %d", c)
+	}
+}
+
+// TestAlertRules runs the built-in rules against the test data, as
+// they are not otherwise run by the report tests
+func TestAlertRules(t *testing.T) {
+	captureErrors(t)
+	ctx := context.Background()
+	cf := testConfig(t, "sqlite", map[string]any{"alerts.enable": true})
+	db := openTestDB(t, ctx, cf)
+	loadTestData(t, ctx, cf, db)
+	if err := processAlerts(ctx, cf, db); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -45,6 +46,12 @@ import (
 	"github.com/itrs-group/cordial/pkg/host"
 )
 
+// dbMutex serialises transactions between the scheduled jobs and HTTP
+// requests, as the reporting tables are rebuilt for each run and
+// concurrent writers on a shared cache SQLite database fail with
+// locking errors
+var dbMutex sync.Mutex
+
 // openDB opens the given DSN and returns both a *sql.DB object and a
 // ready to go single *sql.Conn object. Remember to close the conn
 // first, then the db, else WAL files are left behind.
@@ -55,15 +62,15 @@ import (
 // check if a `gdna-version` table already exists and then do version
 // specific updates as necessary. currently none, just update version
 func openDB(ctx context.Context, cf *config.Config, dsnBase string, readonly bool) (db *sql.DB, err error) {
-	dsn := config.Get[string](cf, dsnBase)
-	if after, ok := strings.CutPrefix(dsn, "file:"); ok {
-		// check and replace short form home in a file DSN
-		dsn = "file:" + config.ResolveHome(after)
+	name, d, err := dbDialect(cf)
+	if err != nil {
+		return
 	}
-	log.Info("opening database", slog.String("dsn", dsn))
-	db, err = sql.Open(dbtype, dsn)
+	dsn := config.Get[string](cf, dsnBase)
+	log.Info("opening database", slog.String("dialect", name), slog.String("dsn", d.redact(dsn)))
+	db, err = d.open(dsn)
 	if err != nil {
-		log.Error("cannot connect to database", slog.String("dsn", dsn), slog.Any("error", err))
+		log.Error("cannot connect to database", slog.String("dsn", d.redact(dsn)), slog.Any("error", err))
 		return
 	}
 
@@ -110,11 +117,10 @@ func openDB(ctx context.Context, cf *config.Config, dsnBase string, readonly boo
 // updateSchema applies any required schema updates to bring the database
 // schema up to the latest version
 func updateSchema(ctx context.Context, db *sql.DB, cf *config.Config) (err error) {
-	// update schema as required
-	userVersionQuery := "PRAGMA user_version"
-	userVersionUpdate := "PRAGMA user_version = %d"
+	// update schema as required. the schema version is stored in a
+	// dialect specific way, e.g. `PRAGMA user_version` for SQLite
 	var userVersion int64
-	if err = db.QueryRowContext(ctx, userVersionQuery).Scan(&userVersion); err != nil {
+	if err = db.QueryRowContext(ctx, config.Get[string](cf, cf.Join("db", "schema-version", "query"))).Scan(&userVersion); err != nil {
 		return
 	}
 	log.Debug("user_version", slog.Int64("user_version", userVersion))
@@ -168,7 +174,8 @@ func updateSchema(ctx context.Context, db *sql.DB, cf *config.Config) (err error
 
 		// update user_version
 		log.Debug("set user_version", slog.Int64("user_version", i))
-		if _, err = db.ExecContext(ctx, fmt.Sprintf(userVersionUpdate, i)); err != nil {
+		userVersionUpdate := config.Get[string](cf, cf.Join("db", "schema-version", "update"), config.LookupTable(map[string]string{"version": strconv.FormatInt(i, 10)}))
+		if _, err = db.ExecContext(ctx, userVersionUpdate); err != nil {
 			return
 		}
 	}
@@ -856,7 +863,7 @@ func updateReportingDatabase(ctx context.Context, cf *config.Config, tx *sql.Tx,
 	}
 
 	if len(validSources) == 0 {
-		rows, err := tx.QueryContext(ctx, "SELECT source FROM sources WHERE valid = 1")
+		rows, err := tx.QueryContext(ctx, "SELECT source FROM sources WHERE valid")
 		if err != nil {
 			return err
 		}
@@ -875,6 +882,10 @@ func updateReportingDatabase(ctx context.Context, cf *config.Config, tx *sql.Tx,
 	}
 
 	s := strings.Join(validSources, ", ")
+	if s == "" {
+		// an empty list is not valid SQL for all dialects
+		s = "''"
+	}
 
 	if err = execSQL(ctx, cf, tx, "db.sources", "update-valid", map[string]string{"sources": s},
 		sql.Named("oldestValidTime", oldestTimeUnix),
@@ -931,9 +942,49 @@ func updateReportingDatabase(ctx context.Context, cf *config.Config, tx *sql.Tx,
 		return
 	}
 
+	if err = recordHistory(ctx, cf, tx); err != nil {
+		return
+	}
+
+	// the alerts table is created here, even if alerts are not enabled,
+	// so that the `coverage-alerts` report always has a table to query
+	if err = execSQL(ctx, cf, tx, config.Join("db", "alerts"), "create", nil); err != nil {
+		return
+	}
+
 	return execSQL(ctx, cf, tx, config.Join("db", "reporting-updates"), "update", nil)
 }
 
+// recordHistory adds the coverage metrics from the report tables to
+// each of the `db.history-tables` and then removes rows older than
+// `gdna.history.retention`, unless that is zero. The history tables are
+// created here, and not with the main tables, so that reports can be
+// run against an existing database without a new fetch.
+func recordHistory(ctx context.Context, cf *config.Config, tx *sql.Tx) (err error) {
+	if !config.Get[bool](cf, cf.Join("gdna", "history", "enable")) {
+		return
+	}
+
+	if err = createTables(ctx, cf, tx, config.Join("db", "history-tables"), "create"); err != nil {
+		return
+	}
+
+	retention := config.Get[time.Duration](cf, cf.Join("gdna", "history", "retention"))
+	oldest := time.Now().Add(-retention).UTC().Format(time.RFC3339)
+
+	for _, table := range config.Get[[]string](cf, cf.Join("db", "history-tables")) {
+		if err = execSQL(ctx, cf, tx, config.Join("db", table), "insert", nil); err != nil {
+			return
+		}
+		if retention > 0 {
+			if err = execSQL(ctx, cf, tx, config.Join("db", table), "prune", nil, sql.Named("oldest", oldest)); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
 // execSQL is a simple wrapper to run ExecContext for the transaction tx
 // and query found in cf under `root`.`queryName` passing the arguments
 // in args. Any error is returned.
@@ -0,0 +1,287 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/reporter"
+)
+
+// testDetailReport is a licence daemon detail report in the older
+// format, without host columns. host1 has level 1 and 2 coverage, host2
+// only level 3 and host3 on gateway GW2 has no samplers.
+const testDetailReport = `id,requestingComponent,licenceComponent,component,item,description,number
+1,gateway:GW1,gateway:GW1,gateway_component,gateway,,1
+2,gateway:GW1,gateway:GW1,binary,netprobe,host1:7036 [aaaa0001],1
+3,gateway:GW1,gateway:GW1,plugin,cpu,host1:7036 [aaaa0001],1
+4,gateway:GW1,gateway:GW1,plugin,disk,host1:7036 [aaaa0001],1
+5,gateway:GW1,gateway:GW1,plugin,network,host1:7036 [aaaa0001],1
+6,gateway:GW1,gateway:GW1,plugin,hardware,host1:7036 [aaaa0001],1
+7,gateway:GW1,gateway:GW1,plugin,fkm,host1:7036 [aaaa0001],1
+8,gateway:GW1,gateway:GW1,plugin,processes,host1:7036 [aaaa0001],1
+9,gateway:GW1,gateway:GW1,binary,netprobe,host2:7036 [aaaa0002],1
+10,gateway:GW1,gateway:GW1,plugin,cpu,host2:7036 [aaaa0002],1
+11,gateway:GW1,gateway:GW1,plugin,sql-toolkit,host2:7036 [aaaa0002],2
+12,gateway:GW2,gateway:GW2,gateway_component,gateway,,1
+13,gateway:GW2,gateway:GW2,binary,netprobe,host3:7036 [aaaa0003],1
+14,gateway:GW2,gateway:GW2,ca_plugin,prometheus-plugin,entity1,1
+15,gateway:GW2,gateway:GW2,gateway-plugin,gateway-breachpredictor,,1
+`
+
+// errorHandler is a slog.Handler that records error messages, as the
+// report functions log failed queries instead of returning them
+type errorHandler struct {
+	mutex  sync.Mutex
+	errors []string
+}
+
+func (h *errorHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelError
+}
+
+func (h *errorHandler) Handle(_ context.Context, r slog.Record) error {
+	msg := r.Message
+	r.Attrs(func(a slog.Attr) bool {
+		msg += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+		return true
+	})
+	h.mutex.Lock()
+	h.errors = append(h.errors, msg)
+	h.mutex.Unlock()
+	return nil
+}
+
+func (h *errorHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *errorHandler) WithGroup(string) slog.Handler      { return h }
+
+// captureErrors replaces the package logger for the test and fails the
+// test at the end if anything was logged at error level
+func captureErrors(t *testing.T) {
+	t.Helper()
+	h := &errorHandler{}
+	saved := log
+	log = slog.New(h)
+	t.Cleanup(func() {
+		log = saved
+		for _, e := range h.errors {
+			t.Error(e)
+		}
+	})
+}
+
+// testConfig returns the built-in configuration for dialect, with the
+// database and filters file in a temporary directory, and with any
+// settings from values
+func testConfig(t *testing.T, dialect string, values map[string]any) *config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	cf, err := config.Read("gdna",
+		config.FilePath(filepath.Join(dir, "gdna.yaml")),
+		config.Format("yaml"),
+		config.WithDefaults(defaults, "yaml"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set(cf, "db.dialect", dialect)
+	config.Set(cf, "db.file", filepath.Join(dir, "gdna.sqlite"))
+	config.Set(cf, "filters.file", filepath.Join(dir, "gdna-filters.json"))
+	for k, v := range values {
+		config.Set(cf, k, v)
+	}
+	if err = applyDialect(cf); err != nil {
+		t.Fatal(err)
+	}
+	return cf
+}
+
+// openTestDB opens the database for cf, closing it at the end of the test
+func openTestDB(t *testing.T, ctx context.Context, cf *config.Config) *sql.DB {
+	t.Helper()
+	db, err := openDB(ctx, cf, "db.dsn", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// loadTestData loads testDetailReport into db and builds the reporting
+// tables, as `gdna fetch` followed by a report run would
+func loadTestData(t *testing.T, ctx context.Context, cf *config.Config, db *sql.DB) {
+	t.Helper()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err = createTables(ctx, cf, tx, "db.main-tables", "create"); err != nil {
+		t.Fatal(err)
+	}
+	c := csv.NewReader(strings.NewReader(testDetailReport))
+	c.ReuseRecord = true
+	if err = detailReportToDB(ctx, cf, tx, c, "test", "file", "testdata", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err = runPostInsertHooks(ctx, cf, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if tx, err = db.BeginTx(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = updateReportingDatabase(ctx, cf, tx, []string{"test"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// queryInt returns the single integer result of query, after expansion
+func queryInt(t *testing.T, ctx context.Context, cf *config.Config, db *sql.DB, query string) (n int) {
+	t.Helper()
+	query = config.Expand[string](cf, query)
+	if err := db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return
+}
+
+// testReports loads the test data into db, checks the coverage tables
+// and then runs all the reports, failing on any query error
+func testReports(t *testing.T, ctx context.Context, cf *config.Config, db *sql.DB) {
+	t.Helper()
+	loadTestData(t, ctx, cf, db)
+
+	counts := map[string]int{
+		"SELECT count(*) FROM ${db.sources.table} WHERE valid":              1,
+		"SELECT count(*) FROM ${db.gateways.active}":                        2,
+		"SELECT count(DISTINCT server) FROM ${db.servers.table}":            3,
+		"SELECT count(*) FROM ${db.l1covered-servers.table}":                1,
+		"SELECT count(*) FROM ${db.l2covered-servers.table}":                1,
+		"SELECT count(*) FROM ${db.l3covered-servers.table}":                1,
+		"SELECT count(*) FROM ${db.server-coverage-history.table}":          3,
+		"SELECT count(DISTINCT category) FROM ${db.coverage-history.table}": 3,
+	}
+	for query, want := range counts {
+		if got := queryInt(t, ctx, cf, db, query); got != want {
+			t.Errorf("%s = %d, want %d", query, got, want)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	r, err := reporter.NewReporter("json", io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err = runReports(ctx, cf, tx, r, "", -1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLiteReports(t *testing.T) {
+	captureErrors(t)
+	ctx := context.Background()
+	cf := testConfig(t, "sqlite", nil)
+	db := openTestDB(t, ctx, cf)
+
+	if v := queryInt(t, ctx, cf, db, "PRAGMA user_version"); v != 5 {
+		t.Errorf("new database schema version = %d, want 5", v)
+	}
+	testReports(t, ctx, cf, db)
+}
+
+func TestSQLiteSchemaUpdates(t *testing.T) {
+	captureErrors(t)
+	ctx := context.Background()
+	cf := testConfig(t, "sqlite", nil)
+
+	// build a version 0 database with the original gateways table, with
+	// untrimmed names, and the other tables as they are now
+	db, err := sql.Open(dbtype, config.Get[string](cf, "db.dsn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		`CREATE TABLE ${db.gateways.table} (gateway TEXT NOT NULL, firstSeen TIMESTAMP NOT NULL, lastSeen TIMESTAMP NOT NULL, source TEXT NOT NULL)`,
+		`INSERT INTO ${db.gateways.table} VALUES (' GW1 ', '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z', 'test')`,
+	} {
+		if _, err = db.Exec(config.Expand[string](cf, query)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = createTables(ctx, cf, tx, "db.main-tables", "create"); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db = openTestDB(t, ctx, cf)
+	if v := queryInt(t, ctx, cf, db, "PRAGMA user_version"); v != 5 {
+		t.Errorf("updated schema version = %d, want 5", v)
+	}
+	if n := queryInt(t, ctx, cf, db, "SELECT count(*) FROM pragma_table_info('${db.gateways.table}')"); n != 7 {
+		t.Errorf("gateways table has %d columns after update, want 7", n)
+	}
+	var gateway, source string
+	if err = db.QueryRow(config.Expand[string](cf, "SELECT gateway, source FROM ${db.gateways.table}")).Scan(&gateway, &source); err != nil {
+		t.Fatal(err)
+	}
+	if gateway != "GW1" || source != "test" {
+		t.Errorf("gateway row after update = %q, %q, want %q, %q", gateway, source, "GW1", "test")
+	}
+	if !config.Get[bool](cf, "db.updated") {
+		t.Error("db.updated not set after schema updates")
+	}
+
+	// and reopening does nothing more
+	config.Set(cf, "db.updated", false)
+	db.Close()
+	openTestDB(t, ctx, cf)
+	if config.Get[bool](cf, "db.updated") {
+		t.Error("schema updates applied twice")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// The SQL in the configuration is written for SQLite. Other databases
+// are supported through a dialect, which has its own way of opening the
+// database and built-in overrides for any SQL that differs. Users can
+// further override any setting for a dialect under `dialects.NAME` in
+// their configuration file.
+
+//go:embed gdna.postgres.yaml
+var postgresDefaults []byte
+
+// dialect is a supported database type
+type dialect struct {
+	// open returns a database handle for the DSN
+	open func(dsn string) (*sql.DB, error)
+
+	// redact returns the DSN with any password removed, for logging
+	redact func(dsn string) string
+
+	// glob returns SQL that is true when the value expression matches
+	// the shell style pattern expression, like the SQLite GLOB operator
+	glob func(pattern, value string) string
+
+	// defaults are the built-in configuration overrides, in YAML
+	defaults []byte
+}
+
+var dialects = map[string]dialect{
+	"sqlite": {
+		open: func(dsn string) (*sql.DB, error) {
+			if after, ok := strings.CutPrefix(dsn, "file:"); ok {
+				// check and replace short form home in a file DSN
+				dsn = "file:" + config.ResolveHome(after)
+			}
+			return sql.Open(dbtype, dsn)
+		},
+		redact: func(dsn string) string { return dsn },
+		glob:   func(pattern, value string) string { return value + " GLOB " + pattern },
+	},
+	"postgres": {
+		open:     openPostgres,
+		redact:   redactPostgresDSN,
+		glob:     postgresGlob,
+		defaults: postgresDefaults,
+	},
+}
+
+// dbDialect returns the name and details of the dialect configured in
+// `db.dialect`, defaulting to `sqlite`
+func dbDialect(cf *config.Config) (name string, d dialect, err error) {
+	name = strings.ToLower(config.Get[string](cf, cf.Join("db", "dialect"), config.DefaultValue("sqlite")))
+	if name == "postgresql" {
+		name = "postgres"
+	}
+	d, ok := dialects[name]
+	if !ok {
+		err = fmt.Errorf("unsupported db.dialect %q, must be one of %s", name, strings.Join(slices.Sorted(maps.Keys(dialects)), ", "))
+	}
+	return
+}
+
+// applyDialect merges the built-in overrides for the configured dialect
+// and then any user overrides under `dialects.NAME` into cf. Values are
+// not expanded so that references such as `${db.probes.table}` are
+// still resolved when the SQL is used.
+func applyDialect(cf *config.Config) (err error) {
+	name, d, err := dbDialect(cf)
+	if err != nil {
+		return
+	}
+
+	if len(d.defaults) > 0 {
+		var overrides map[string]any
+		if err = yaml.Unmarshal(d.defaults, &overrides); err != nil {
+			return fmt.Errorf("built-in %s dialect settings: %w", name, err)
+		}
+		if err = cf.MergeConfigMap(overrides); err != nil {
+			return
+		}
+	}
+
+	if overrides := config.Get[map[string]any](cf, cf.Join("dialects", name)); len(overrides) > 0 {
+		err = cf.MergeConfigMap(overrides)
+	}
+	return
+}
+
+var passwordRE = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// redactPostgresDSN removes the password from a PostgreSQL URL or
+// keyword/value connection string
+func redactPostgresDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if q := u.Query(); q.Has("password") {
+			q.Set("password", "xxxxx")
+			u.RawQuery = q.Encode()
+		}
+		return u.Redacted()
+	}
+	return passwordRE.ReplaceAllString(dsn, "${1}xxxxx")
+}
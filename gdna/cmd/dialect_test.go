@@ -0,0 +1,116 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+func TestDBDialect(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+		wantErr bool
+	}{
+		{"", "sqlite", false},
+		{"sqlite", "sqlite", false},
+		{"SQLite", "sqlite", false},
+		{"postgres", "postgres", false},
+		{"postgresql", "postgres", false},
+		{"mysql", "mysql", true},
+	}
+	for _, tt := range tests {
+		cf := config.New()
+		if tt.dialect != "" {
+			config.Set(cf, "db.dialect", tt.dialect)
+		}
+		name, _, err := dbDialect(cf)
+		if name != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("dbDialect(%q) = %q, %v, want %q, error %v", tt.dialect, name, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyDialect(t *testing.T) {
+	sqlite := testConfig(t, "sqlite", nil)
+	postgres := testConfig(t, "postgres", nil)
+
+	if got := config.Get[string](sqlite, "db.schema-version.query"); got != "PRAGMA user_version" {
+		t.Errorf("sqlite schema-version query = %q", got)
+	}
+	if got := config.Get[string](postgres, "db.schema-version.query"); !strings.Contains(got, "gdna_schema_version") {
+		t.Errorf("postgres schema-version query = %q", got)
+	}
+
+	// statements that are not overridden are the defaults, and
+	// overrides are not expanded when merged
+	if a, b := config.Get[string](sqlite, "db.sources.create", config.NoExpand()), config.Get[string](postgres, "db.sources.create", config.NoExpand()); a != b {
+		t.Errorf("db.sources.create differs between dialects:\n%s\n%s", a, b)
+	}
+	if got := config.Get[string](postgres, "db.sources.insert", config.NoExpand()); !strings.Contains(got, "${db.sources.table}.lastSeen") {
+		t.Errorf("postgres db.sources.insert not overridden or expanded early:\n%s", got)
+	}
+
+	// user settings for the dialect in use are applied last
+	cf := testConfig(t, "postgres", map[string]any{
+		"dialects.postgres.db.on-open": "SET search_path TO gdna",
+		"dialects.sqlite.db.on-open":   "PRAGMA foreign_keys = ON",
+	})
+	if got := config.Get[string](cf, "db.on-open"); got != "SET search_path TO gdna" {
+		t.Errorf("db.on-open with user override = %q", got)
+	}
+	if got := config.Get[string](cf, "db.samplers.insert"); !strings.Contains(got, "ON CONFLICT (gateway, plugin, probeName, probePort, tokenID)\n") {
+		t.Errorf("built-in postgres override lost after user override:\n%s", got)
+	}
+}
+
+// TestPostgresOverrides checks that every statement in the postgres
+// dialect settings replaces a default and differs from it
+func TestPostgresOverrides(t *testing.T) {
+	var base, overrides map[string]any
+	if err := yaml.Unmarshal(defaults, &base); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(postgresDefaults, &overrides); err != nil {
+		t.Fatal(err)
+	}
+
+	var walk func(path string, o, d any)
+	walk = func(path string, o, d any) {
+		om, ok := o.(map[string]any)
+		if !ok {
+			if fmt.Sprint(d) == "<nil>" {
+				t.Errorf("%s: not in the defaults", path)
+			} else if strings.Join(strings.Fields(fmt.Sprint(o)), " ") == strings.Join(strings.Fields(fmt.Sprint(d)), " ") {
+				t.Errorf("%s: same as the defaults", path)
+			}
+			return
+		}
+		dm, _ := d.(map[string]any)
+		for k, v := range om {
+			walk(strings.TrimPrefix(path+"."+k, "."), v, dm[k])
+		}
+	}
+	walk("", overrides, base)
+}
@@ -123,6 +123,9 @@ type emailData struct {
 func doEmail(ctx context.Context, cf *config.Config, db *sql.DB, reports string) (err error) {
 	log.Info("running email report")
 
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Error("cannot BEGIN transaction", slog.Any("error", err))
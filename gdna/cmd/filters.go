@@ -475,8 +475,14 @@ OUTER:
 //	   AND NOT EXISTS (SELECT gateway FROM ${filters.exclude.gateway.table} WHERE gw.gateway GLOB gateway)
 //
 // but we have to expand the table names before passing them back as
-// expand does not recurse.
+// expand does not recurse. The GLOB match is built for the database
+// dialect in use.
 func buildFilterSQL(cf *config.Config) config.ExpandOption {
+	_, d, err := dbDialect(cf)
+	if err != nil {
+		d = dialects["sqlite"]
+	}
+
 	// build a prefix "filters" that takes a table name to test and a list of filter categories,
 	// e.g. "${filter:gw:gateway,source}"
 	return config.Prefix("filters", func(_ map[string]any, s string, b bool) (r string, err error) {
@@ -489,11 +495,13 @@ func buildFilterSQL(cf *config.Config) config.ExpandOption {
 		fs := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
 
 		for _, f := range fs {
-			clauses = append(clauses, config.Expand[string](cf, fmt.Sprintf(
-				"EXISTS (SELECT %[1]s FROM ${filters.include.%[1]s.table} WHERE %[2]s.%[1]s GLOB %[1]s) "+
-					"AND NOT EXISTS (SELECT %[1]s FROM ${filters.exclude.%[1]s.table} WHERE %[2]s.%[1]s GLOB %[1]s)",
-				f, table)),
-			)
+			clauses = append(clauses, fmt.Sprintf(
+				"EXISTS (SELECT %[1]s FROM %[2]s WHERE %[4]s) AND NOT EXISTS (SELECT %[1]s FROM %[3]s WHERE %[4]s)",
+				f,
+				config.Get[string](cf, cf.Join("filters", "include", f, "table")),
+				config.Get[string](cf, cf.Join("filters", "exclude", f, "table")),
+				d.glob(f, table+"."+f),
+			))
 		}
 		r = strings.Join(clauses, " AND ")
 		return
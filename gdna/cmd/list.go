@@ -146,19 +146,30 @@ var listExcludeCmd = &cobra.Command{
 }
 
 func listReports(cf *config.Config, r reporter.Reporter) (err error) {
+	columns, rows, err := reportList(cf, reportNames)
+	if err != nil {
+		return
+	}
+	r.UpdateTable(columns, rows)
+	r.Render()
+
+	return
+}
+
+// reportList returns a table of the configured reports matching names,
+// or all reports if names is empty, ordered by group and title
+func reportList(cf *config.Config, names string) (columns []string, rows [][]string, err error) {
 	var reports []string
 	for name := range config.Get[map[string]any](cf, "reports") {
 		reports = append(reports, name)
 	}
 	slices.Sort(reports)
 
-	var rows [][]string
-
 	for _, name := range reports {
 		var rep Report
 
-		if reportNames != "" {
-			if match, _ := matchReport(name, reportNames); !match {
+		if names != "" {
+			if match, _ := matchReport(name, names); !match {
 				continue
 			}
 		}
@@ -192,8 +203,6 @@ func listReports(cf *config.Config, r reporter.Reporter) (err error) {
 		}
 		return strings.Compare(a[1], b[1])
 	})
-	r.UpdateTable([]string{"Report Name", "Group", "Title", "Type", "Dataview", "XLSX"}, rows)
-	r.Render()
-
+	columns = []string{"Report Name", "Group", "Title", "Type", "Dataview", "XLSX"}
 	return
 }
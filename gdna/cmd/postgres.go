@@ -0,0 +1,255 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// The SQL in the configuration uses SQLite style parameters, either
+// `@name` with sql.Named() arguments or `?` for positional ones. The
+// PostgreSQL driver only understands `$1`, `$2` etc. so connections
+// are wrapped to rewrite queries and reorder arguments to match.
+
+// openPostgres returns a database handle for the PostgreSQL DSN, which
+// can be a URL or a keyword/value string. Sessions use UTC unless the
+// DSN sets a `timezone`, as GDNA stores times as UTC without a zone.
+func openPostgres(dsn string) (db *sql.DB, err error) {
+	pc, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return
+	}
+	if _, ok := pc.RuntimeParams["timezone"]; !ok {
+		pc.RuntimeParams["timezone"] = "UTC"
+	}
+	// tables are dropped and recreated on every run, which invalidates
+	// cached statements, so describe each one instead
+	pc.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	return sql.OpenDB(&pgConnector{stdlib.GetConnector(*pc)}), nil
+}
+
+type pgConnector struct {
+	driver.Connector
+}
+
+func (c *pgConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgConn{conn.(*stdlib.Conn)}, nil
+}
+
+// pgConn rewrites parameters in queries, all other methods are those of
+// the pgx connection
+type pgConn struct {
+	*stdlib.Conn
+}
+
+func (c *pgConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, names := rebind(query)
+	return c.Conn.ExecContext(ctx, query, bindArgs(names, args))
+}
+
+func (c *pgConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, names := rebind(query)
+	return c.Conn.QueryContext(ctx, query, bindArgs(names, args))
+}
+
+func (c *pgConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *pgConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query, names := rebind(query)
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &pgStmt{stmt.(*stdlib.Stmt), names}, nil
+}
+
+// pgStmt reorders arguments to match the parameters in the prepared
+// query
+type pgStmt struct {
+	*stdlib.Stmt
+	names []string
+}
+
+// NumInput returns -1 so that database/sql does not check the number
+// of arguments, as unused named arguments are allowed
+func (s *pgStmt) NumInput() int {
+	return -1
+}
+
+func (s *pgStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.Stmt.ExecContext(ctx, bindArgs(s.names, args))
+}
+
+func (s *pgStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.Stmt.QueryContext(ctx, bindArgs(s.names, args))
+}
+
+// rebind replaces the `@name` and `?` parameters in query with `$N`
+// and returns the new query and the parameter names in order, with an
+// empty name for positional parameters. Quoted strings, identifiers,
+// dollar quoted bodies and comments are copied unchanged. Repeated
+// names use the same number.
+func rebind(query string) (string, []string) {
+	if !strings.ContainsAny(query, "@?") {
+		return query, nil
+	}
+
+	var b strings.Builder
+	var names []string
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(query[i+1:], ch)
+			if end == -1 {
+				b.WriteString(query[i:])
+				return b.String(), names
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case ch == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+		case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				b.WriteString(query[i:])
+				return b.String(), names
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 3
+		case ch == '$':
+			// a dollar quote is `$tag$` where tag may be empty,
+			// anything else (such as `$1`) is copied as-is
+			tagEnd := strings.IndexByte(query[i+1:], '$')
+			if tagEnd == -1 || !isIdent(query[i+1:i+1+tagEnd]) {
+				b.WriteByte(ch)
+				continue
+			}
+			tag := query[i : i+tagEnd+2]
+			end := strings.Index(query[i+len(tag):], tag)
+			if end == -1 {
+				b.WriteString(query[i:])
+				return b.String(), names
+			}
+			b.WriteString(query[i : i+len(tag)+end+len(tag)])
+			i += len(tag) + end + len(tag) - 1
+		case ch == '?':
+			names = append(names, "")
+			b.WriteString("$" + strconv.Itoa(len(names)))
+		case ch == '@' && i+1 < len(query) && isIdentStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isIdentChar(query[j]) {
+				j++
+			}
+			name := query[i+1 : j]
+			n := len(names) + 1
+			for k, m := range names {
+				if m == name {
+					n = k + 1
+					break
+				}
+			}
+			if n > len(names) {
+				names = append(names, name)
+			}
+			b.WriteString("$" + strconv.Itoa(n))
+			i = j - 1
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String(), names
+}
+
+// bindArgs returns args in the order of the parameter names. Named
+// arguments go to the parameter with the same name, or are dropped if
+// there is none, and positional arguments fill the positional
+// parameters in order. Parameters without an argument are NULL, as
+// they are in SQLite.
+func bindArgs(names []string, args []driver.NamedValue) []driver.NamedValue {
+	if len(names) == 0 {
+		return args
+	}
+	bound := make([]driver.NamedValue, len(names))
+	var positional []driver.NamedValue
+	for _, a := range args {
+		if a.Name == "" {
+			positional = append(positional, a)
+		}
+	}
+	for i, name := range names {
+		bound[i].Ordinal = i + 1
+		if name == "" {
+			if len(positional) > 0 {
+				bound[i].Value = positional[0].Value
+				positional = positional[1:]
+			}
+			continue
+		}
+		for _, a := range args {
+			if a.Name == name {
+				bound[i].Value = a.Value
+				break
+			}
+		}
+	}
+	return bound
+}
+
+// postgresGlob returns SQL that is true when value matches the SQLite
+// GLOB style pattern, case sensitively, by converting the pattern to a
+// regular expression
+func postgresGlob(pattern, value string) string {
+	return fmt.Sprintf(`%s COLLATE "C" ~ ('^' || replace(replace(regexp_replace(%s, '([.+$(){}|\\])', '\\\1', 'g'), '*', '.*'), '?', '.') || '$')`, value, pattern)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func isIdent(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) || i == 0 && !isIdentStart(s[i]) {
+			return false
+		}
+	}
+	return true
+}
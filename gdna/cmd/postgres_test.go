@@ -0,0 +1,175 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		names []string
+	}{
+		{"SELECT 1", "SELECT 1", nil},
+		{"SELECT ?, ?", "SELECT $1, $2", []string{"", ""}},
+		{"SELECT @a, @b, @a", "SELECT $1, $2, $1", []string{"a", "b"}},
+		{"SELECT @a, ?", "SELECT $1, $2", []string{"a", ""}},
+		{"SELECT '?', '@a', @b", "SELECT '?', '@a', $1", []string{"b"}},
+		{`SELECT "a?" FROM t WHERE x = ?`, `SELECT "a?" FROM t WHERE x = $1`, []string{""}},
+		{"SELECT ? -- why?\n, @x /* @y? */", "SELECT $1 -- why?\n, $2 /* @y? */", []string{"", "x"}},
+		{"DO $$ BEGIN PERFORM ?; END $$; SELECT ?", "DO $$ BEGIN PERFORM ?; END $$; SELECT $1", []string{""}},
+		{"SELECT $body$ @a $body$, @a", "SELECT $body$ @a $body$, $1", []string{"a"}},
+		{"SELECT $1 WHERE x = ?", "SELECT $1 WHERE x = $1", []string{""}},
+		{"SELECT 'user@example.com', @ 1", "SELECT 'user@example.com', @ 1", nil},
+		{"SELECT 'unterminated ?", "SELECT 'unterminated ?", nil},
+	}
+	for _, tt := range tests {
+		got, names := rebind(tt.query)
+		if got != tt.want || !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("rebind(%q) = %q, %q, want %q, %q", tt.query, got, names, tt.want, tt.names)
+		}
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	args := []driver.NamedValue{
+		{Ordinal: 1, Name: "b", Value: "B"},
+		{Ordinal: 2, Value: 1},
+		{Ordinal: 3, Name: "a", Value: "A"},
+		{Ordinal: 4, Value: 2},
+		{Ordinal: 5, Name: "unused", Value: "X"},
+	}
+	got := bindArgs([]string{"a", "", "b", "", "a", "missing", ""}, args)
+	want := []driver.NamedValue{
+		{Ordinal: 1, Value: "A"},
+		{Ordinal: 2, Value: 1},
+		{Ordinal: 3, Value: "B"},
+		{Ordinal: 4, Value: 2},
+		{Ordinal: 5, Value: "A"},
+		{Ordinal: 6},
+		{Ordinal: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bindArgs() = %v, want %v", got, want)
+	}
+
+	if got := bindArgs(nil, args); !reflect.DeepEqual(got, args) {
+		t.Errorf("bindArgs() with no parameter names = %v, want args unchanged", got)
+	}
+}
+
+func TestRedactPostgresDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"postgres://gdna:secret@db:5432/gdna", "postgres://gdna:xxxxx@db:5432/gdna"},
+		{"postgres://gdna@db/gdna?password=secret&sslmode=disable", "postgres://gdna@db/gdna?password=xxxxx&sslmode=disable"},
+		{"postgres://db/gdna", "postgres://db/gdna"},
+		{"host=db user=gdna password=secret dbname=gdna", "host=db user=gdna password=xxxxx dbname=gdna"},
+		{"host=db PASSWORD = 'my secret' dbname=gdna", "host=db PASSWORD = xxxxx dbname=gdna"},
+		{"host=db dbname=gdna", "host=db dbname=gdna"},
+	}
+	for _, tt := range tests {
+		if got := redactPostgresDSN(tt.dsn); got != tt.want {
+			t.Errorf("redactPostgresDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+// testPostgres returns the connection string for an empty PostgreSQL
+// schema, removed at the end of the test. The server is the one in the
+// GDNA_TEST_POSTGRES environment variable, as a URL, and the test is
+// skipped if it is not set or the server is not available.
+func testPostgres(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv("GDNA_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("set GDNA_TEST_POSTGRES to test against a PostgreSQL server")
+	}
+
+	db, err := openPostgres(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var icu bool
+	if err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_collation WHERE collprovider = 'i')").Scan(&icu); err != nil {
+		t.Skipf("cannot connect to PostgreSQL: %v", err)
+	}
+	if !icu {
+		t.Skip("PostgreSQL server built without ICU collations")
+	}
+
+	schema := fmt.Sprintf("gdna_test_%d", time.Now().UnixNano())
+	if _, err = db.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db, err := openPostgres(dsn)
+		if err != nil {
+			return
+		}
+		defer db.Close()
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func TestPostgresReports(t *testing.T) {
+	dsn := testPostgres(t)
+	captureErrors(t)
+	ctx := context.Background()
+	cf := testConfig(t, "postgres", map[string]any{"db.dsn": dsn, "alerts.enable": true})
+	db := openTestDB(t, ctx, cf)
+
+	// new databases start at the baseline with no updates applied
+	if v := queryInt(t, ctx, cf, db, config.Get[string](cf, "db.schema-version.query")); v != 5 {
+		t.Errorf("new database schema version = %d, want 5", v)
+	}
+	if config.Get[bool](cf, "db.updated") {
+		t.Error("schema updates applied to a new database")
+	}
+	testReports(t, ctx, cf, db)
+	if err := processAlerts(ctx, cf, db); err != nil {
+		t.Fatal(err)
+	}
+
+	// the on-open statements can be run again
+	db.Close()
+	openTestDB(t, ctx, cf)
+}
@@ -23,6 +23,7 @@ import (
 	"database/sql"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -47,6 +48,7 @@ var reportCmdDescription string
 var outputFormat, reportNames, output string
 var outputZip bool
 var resetViews, scrambleNames, reportFetch bool
+var reportSince string
 
 // Reporter is the GDNA specific reporter struct
 type Report struct {
@@ -74,7 +76,7 @@ func init() {
 	Cmd.AddCommand(reportCmd)
 
 	reportCmd.Flags().StringVarP(&output, "output", "o", "-", "output destination `file`, default is console (stdout)")
-	reportCmd.Flags().StringVarP(&outputFormat, "format", "F", "dataview", "output `format` - one of: dataview, table, html, markdown,\ntoolkit, csv, xslx")
+	reportCmd.Flags().StringVarP(&outputFormat, "format", "F", "dataview", "output `format` - one of: dataview, table, html, markdown,\ntoolkit, csv, json, xslx")
 	reportCmd.Flags().BoolVarP(&outputZip, "zip", "Z", false, "Compress report output into a ZIP archive (only for table, html, markdown and csv formats)")
 
 	reportCmd.Flags().BoolVarP(&reportFetch, "adhoc", "A", false, "Ad-hoc reporting: Fetch license reports, build data in-memory and report\n(default format CSV, dataview output not supported)")
@@ -82,6 +84,7 @@ func init() {
 
 	reportCmd.Flags().StringVarP(&reportNames, "reports", "r", "", reportNamesDescription)
 	reportCmd.Flags().BoolVarP(&scrambleNames, "scramble", "S", false, "Scramble configured column of data in reports with sensitive data")
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Compare coverage history with `DATE` (YYYY-MM-DD or RFC3339)\nor a duration ago, e.g. 720h (default from gdna.history.since)")
 
 	reportCmd.Flags().StringVarP(&netprobeHost, "hostname", "H", "localhost", "Connect to netprobe at `hostname`")
 	reportCmd.Flags().Int16VarP(&netprobePort, "port", "P", 7036, "Connect to netprobe on `port`")
@@ -128,10 +131,15 @@ var reportCmd = &cobra.Command{
 		cf.BindPFlag("geneos.netprobe.skip-verify", cmd.Flags().Lookup("skip-verify"))
 		cf.BindPFlag("geneos.entity", cmd.Flags().Lookup("entity"))
 		cf.BindPFlag("geneos.sampler", cmd.Flags().Lookup("sampler"))
+		cf.BindPFlag("gdna.history.since", cmd.Flags().Lookup("since"))
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		var db *sql.DB
 
+		if _, err = historySince(cf); err != nil {
+			return
+		}
+
 		// Handle SIGINT (CTRL+C) gracefully.
 		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 		defer stop()
@@ -195,8 +203,6 @@ var reportCmd = &cobra.Command{
 
 func report(ctx context.Context, cf *config.Config, tx *sql.Tx, w io.Writer, format string, reports string) (err error) {
 	var z *zip.Writer
-	var r reporter.Reporter
-	maxreports := -1
 
 	if outputZip {
 		z = zip.NewWriter(w)
@@ -204,9 +210,26 @@ func report(ctx context.Context, cf *config.Config, tx *sql.Tx, w io.Writer, for
 		// file is closed by reporter.Close()
 	}
 
+	r, maxreports, err := newReporter(cf, w, z, format, reports)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	defer r.Render()
+
+	return runReports(ctx, cf, tx, r, reports, maxreports)
+}
+
+// newReporter returns a reporter for format writing to w, or z if not
+// nil, with the options from the configuration cf. maxreports is the
+// number of reports the format can hold, or -1 for no limit. The csv
+// and toolkit formats require reports to be set.
+func newReporter(cf *config.Config, w io.Writer, z *zip.Writer, format string, reports string) (r reporter.Reporter, maxreports int, err error) {
+	maxreports = -1
+
 	switch format {
 	case "csv":
-		if !outputZip {
+		if z == nil {
 			// csv format only allows one report and that must be
 			// selected on the command line
 			if reports == "" {
@@ -233,6 +256,10 @@ func report(ctx context.Context, cf *config.Config, tx *sql.Tx, w io.Writer, for
 		}
 		maxreports = 1
 		r, _ = reporter.NewReporter("toolkit", w)
+	case "json":
+		r, _ = reporter.NewReporter("json", w,
+			reporter.Scramble(scrambleNames),
+		)
 	case "table", "html", "tsv", "markdown", "md":
 		r, _ = reporter.NewReporter(format, w,
 			reporter.Scramble(scrambleNames),
@@ -276,10 +303,7 @@ func report(ctx context.Context, cf *config.Config, tx *sql.Tx, w io.Writer, for
 			return
 		}
 	}
-	defer r.Close()
-	defer r.Render()
-
-	return runReports(ctx, cf, tx, r, reports, maxreports)
+	return
 }
 
 // matchReport checks if report name matches any component of pattern.
@@ -429,7 +453,7 @@ func runReports(ctx context.Context, cf *config.Config, tx *sql.Tx, r reporter.R
 	return nil
 }
 
-func reportLookupTable(report, group string, scramble bool) (lookupTable map[string]string) {
+func reportLookupTable(cf *config.Config, report, group string, scramble bool) (lookupTable map[string]string) {
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "UNKNOWN"
@@ -457,6 +481,26 @@ func reportLookupTable(report, group string, scramble bool) (lookupTable map[str
 		"report-group": group,
 	}
 
+	if since, err := historySince(cf); err == nil {
+		lookupTable["since"] = since.UTC().Format(time.RFC3339)
+	}
+
+	return
+}
+
+// historySince returns the time from `gdna.history.since`, which is
+// either a date, an RFC3339 timestamp or a duration before now
+func historySince(cf *config.Config) (since time.Time, err error) {
+	s := config.Get[string](cf, cf.Join("gdna", "history", "since"), config.DefaultValue("168h"))
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if since, err = time.Parse(time.DateOnly, s); err == nil {
+		return
+	}
+	if since, err = time.Parse(time.RFC3339, s); err != nil {
+		err = fmt.Errorf("invalid history since %q: must be a date, an RFC3339 timestamp or a duration", s)
+	}
 	return
 }
 
@@ -464,7 +508,7 @@ func publishReport(ctx context.Context, cf *config.Config, tx *sql.Tx, r reporte
 	var err error
 
 	if report.FilePath != "" {
-		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames)))
+		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames)))
 	} else {
 		// generate filepath from report name
 		report.FilePath = strings.ReplaceAll(report.Name, " ", "_") + "." + r.Extension()
@@ -479,7 +523,7 @@ func publishReport(ctx context.Context, cf *config.Config, tx *sql.Tx, r reporte
 			r.AddHeadline("scrambledColumns", strings.Join(report.ScrambleColumns, ","))
 		}
 	}
-	lookup := config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames))
+	lookup := config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames))
 
 	query := config.Expand[string](cf, report.Query, lookup, config.ExpandNonStringToCSV())
 	log.Debug("query", slog.String("query", query))
@@ -510,7 +554,7 @@ func publishReportIndirect(ctx context.Context, cf *config.Config, tx *sql.Tx, r
 	var err error
 
 	if report.FilePath != "" {
-		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames)))
+		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames)))
 	} else {
 		// generate filepath from report name
 		report.FilePath = strings.ReplaceAll(report.Name, " ", "_") + "." + r2.Extension()
@@ -525,7 +569,7 @@ func publishReportIndirect(ctx context.Context, cf *config.Config, tx *sql.Tx, r
 			r2.AddHeadline("scrambledColumns", strings.Join(report.ScrambleColumns, ","))
 		}
 	}
-	lookup := config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames))
+	lookup := config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames))
 
 	prequery := config.Expand[string](cf, report.Query, lookup, config.ExpandNonStringToCSV())
 	r := tx.QueryRowContext(ctx, prequery)
@@ -568,7 +612,7 @@ func publishReportPluginGroups(ctx context.Context, cf *config.Config, tx *sql.T
 	table := [][]string{report.Columns}
 
 	if report.FilePath != "" {
-		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames)))
+		report.FilePath = config.Expand[string](cf, report.FilePath, config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames)))
 	} else {
 		// generate filepath from report name
 		report.FilePath = strings.ReplaceAll(report.Name, " ", "_") + "." + r.Extension()
@@ -583,7 +627,7 @@ func publishReportPluginGroups(ctx context.Context, cf *config.Config, tx *sql.T
 			r.AddHeadline("scrambledColumns", strings.Join(report.ScrambleColumns, ","))
 		}
 	}
-	lookup := config.LookupTable(reportLookupTable(report.Dataview.Group, report.Title, scrambleNames))
+	lookup := config.LookupTable(reportLookupTable(cf, report.Dataview.Group, report.Title, scrambleNames))
 
 	groups := config.Get[map[string]string](cf, report.Grouping)
 
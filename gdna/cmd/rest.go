@@ -1,7 +1,357 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
 package cmd
 
-// REST API to control limited features
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/reporter"
+)
+
+// The HTTP API serves the reports and source status from the running
+// `gdna start` process. All requests must be authenticated with the
+// configured token, either as a bearer token or as the password for
+// basic authentication, so that the web view works in a browser.
+
+//go:embed _web/index.html
+var indexHTML string
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+
+// contentTypes are the formats that can be requested with the `format`
+// query parameter and their content types
+var contentTypes = map[string]string{
+	"json":     "application/json",
+	"csv":      "text/csv; charset=utf-8",
+	"xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"html":     "text/html; charset=utf-8",
+	"table":    "text/plain; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"tsv":      "text/tab-separated-values; charset=utf-8",
+	"toolkit":  "text/plain; charset=utf-8",
+}
+
+// restServer holds the state for HTTP handlers
+type restServer struct {
+	cf *config.Config
+	db *sql.DB
+}
+
+// startHTTP starts the HTTP server on the address in `gdna.http.listen`
+// and shuts it down when ctx is cancelled. An authentication token must
+// be configured.
+func startHTTP(ctx context.Context, cf *config.Config, db *sql.DB) (err error) {
+	listen := config.Get[string](cf, cf.Join("gdna", "http", "listen"))
+	token := config.Get[config.Secret](cf, cf.Join("gdna", "http", "token"))
+	if len(token) == 0 {
+		return errors.New("gdna.http.token must be set to serve HTTP requests")
+	}
+
+	base := config.Get[string](cf, cf.Join("gdna", "http", "path"), config.DefaultValue("/"))
+	base = "/" + strings.Trim(base, "/")
+	if base != "/" {
+		base += "/"
+	}
+
+	s := &restServer{cf: cf, db: db}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+base+"{$}", s.index)
+	mux.HandleFunc("GET "+base+"api/reports", s.reports)
+	mux.HandleFunc("GET "+base+"api/reports/{name}", s.report)
+	mux.HandleFunc("GET "+base+"api/sources", s.sources)
+
+	srv := &http.Server{
+		Handler:           withRequestLog(withTokenAuth(token, mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return
+	}
+
+	if config.Get[bool](cf, cf.Join("gdna", "http", "tls", "enabled")) {
+		cert, err := tls.X509KeyPair(
+			config.Get[[]byte](cf, cf.Join("gdna", "http", "tls", "certificate")),
+			config.Get[[]byte](cf, cf.Join("gdna", "http", "tls", "private-key")),
+		)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		srv.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		ln = tls.NewListener(ln, srv.TLSConfig)
+	}
+
+	go func() {
+		log.Info("serving HTTP requests", slog.String("listen", ln.Addr().String()), slog.String("path", base), slog.Bool("tls", srv.TLSConfig != nil))
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("HTTP server failed", slog.Any("error", err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	return
+}
+
+// withTokenAuth rejects requests that do not carry token, either as a
+// bearer token or as a basic authentication password
+func withTokenAuth(token []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var key string
+		if _, password, ok := r.BasicAuth(); ok {
+			key = password
+		} else if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = strings.TrimSpace(auth)
+		}
+		if subtle.ConstantTimeCompare([]byte(key), token) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gdna"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder saves the response status for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func withRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sr, r)
+		log.Info("request completed",
+			slog.String("method", r.Method),
+			slog.String("url", r.URL.String()),
+			slog.Int("status", sr.status),
+			slog.String("remote", r.RemoteAddr),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// requestFormat returns the output format from the request, defaulting
+// to json, or an error if it is not supported
+func requestFormat(r *http.Request) (format string, err error) {
+	format = r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if _, ok := contentTypes[format]; !ok {
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	return
+}
+
+// writeOutput sends the rendered output in buf with the content type
+// for format. csv and xlsx are sent as attachments named after name.
+func writeOutput(w http.ResponseWriter, name, format string, buf *bytes.Buffer) {
+	w.Header().Set("Content-Type", contentTypes[format])
+	if format == "csv" || format == "xlsx" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", strings.ReplaceAll(name, " ", "_")+"."+format))
+	}
+	w.Write(buf.Bytes())
+}
+
+// writeTable renders a single table in the requested format
+func (s *restServer) writeTable(w http.ResponseWriter, r *http.Request, report reporter.Report, columns []string, rows [][]string) {
+	format, err := requestFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if format == "html" {
+		if err = indexTemplate.ExecuteTemplate(&buf, "head", report.Title); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	rep, _, err := newReporter(s.cf, &buf, nil, format, report.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = rep.Prepare(report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rep.UpdateTable(columns, rows)
+	rep.Render()
+	rep.Close()
+	if format == "html" {
+		indexTemplate.ExecuteTemplate(&buf, "tail", nil)
+	}
+	writeOutput(w, report.Name, format, &buf)
+}
+
+// reports lists the configured reports
+func (s *restServer) reports(w http.ResponseWriter, r *http.Request) {
+	columns, rows, err := reportList(s.cf, r.URL.Query().Get("reports"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writeTable(w, r, reporter.Report{Name: "reports", Title: "Reports"}, columns, rows)
+}
+
+// report runs the reports matching the `name` path value, which can be
+// a comma separated list of patterns as for the `--reports` flag, and
+// returns the results in the requested format. The optional `since`
+// parameter is as for the `--since` flag.
+func (s *restServer) report(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	format, err := requestFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, rows, err := reportList(s.cf, name); err != nil || len(rows) == 0 {
+		http.Error(w, fmt.Sprintf("no reports match %q", name), http.StatusNotFound)
+		return
+	}
+
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+
+	// reports only run while holding dbMutex, so the `since` parameter
+	// can override the configured value for the duration of the request
+	if since := r.URL.Query().Get("since"); since != "" {
+		key := s.cf.Join("gdna", "history", "since")
+		defer config.Set(s.cf, key, config.Get[string](s.cf, key))
+		config.Set(s.cf, key, since)
+		if _, err = historySince(s.cf); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error("cannot BEGIN transaction", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if err = updateReportingDatabase(ctx, s.cf, tx, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if format == "html" {
+		indexTemplate.ExecuteTemplate(&buf, "head", name)
+	}
+	if err = report(ctx, s.cf, tx, &buf, format, name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if format == "html" {
+		indexTemplate.ExecuteTemplate(&buf, "tail", nil)
+	}
+	writeOutput(w, name, format, &buf)
+}
+
+// sources returns the status of each source from the sources table
+func (s *restServer) sources(w http.ResponseWriter, r *http.Request) {
+	table, err := s.sourcesTable(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writeTable(w, r, reporter.Report{Name: "sources", Title: "Sources"}, table[0], table[1:])
+}
+
+// sourcesTable returns the contents of the sources table, with the
+// column names as the first row
+func (s *restServer) sourcesTable(ctx context.Context) (table [][]string, err error) {
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	query := config.Expand[string](s.cf, `SELECT ${db.sources.columns} FROM ${db.sources.table} ORDER BY source`)
+	return queryToTable(ctx, tx, nil, query)
+}
 
-// `ignore` set
+// index renders the web view, with the sources and links to each report
+func (s *restServer) index(w http.ResponseWriter, r *http.Request) {
+	sources, err := s.sourcesTable(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	columns, reports, err := reportList(s.cf, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-// `grouping` set
+	var buf bytes.Buffer
+	if err = indexTemplate.Execute(&buf, map[string]any{
+		"Site":          config.Get[string](s.cf, s.cf.Join("gdna", "site-name")),
+		"Time":          time.Now().Format(time.RFC3339),
+		"SourceColumns": sources[0],
+		"Sources":       sources[1:],
+		"ReportColumns": columns,
+		"Reports":       reports,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeOutput(w, "index", "html", &buf)
+}
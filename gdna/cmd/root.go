@@ -147,6 +147,11 @@ func initConfig(cmd *cobra.Command) {
 			os.Exit(1)
 		}
 
+		if err = applyDialect(cf); err != nil {
+			log.Error("loading database dialect settings", slog.Any("error", err))
+			os.Exit(1)
+		}
+
 		// save log for after log setup
 		deferredlog = fmt.Sprintf("configuration loaded from %s",
 			config.Path(execname,
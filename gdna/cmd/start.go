@@ -38,6 +38,7 @@ var once bool
 var netprobeHost, entity, sampler string
 var netprobePort int16
 var secure, skipVerify, onStart, onStartEMail bool
+var httpListen string
 
 func init() {
 	Cmd.AddCommand(startCmd)
@@ -57,6 +58,8 @@ func init() {
 	startCmd.Flags().StringVarP(&sampler, "sampler", "s", "GDNA", "Send reports to `Sampler`")
 	startCmd.Flags().BoolVarP(&resetViews, "reset", "R", false, "Reset/Delete configured Dataviews on first run")
 
+	startCmd.Flags().StringVar(&httpListen, "http", "", "Serve the HTTP API and web view on `address`, e.g. ':8080'.\nRequires gdna.http.token to be set")
+
 	startCmd.Flags().SortFlags = false
 }
 
@@ -87,6 +90,7 @@ var startCmd = &cobra.Command{
 		cf.BindPFlag("geneos.netprobe.skip-verify", cmd.Flags().Lookup("skip-verify"))
 		cf.BindPFlag("geneos.entity", cmd.Flags().Lookup("entity"))
 		cf.BindPFlag("geneos.sampler", cmd.Flags().Lookup("sampler"))
+		cf.BindPFlag("gdna.http.listen", cmd.Flags().Lookup("http"))
 	},
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		c := make(chan os.Signal, 1)
@@ -110,6 +114,13 @@ func start() (err error) {
 		return do(ctx, cf, db)
 	}
 
+	if config.Get[string](cf, cf.Join("gdna", "http", "listen")) != "" {
+		if err = startHTTP(ctx, cf, db); err != nil {
+			log.Error("starting HTTP server", slog.Any("error", err))
+			return
+		}
+	}
+
 	sched, err = gocron.NewScheduler(gocron.WithLimitConcurrentJobs(1, gocron.LimitModeWait))
 	if err != nil {
 		return
@@ -139,7 +150,12 @@ func start() (err error) {
 		}
 	} else {
 		sched.NewJob(gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()),
-			gocron.NewTask(fetch, ctx, cf, db),
+			gocron.NewTask(func() error {
+				dbMutex.Lock()
+				defer dbMutex.Unlock()
+				_, err := fetch(ctx, cf, db)
+				return err
+			}),
 			gocron.WithName("initial fetch"),
 			gocron.WithSingletonMode(gocron.LimitModeReschedule),
 			listeners,
@@ -254,6 +270,9 @@ func afterjoberr(_ uuid.UUID, jobName string, err error) {
 }
 
 func do(ctx context.Context, cf *config.Config, db *sql.DB) (err error) {
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+
 	sources, err := fetch(ctx, cf, db)
 	if err != nil {
 		return
@@ -276,6 +295,11 @@ func do(ctx context.Context, cf *config.Config, db *sql.DB) (err error) {
 		return
 	}
 
+	// alert errors are logged but do not stop the reports
+	if err := processAlerts(ctx, cf, db); err != nil {
+		log.Error("processing alerts", slog.Any("error", err))
+	}
+
 	tx, err = db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Error("cannot BEGIN transaction", slog.Any("error", err))
@@ -21,4 +21,5 @@ import "errors"
 
 var (
 	ErrInvalidArgs = errors.New("invalid arguments")
+	ErrNotFound    = errors.New("not found")
 )
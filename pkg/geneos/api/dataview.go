@@ -48,9 +48,10 @@ func NewDataview(c APIClient, entity, sampler, typeName, groupHeading, viewName
 	}
 
 	view = &Dataview{
-		Entity:  entity,
-		Sampler: sampler,
-		Name:    viewName,
+		APIClient: c,
+		Entity:    entity,
+		Sampler:   sampler,
+		Name:      viewName,
 	}
 	exists, err := view.Exists()
 	if err != nil && !errors.Is(err, errors.ErrUnsupported) {
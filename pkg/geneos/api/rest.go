@@ -19,8 +19,12 @@ package api
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/itrs-group/cordial/pkg/rest"
 )
@@ -35,17 +39,65 @@ import (
 // - Delete row
 // - Create or update stream
 // - Healthcheck
+//
+// The documented entry points do not cover every method in the
+// APIClient interface, so the following are also used, and are
+// implemented by the resttest fake Netprobe:
+//
+// - Get managed entity, sampler or dataview (for the *Exists methods)
+// - Delete headline
 
+// RESTClient is a client for the Netprobe REST API. Like the
+// XMLRPCClient it can be used to connect to multiple entities and
+// samplers on the same Netprobe.
+//
+// The REST API identifies cells by column name and not by position, so
+// the client caches the column names of each dataview, as fetched from
+// the Netprobe. As the Netprobe appends new columns to those it already
+// has, the cache is dropped after any change to the columns and
+// fetched again before the next UpdateRow.
 type RESTClient struct {
 	*rest.Client
+
+	mutex   sync.Mutex
+	columns map[string][]string
 }
 
 // check we implement all methods
 var _ APIClient = (*RESTClient)(nil)
 
+// RESTDataview is the JSON representation of the contents of a
+// dataview, as sent to and received from the Netprobe REST API. The
+// first element of Columns is the row name column heading.
+//
+// Rows is always sent, and replaces all the existing rows, so an empty
+// or nil Rows clears the dataview.
+type RESTDataview struct {
+	Columns   []string                     `json:"columns,omitempty"`
+	Rows      map[string]map[string]string `json:"rows"`
+	Headlines map[string]string            `json:"headlines,omitempty"`
+}
+
+// restDataviewUpdate is a partial update of a dataview that leaves the
+// existing rows unchanged
+type restDataviewUpdate struct {
+	Columns   []string          `json:"columns,omitempty"`
+	Headlines map[string]string `json:"headlines,omitempty"`
+}
+
+// RESTRequest wraps data sent to the Netprobe REST API
+type RESTRequest struct {
+	Data any `json:"data"`
+}
+
+// NewRESTClient returns a new client that uses endpoint, which would
+// normally end in `/v1`, configured with any options.
 func NewRESTClient(endpoint string, options ...rest.Option) (c APIClient, err error) {
 	options = append(options, rest.BaseURLString(endpoint))
-	return &RESTClient{Client: rest.NewClient(options...)}, nil
+	return &RESTClient{
+		Client:  rest.NewClient(options...),
+		columns: make(map[string][]string),
+	}, nil
 }
 
 func (c *RESTClient) Healthy() bool {
@@ -57,77 +109,280 @@ func (c *RESTClient) Healthy() bool {
 }
 
 func (c *RESTClient) CreateDataview(entity, sampler, name string) (err error) {
+	if entity == "" || sampler == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", name), RESTRequest{Data: restDataviewUpdate{}}, nil)
 	return
 }
 
+// UpdateDataview replaces the rows of the dataview with values. As for
+// the XML-RPC API the first row of values must be the column headings
+// and the first column of each subsequent row is the row name. Each row
+// must have a value for every column. If there are no rows then the
+// dataview is cleared.
 func (c *RESTClient) UpdateDataview(entity, sampler, name string, values [][]string) (err error) {
+	if entity == "" || sampler == "" || name == "" || len(values) == 0 {
+		return ErrInvalidArgs
+	}
+	columns := values[0]
+	dv := RESTDataview{
+		Columns: columns,
+		Rows:    make(map[string]map[string]string, len(values)-1),
+	}
+	for _, row := range values[1:] {
+		if len(row) == 0 {
+			continue
+		}
+		if dv.Rows[row[0]], err = rowCells(columns, row[1:]); err != nil {
+			return
+		}
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", name), RESTRequest{Data: dv}, nil)
+	c.setColumns(entity, sampler, name, nil)
 	return
 }
 
 func (c *RESTClient) DeleteDataview(entity, sampler, name string) (err error) {
+	if entity == "" || sampler == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	if _, err = c.Delete(context.Background(), restPath(entity, sampler, "dataview", name), nil); err != nil {
+		return
+	}
+	c.setColumns(entity, sampler, name, nil)
 	return
 }
 
 func (c *RESTClient) CreateRow(entity, sampler, view, name string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", view, "row", name), RESTRequest{Data: map[string]string{}}, nil)
 	return
 }
 
+// UpdateRow sets the cells of row name to values, in column order and
+// not including the row name. There must be one value for each column
+// of the dataview.
 func (c *RESTClient) UpdateRow(entity, sampler, view, name string, values []string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	columns, err := c.getColumns(entity, sampler, view)
+	if err != nil {
+		return
+	}
+	cells, err := rowCells(columns, values)
+	if err != nil {
+		return
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", view, "row", name), RESTRequest{Data: cells}, nil)
 	return
 }
 
 func (c *RESTClient) DeleteRow(entity, sampler, view, name string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Delete(context.Background(), restPath(entity, sampler, "dataview", view, "row", name), nil)
 	return
 }
 
+// CreateColumn adds a column to the dataview. Columns are only ever
+// appended and existing columns are left unchanged. As for the XML-RPC
+// API, the first column of a dataview is the row name heading.
 func (c *RESTClient) CreateColumn(entity, sampler, view, name string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", view), RESTRequest{Data: restDataviewUpdate{Columns: []string{name}}}, nil)
+	c.setColumns(entity, sampler, view, nil)
 	return
 }
 
 func (c *RESTClient) CreateHeadline(entity, sampler, view, name string) (err error) {
-	return
+	return c.UpdateHeadline(entity, sampler, view, name, "")
 }
 
 func (c *RESTClient) UpdateHeadline(entity, sampler, view, name, value string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "dataview", view), RESTRequest{Data: restDataviewUpdate{Headlines: map[string]string{name: value}}}, nil)
 	return
 }
 
 func (c *RESTClient) DeleteHeadline(entity, sampler, view, name string) (err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Delete(context.Background(), restPath(entity, sampler, "dataview", view, "headline", name), nil)
 	return
 }
 
 func (c *RESTClient) CreateStream(entity, sampler, name string) (err error) {
-	endpoint, _ := url.JoinPath("managedEntity", entity, "sampler", sampler, "stream", name)
-	_, err = c.Put(context.Background(), endpoint, nil, nil)
+	if entity == "" || sampler == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "stream", name), nil, nil)
 	return
 }
 
 func (c *RESTClient) UpdateStream(entity, sampler, name string, message any) (err error) {
-	endpoint, _ := url.JoinPath("managedEntity", entity, "sampler", sampler, "stream", name)
-	_, err = c.Put(context.Background(), endpoint, message, nil)
+	if entity == "" || sampler == "" || name == "" {
+		return ErrInvalidArgs
+	}
+	_, err = c.Put(context.Background(), restPath(entity, sampler, "stream", name), message, nil)
 	return
 }
 
 func (c *RESTClient) ManagedEntityExists(entity string) (bool, error) {
-	return false, errors.ErrUnsupported
+	if entity == "" {
+		return false, ErrInvalidArgs
+	}
+	return c.exists(restPath(entity), nil)
 }
 
 func (c *RESTClient) SamplerExists(entity, sampler string) (bool, error) {
-	return false, errors.ErrUnsupported
+	if entity == "" || sampler == "" {
+		return false, ErrInvalidArgs
+	}
+	return c.exists(restPath(entity, sampler), nil)
 }
 
 func (c *RESTClient) DataviewExists(entity, sampler, name string) (bool, error) {
-	return false, errors.ErrUnsupported
+	if entity == "" || sampler == "" || name == "" {
+		return false, ErrInvalidArgs
+	}
+	return c.exists(restPath(entity, sampler, "dataview", name), nil)
 }
 
-func (c *RESTClient) RowExists(entity, sampler, view, name string) (bool, error) {
-	return false, errors.ErrUnsupported
+func (c *RESTClient) RowExists(entity, sampler, view, name string) (exists bool, err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return false, ErrInvalidArgs
+	}
+	dv, exists, err := c.getDataview(entity, sampler, view)
+	if err != nil || !exists {
+		return
+	}
+	_, exists = dv.Rows[name]
+	return
 }
 
-func (c *RESTClient) ColumnExists(entity, sampler, view, name string) (bool, error) {
-	return false, errors.ErrUnsupported
+func (c *RESTClient) ColumnExists(entity, sampler, view, name string) (exists bool, err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return false, ErrInvalidArgs
+	}
+	dv, exists, err := c.getDataview(entity, sampler, view)
+	if err != nil || !exists {
+		return
+	}
+	exists = slices.Contains(dv.Columns, name)
+	return
+}
+
+func (c *RESTClient) HeadlineExists(entity, sampler, view, name string) (exists bool, err error) {
+	if entity == "" || sampler == "" || view == "" || name == "" {
+		return false, ErrInvalidArgs
+	}
+	dv, exists, err := c.getDataview(entity, sampler, view)
+	if err != nil || !exists {
+		return
+	}
+	_, exists = dv.Headlines[name]
+	return
+}
+
+// GetDataview returns the current contents of the dataview. If the
+// dataview does not exist then an error is returned.
+func (c *RESTClient) GetDataview(entity, sampler, view string) (dv RESTDataview, err error) {
+	if entity == "" || sampler == "" || view == "" {
+		err = ErrInvalidArgs
+		return
+	}
+	dv, exists, err := c.getDataview(entity, sampler, view)
+	if err == nil && !exists {
+		err = ErrNotFound
+	}
+	return
+}
+
+// exists issues a GET request for endpoint and returns false, without
+// an error, if the Netprobe responds with a 404. response, if not nil,
+// is filled in from the returned body.
+func (c *RESTClient) exists(endpoint string, response any) (exists bool, err error) {
+	resp, err := c.Get(context.Background(), endpoint, nil, response)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return
+	}
+	return true, nil
+}
+
+func (c *RESTClient) getDataview(entity, sampler, view string) (dv RESTDataview, exists bool, err error) {
+	exists, err = c.exists(restPath(entity, sampler, "dataview", view), &dv)
+	if err == nil && exists {
+		c.setColumns(entity, sampler, view, dv.Columns)
+	}
+	return
 }
 
-func (c *RESTClient) HeadlineExists(entity, sampler, view, name string) (bool, error) {
-	return false, errors.ErrUnsupported
+// getColumns returns the cached column names for the dataview,
+// fetching them from the Netprobe if not known
+func (c *RESTClient) getColumns(entity, sampler, view string) (columns []string, err error) {
+	c.mutex.Lock()
+	columns, ok := c.columns[restPath(entity, sampler, "dataview", view)]
+	c.mutex.Unlock()
+	if ok {
+		return
+	}
+	dv, err := c.GetDataview(entity, sampler, view)
+	return dv.Columns, err
+}
+
+// setColumns caches columns for the dataview. A nil columns removes
+// any cached value.
+func (c *RESTClient) setColumns(entity, sampler, view string, columns []string) {
+	key := restPath(entity, sampler, "dataview", view)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.columns == nil {
+		c.columns = make(map[string][]string)
+	}
+	if columns == nil {
+		delete(c.columns, key)
+		return
+	}
+	c.columns[key] = slices.Clone(columns)
+}
+
+// rowCells maps values onto columns, skipping the first column which is
+// the row name. It is an error if there is not exactly one value for
+// each column.
+func rowCells(columns []string, values []string) (cells map[string]string, err error) {
+	if len(values) != max(len(columns)-1, 0) {
+		err = fmt.Errorf("%w: %d values for %d columns", ErrInvalidArgs, len(values), max(len(columns)-1, 0))
+		return
+	}
+	cells = make(map[string]string, len(values))
+	for i, v := range values {
+		cells[columns[i+1]] = v
+	}
+	return
+}
+
+// restPath returns the escaped API path for the entity and sampler
+// followed by any further elements
+func restPath(entity string, elements ...string) string {
+	p := []string{"managedEntity", url.PathEscape(entity)}
+	if len(elements) > 0 {
+		p = append(p, "sampler", url.PathEscape(elements[0]))
+	}
+	for _, e := range elements[min(len(elements), 1):] {
+		p = append(p, url.PathEscape(e))
+	}
+	return strings.Join(p, "/")
 }
@@ -0,0 +1,120 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/itrs-group/cordial/pkg/geneos/api"
+	"github.com/itrs-group/cordial/pkg/geneos/api/resttest"
+)
+
+func newRESTClient(t *testing.T) (*resttest.Server, api.APIClient) {
+	t.Helper()
+	s := resttest.NewServer()
+	t.Cleanup(s.Close)
+	s.AddSampler("entity", "sampler")
+	c, err := api.NewRESTClient(s.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, c
+}
+
+func TestUpdateDataviewReplacesRows(t *testing.T) {
+	s, c := newRESTClient(t)
+
+	if err := c.UpdateDataview("entity", "sampler", "view", [][]string{
+		{"name", "a", "b"},
+		{"row1", "1", "2"},
+		{"row2", "3", "4"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dv, _ := s.Dataview("entity", "sampler", "view")
+	if len(dv.Rows) != 2 || dv.Rows["row2"]["b"] != "4" {
+		t.Fatalf("rows after first update: %v", dv.Rows)
+	}
+
+	// headlines are partial updates and leave the rows alone
+	if err := c.UpdateHeadline("entity", "sampler", "view", "status", "OK"); err != nil {
+		t.Fatal(err)
+	}
+	dv, _ = s.Dataview("entity", "sampler", "view")
+	if len(dv.Rows) != 2 || dv.Headlines["status"] != "OK" {
+		t.Fatalf("dataview after headline update: %+v", dv)
+	}
+
+	// an update with only the column headings empties the dataview
+	if err := c.UpdateDataview("entity", "sampler", "view", [][]string{{"name", "a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	dv, _ = s.Dataview("entity", "sampler", "view")
+	if len(dv.Rows) != 0 {
+		t.Fatalf("rows not cleared: %v", dv.Rows)
+	}
+}
+
+func TestUpdateRowColumns(t *testing.T) {
+	s, c := newRESTClient(t)
+
+	if err := c.UpdateDataview("entity", "sampler", "view", [][]string{
+		{"name", "a", "b"},
+		{"row1", "1", "2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateColumn("entity", "sampler", "view", "c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the Netprobe keeps column "c", which the client must follow
+	if err := c.UpdateDataview("entity", "sampler", "view", [][]string{
+		{"name", "a", "b"},
+		{"row1", "1", "2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dv, _ := s.Dataview("entity", "sampler", "view")
+	if !slices.Equal(dv.Columns, []string{"name", "a", "b", "c"}) {
+		t.Fatalf("server columns: %v", dv.Columns)
+	}
+
+	if err := c.UpdateRow("entity", "sampler", "view", "row1", []string{"5", "6", "7"}); err != nil {
+		t.Fatal(err)
+	}
+	dv, _ = s.Dataview("entity", "sampler", "view")
+	if dv.Rows["row1"]["c"] != "7" {
+		t.Fatalf("row1 after update: %v", dv.Rows["row1"])
+	}
+
+	for _, values := range [][]string{{"5", "6"}, {"5", "6", "7", "8"}} {
+		if err := c.UpdateRow("entity", "sampler", "view", "row1", values); !errors.Is(err, api.ErrInvalidArgs) {
+			t.Errorf("UpdateRow(%v) error = %v, want ErrInvalidArgs", values, err)
+		}
+	}
+
+	if err := c.UpdateDataview("entity", "sampler", "view", [][]string{
+		{"name", "a", "b"},
+		{"row1", "1"},
+	}); !errors.Is(err, api.ErrInvalidArgs) {
+		t.Errorf("UpdateDataview with short row error = %v, want ErrInvalidArgs", err)
+	}
+}
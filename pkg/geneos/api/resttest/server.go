@@ -0,0 +1,378 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resttest provides a local, in-memory imitation of the
+// Netprobe REST API for testing code that uses the api.RESTClient.
+//
+// Like a real Netprobe the fake server only accepts updates for
+// managed entities and samplers that have been configured, which here
+// is done with AddSampler. Dataviews, rows, headlines and streams are
+// then created on demand.
+//
+//	s := resttest.NewServer()
+//	defer s.Close()
+//	s.AddSampler("myEntity", "mySampler")
+//	c, _ := api.NewRESTClient(s.Endpoint)
+package resttest
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+
+	"github.com/itrs-group/cordial/pkg/geneos/api"
+)
+
+// Server is a fake Netprobe REST API server
+type Server struct {
+	*httptest.Server
+
+	// Endpoint is the base URL to pass to api.NewRESTClient
+	Endpoint string
+
+	mutex    sync.Mutex
+	entities map[string]map[string]*sampler
+}
+
+type sampler struct {
+	dataviews map[string]*api.RESTDataview
+	streams   map[string][]string
+}
+
+// NewServer starts and returns a new Server. The caller should call
+// Close when finished to shut it down.
+func NewServer() (s *Server) {
+	s = &Server{
+		entities: make(map[string]map[string]*sampler),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/healthcheck", s.healthcheck)
+	mux.HandleFunc("GET /v1/managedEntity/{entity}", s.getEntity)
+	mux.HandleFunc("GET /v1/managedEntity/{entity}/sampler/{sampler}", s.getSampler)
+
+	dataview := "/v1/managedEntity/{entity}/sampler/{sampler}/dataview/{dataview}"
+	mux.HandleFunc("GET "+dataview, s.getDataview)
+	mux.HandleFunc("PUT "+dataview, s.putDataview)
+	mux.HandleFunc("DELETE "+dataview, s.deleteDataview)
+	mux.HandleFunc("PUT "+dataview+"/row/{row}", s.putRow)
+	mux.HandleFunc("DELETE "+dataview+"/row/{row}", s.deleteRow)
+	mux.HandleFunc("DELETE "+dataview+"/headline/{headline}", s.deleteHeadline)
+
+	mux.HandleFunc("PUT /v1/managedEntity/{entity}/sampler/{sampler}/stream/{stream}", s.putStream)
+
+	s.Server = httptest.NewServer(mux)
+	s.Endpoint = s.URL + "/v1"
+	return
+}
+
+// AddSampler configures an API sampler on entity, creating the
+// managed entity if required.
+func (s *Server) AddSampler(entity, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.entities[entity]; !ok {
+		s.entities[entity] = make(map[string]*sampler)
+	}
+	if _, ok := s.entities[entity][name]; !ok {
+		s.entities[entity][name] = &sampler{
+			dataviews: make(map[string]*api.RESTDataview),
+			streams:   make(map[string][]string),
+		}
+	}
+}
+
+// Dataview returns a copy of the named dataview and true, or false if
+// it does not exist.
+func (s *Server) Dataview(entity, samplerName, name string) (dv api.RESTDataview, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(entity, samplerName)
+	if sa == nil {
+		return
+	}
+	d, ok := sa.dataviews[name]
+	if !ok {
+		return
+	}
+	return copyDataview(d), true
+}
+
+// Stream returns a copy of all the messages written to the stream
+func (s *Server) Stream(entity, samplerName, name string) (messages []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(entity, samplerName)
+	if sa == nil {
+		return
+	}
+	return slices.Clone(sa.streams[name])
+}
+
+// sampler returns the named sampler or nil. The caller must hold the
+// mutex.
+func (s *Server) sampler(entity, name string) *sampler {
+	samplers, ok := s.entities[entity]
+	if !ok {
+		return nil
+	}
+	return samplers[name]
+}
+
+func (s *Server) healthcheck(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getEntity(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	samplers, ok := s.entities[r.PathValue("entity")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"samplers": slices.Sorted(maps.Keys(samplers)),
+	})
+}
+
+func (s *Server) getSampler(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"dataviews": slices.Sorted(maps.Keys(sa.dataviews)),
+		"streams":   slices.Sorted(maps.Keys(sa.streams)),
+	})
+}
+
+func (s *Server) getDataview(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return
+	}
+	dv, ok := sa.dataviews[r.PathValue("dataview")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, dv)
+}
+
+// putDataview creates the dataview if required and then merges the
+// request. Columns are appended if not already present, headlines are
+// merged and, if the request includes `rows`, even as null or empty,
+// they replace all existing rows.
+func (s *Server) putDataview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Data struct {
+			Columns   []string          `json:"columns"`
+			Rows      json.RawMessage   `json:"rows"`
+			Headlines map[string]string `json:"headlines"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var rows map[string]map[string]string
+	if len(req.Data.Rows) > 0 {
+		if err := json.Unmarshal(req.Data.Rows, &rows); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.PathValue("dataview")
+	dv, ok := sa.dataviews[name]
+	if !ok {
+		dv = &api.RESTDataview{
+			Rows:      make(map[string]map[string]string),
+			Headlines: make(map[string]string),
+		}
+		sa.dataviews[name] = dv
+	}
+
+	for _, c := range req.Data.Columns {
+		if !slices.Contains(dv.Columns, c) {
+			dv.Columns = append(dv.Columns, c)
+		}
+	}
+	maps.Copy(dv.Headlines, req.Data.Headlines)
+	if len(req.Data.Rows) > 0 {
+		dv.Rows = make(map[string]map[string]string, len(rows))
+		for n, cells := range rows {
+			dv.Rows[n] = maps.Clone(cells)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteDataview(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.PathValue("dataview")
+	if _, ok := sa.dataviews[name]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	delete(sa.dataviews, name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// putRow creates the row if required and merges in the cells given.
+// Cells for unknown columns are rejected.
+func (s *Server) putRow(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dv := s.dataview(w, r)
+	if dv == nil {
+		return
+	}
+	for c := range req.Data {
+		if !slices.Contains(dv.Columns[min(len(dv.Columns), 1):], c) {
+			http.Error(w, "no such column: "+c, http.StatusBadRequest)
+			return
+		}
+	}
+	row := r.PathValue("row")
+	if _, ok := dv.Rows[row]; !ok {
+		dv.Rows[row] = make(map[string]string)
+	}
+	maps.Copy(dv.Rows[row], req.Data)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteRow(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dv := s.dataview(w, r)
+	if dv == nil {
+		return
+	}
+	row := r.PathValue("row")
+	if _, ok := dv.Rows[row]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	delete(dv.Rows, row)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) deleteHeadline(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dv := s.dataview(w, r)
+	if dv == nil {
+		return
+	}
+	headline := r.PathValue("headline")
+	if _, ok := dv.Headlines[headline]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	delete(dv.Headlines, headline)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) putStream(w http.ResponseWriter, r *http.Request) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return
+	}
+	stream := r.PathValue("stream")
+	if len(b) == 0 {
+		// create only
+		if _, ok := sa.streams[stream]; !ok {
+			sa.streams[stream] = []string{}
+		}
+	} else {
+		sa.streams[stream] = append(sa.streams[stream], string(b))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// dataview returns the dataview from the request path or writes a 404
+// and returns nil. The caller must hold the mutex.
+func (s *Server) dataview(w http.ResponseWriter, r *http.Request) *api.RESTDataview {
+	sa := s.sampler(r.PathValue("entity"), r.PathValue("sampler"))
+	if sa == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	dv, ok := sa.dataviews[r.PathValue("dataview")]
+	if !ok {
+		http.NotFound(w, r)
+		return nil
+	}
+	return dv
+}
+
+func copyDataview(d *api.RESTDataview) (dv api.RESTDataview) {
+	dv.Columns = slices.Clone(d.Columns)
+	dv.Headlines = maps.Clone(d.Headlines)
+	dv.Rows = make(map[string]map[string]string, len(d.Rows))
+	for n, cells := range d.Rows {
+		dv.Rows[n] = maps.Clone(cells)
+	}
+	return
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
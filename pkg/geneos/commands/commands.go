@@ -303,7 +303,9 @@ func (c *Connection) RunCommand(command string, target *xpath.XPath, args ...Arg
 
 // RunCommandAll runs command against all matching data items,
 // returning separately concatenated stdout, stderr and execlog when
-// returned by the underlying command
+// returned by the underlying command. There is one response for each
+// target and if the command fails for a target then the Status of the
+// response is "error" and the reason is in Stderr.
 func (c *Connection) RunCommandAll(command string, target *xpath.XPath, args ...Args) (responses []CommandResponse, err error) {
 	arguments := &CommandArgs{}
 	evalArgOptions(arguments, args...)
@@ -322,9 +324,29 @@ func (c *Connection) RunCommandAll(command string, target *xpath.XPath, args ...
 			Target: t,
 			Args:   arguments,
 		})
-		responses = append(responses, cr)
 		if err != nil {
-			continue
+			cr.Target = t
+			if cr.Status == "" {
+				cr.Status = "error"
+				cr.Stderr = err.Error()
+			}
+		}
+		responses = append(responses, cr)
+	}
+	return
+}
+
+// runAll runs command against all matching data items, like
+// RunCommandAll, and returns an error if the command fails for any
+// target
+func (c *Connection) runAll(command string, target *xpath.XPath, args ...Args) (err error) {
+	crs, err := c.RunCommandAll(command, target, args...)
+	if err != nil {
+		return
+	}
+	for _, cr := range crs {
+		if cr.Status == "error" {
+			return fmt.Errorf("%s: %s", cr.Target, strings.TrimSpace(cr.Stderr))
 		}
 	}
 	return
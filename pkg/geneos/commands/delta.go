@@ -0,0 +1,168 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"maps"
+	"slices"
+)
+
+// The kinds of change reported in a [Delta]
+const (
+	DataviewAdded   = "dataview-added"
+	DataviewRemoved = "dataview-removed"
+	RowAdded        = "row-added"
+	RowRemoved      = "row-removed"
+	CellChanged     = "cell"
+	HeadlineChanged = "headline"
+)
+
+// Delta is a single change between two snapshots of the same dataview,
+// as returned by [DiffDataviews].
+//
+// For CellChanged and HeadlineChanged either Old or New may be nil if
+// the column or headline has been added or removed. For RowAdded and
+// RowRemoved Cells holds the contents of the row, from the new or old
+// dataview respectively.
+type Delta struct {
+	Change   string              `json:"change"`
+	Row      string              `json:"row,omitempty"`
+	Column   string              `json:"column,omitempty"`
+	Headline string              `json:"headline,omitempty"`
+	Old      *DataItem           `json:"old,omitempty"`
+	New      *DataItem           `json:"new,omitempty"`
+	Cells    map[string]DataItem `json:"cells,omitempty"`
+}
+
+// SeverityChanged returns true if d is a cell or headline change where
+// the severity is different, including when the cell or headline has
+// been added or removed and has a severity.
+func (d Delta) SeverityChanged() bool {
+	if d.Change != CellChanged && d.Change != HeadlineChanged {
+		return false
+	}
+	var o, n string
+	if d.Old != nil {
+		o = d.Old.Severity
+	}
+	if d.New != nil {
+		n = d.New.Severity
+	}
+	return o != n
+}
+
+// DiffDataviews returns the differences between two snapshots of the
+// same dataview. If old is nil then a single DataviewAdded delta is
+// returned and if new is nil then a single DataviewRemoved delta is
+// returned.
+//
+// Headlines are compared first, followed by rows in the order of the
+// new dataview, with removed rows last. Within a row cells are compared
+// in column order. Cells are compared on all fields of the [DataItem],
+// so only the properties requested in the snapshot [Scope] are
+// significant.
+func DiffDataviews(old, new *Dataview) (deltas []Delta) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		return []Delta{{Change: DataviewAdded}}
+	case new == nil:
+		return []Delta{{Change: DataviewRemoved}}
+	}
+
+	for _, h := range order(new.HeadlineOrder, new.Headlines) {
+		n := new.Headlines[h]
+		o, ok := old.Headlines[h]
+		switch {
+		case !ok:
+			deltas = append(deltas, Delta{Change: HeadlineChanged, Headline: h, New: &n})
+		case o != n:
+			deltas = append(deltas, Delta{Change: HeadlineChanged, Headline: h, Old: &o, New: &n})
+		}
+	}
+	for _, h := range order(old.HeadlineOrder, old.Headlines) {
+		if _, ok := new.Headlines[h]; !ok {
+			o := old.Headlines[h]
+			deltas = append(deltas, Delta{Change: HeadlineChanged, Headline: h, Old: &o})
+		}
+	}
+
+	columns := slices.Clone(new.ColumnOrder)
+	for _, c := range old.ColumnOrder {
+		if !slices.Contains(columns, c) {
+			columns = append(columns, c)
+		}
+	}
+
+	for _, r := range order(new.RowOrder, new.Table) {
+		nr := new.Table[r]
+		or, ok := old.Table[r]
+		if !ok {
+			deltas = append(deltas, Delta{Change: RowAdded, Row: r, Cells: nr})
+			continue
+		}
+		for _, c := range columnsOf(columns, or, nr) {
+			o, ook := or[c]
+			n, nok := nr[c]
+			switch {
+			case ook && nok && o == n:
+				continue
+			case !ook:
+				deltas = append(deltas, Delta{Change: CellChanged, Row: r, Column: c, New: &n})
+			case !nok:
+				deltas = append(deltas, Delta{Change: CellChanged, Row: r, Column: c, Old: &o})
+			default:
+				deltas = append(deltas, Delta{Change: CellChanged, Row: r, Column: c, Old: &o, New: &n})
+			}
+		}
+	}
+	for _, r := range order(old.RowOrder, old.Table) {
+		if _, ok := new.Table[r]; !ok {
+			deltas = append(deltas, Delta{Change: RowRemoved, Row: r, Cells: old.Table[r]})
+		}
+	}
+	return
+}
+
+// order returns the keys of m in the order given, followed by any keys
+// not in the order, sorted
+func order[T any](keys []string, m map[string]T) (ordered []string) {
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			ordered = append(ordered, k)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if !slices.Contains(keys, k) {
+			ordered = append(ordered, k)
+		}
+	}
+	return
+}
+
+// columnsOf returns the names of the columns in either row a or b,
+// using the order in columns first
+func columnsOf(columns []string, a, b map[string]DataItem) (names []string) {
+	all := maps.Clone(a)
+	if all == nil {
+		all = map[string]DataItem{}
+	}
+	maps.Copy(all, b)
+	return order(columns, all)
+}
@@ -25,7 +25,7 @@ import (
 // connection c and against the target XPath. An error is returned on
 // failure to run the command.
 func (c *Connection) SampleNow(target *xpath.XPath) (err error) {
-	_, err = c.RunCommandAll("/PLUGIN:sampleNow", target)
+	err = c.runAll("/PLUGIN:sampleNow", target)
 	return
 }
 
@@ -0,0 +1,102 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"strconv"
+
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+)
+
+// Cell is a headline or table cell selected from a dataview by
+// [Dataview.Select]
+type Cell struct {
+	XPath *xpath.XPath `json:"xpath"`
+	DataItem
+}
+
+// Select returns the headlines and table cells of the dataview that
+// match x, in dataview order, so that callers can filter a snapshot
+// without further requests to the Gateway. If x is a path to headlines
+// then only headlines are returned, if it is a path to rows, rows or
+// cells then only table cells are returned and if it ends at the
+// dataview or above then all cells are returned.
+//
+// Only the dataview name and the elements below the dataview are
+// evaluated, as the snapshot does not include the properties of the
+// higher levels. Headlines are evaluated with the properties "name",
+// "value", "severity", "snoozed" and "userAssigned", rows with "name"
+// and "rowname" and table cells with "column" and "rowname" in place of
+// "name". The values are those returned in the snapshot, so it must be
+// taken with a [Scope] that includes the properties used.
+func (dv *Dataview) Select(x *xpath.XPath) (cells []Cell) {
+	if dv == nil {
+		return
+	}
+	if x == nil {
+		x = &xpath.XPath{}
+	}
+
+	if !x.Dataview.Matches(xpath.Values{Properties: map[string]string{"name": dv.Name}}) {
+		return
+	}
+
+	base := dv.XPath
+	if base == nil {
+		base = xpath.NewDataviewPath(dv.Name)
+	}
+
+	if !x.Rows {
+		for _, h := range order(dv.HeadlineOrder, dv.Headlines) {
+			d := dv.Headlines[h]
+			if !x.Headline.Matches(dataItemNode(d, map[string]string{"name": h})) {
+				continue
+			}
+			cells = append(cells, Cell{XPath: base.ResolveTo(&xpath.Headline{Name: h}), DataItem: d})
+		}
+		if x.Headline != nil {
+			return
+		}
+	}
+
+	for _, r := range order(dv.RowOrder, dv.Table) {
+		if !x.Row.Matches(xpath.Values{Properties: map[string]string{"name": r, "rowname": r}}) {
+			continue
+		}
+		row := dv.Table[r]
+		rx := base.ResolveTo(&xpath.Row{Name: r})
+		for _, c := range order(dv.ColumnOrder, row) {
+			d := row[c]
+			if !x.Column.Matches(dataItemNode(d, map[string]string{"column": c, "rowname": r})) {
+				continue
+			}
+			cells = append(cells, Cell{XPath: rx.ResolveTo(&xpath.Column{Name: c}), DataItem: d})
+		}
+	}
+	return
+}
+
+// dataItemNode returns the properties of data item d, plus those in
+// props, for predicate evaluation
+func dataItemNode(d DataItem, props map[string]string) xpath.Node {
+	props["value"] = d.Value
+	props["severity"] = d.Severity
+	props["snoozed"] = strconv.FormatBool(d.Snoozed)
+	props["userAssigned"] = strconv.FormatBool(d.Assigned)
+	return xpath.Values{Properties: props}
+}
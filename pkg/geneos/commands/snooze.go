@@ -17,16 +17,36 @@ limitations under the License.
 
 package commands
 
-import "github.com/itrs-group/cordial/pkg/geneos/xpath"
+import (
+	"fmt"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+)
 
 // SnoozeManual runs the internal command of the same name
 func (c *Connection) SnoozeManual(target *xpath.XPath, info string) (err error) {
 	if target.IsGateway() || target.IsProbe() || target.IsEntity() {
-		_, err = c.RunCommandAll("/SNOOZE:manual", target, Arg(1, info))
+		err = c.runAll("/SNOOZE:manual", target, Arg(1, info))
+		return
+	}
+	if target.IsSampler() || target.IsHeadline() || target.IsTableCell() || target.IsDataview() {
+		err = c.runAll("/SNOOZE:manualAllMe", target, Arg(1, info), Arg(5, "this"))
+	}
+	return
+}
+
+// SnoozeFor runs the internal time snooze command against target, so
+// that the snooze is removed by the Gateway after duration d. The
+// duration is rounded up to whole minutes.
+func (c *Connection) SnoozeFor(target *xpath.XPath, d time.Duration, info string) (err error) {
+	minutes := fmt.Sprint(int64((d + time.Minute - 1) / time.Minute))
+	if target.IsGateway() || target.IsProbe() || target.IsEntity() {
+		err = c.runAll("/SNOOZE:time", target, Arg(1, info), Arg(2, minutes))
 		return
 	}
 	if target.IsSampler() || target.IsHeadline() || target.IsTableCell() || target.IsDataview() {
-		_, err = c.RunCommandAll("/SNOOZE:manualAllMe", target, Arg(1, info), Arg(5, "this"))
+		err = c.runAll("/SNOOZE:timeAllMe", target, Arg(1, info), Arg(2, minutes), Arg(5, "this"))
 	}
 	return
 }
@@ -34,11 +54,11 @@ func (c *Connection) SnoozeManual(target *xpath.XPath, info string) (err error)
 // Unsnooze runs the internal command of the same name
 func (c *Connection) Unsnooze(target *xpath.XPath, info string) (err error) {
 	if target.IsGateway() || target.IsProbe() || target.IsEntity() {
-		_, err = c.RunCommandAll("/SNOOZE:unsnooze", target, Arg(1, info))
+		err = c.runAll("/SNOOZE:unsnooze", target, Arg(1, info))
 		return
 	}
 	if target.Rows || target.Headline != nil || target.Sampler != nil {
-		_, err = c.RunCommandAll("/SNOOZE:unsnoozeAllMe", target, Arg(1, "this"), Arg(2, info))
+		err = c.runAll("/SNOOZE:unsnoozeAllMe", target, Arg(1, "this"), Arg(2, info))
 	}
 	return
 }
@@ -42,6 +42,8 @@ type Vars struct {
 	NameValueList *NameValueList `xml:"nameValueList,omitempty" json:",omitempty" yaml:",omitempty"`
 	Macro         *Macro         `xml:"macro,omitempty" json:",omitempty" yaml:",omitempty"`
 	Regex         *Regex         `xml:"regex,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other         []XMLElement   `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs    []xml.Attr     `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
 }
 
 // GetKey satisfies the KeyedObject interface
@@ -0,0 +1,493 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geneos
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// The kinds of difference reported by DiffGateways
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// The sections of a Gateway setup compared by DiffGateways, in the
+// order that differences are returned
+var DiffSections = []string{
+	"operating environment",
+	"include",
+	"probe group",
+	"probe",
+	"virtual probe",
+	"floating probe",
+	"entity group",
+	"entity",
+	"type group",
+	"type",
+	"sampler group",
+	"sampler",
+	"rule group",
+	"rule",
+	"environment group",
+	"environment",
+}
+
+// SetupDifference is a single added, removed or changed item between
+// two Gateway setups. Name is the item name or, for rules and groups,
+// the path using the Geneos " > " separator. Details are only set for
+// changed items.
+type SetupDifference struct {
+	Section string        `json:"section"`
+	Name    string        `json:"name"`
+	Change  string        `json:"change"`
+	Details []SetupDetail `json:"details,omitempty"`
+}
+
+// SetupDetail is a changed field in an item. An empty Old or New means
+// the field was added or removed respectively.
+type SetupDetail struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// setupItem is a named item in a setup and the group path it is in
+type setupItem struct {
+	section string
+	name    string
+	group   string
+	value   any
+}
+
+// DiffGateways compares two Gateway setups semantically and returns
+// the items that have been added, removed or changed between from and
+// to. Includes, probes, managed entities, types, samplers, rules and
+// environments, and the groups they are in, are matched by name (or
+// path for rules) so that the order of elements does not matter.
+// Within an item the order of named elements, such as attributes and
+// variables, and of lists of plain values is also ignored, as is
+// leading and trailing whitespace. Items that have been moved between
+// groups are reported as changed, with a "group" detail.
+//
+// The setups should be merged, e.g. using `gateway2 -dump-xml` or the
+// Gateway Setup Editor's "Save merged" option, otherwise changes in
+// included files are not seen.
+func DiffGateways(from, to *Gateway) (diffs []SetupDifference) {
+	a, b := setupItems(from), setupItems(to)
+
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		ia, inA := a[k]
+		ib, inB := b[k]
+		switch {
+		case !inA:
+			diffs = append(diffs, SetupDifference{Section: ib.section, Name: ib.name, Change: DiffAdded})
+		case !inB:
+			diffs = append(diffs, SetupDifference{Section: ia.section, Name: ia.name, Change: DiffRemoved})
+		default:
+			if details := diffItems(ia, ib); len(details) > 0 {
+				diffs = append(diffs, SetupDifference{Section: ia.section, Name: ia.name, Change: DiffChanged, Details: details})
+			}
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b SetupDifference) int {
+		if c := slices.Index(DiffSections, a.Section) - slices.Index(DiffSections, b.Section); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return
+}
+
+// diffItems returns the field level differences between two items
+func diffItems(a, b setupItem) (details []SetupDetail) {
+	if a.group != b.group {
+		details = append(details, SetupDetail{Field: "group", Old: a.group, New: b.group})
+	}
+
+	fa, fb := flattenItem(a.value), flattenItem(b.value)
+	fields := slices.Collect(maps.Keys(fa))
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	slices.Sort(fields)
+
+	for _, f := range fields {
+		if fa[f] != fb[f] {
+			details = append(details, SetupDetail{Field: f, Old: fa[f], New: fb[f]})
+		}
+	}
+	return
+}
+
+// setupItems returns all the named items in the setup g, keyed on
+// section and name. Names that are not unique in a section, which
+// Geneos would reject, are replaced by the full group path of each
+// item and, if that is not unique either, by the path followed by the
+// position of the item, e.g. "group > probe [2]", so that no item is
+// lost.
+func setupItems(g *Gateway) (items map[string]setupItem) {
+	items = map[string]setupItem{}
+	if g == nil {
+		return
+	}
+
+	var all []setupItem
+	add := func(section, name, group string, value any) {
+		all = append(all, setupItem{section: section, name: name, group: group, value: value})
+	}
+
+	if g.OperatingEnvironment != nil {
+		add("operating environment", "", "", g.OperatingEnvironment)
+	}
+
+	if g.Includes != nil {
+		for _, i := range g.Includes.Includes {
+			add("include", i.Location, "", i)
+		}
+	}
+
+	if g.Probes != nil {
+		var walk func(group string, probes []Probe, virtual []VirtualProbe, floating []FloatingProbe, groups []ProbeGroup)
+		walk = func(group string, probes []Probe, virtual []VirtualProbe, floating []FloatingProbe, groups []ProbeGroup) {
+			for _, p := range probes {
+				add("probe", p.Name, group, p)
+			}
+			for _, p := range virtual {
+				add("virtual probe", p.Name, group, p)
+			}
+			for _, p := range floating {
+				add("floating probe", p.Name, group, p)
+			}
+			for _, pg := range groups {
+				path := groupPath(group, pg.Name)
+				children := pg
+				children.Probes, children.VirtualProbes, children.FloatingProbes, children.ProbeGroups = nil, nil, nil, nil
+				add("probe group", path, "", children)
+				walk(path, pg.Probes, pg.VirtualProbes, pg.FloatingProbes, pg.ProbeGroups)
+			}
+		}
+		walk("", g.Probes.Probes, g.Probes.VirtualProbes, g.Probes.FloatingProbes, g.Probes.ProbeGroups)
+	}
+
+	if g.ManagedEntities != nil {
+		var walk func(group string, entities []ManagedEntity, groups []ManagedEntityGroup)
+		walk = func(group string, entities []ManagedEntity, groups []ManagedEntityGroup) {
+			for _, e := range entities {
+				add("entity", e.Name, group, e)
+			}
+			for _, eg := range groups {
+				path := groupPath(group, eg.Name)
+				settings := eg
+				settings.Entities, settings.ManagedEntityGroups = nil, nil
+				add("entity group", path, "", settings)
+				walk(path, eg.Entities, eg.ManagedEntityGroups)
+			}
+		}
+		walk("", g.ManagedEntities.Entities, g.ManagedEntities.ManagedEntityGroups)
+	}
+
+	if g.Types != nil {
+		var walk func(group string, types []Type, groups []TypeGroup)
+		walk = func(group string, types []Type, groups []TypeGroup) {
+			for _, t := range types {
+				add("type", t.Name, group, t)
+			}
+			for _, tg := range groups {
+				path := groupPath(group, tg.Name)
+				add("type group", path, "", TypeGroup{Name: tg.Name, Disabled: tg.Disabled})
+				walk(path, tg.Types, tg.TypeGroups)
+			}
+		}
+		walk("", g.Types.Types, g.Types.TypeGroups)
+	}
+
+	if g.Samplers != nil {
+		var walk func(group string, samplers []Sampler, groups []SamplerGroup)
+		walk = func(group string, samplers []Sampler, groups []SamplerGroup) {
+			for _, s := range samplers {
+				add("sampler", s.Name, group, s)
+			}
+			for _, sg := range groups {
+				path := groupPath(group, sg.Name)
+				add("sampler group", path, "", SamplerGroup{Name: sg.Name, Disabled: sg.Disabled})
+				walk(path, sg.Samplers, sg.SamplerGroups)
+			}
+		}
+		walk("", g.Samplers.Samplers, g.Samplers.SamplerGroups)
+	}
+
+	if g.Rules != nil {
+		// rules are identified by their full path, as in Geneos
+		var walk func(group string, rules []Rule, groups []RuleGroup)
+		walk = func(group string, rules []Rule, groups []RuleGroup) {
+			for _, r := range rules {
+				add("rule", groupPath(group, r.Name), "", ruleItem{Rule: r, Block: xmlTree(r.Block)})
+			}
+			for _, rg := range groups {
+				path := groupPath(group, rg.Name)
+				settings := rg
+				settings.Rules, settings.RuleGroups = nil, nil
+				add("rule group", path, "", settings)
+				walk(path, rg.Rules, rg.RuleGroups)
+			}
+		}
+		walk("", g.Rules.Rules, g.Rules.RuleGroups)
+	}
+
+	if g.Environments != nil {
+		var walk func(parent string, environments []Environment)
+		walk = func(parent string, environments []Environment) {
+			for _, e := range environments {
+				path := groupPath(parent, e.Name)
+				vars := e
+				vars.Environments = nil
+				add("environment", path, "", vars)
+				walk(path, e.Environments)
+			}
+		}
+		walk("", g.Environments.Environments)
+		for _, eg := range g.Environments.Groups {
+			add("environment group", eg.Name, "", EnvironmentGroup{Name: eg.Name})
+			walk(eg.Name, eg.Environments)
+		}
+	}
+
+	count := map[string]int{}
+	for _, i := range all {
+		count[i.section+"\x00"+i.name]++
+	}
+	seen := map[string]int{}
+	for _, i := range all {
+		if count[i.section+"\x00"+i.name] > 1 {
+			i.name = groupPath(i.group, i.name)
+			seen[i.section+"\x00"+i.name]++
+			if n := seen[i.section+"\x00"+i.name]; n > 1 {
+				i.name = fmt.Sprintf("%s [%d]", i.name, n)
+			}
+		}
+		items[i.section+"\x00"+i.name] = i
+	}
+	return
+}
+
+func groupPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + " > " + name
+}
+
+// flattenItem returns the JSON encoding of v as a map of field paths to
+// values
+func flattenItem(v any) (flat map[string]string) {
+	flat = map[string]string{}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	var j any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&j); err != nil {
+		return
+	}
+	flattenValue("", j, flat)
+	return
+}
+
+// diffKeys are the fields, in order of preference, used to match
+// elements of lists of objects regardless of order
+var diffKeys = []string{"Name", "name", "Type", "Location"}
+
+// flattenValue adds the leaf values of v to flat. Elements of lists of
+// objects are identified by the first of diffKeys that is set and
+// unique across the list, or by position otherwise. Lists of plain
+// values are sorted and joined into a single value.
+func flattenValue(prefix string, v any, flat map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			flattenValue(join(k), e, flat)
+		}
+	case []any:
+		if len(t) == 0 {
+			return
+		}
+		if key := listKey(t); key != "" {
+			for _, e := range t {
+				flattenValue(fmt.Sprintf("%s[%s]", prefix, e.(map[string]any)[key]), e, flat)
+			}
+			return
+		}
+		var values []string
+		for n, e := range t {
+			switch e.(type) {
+			case map[string]any, []any:
+				flattenValue(fmt.Sprintf("%s[%d]", prefix, n), e, flat)
+			default:
+				values = append(values, scalarString(e))
+			}
+		}
+		if len(values) > 0 {
+			slices.Sort(values)
+			flat[prefix] = strings.Join(values, ", ")
+		}
+	case nil:
+		return
+	default:
+		if s := scalarString(t); s != "" {
+			flat[prefix] = s
+		}
+	}
+}
+
+// listKey returns the first of diffKeys that is a unique, non-empty
+// value in every element of list, or an empty string
+func listKey(list []any) string {
+KEYS:
+	for _, key := range diffKeys {
+		seen := map[any]bool{}
+		for _, e := range list {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return ""
+			}
+			k, ok := m[key]
+			if !ok || k == "" {
+				continue KEYS
+			}
+			switch k.(type) {
+			case map[string]any, []any:
+				continue KEYS
+			}
+			if seen[k] {
+				continue KEYS
+			}
+			seen[k] = true
+		}
+		return key
+	}
+	return ""
+}
+
+func scalarString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// ruleItem replaces the unparsed rule block with a tree of its
+// contents, so that changes are reported against the elements in the
+// block
+type ruleItem struct {
+	Rule
+	Block any `json:",omitempty"`
+}
+
+// xmlTree returns the contents of the rule block b as nested maps
+// of element names to their contents, ignoring comments and whitespace
+// between elements. Attributes are stored with an "@" prefix and text in
+// elements that also have child elements under "#text". Repeated
+// elements are stored, in order, as a slice. If the block cannot be
+// parsed then the trimmed text of the block is returned.
+func xmlTree(b *RuleBlock) any {
+	if b == nil {
+		return nil
+	}
+	d := xml.NewDecoder(strings.NewReader("<block>" + b.Contents + "</block>"))
+	tok, err := d.Token()
+	if err != nil {
+		return strings.TrimSpace(b.Contents)
+	}
+	start, _ := tok.(xml.StartElement)
+	v, err := xmlElement(d, start)
+	if err != nil {
+		return strings.TrimSpace(b.Contents)
+	}
+	return v
+}
+
+func xmlElement(d *xml.Decoder, start xml.StartElement) (v any, err error) {
+	m := map[string]any{}
+	for _, a := range start.Attr {
+		m["@"+a.Name.Local] = a.Value
+	}
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := xmlElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			switch e := m[t.Name.Local].(type) {
+			case nil:
+				m[t.Name.Local] = child
+			case []any:
+				m[t.Name.Local] = append(e, child)
+			default:
+				m[t.Name.Local] = []any{e, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			switch {
+			case len(m) == 0 && s == "":
+				return "{}", nil
+			case len(m) == 0:
+				return s, nil
+			case s != "":
+				m["#text"] = s
+			}
+			return m, nil
+		}
+	}
+}
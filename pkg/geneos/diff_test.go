@@ -0,0 +1,77 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geneos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffGatewaysZeroValues(t *testing.T) {
+	secure := func(b bool) *bool { return &b }
+	gateway := func(s *bool) *Gateway {
+		return &Gateway{Probes: &Probes{Probes: []Probe{
+			{Name: "p1", Hostname: "host1", ProbeInfo: ProbeInfo{Port: 7036, Secure: s}},
+		}}}
+	}
+
+	tests := []struct {
+		from, to *bool
+		want     []SetupDetail
+	}{
+		{secure(false), secure(true), []SetupDetail{{Field: "secure", Old: "false", New: "true"}}},
+		{nil, secure(false), []SetupDetail{{Field: "secure", New: "false"}}},
+		{secure(false), nil, []SetupDetail{{Field: "secure", Old: "false"}}},
+		{secure(false), secure(false), nil},
+	}
+	for _, tt := range tests {
+		diffs := DiffGateways(gateway(tt.from), gateway(tt.to))
+		var got []SetupDetail
+		if len(diffs) > 0 {
+			got = diffs[0].Details
+		}
+		if len(diffs) > 1 || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("secure %v to %v: got %+v, want details %+v", tt.from, tt.to, diffs, tt.want)
+		}
+	}
+}
+
+func TestDiffGatewaysDuplicates(t *testing.T) {
+	from := &Gateway{Probes: &Probes{
+		Probes: []Probe{{Name: "p1", Hostname: "host1"}},
+		ProbeGroups: []ProbeGroup{
+			{Name: "g1", Probes: []Probe{{Name: "p1", Hostname: "host2"}, {Name: "p1", Hostname: "host3"}}},
+		},
+	}}
+	to := &Gateway{Probes: &Probes{
+		Probes: []Probe{{Name: "p1", Hostname: "host1"}},
+		ProbeGroups: []ProbeGroup{
+			{Name: "g1", Probes: []Probe{{Name: "p1", Hostname: "host2"}, {Name: "p1", Hostname: "host4"}}},
+		},
+	}}
+
+	if n := len(setupItems(from)); n != 4 {
+		t.Errorf("setupItems() returned %d items, want 4", n)
+	}
+	want := []SetupDifference{
+		{Section: "probe", Name: "g1 > p1 [2]", Change: DiffChanged, Details: []SetupDetail{{Field: "hostname", Old: "host3", New: "host4"}}},
+	}
+	if got := DiffGateways(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffGateways() = %+v, want %+v", got, want)
+	}
+}
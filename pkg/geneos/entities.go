@@ -25,6 +25,9 @@ type ManagedEntities struct {
 	XMLName             xml.Name             `xml:"managedEntities" json:"-" yaml:"-"`
 	Entities            []ManagedEntity      `xml:"managedEntity,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"managedentity"`
 	ManagedEntityGroups []ManagedEntityGroup `xml:"managedEntityGroup,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"managedentitygroup"`
+	Other               []XMLElement         `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs          []xml.Attr           `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw                 preserved
 }
 
 type ManagedEntityGroup struct {
@@ -34,6 +37,9 @@ type ManagedEntityGroup struct {
 	ManagedEntityInfo   `yaml:",inline" mapstructure:",squash"`
 	Entities            []ManagedEntity      `xml:"managedEntity,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"managedentity"`
 	ManagedEntityGroups []ManagedEntityGroup `xml:"managedEntityGroup,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"managedentitygroup"`
+	Other               []XMLElement         `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs          []xml.Attr           `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw                 preserved
 }
 
 type ManagedEntity struct {
@@ -46,6 +52,9 @@ type ManagedEntity struct {
 	Environment       *EnvironmentRef `xml:"environment,omitempty" json:",omitempty" yaml:",omitempty"`
 	ManagedEntityInfo `yaml:",inline" mapstructure:",squash"`
 	Samplers          []SamplerRef `xml:"sampler,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"sampler"`
+	Other             []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs        []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw               preserved
 }
 
 type ManagedEntityInfo struct {
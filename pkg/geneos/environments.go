@@ -28,12 +28,18 @@ type Environments struct {
 	XMLName      xml.Name           `xml:"environments" json:"-" yaml:"-"`
 	Groups       []EnvironmentGroup `xml:"environmentGroup,omitempty" json:",omitempty" yaml:",omitempty"`
 	Environments []Environment      `xml:"environment,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other        []XMLElement       `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs   []xml.Attr         `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw          preserved
 }
 
 type EnvironmentGroup struct {
 	XMLName      xml.Name      `xml:"environmentGroup" json:"-" yaml:"-"`
 	Name         string        `xml:"name,attr"`
 	Environments []Environment `xml:"environment,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other        []XMLElement  `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs   []xml.Attr    `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw          preserved
 }
 
 type Environment struct {
@@ -41,4 +47,7 @@ type Environment struct {
 	Name         string        `xml:"name,attr"`
 	Environments []Environment `xml:"environment,omitempty" json:",omitempty" yaml:",omitempty"`
 	Vars         []Vars        `xml:"var,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"var"`
+	Other        []XMLElement  `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs   []xml.Attr    `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw          preserved
 }
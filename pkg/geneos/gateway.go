@@ -31,7 +31,10 @@ package geneos
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
+
+	"golang.org/x/net/html/charset"
 )
 
 // Gateway is for reading a Gateway configuration
@@ -40,6 +43,7 @@ type Gateway struct {
 	Compatibility        int                   `xml:"compatibility,attr"`
 	XMLNs                string                `xml:"xmlns:xsi,attr"`                     // http://www.w3.org/2001/XMLSchema-instance
 	XSI                  string                `xml:"xsi:noNamespaceSchemaLocation,attr"` // http://schema.itrsgroup.com/GA5.12.0-220125/gateway.xsd
+	Includes             *Includes             `xml:"includes,omitempty"`
 	Probes               *Probes               `xml:"probes"`
 	ManagedEntities      *ManagedEntities      `xml:"managedEntities,omitempty"`
 	Types                *Types                `xml:"types,omitempty"`
@@ -48,14 +52,56 @@ type Gateway struct {
 	Environments         *Environments         `xml:"environments,omitempty"`
 	ProcessDescriptors   *ProcessDescriptors   `xml:"staticVars>processDescriptors,omitempty"`
 	OperatingEnvironment *OperatingEnvironment `xml:"operatingEnvironment,omitempty"`
+	Other                []XMLElement          `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs           []xml.Attr            `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+
+	order      []xml.Token // top-level section names, whitespace and comments, as read
+	staticVars *staticVars // staticVars as read, for unmodelled variables
+}
+
+// ReadGateway decodes a Gateway setup from r. Non UTF-8 character
+// sets, as declared in the XML header, are converted. On a decoding
+// error the position in the input is included in the error.
+func ReadGateway(r io.Reader) (g *Gateway, err error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+
+	g = &Gateway{}
+	if err = d.Decode(g); err != nil {
+		line, column := d.InputPos()
+		return nil, fmt.Errorf("%w: line: %d char: %d", err, line, column)
+	}
+	return
+}
+
+type Includes struct {
+	XMLName    xml.Name     `xml:"includes" json:"-" yaml:"-"`
+	Includes   []Include    `xml:"include,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
+}
+
+type Include struct {
+	XMLName    xml.Name     `xml:"include" json:"-" yaml:"-"`
+	Disabled   bool         `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	Priority   int          `xml:"priority"`
+	Required   bool         `xml:"required,omitempty" json:",omitempty" yaml:",omitempty"`
+	Location   string       `xml:"location"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type OperatingEnvironment struct {
-	XMLName      xml.Name `xml:"operatingEnvironment"`
-	GatewayID    int      `xml:"gatewayId"`
-	GatewayName  string   `xml:"gatewayName"`
-	SecurePort   int      `xml:"listenPorts>secure>listenPort,omitempty"`
-	InsecurePort int      `xml:"listenPorts>insecure>listenPort,omitempty"`
+	XMLName      xml.Name     `xml:"operatingEnvironment"`
+	GatewayID    int          `xml:"gatewayId"`
+	GatewayName  string       `xml:"gatewayName"`
+	SecurePort   int          `xml:"listenPorts>secure>listenPort,omitempty"`
+	InsecurePort int          `xml:"listenPorts>insecure>listenPort,omitempty"`
+	Other        []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs   []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw          preserved
 }
 
 type Dataview struct {
@@ -0,0 +1,276 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geneos
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+)
+
+// Directory is the resolved directory of a Gateway setup, the probes,
+// managed entities and samplers that the Gateway would create, used to
+// evaluate XPaths without a running Gateway. Disabled items are not
+// included.
+type Directory struct {
+	Gateway string           `json:"gateway,omitempty"`
+	Probes  []DirectoryProbe `json:"probes,omitempty"`
+}
+
+// DirectoryProbe is a probe and the managed entities that use it
+type DirectoryProbe struct {
+	Name     string            `json:"name"`
+	Type     int               `json:"-"`
+	Hostname string            `json:"hostname,omitempty"`
+	Port     int               `json:"port,omitempty"`
+	Entities []DirectoryEntity `json:"entities,omitempty"`
+}
+
+// DirectoryEntity is a managed entity, with its inherited attributes
+// and resolved samplers
+type DirectoryEntity struct {
+	Name       string             `json:"name"`
+	Attributes map[string]string  `json:"attributes,omitempty"`
+	Samplers   []DirectorySampler `json:"samplers,omitempty"`
+}
+
+// DirectorySampler is a sampler on a managed entity. Type is empty for
+// samplers added directly to the entity.
+type DirectorySampler struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Plugin string `json:"plugin,omitempty"`
+	Group  string `json:"group,omitempty"`
+}
+
+// NewDirectory resolves the managed entities in the Gateway setup g,
+// including attributes and types inherited from groups, and returns
+// them under their probes, sorted by name. Entities with no probe or
+// with a probe not in the setup are not included.
+func NewDirectory(g *Gateway) (d *Directory) {
+	d = &Directory{}
+	if g == nil {
+		return
+	}
+	if g.OperatingEnvironment != nil {
+		d.Gateway = g.OperatingEnvironment.GatewayName
+	}
+
+	probes := UnrollProbes(g.Probes)
+	types := UnrollTypes(g.Types)
+	entities := UnrollEntities(g.ManagedEntities, types)
+	samplers := UnrollSamplers(g.Samplers)
+
+	byProbe := map[string][]DirectoryEntity{}
+	for _, name := range slices.Sorted(maps.Keys(entities)) {
+		e := entities[name]
+		var probe string
+		switch {
+		case e.Probe != nil:
+			probe = e.Probe.Name
+		case e.FloatingProbe != nil:
+			probe = e.FloatingProbe.Name
+		case e.VirtualProbe != nil:
+			probe = e.VirtualProbe.Name
+		}
+		if _, ok := probes[probe]; !ok {
+			continue
+		}
+
+		de := DirectoryEntity{
+			Name:       e.Name,
+			Attributes: map[string]string{},
+		}
+		for _, a := range e.Attributes {
+			de.Attributes[a.Name] = a.Value
+		}
+		for _, ts := range slices.Sorted(maps.Keys(e.ResolvedSamplers)) {
+			t, s, _ := strings.Cut(ts, ":")
+			sampler := samplers[s]
+			group := sampler.Group
+			if group == nil {
+				group = sampler.GroupNoVar
+			}
+			de.Samplers = append(de.Samplers, DirectorySampler{
+				Name:   s,
+				Type:   t,
+				Plugin: PluginName(sampler.Plugin),
+				Group:  group.String(),
+			})
+		}
+		byProbe[probe] = append(byProbe[probe], de)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(probes)) {
+		p := probes[name]
+		d.Probes = append(d.Probes, DirectoryProbe{
+			Name:     p.Name,
+			Type:     p.Type,
+			Hostname: p.Hostname,
+			Port:     p.Port,
+			Entities: byProbe[name],
+		})
+	}
+	return
+}
+
+// PluginName returns the name of the plugin configured in p, as used
+// in the Gateway setup, e.g. "fkm", or an empty string if there is
+// none.
+func PluginName(p *Plugin) string {
+	if p == nil {
+		return ""
+	}
+	v := reflect.ValueOf(p).Elem()
+	for sf, fv := range v.Fields() {
+		if fv.Kind() != reflect.Pointer || fv.IsNil() {
+			continue
+		}
+		if u, ok := fv.Interface().(*UnsupportedPlugin); ok {
+			return u.XMLName.Local
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("xml"), ",")
+		return name
+	}
+	return ""
+}
+
+// Match returns an XPath to each item in the directory that x matches.
+// The names, attributes and predicates of each element in x are
+// evaluated with:
+//
+//   - Gateway: `@name`, if the setup includes the Gateway name
+//   - Probe: `@name` and the parameters `HostName` and `Port`
+//   - Managed Entity: `@name` and `attr()` for all inherited attributes
+//   - Sampler: `@name`, `@type` and the parameters `PluginName` and
+//     `Group`
+//
+// Dataviews and their contents are only known to a running Gateway, so
+// for XPaths below samplers the matching samplers are returned.
+// Predicates that test properties that are not available offline
+// evaluate to false, as they would for an item without the property.
+func (d *Directory) Match(x *xpath.XPath) (matches []*xpath.XPath) {
+	if d == nil || x == nil || x.Gateway == nil {
+		return
+	}
+	if d.Gateway != "" && !x.Gateway.Matches(xpath.Values{Properties: map[string]string{"name": d.Gateway}}) {
+		return
+	}
+	gw := &xpath.Gateway{Name: d.Gateway}
+	if x.Probe == nil {
+		return []*xpath.XPath{{Gateway: gw}}
+	}
+
+	for _, p := range d.Probes {
+		pn := xpath.Values{
+			Properties: map[string]string{"name": p.Name},
+			Params:     map[string]string{"HostName": p.Hostname},
+		}
+		if p.Port != 0 {
+			pn.Params["Port"] = fmt.Sprint(p.Port)
+		}
+		if !x.Probe.Matches(pn) {
+			continue
+		}
+		probe := &xpath.Probe{Name: p.Name}
+		if x.Entity == nil {
+			matches = append(matches, &xpath.XPath{Gateway: gw, Probe: probe})
+			continue
+		}
+
+		for _, e := range p.Entities {
+			en := xpath.Values{
+				Properties: map[string]string{"name": e.Name},
+				Attributes: e.Attributes,
+			}
+			if !x.Entity.Matches(en) {
+				continue
+			}
+			entity := &xpath.Entity{Name: e.Name, Attributes: map[string]string{}}
+			if x.Sampler == nil {
+				matches = append(matches, &xpath.XPath{Gateway: gw, Probe: probe, Entity: entity})
+				continue
+			}
+
+			for _, s := range e.Samplers {
+				sn := xpath.Values{
+					Properties: map[string]string{"name": s.Name, "type": s.Type},
+					Params:     map[string]string{"PluginName": s.Plugin, "Group": s.Group},
+				}
+				if !x.Sampler.Matches(sn) {
+					continue
+				}
+				matches = append(matches, &xpath.XPath{
+					Gateway: gw,
+					Probe:   probe,
+					Entity:  entity,
+					Sampler: &xpath.Sampler{Name: s.Name, Type: &s.Type},
+				})
+			}
+		}
+	}
+	return
+}
+
+// TargetMatches is the result of matching a target XPath against a
+// Gateway setup. Rule is the path to the rule the target is from, if
+// any, and Error is set if the target cannot be parsed.
+type TargetMatches struct {
+	Rule    string         `json:"rule,omitempty"`
+	Target  string         `json:"target"`
+	Matches []*xpath.XPath `json:"matches"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// MatchTarget parses target and returns the items in the directory
+// that it matches. See [Directory.Match].
+func (d *Directory) MatchTarget(target string) (t TargetMatches) {
+	t = TargetMatches{Target: target, Matches: []*xpath.XPath{}}
+	x, err := xpath.Parse(target)
+	if err != nil {
+		t.Error = err.Error()
+		return
+	}
+	if m := d.Match(x); m != nil {
+		t.Matches = m
+	}
+	return
+}
+
+// MatchRuleTargets returns the matches for every target of every
+// enabled rule in the Gateway setup g, ordered by rule path, in the
+// same `Group > Rule` form as used by Geneos, and then target order.
+func MatchRuleTargets(g *Gateway) (targets []TargetMatches) {
+	if g == nil {
+		return
+	}
+	d := NewDirectory(g)
+	rules := UnrollRules(g.Rules)
+	for _, name := range slices.Sorted(maps.Keys(rules)) {
+		for _, target := range rules[name].Targets {
+			t := d.MatchTarget(strings.TrimSpace(target))
+			t.Rule = name
+			targets = append(targets, t)
+		}
+	}
+	return
+}
@@ -36,6 +36,9 @@ type Probes struct {
 	Probes         []Probe         `xml:"probe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"probe"`
 	VirtualProbes  []VirtualProbe  `xml:"virtualProbe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"virtualprobe"`
 	FloatingProbes []FloatingProbe `xml:"floatingProbe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"floatingprobe"`
+	Other          []XMLElement    `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs     []xml.Attr      `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw            preserved
 }
 
 type ProbeGroup struct {
@@ -47,6 +50,9 @@ type ProbeGroup struct {
 	Probes         []Probe         `xml:"probe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"probe"`
 	VirtualProbes  []VirtualProbe  `xml:"virtualProbe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"virtualprobe"`
 	FloatingProbes []FloatingProbe `xml:"floatingProbe,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"floatingprobe"`
+	Other          []XMLElement    `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs     []xml.Attr      `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw            preserved
 }
 
 // ProbeInfo is embedded in groups and probes
@@ -72,23 +78,32 @@ const (
 )
 
 type Probe struct {
-	XMLName   xml.Name `xml:"probe" json:"-" yaml:"-"`
-	Type      int      `xml:"-" json:"-" yaml:"-"`
-	Name      string   `xml:"name,attr" json:"name" yaml:"name"`
-	Disabled  bool     `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
-	Hostname  string   `xml:"hostname" json:"hostname" yaml:"hostname"`
-	ProbeInfo `json:",inline" yaml:",inline" mapstructure:",squash"`
+	XMLName    xml.Name `xml:"probe" json:"-" yaml:"-"`
+	Type       int      `xml:"-" json:"-" yaml:"-"`
+	Name       string   `xml:"name,attr" json:"name" yaml:"name"`
+	Disabled   bool     `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	Hostname   string   `xml:"hostname" json:"hostname" yaml:"hostname"`
+	ProbeInfo  `json:",inline" yaml:",inline" mapstructure:",squash"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type VirtualProbe struct {
-	XMLName  xml.Name `xml:"virtualProbe" json:"-" yaml:"-"`
-	Name     string   `xml:"name,attr" json:"name" yaml:"name"`
-	Disabled bool     `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	XMLName    xml.Name     `xml:"virtualProbe" json:"-" yaml:"-"`
+	Name       string       `xml:"name,attr" json:"name" yaml:"name"`
+	Disabled   bool         `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type FloatingProbe struct {
 	XMLName              xml.Name `xml:"floatingProbe" json:"-" yaml:"-"`
-	Name                 string   `xml:"name.attr" json:"name" yaml:"name"`
+	Name                 string   `xml:"name,attr" json:"name" yaml:"name"`
 	Disabled             bool     `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
 	ProbeInfoWithoutPort `yaml:",inline" mapstructure:",squash"`
+	Other                []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs           []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw                  preserved
 }
@@ -44,6 +44,9 @@ type ProcessDescriptors struct {
 	XMLName                 xml.Name                 `xml:"processDescriptors" json:"-" yaml:"-"`
 	ProcessDescriptors      []ProcessDescriptor      `xml:"data,omitempty" json:",omitempty" yaml:",omitempty"`
 	ProcessDescriptorGroups []ProcessDescriptorGroup `xml:"processDescriptorGroup,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other                   []XMLElement             `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs              []xml.Attr               `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw                     preserved
 }
 
 type ProcessDescriptorGroup struct {
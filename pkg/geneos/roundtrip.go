@@ -0,0 +1,669 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geneos
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Round-trip support
+//
+// The structures in this package only model part of a Gateway setup.
+// So that a setup can be read, changed and written back without losing
+// anything, two things are done:
+//
+//   - Section, group and item structures have `Other` and `OtherAttrs`
+//     fields that collect any child elements and attributes that are not
+//     otherwise modelled. These are written back, after the modelled
+//     fields, when the structure is marshalled.
+//
+//   - When these structures are unmarshalled the original XML is kept
+//     along with the result of marshalling the value as it was read.
+//     When the value is marshalled again, if the result is the same then
+//     the original XML is written instead, keeping the order of elements
+//     and any content in partially modelled plugins and settings.
+//
+// So, unchanged items are always written exactly as read, while changed
+// items are written from the structures, including the unknown content
+// captured in `Other` and `OtherAttrs`.
+//
+// The decoder replaces namespace prefixes, such as those on vendor
+// elements and attributes, with the namespace URL and the encoder would
+// then add its own declarations. Names are instead kept with the prefix
+// in the local name, e.g. `acme:item`, so they are written back as read.
+
+// XMLElement is an XML element that is not otherwise modelled, kept so
+// that it can be written back unchanged
+type XMLElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	Inner   string     `xml:",innerxml" json:",omitempty" yaml:",omitempty"`
+}
+
+func (x *XMLElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain XMLElement
+	start = prefixedElement(start, nil)
+	return d.DecodeElement((*plain)(x), &start)
+}
+
+// namespaces returns a map of namespace URL to prefix for the
+// namespaces declared in attrs
+func namespaces(attrs []xml.Attr) (ns map[string]string) {
+	ns = map[string]string{}
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			ns[a.Value] = a.Name.Local
+		}
+	}
+	return
+}
+
+// prefixedName returns n, as translated by the decoder, with the
+// namespace prefix back in the local name. The prefix is looked up in
+// ns and otherwise, when the namespace was not declared in the XML
+// being decoded, the decoder leaves the prefix in n.Space. Names in
+// namespaces that cannot be mapped back are returned unchanged.
+func prefixedName(n xml.Name, ns map[string]string) xml.Name {
+	switch {
+	case n.Space == "":
+		return n
+	case n.Space == "xmlns":
+		return xml.Name{Local: "xmlns:" + n.Local}
+	case n.Space == "http://www.w3.org/XML/1998/namespace":
+		return xml.Name{Local: "xml:" + n.Local}
+	}
+	if prefix, ok := ns[n.Space]; ok {
+		return xml.Name{Local: prefix + ":" + n.Local}
+	}
+	if strings.ContainsAny(n.Space, ":/") {
+		return n
+	}
+	return xml.Name{Local: n.Space + ":" + n.Local}
+}
+
+// prefixedElement returns a copy of start with prefixedName applied to
+// the element and attribute names. Namespaces declared on start are
+// added to those in ns.
+func prefixedElement(start xml.StartElement, ns map[string]string) xml.StartElement {
+	own := namespaces(start.Attr)
+	for url, prefix := range ns {
+		if _, ok := own[url]; !ok {
+			own[url] = prefix
+		}
+	}
+	start = start.Copy()
+	start.Name = prefixedName(start.Name, own)
+	for i := range start.Attr {
+		start.Attr[i].Name = prefixedName(start.Attr[i].Name, own)
+	}
+	return start
+}
+
+// preserved holds the original XML of an element and the encoding of
+// the value it was unmarshalled into
+type preserved struct {
+	start       xml.StartElement
+	inner       string
+	fingerprint string
+}
+
+// unmarshalPreserved decodes the element start from d into v, which
+// must be a pointer to a type without its own UnmarshalXML method, and
+// saves the original XML in p
+func unmarshalPreserved(d *xml.Decoder, start xml.StartElement, v any, p *preserved) (err error) {
+	ns := namespaces(start.Attr)
+	start = prefixedElement(start, ns)
+
+	var raw struct {
+		Inner []byte `xml:",innerxml"`
+	}
+	if err = d.DecodeElement(&raw, &start); err != nil {
+		return
+	}
+
+	// rebuild the element and decode it again into v
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	if err = e.EncodeElement(raw, start); err != nil {
+		return
+	}
+	if err = xml.NewDecoder(&b).Decode(v); err != nil {
+		return
+	}
+	if attrs := reflect.ValueOf(v).Elem().FieldByName("OtherAttrs"); attrs.IsValid() {
+		for i := range attrs.Len() {
+			a := attrs.Index(i).Addr().Interface().(*xml.Attr)
+			a.Name = prefixedName(a.Name, ns)
+		}
+	}
+
+	p.start = start.Copy()
+	p.inner = string(raw.Inner)
+	p.fingerprint, err = fingerprint(v, start)
+	return
+}
+
+// marshalPreserved encodes v, which must be of a type without its own
+// MarshalXML method, to e. If v encodes to the same XML as when it was
+// unmarshalled into p then the original XML is written instead.
+func marshalPreserved(e *xml.Encoder, start xml.StartElement, v any, p preserved) (err error) {
+	start = xml.StartElement{Name: xml.Name{Local: start.Name.Local}}
+	if p.start.Name.Local != "" {
+		if f, err := fingerprint(v, start); err == nil && f == p.fingerprint {
+			return e.EncodeElement(struct {
+				Inner string `xml:",innerxml"`
+			}{p.inner}, p.start)
+		}
+	}
+	return e.EncodeElement(v, start)
+}
+
+// fingerprint returns the encoding of v as an element with the local
+// name of start
+func fingerprint(v any, start xml.StartElement) (string, error) {
+	var b bytes.Buffer
+	if err := xml.NewEncoder(&b).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: start.Name.Local}}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// WriteGateway encodes the Gateway setup g to w, with an XML header. A
+// setup read with ReadGateway and written with WriteGateway is
+// unchanged except for the header and, in changed items, formatting.
+func WriteGateway(w io.Writer, g *Gateway) (err error) {
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	e := xml.NewEncoder(w)
+	if err = e.Encode(g); err != nil {
+		return
+	}
+	_, err = io.WriteString(w, "\n")
+	return
+}
+
+// gatewaySections are the elements directly under the gateway root
+// that are modelled, in the order they are written in a new setup
+var gatewaySections = []string{
+	"includes",
+	"probes",
+	"managedEntities",
+	"types",
+	"samplers",
+	"rules",
+	"environments",
+	"staticVars",
+	"operatingEnvironment",
+}
+
+// staticVars is the container for the process descriptors, and any
+// other static variables that are not modelled
+type staticVars struct {
+	XMLName            xml.Name            `xml:"staticVars"`
+	ProcessDescriptors *ProcessDescriptors `xml:"processDescriptors,omitempty"`
+	Other              []XMLElement        `xml:",any"`
+	OtherAttrs         []xml.Attr          `xml:",any,attr"`
+	raw                preserved
+}
+
+func (v *staticVars) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain staticVars
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v staticVars) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain staticVars
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+// UnmarshalXML decodes a Gateway setup, recording the order of the
+// top-level sections and keeping any sections and attributes that are
+// not modelled
+func (g *Gateway) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	g.XMLName = start.Name
+	ns := namespaces(start.Attr)
+	start = prefixedElement(start, ns)
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "compatibility":
+			if g.Compatibility, err = strconv.Atoi(a.Value); err != nil {
+				return
+			}
+		case "xmlns:xsi":
+			g.XMLNs = a.Value
+		case "xsi:noNamespaceSchemaLocation":
+			g.XSI = a.Value
+		default:
+			g.OtherAttrs = append(g.OtherAttrs, a)
+		}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.CharData, xml.Comment:
+			g.order = append(g.order, xml.CopyToken(t))
+		case xml.StartElement:
+			t = prefixedElement(t, ns)
+			g.order = append(g.order, t.Name.Local)
+			switch t.Name.Local {
+			case "includes":
+				err = d.DecodeElement(&g.Includes, &t)
+			case "probes":
+				err = d.DecodeElement(&g.Probes, &t)
+			case "managedEntities":
+				err = d.DecodeElement(&g.ManagedEntities, &t)
+			case "types":
+				err = d.DecodeElement(&g.Types, &t)
+			case "samplers":
+				err = d.DecodeElement(&g.Samplers, &t)
+			case "rules":
+				err = d.DecodeElement(&g.Rules, &t)
+			case "environments":
+				err = d.DecodeElement(&g.Environments, &t)
+			case "operatingEnvironment":
+				err = d.DecodeElement(&g.OperatingEnvironment, &t)
+			case "staticVars":
+				g.staticVars = &staticVars{}
+				if err = d.DecodeElement(g.staticVars, &t); err == nil {
+					g.ProcessDescriptors = g.staticVars.ProcessDescriptors
+				}
+			default:
+				var o XMLElement
+				err = d.DecodeElement(&o, &t)
+				g.Other = append(g.Other, o)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// MarshalXML encodes a Gateway setup. If the setup was unmarshalled then
+// the top-level sections are written in their original order, with any
+// new sections placed before the next section that follows them in a
+// new setup, otherwise in the order of gatewaySections followed by any
+// other sections.
+func (g Gateway) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+	start = xml.StartElement{Name: xml.Name{Local: "gateway"}}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "compatibility"}, Value: strconv.Itoa(g.Compatibility)})
+	if g.XMLNs != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: g.XMLNs})
+	}
+	if g.XSI != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xsi:noNamespaceSchemaLocation"}, Value: g.XSI})
+	}
+	for _, a := range g.OtherAttrs {
+		if a.Name.Space == "xmlns" {
+			a.Name = xml.Name{Local: "xmlns:" + a.Name.Local}
+		}
+		start.Attr = append(start.Attr, a)
+	}
+
+	sv := g.staticVars
+	if g.ProcessDescriptors != nil || sv != nil {
+		if sv == nil {
+			sv = &staticVars{}
+		}
+		s := *sv
+		s.ProcessDescriptors = g.ProcessDescriptors
+		sv = &s
+	}
+
+	sections := map[string]any{}
+	set := func(name string, present bool, v any) {
+		if present {
+			sections[name] = v
+		}
+	}
+	set("includes", g.Includes != nil, g.Includes)
+	set("probes", g.Probes != nil, g.Probes)
+	set("managedEntities", g.ManagedEntities != nil, g.ManagedEntities)
+	set("types", g.Types != nil, g.Types)
+	set("samplers", g.Samplers != nil, g.Samplers)
+	set("rules", g.Rules != nil, g.Rules)
+	set("environments", g.Environments != nil, g.Environments)
+	set("staticVars", sv != nil, sv)
+	set("operatingEnvironment", g.OperatingEnvironment != nil, g.OperatingEnvironment)
+
+	order := slices.Clone(g.order)
+	for n, name := range gatewaySections {
+		if _, ok := sections[name]; !ok || slices.ContainsFunc(order, func(o xml.Token) bool { return o == name }) {
+			continue
+		}
+		// insert before the first recorded section that comes later
+		pos := len(order)
+		for i, o := range order {
+			if o, ok := o.(string); ok && slices.Contains(gatewaySections[n+1:], o) {
+				pos = i
+				break
+			}
+		}
+		order = slices.Insert(order, pos, xml.Token(name))
+	}
+
+	// the contents are built separately so that whitespace and comments
+	// between sections can be written unescaped
+	var b bytes.Buffer
+	ce := xml.NewEncoder(&b)
+	other := slices.Clone(g.Other)
+	for _, o := range order {
+		switch t := o.(type) {
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				ce.Flush()
+				b.Write(t)
+			} else if err = ce.EncodeToken(t); err != nil {
+				return
+			}
+			continue
+		case xml.Comment:
+			if err = ce.EncodeToken(t); err != nil {
+				return
+			}
+			continue
+		}
+		name := o.(string)
+		if v, ok := sections[name]; ok {
+			if err = ce.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return
+			}
+			delete(sections, name)
+			continue
+		}
+		if slices.Contains(gatewaySections, name) {
+			// modelled section that has since been removed
+			continue
+		}
+		if i := slices.IndexFunc(other, func(o XMLElement) bool { return o.XMLName.Local == name }); i != -1 {
+			if err = ce.Encode(other[i]); err != nil {
+				return
+			}
+			other = slices.Delete(other, i, i+1)
+		}
+	}
+	for _, o := range other {
+		if err = ce.Encode(o); err != nil {
+			return
+		}
+	}
+	if err = ce.Flush(); err != nil {
+		return
+	}
+
+	return e.EncodeElement(struct {
+		Inner []byte `xml:",innerxml"`
+	}{b.Bytes()}, start)
+}
+
+// The UnmarshalXML and MarshalXML methods below keep the original XML of
+// each section, group and item so that unchanged ones are written back
+// as read. The local plain type has no methods, avoiding recursion.
+
+func (v *Includes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Includes
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Includes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Includes
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Include) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Include
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Include) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Include
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *OperatingEnvironment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain OperatingEnvironment
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v OperatingEnvironment) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain OperatingEnvironment
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Probes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Probes
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Probes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Probes
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *ProbeGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ProbeGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v ProbeGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ProbeGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Probe) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Probe
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Probe) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Probe
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *VirtualProbe) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain VirtualProbe
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v VirtualProbe) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain VirtualProbe
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *FloatingProbe) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain FloatingProbe
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v FloatingProbe) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain FloatingProbe
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *ManagedEntities) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ManagedEntities
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v ManagedEntities) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ManagedEntities
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *ManagedEntityGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ManagedEntityGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v ManagedEntityGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ManagedEntityGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *ManagedEntity) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ManagedEntity
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v ManagedEntity) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ManagedEntity
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Types) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Types
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Types) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Types
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *TypeGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain TypeGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v TypeGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain TypeGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Type) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Type
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Type) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Type
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Samplers) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Samplers
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Samplers) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Samplers
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *SamplerGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain SamplerGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v SamplerGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain SamplerGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Sampler) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Sampler
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Sampler) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Sampler
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Rules) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Rules
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Rules) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Rules
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *RuleGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain RuleGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v RuleGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain RuleGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Rule) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Rule
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Rule) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Rule
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Environments) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Environments
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Environments) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Environments
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *EnvironmentGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain EnvironmentGroup
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v EnvironmentGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain EnvironmentGroup
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *Environment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Environment
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v Environment) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Environment
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
+
+func (v *ProcessDescriptors) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ProcessDescriptors
+	return unmarshalPreserved(d, start, (*plain)(v), &v.raw)
+}
+
+func (v ProcessDescriptors) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ProcessDescriptors
+	return marshalPreserved(e, start, plain(v), v.raw)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geneos
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func readSetup(t *testing.T, path string) *Gateway {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := ReadGateway(f)
+	if err != nil {
+		t.Fatalf("ReadGateway(%s): %v", path, err)
+	}
+	return g
+}
+
+func writeSetup(t *testing.T, g *Gateway) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := WriteGateway(&b, g); err != nil {
+		t.Fatalf("WriteGateway: %v", err)
+	}
+	return b.Bytes()
+}
+
+// compareGolden compares got with the golden file path, first updating
+// it if the -update flag is set
+func compareGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// TestGatewayRoundTrip checks that each sample setup, which all have an
+// XML header the same as that written by WriteGateway, is written back
+// byte for byte as read
+func TestGatewayRoundTrip(t *testing.T) {
+	setups, err := filepath.Glob(filepath.Join("testdata", "*.setup.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(setups) == 0 {
+		t.Fatal("no sample setups in testdata")
+	}
+	for _, path := range setups {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := writeSetup(t, readSetup(t, path))
+			if !bytes.Equal(got, want) {
+				t.Errorf("round trip changed setup:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestGatewayRoundTripEdited changes some items in a sample setup and
+// compares the result with a golden file. Changed items are rewritten
+// but must keep their unknown elements and attributes, while unchanged
+// items are written as read.
+func TestGatewayRoundTripEdited(t *testing.T) {
+	g := readSetup(t, filepath.Join("testdata", "vendor.setup.xml"))
+
+	g.Probes.ProbeGroups[0].Probes[0].Port = 7037
+	g.Samplers.Samplers[0].Disabled = true
+	g.Probes.ProbeGroups[0].Probes = append(g.Probes.ProbeGroups[0].Probes, Probe{
+		Name:     "app3",
+		Hostname: "app3.example.com",
+	})
+
+	got := writeSetup(t, g)
+	compareGolden(t, filepath.Join("testdata", "vendor.edited.xml"), got)
+
+	for _, s := range []string{
+		`acme:tier="gold"`,
+		`<acme:rack>A12</acme:rack>`,
+		`acme:generated="true"`,
+		`<acme:docs><![CDATA[See <runbook> & notes]]></acme:docs>`,
+	} {
+		if !strings.Contains(string(got), s) {
+			t.Errorf("edited setup is missing %s", s)
+		}
+	}
+
+	// the edited setup must itself round trip unchanged
+	again, err := ReadGateway(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := writeSetup(t, again); !bytes.Equal(b, got) {
+		t.Errorf("edited setup changed on second round trip:\ngot:\n%s\nwant:\n%s", b, got)
+	}
+}
+
+// TestGatewayNewSections checks that a section added to a setup that
+// did not have one is placed before the sections that follow it in a
+// new setup, leaving the rest as read
+func TestGatewayNewSections(t *testing.T) {
+	g := readSetup(t, filepath.Join("testdata", "unknown.setup.xml"))
+	g.ManagedEntities = &ManagedEntities{
+		Entities: []ManagedEntity{{Name: "DB 1", Probe: &Reference{Name: "db1"}}},
+	}
+	compareGolden(t, filepath.Join("testdata", "unknown.edited.xml"), writeSetup(t, g))
+}
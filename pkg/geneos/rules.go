@@ -22,9 +22,12 @@ import "encoding/xml"
 // Rules
 
 type Rules struct {
-	XMLName    xml.Name    `xml:"rules" json:"-" yaml:"-"`
-	RuleGroups []RuleGroup `xml:"ruleGroup,omitempty" json:"rulegroup,omitempty"`
-	Rules      []Rule      `xml:"rule,omitempty" json:"rule,omitempty"`
+	XMLName    xml.Name     `xml:"rules" json:"-" yaml:"-"`
+	RuleGroups []RuleGroup  `xml:"ruleGroup,omitempty" json:"rulegroup,omitempty"`
+	Rules      []Rule       `xml:"rule,omitempty" json:"rule,omitempty"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type RuleGroup struct {
@@ -34,6 +37,9 @@ type RuleGroup struct {
 	Defaults   []RuleDefault `xml:"default,omitempty"`
 	Rules      []Rule        `xml:"rule,omitempty" json:"rule,omitempty"`
 	RuleGroups []RuleGroup   `xml:"ruleGroup,omitempty" json:"rulegroup,omitempty"`
+	Other      []XMLElement  `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr    `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type RuleDefault struct {
@@ -45,12 +51,21 @@ type RuleDefault struct {
 }
 
 type Rule struct {
-	XMLName       xml.Name `xml:"rule" json:"-" yaml:"-"`
-	Name          string   `xml:"name,attr"`
-	Disabled      bool     `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
-	Targets       []string `xml:"targets>target"`
-	PriorityGroup int      `xml:"priorityGroup,omitempty" json:",omitempty" yaml:",omitempty"`
-	Priority      int      `xml:"priority"`
-	Ifs           []any    `xml:"ifs,omitempty" json:",omitempty" yaml:",omitempty"`
-	Transactions  []any    `xml:"tranactions,omitempty" json:",omitempty" yaml:",omitempty"`
+	XMLName       xml.Name     `xml:"rule" json:"-" yaml:"-"`
+	Name          string       `xml:"name,attr"`
+	Disabled      bool         `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	Targets       []string     `xml:"targets>target"`
+	PriorityGroup int          `xml:"priorityGroup,omitempty" json:",omitempty" yaml:",omitempty"`
+	Priority      int          `xml:"priority"`
+	Ifs           []any        `xml:"ifs,omitempty" json:",omitempty" yaml:",omitempty"`
+	Transactions  []any        `xml:"tranactions,omitempty" json:",omitempty" yaml:",omitempty"`
+	Block         *RuleBlock   `xml:"block,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other         []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs    []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw           preserved
+}
+
+// RuleBlock holds the body of a rule as unparsed XML
+type RuleBlock struct {
+	Contents string `xml:",innerxml"`
 }
@@ -25,6 +25,9 @@ type Samplers struct {
 	XMLName       xml.Name       `xml:"samplers" json:"-" yaml:"-"`
 	Samplers      []Sampler      `xml:"sampler,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"sampler"`
 	SamplerGroups []SamplerGroup `xml:"samplerGroup,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"samplergroup"`
+	Other         []XMLElement   `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs    []xml.Attr     `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw           preserved
 }
 
 type SamplerGroup struct {
@@ -33,6 +36,9 @@ type SamplerGroup struct {
 	Disabled      bool           `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
 	Samplers      []Sampler      `xml:"sampler,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"sampler"`
 	SamplerGroups []SamplerGroup `xml:"samplerGroup,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"samplergroup"`
+	Other         []XMLElement   `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs    []xml.Attr     `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw           preserved
 }
 
 // A Sampler is a Geneos Sampler structure. The Plugin field should be
@@ -50,6 +56,9 @@ type Sampler struct {
 	Dataviews              *[]Dataview             `xml:"dataviews>dataview,omitempty" json:",omitempty" yaml:",omitempty"`
 	Schemas                *Schemas                `xml:"schemas,omitempty" json:",omitempty" yaml:",omitempty"`
 	StandardisedFormatting *StandardisedFormatting `xml:"standardisedFormatting,omitempty" json:",omitempty" yaml:",omitempty"`
+	Other                  []XMLElement            `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs             []xml.Attr              `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw                    preserved
 }
 
 // Plugin lists all the plugins we know about
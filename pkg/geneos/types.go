@@ -20,17 +20,23 @@ package geneos
 import "encoding/xml"
 
 type Types struct {
-	XMLName    xml.Name    `xml:"types" json:"-" yaml:"-"`
-	Types      []Type      `xml:"type,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"type"`
-	TypeGroups []TypeGroup `xml:"typeGroup,omitempty" json:"typeGroup,omitempty" yaml:",omitempty" mapstructure:"typegroup"`
+	XMLName    xml.Name     `xml:"types" json:"-" yaml:"-"`
+	Types      []Type       `xml:"type,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"type"`
+	TypeGroups []TypeGroup  `xml:"typeGroup,omitempty" json:"typeGroup,omitempty" yaml:",omitempty" mapstructure:"typegroup"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type TypeGroup struct {
-	XMLName    xml.Name    `xml:"typeGroup" json:"-" yaml:"-"`
-	Name       string      `xml:"name,attr"`
-	Disabled   bool        `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
-	Types      []Type      `xml:"type,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"type"`
-	TypeGroups []TypeGroup `xml:"typeGroup,omitempty" json:"typeGroup,omitempty" yaml:",omitempty" mapstructure:"typegroup"`
+	XMLName    xml.Name     `xml:"typeGroup" json:"-" yaml:"-"`
+	Name       string       `xml:"name,attr"`
+	Disabled   bool         `xml:"disabled,attr,omitempty" json:",omitempty" yaml:",omitempty"`
+	Types      []Type       `xml:"type,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"type"`
+	TypeGroups []TypeGroup  `xml:"typeGroup,omitempty" json:"typeGroup,omitempty" yaml:",omitempty" mapstructure:"typegroup"`
+	Other      []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw        preserved
 }
 
 type Type struct {
@@ -40,6 +46,9 @@ type Type struct {
 	Environment *Reference   `xml:"environment,omitempty" json:",omitempty" yaml:",omitempty"`
 	Vars        []Vars       `xml:"var,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"var"`
 	Samplers    []SamplerRef `xml:"sampler,omitempty" json:",omitempty" yaml:",omitempty" mapstructure:"sampler"`
+	Other       []XMLElement `xml:",any" json:",omitempty" yaml:",omitempty"`
+	OtherAttrs  []xml.Attr   `xml:",any,attr" json:",omitempty" yaml:",omitempty"`
+	raw         preserved
 }
 
 type SamplerRef struct {
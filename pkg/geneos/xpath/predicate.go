@@ -0,0 +1,621 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Node is an item that a Predicate is evaluated against, such as a
+// Managed Entity or a dataview cell. Each method returns the value and
+// true, or false if the node does not have the property, parameter or
+// attribute.
+type Node interface {
+	// Property returns the value of a property, given without the
+	// leading "@", e.g. "name", "value" or "severity". The bare
+	// `rowname` term is evaluated as the property "rowname".
+	Property(name string) (value string, ok bool)
+
+	// Param returns the value of a sampler parameter, as used by
+	// `param("NAME")`
+	Param(name string) (value string, ok bool)
+
+	// Attribute returns the value of a Managed Entity attribute, as
+	// used by `attr("NAME")`
+	Attribute(name string) (value string, ok bool)
+}
+
+// Values is a simple implementation of Node using maps. Any of the maps
+// can be nil.
+type Values struct {
+	Properties map[string]string
+	Params     map[string]string
+	Attributes map[string]string
+}
+
+var _ Node = Values{}
+
+func (v Values) Property(name string) (value string, ok bool) {
+	value, ok = v.Properties[name]
+	return
+}
+
+func (v Values) Param(name string) (value string, ok bool) {
+	value, ok = v.Params[name]
+	return
+}
+
+func (v Values) Attribute(name string) (value string, ok bool) {
+	value, ok = v.Attributes[name]
+	return
+}
+
+// A Predicate is a parsed XPath predicate, the expression between `[`
+// and `]` in a path element. A predicate is made of comparisons of
+// properties (`@name`, `@value`, `@severity` etc.), `rowname`,
+// `param("NAME")`, `attr("NAME")`, strings and numbers using `=`, `!=`,
+// `<`, `<=`, `>` and `>=`, combined with `and`, `or`, `not()` and
+// parentheses. The functions `wild(VALUE, "PATTERN")`, using `*` and
+// `?` wildcards, `contains(VALUE, "TEXT")` and `starts-with(VALUE,
+// "TEXT")` are also supported. A bare value is true if it exists and is
+// not empty.
+//
+// Severities are compared as levels, so `@severity="critical"`,
+// `@severity="3"` and `@severity>"warning"` all match a critical cell.
+type Predicate struct {
+	e *expr
+}
+
+// ParsePredicate parses the predicate expression s, with or without
+// the enclosing `[` and `]`.
+func ParsePredicate(s string) (p *Predicate, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	ps := &predicateParser{}
+	if ps.tokens, err = lexPredicate(s); err != nil {
+		return
+	}
+	e, err := ps.or()
+	if err != nil {
+		return
+	}
+	if ps.pos < len(ps.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q in predicate %q", ErrInvalidPath, ps.tokens[ps.pos].text, s)
+	}
+	return &Predicate{e: e}, nil
+}
+
+// String returns the predicate in canonical form, including the
+// enclosing `[` and `]`. Parsing the result returns an identical
+// predicate.
+func (p *Predicate) String() string {
+	if p == nil || p.e == nil {
+		return ""
+	}
+	return "[" + p.e.String() + "]"
+}
+
+// Eval returns the result of evaluating the predicate against node n
+func (p *Predicate) Eval(n Node) bool {
+	if p == nil || p.e == nil {
+		return true
+	}
+	return p.e.bool(n)
+}
+
+// equals returns the name of the property compared and the literal
+// value if the predicate is a simple comparison in the form
+// `@property="value"`
+func (p *Predicate) equals() (property, value string, ok bool) {
+	if p.e.op != "=" || p.e.args[0].op != "prop" || p.e.args[1].op != "string" {
+		return
+	}
+	return p.e.args[0].name, p.e.args[1].name, true
+}
+
+// attribute returns the attribute name and value if the predicate is a
+// simple comparison in the form `attr("name")="value"`
+func (p *Predicate) attribute() (name, value string, ok bool) {
+	if p.e.op != "=" || p.e.args[1].op != "string" {
+		return
+	}
+	a := p.e.args[0]
+	if a.op != "call" || a.name != "attr" || len(a.args) != 1 || a.args[0].op != "string" {
+		return
+	}
+	return a.args[0].name, p.e.args[1].name, true
+}
+
+// matches returns true if node n has the property "name" equal to name,
+// unless name is empty, and all the predicates evaluate to true
+func matches(n Node, name string, predicates []*Predicate) bool {
+	if name != "" {
+		if v, ok := n.Property("name"); !ok || v != name {
+			return false
+		}
+	}
+	for _, p := range predicates {
+		if !p.Eval(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// expr is a node in a predicate expression tree. op is one of "or",
+// "and", a comparison operator, "call" (a function call, with name as
+// the function), "prop" (name is the property), "string" or "number"
+// (name is the literal value).
+type expr struct {
+	op   string
+	name string
+	args []*expr
+}
+
+var comparisons = []string{"=", "!=", "<", "<=", ">", ">="}
+
+func (e *expr) String() string {
+	switch e.op {
+	case "or", "and":
+		var s []string
+		for _, a := range e.args {
+			// only an "or" inside an "and" needs parentheses, as
+			// comparisons are always wrapped
+			if a.op == "or" {
+				s = append(s, "("+a.String()+")")
+			} else {
+				s = append(s, a.String())
+			}
+		}
+		return strings.Join(s, " "+e.op+" ")
+	case "call":
+		var s []string
+		for _, a := range e.args {
+			s = append(s, a.String())
+		}
+		return e.name + "(" + strings.Join(s, ",") + ")"
+	case "prop":
+		if e.name == "rowname" {
+			return e.name
+		}
+		return "@" + e.name
+	case "string":
+		return strconv.Quote(e.name)
+	case "number":
+		return e.name
+	default:
+		var s []string
+		for _, a := range e.args {
+			if a.op == "or" || a.op == "and" {
+				s = append(s, "("+a.String()+")")
+			} else {
+				s = append(s, a.String())
+			}
+		}
+		return "(" + strings.Join(s, e.op) + ")"
+	}
+}
+
+// value returns the value of e for node n
+func (e *expr) value(n Node) (v string, ok bool) {
+	switch e.op {
+	case "string", "number":
+		return e.name, true
+	case "prop":
+		return n.Property(e.name)
+	case "call":
+		switch e.name {
+		case "param", "attr":
+			name, ok := e.args[0].value(n)
+			if !ok {
+				return "", false
+			}
+			if e.name == "param" {
+				return n.Param(name)
+			}
+			return n.Attribute(name)
+		}
+		return strconv.FormatBool(e.bool(n)), true
+	default:
+		return strconv.FormatBool(e.bool(n)), true
+	}
+}
+
+// bool returns the value of e for node n as a boolean
+func (e *expr) bool(n Node) bool {
+	switch e.op {
+	case "or":
+		for _, a := range e.args {
+			if a.bool(n) {
+				return true
+			}
+		}
+		return false
+	case "and":
+		for _, a := range e.args {
+			if !a.bool(n) {
+				return false
+			}
+		}
+		return true
+	case "call":
+		switch e.name {
+		case "not":
+			return !e.args[0].bool(n)
+		case "wild", "contains", "starts-with":
+			v, ok := e.args[0].value(n)
+			if !ok {
+				return false
+			}
+			arg, ok := e.args[1].value(n)
+			if !ok {
+				return false
+			}
+			switch e.name {
+			case "wild":
+				return wildRE(arg).MatchString(v)
+			case "contains":
+				return strings.Contains(v, arg)
+			default:
+				return strings.HasPrefix(v, arg)
+			}
+		}
+	}
+	if len(e.args) == 2 {
+		return e.compare(n)
+	}
+	v, ok := e.value(n)
+	return ok && v != ""
+}
+
+// compare evaluates the comparison e for node n. Values are compared
+// as numbers if both are numeric, otherwise as strings. If either value
+// does not exist then the result is false, whatever the operator.
+func (e *expr) compare(n Node) bool {
+	l, ok := e.args[0].value(n)
+	if !ok {
+		return false
+	}
+	r, ok := e.args[1].value(n)
+	if !ok {
+		return false
+	}
+	if e.args[0].isSeverity() || e.args[1].isSeverity() {
+		l, r = severityLevel(l), severityLevel(r)
+	}
+
+	var c int
+	lf, lerr := strconv.ParseFloat(l, 64)
+	rf, rerr := strconv.ParseFloat(r, 64)
+	switch {
+	case lerr == nil && rerr == nil:
+		switch {
+		case lf < rf:
+			c = -1
+		case lf > rf:
+			c = 1
+		}
+	case e.op == "=" || e.op == "!=":
+		c = strings.Compare(l, r)
+	default:
+		// ordering only applies to numbers
+		return false
+	}
+
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func (e *expr) isSeverity() bool {
+	return e.op == "prop" && e.name == "severity"
+}
+
+// severityLevel returns the numeric level of severity s, which can be
+// a number or a name in any case. Unknown values are returned as-is.
+func severityLevel(s string) string {
+	switch strings.ToLower(s) {
+	case "", "0", "undefined", "none":
+		return "0"
+	case "1", "ok":
+		return "1"
+	case "2", "warning":
+		return "2"
+	case "3", "critical":
+		return "3"
+	default:
+		return s
+	}
+}
+
+// wildRE returns a regular expression for the wildcard pattern, where
+// `*` matches any sequence of characters and `?` any single character
+func wildRE(pattern string) *regexp.Regexp {
+	p := regexp.QuoteMeta(pattern)
+	p = strings.ReplaceAll(p, `\*`, `.*`)
+	p = strings.ReplaceAll(p, `\?`, `.`)
+	return regexp.MustCompile(`^` + p + `$`)
+}
+
+// functions are the functions supported in predicates, and the number
+// of arguments each takes
+var functions = map[string]int{
+	"not":         1,
+	"param":       1,
+	"attr":        1,
+	"wild":        2,
+	"contains":    2,
+	"starts-with": 2,
+}
+
+type token struct {
+	kind string // "(", ")", ",", "@", "op", "ident", "string", "number"
+	text string
+}
+
+// lexPredicate splits the predicate expression s into tokens
+func lexPredicate(s string) (tokens []token, err error) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '@':
+			tokens = append(tokens, token{kind: string(c), text: string(c)})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{kind: "op", text: "="})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			if i+1 < len(s) && s[i+1] == '=' {
+				tokens = append(tokens, token{kind: "op", text: s[i : i+2]})
+				i += 2
+			} else if c == '!' {
+				return nil, fmt.Errorf("%w: unexpected '!' in predicate %q", ErrInvalidPath, s)
+			} else {
+				tokens = append(tokens, token{kind: "op", text: string(c)})
+				i++
+			}
+		case c == '"' || c == '\'':
+			var text string
+			var n int
+			if text, n, err = readString(s[i:]); err != nil {
+				return
+			}
+			tokens = append(tokens, token{kind: "string", text: text})
+			i += n
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			if _, err = strconv.ParseFloat(s[i:j], 64); err != nil {
+				return nil, fmt.Errorf("%w: invalid number %q in predicate", ErrInvalidPath, s[i:j])
+			}
+			tokens = append(tokens, token{kind: "number", text: s[i:j]})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '-' || s[j] == '.' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{kind: "ident", text: s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in predicate %q", ErrInvalidPath, c, s)
+		}
+	}
+	return
+}
+
+// readString reads a quoted string from the start of s, returning the
+// unquoted value and the number of bytes read. Double quoted strings
+// may contain backslash escapes, single quoted strings are literal.
+func readString(s string) (text string, n int, err error) {
+	q := s[0]
+	for n = 1; n < len(s); n++ {
+		if s[n] == '\\' && q == '"' {
+			n++
+			continue
+		}
+		if s[n] == q {
+			n++
+			if q == '\'' {
+				return s[1 : n-1], n, nil
+			}
+			if text, err = strconv.Unquote(s[:n]); err != nil {
+				// not a Go string, so just drop the escapes
+				text, err = unescape(s[1:n-1]), nil
+			}
+			return
+		}
+	}
+	return "", 0, fmt.Errorf("%w: unterminated string in predicate %q", ErrInvalidPath, s)
+}
+
+// unescape removes backslash escapes from s
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// predicateParser is a recursive descent parser over the tokens of a
+// predicate
+type predicateParser struct {
+	tokens []token
+	pos    int
+}
+
+func (ps *predicateParser) peek() (t token, ok bool) {
+	if ps.pos < len(ps.tokens) {
+		return ps.tokens[ps.pos], true
+	}
+	return
+}
+
+func (ps *predicateParser) expect(kind string) (t token, err error) {
+	t, ok := ps.peek()
+	if !ok {
+		return t, fmt.Errorf("%w: predicate ends early, expected %q", ErrInvalidPath, kind)
+	}
+	if t.kind != kind {
+		return t, fmt.Errorf("%w: unexpected %q in predicate, expected %q", ErrInvalidPath, t.text, kind)
+	}
+	ps.pos++
+	return
+}
+
+func (ps *predicateParser) keyword(word string) bool {
+	if t, ok := ps.peek(); ok && t.kind == "ident" && t.text == word {
+		ps.pos++
+		return true
+	}
+	return false
+}
+
+// or := and { "or" and }
+func (ps *predicateParser) or() (e *expr, err error) {
+	return ps.list("or", ps.and)
+}
+
+// and := comparison { "and" comparison }
+func (ps *predicateParser) and() (e *expr, err error) {
+	return ps.list("and", ps.comparison)
+}
+
+// list parses one or more terms using next, separated by the keyword
+// op. Nested lists of the same op are flattened.
+func (ps *predicateParser) list(op string, next func() (*expr, error)) (e *expr, err error) {
+	if e, err = next(); err != nil {
+		return
+	}
+	if t, ok := ps.peek(); !ok || t.kind != "ident" || t.text != op {
+		return
+	}
+	l := &expr{op: op}
+	for {
+		if e.op == op {
+			l.args = append(l.args, e.args...)
+		} else {
+			l.args = append(l.args, e)
+		}
+		if !ps.keyword(op) {
+			return l, nil
+		}
+		if e, err = next(); err != nil {
+			return
+		}
+	}
+}
+
+// comparison := term [ op term ]
+func (ps *predicateParser) comparison() (e *expr, err error) {
+	if e, err = ps.term(); err != nil {
+		return
+	}
+	t, ok := ps.peek()
+	if !ok || t.kind != "op" {
+		return
+	}
+	ps.pos++
+	r, err := ps.term()
+	if err != nil {
+		return
+	}
+	return &expr{op: t.text, args: []*expr{e, r}}, nil
+}
+
+// term := "(" or ")" | "@" ident | "rowname" | function "(" args ")" | string | number
+func (ps *predicateParser) term() (e *expr, err error) {
+	t, ok := ps.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: predicate ends early", ErrInvalidPath)
+	}
+	ps.pos++
+	switch t.kind {
+	case "(":
+		if e, err = ps.or(); err != nil {
+			return
+		}
+		_, err = ps.expect(")")
+		return
+	case "@":
+		var name token
+		if name, err = ps.expect("ident"); err != nil {
+			return
+		}
+		return &expr{op: "prop", name: name.text}, nil
+	case "string", "number":
+		return &expr{op: t.kind, name: t.text}, nil
+	case "ident":
+		if t.text == "rowname" {
+			return &expr{op: "prop", name: t.text}, nil
+		}
+		nargs, ok := functions[t.text]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown function %q in predicate", ErrInvalidPath, t.text)
+		}
+		if _, err = ps.expect("("); err != nil {
+			return
+		}
+		e = &expr{op: "call", name: t.text}
+		for {
+			var a *expr
+			if a, err = ps.or(); err != nil {
+				return
+			}
+			e.args = append(e.args, a)
+			if n, ok := ps.peek(); ok && n.kind == "," {
+				ps.pos++
+				continue
+			}
+			break
+		}
+		if _, err = ps.expect(")"); err != nil {
+			return
+		}
+		if len(e.args) != nargs {
+			return nil, fmt.Errorf("%w: %s() takes %d argument(s)", ErrInvalidPath, t.text, nargs)
+		}
+		return
+	}
+	return nil, fmt.Errorf("%w: unexpected %q in predicate", ErrInvalidPath, t.text)
+}
@@ -18,10 +18,10 @@ limitations under the License.
 /*
 Package to handle Geneos Gateway specific XPaths
 
-These are a subset of the W3C XPath 1.0 standard and this package is
-only interested in absolute paths and no matching is done. Geneos XPaths
-are of a particular hierarchy and the ones we are interesting in here
-are those used to communicate with the Gateway REST command API.
+These follow the Geneos XPath grammar as used by the Gateway, which is
+based on the W3C XPath 1.0 standard. Geneos XPaths are of a fixed
+hierarchy and the ones we are interested in here are those used to
+communicate with the Gateway REST command API.
 
 The two types of path handled are for headline or table cells, which
 have the form:
@@ -32,8 +32,13 @@ have the form:
 
 Each component except "geneos", "directory", "headlines" and "rows" can
 have a name and other predicates. The path can terminate at any level
-that can carry a name. Apart from names, only attributes in
-managedEntities are currently handled in anyway.
+that can carry a name. Paths can include `//` descendant steps and `*`
+wildcard elements, and predicates can test properties such as `@name`,
+`@value` and `@severity`, `rowname`, `param()` and `attr()`, combined
+with `and`, `or` and `not()`. See [Parse] and [Predicate] for details.
+
+Predicates can be evaluated locally against any [Node], such as the
+cells of a dataview snapshot, using the Matches method of each element.
 */
 package xpath
 
@@ -41,10 +46,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
-	"regexp"
+	"slices"
 	"strings"
-	"unicode"
 )
 
 var ErrInvalidPath = errors.New("invalid Geneos XPath")
@@ -67,38 +72,125 @@ type XPath struct {
 	Column   *Column   `json:"column,omitempty"`
 }
 
+// The elements of an XPath. Name is the value of a simple `@name`
+// predicate, or `@column` for a Column, and is empty for a wildcard.
+// Predicates holds any other predicates, all of which must be true for
+// the element to match.
+
 type Gateway struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Probe struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Entity struct {
 	Name       string            `json:"name,omitempty"`
 	Attributes map[string]string `json:"attributes,omitempty"`
+	Predicates []*Predicate      `json:"-"`
 }
 
 type Sampler struct {
-	Name string  `json:"name,omitempty"`
-	Type *string `json:"type,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Type       *string      `json:"type,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Dataview struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Headline struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Row struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
 }
 
 type Column struct {
-	Name string `json:"name,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	Predicates []*Predicate `json:"-"`
+}
+
+// Matches returns true if node n matches the name and predicates of
+// the element. A nil element matches any node.
+func (g *Gateway) Matches(n Node) bool {
+	return g == nil || matches(n, g.Name, g.Predicates)
+}
+
+// Matches returns true if node n matches the name and predicates of
+// the element. A nil element matches any node.
+func (p *Probe) Matches(n Node) bool {
+	return p == nil || matches(n, p.Name, p.Predicates)
+}
+
+// Matches returns true if node n matches the name, attributes and
+// predicates of the element. A nil element matches any node.
+func (e *Entity) Matches(n Node) bool {
+	if e == nil {
+		return true
+	}
+	for k, v := range e.Attributes {
+		if a, ok := n.Attribute(k); !ok || a != v {
+			return false
+		}
+	}
+	return matches(n, e.Name, e.Predicates)
+}
+
+// Matches returns true if node n matches the name, type and predicates
+// of the element. The type is checked against the "type" property of
+// n. A nil element matches any node.
+func (s *Sampler) Matches(n Node) bool {
+	if s == nil {
+		return true
+	}
+	if s.Type != nil {
+		if t, _ := n.Property("type"); t != *s.Type {
+			return false
+		}
+	}
+	return matches(n, s.Name, s.Predicates)
+}
+
+// Matches returns true if node n matches the name and predicates of
+// the element. A nil element matches any node.
+func (d *Dataview) Matches(n Node) bool {
+	return d == nil || matches(n, d.Name, d.Predicates)
+}
+
+// Matches returns true if node n matches the name and predicates of
+// the element. A nil element matches any node.
+func (h *Headline) Matches(n Node) bool {
+	return h == nil || matches(n, h.Name, h.Predicates)
+}
+
+// Matches returns true if node n matches the name and predicates of
+// the element. A nil element matches any node.
+func (r *Row) Matches(n Node) bool {
+	return r == nil || matches(n, r.Name, r.Predicates)
+}
+
+// Matches returns true if node n matches the column name and
+// predicates of the element. The name is checked against the "column"
+// property of n. A nil element matches any node.
+func (c *Column) Matches(n Node) bool {
+	if c == nil {
+		return true
+	}
+	if c.Name != "" {
+		if v, ok := n.Property("column"); !ok || v != c.Name {
+			return false
+		}
+	}
+	return matches(n, "", c.Predicates)
 }
 
 // New returns an XPath to the level of the element passed, which can be
@@ -382,64 +474,53 @@ func (x *XPath) IsGateway() bool {
 	return x.Gateway != nil && x.Probe == nil
 }
 
-// return a string representation of an XPath
+// String returns the string representation of an XPath. Names and
+// other simple predicates are written first, in a fixed order, followed
+// by any other predicates in the order they were parsed, so that
+// parsing the result returns an identical XPath. The exception is a
+// Headline with no name or predicates, which is written as
+// `.../headlines`, without a cell, as in earlier releases.
 func (x *XPath) String() (p string) {
 	if x.Gateway == nil {
 		return
 	}
-	p += "/geneos/gateway"
-	if x.Gateway.Name != "" {
-		p += fmt.Sprintf("[(@name=%q)]", x.Gateway.Name)
-	}
+	p += "/geneos/gateway" + nameString(x.Gateway.Name) + predicatesString(x.Gateway.Predicates)
 	p += "/directory"
 
 	if x.Probe == nil {
 		return
 	}
-	p += "/probe"
-	if x.Probe.Name != "" {
-		p += fmt.Sprintf("[(@name=%q)]", x.Probe.Name)
-	}
+	p += "/probe" + nameString(x.Probe.Name) + predicatesString(x.Probe.Predicates)
 
 	if x.Entity == nil {
 		return
 	}
-	p += "/managedEntity"
-	if x.Entity.Name != "" {
-		p += fmt.Sprintf("[(@name=%q)]", x.Entity.Name)
-	}
-	for k, v := range x.Entity.Attributes {
-		p += fmt.Sprintf("[(attr(%q)=%q)]", k, v)
+	p += "/managedEntity" + nameString(x.Entity.Name)
+	for _, k := range slices.Sorted(maps.Keys(x.Entity.Attributes)) {
+		p += fmt.Sprintf("[(attr(%q)=%q)]", k, x.Entity.Attributes[k])
 	}
+	p += predicatesString(x.Entity.Predicates)
 
 	if x.Sampler == nil {
 		return
 	}
-	p += "/sampler"
-	if x.Sampler.Name != "" {
-		p += fmt.Sprintf("[(@name=%q)]", x.Sampler.Name)
-	}
+	p += "/sampler" + nameString(x.Sampler.Name)
 	if x.Sampler.Type != nil {
 		p += fmt.Sprintf("[(@type=%q)]", *x.Sampler.Type)
 	}
+	p += predicatesString(x.Sampler.Predicates)
 
 	if x.Dataview == nil {
 		return
 	}
-	p += "/dataview"
-	if x.Dataview.Name != "" {
-		p += fmt.Sprintf("[(@name=%q)]", x.Dataview.Name)
-	}
+	p += "/dataview" + nameString(x.Dataview.Name) + predicatesString(x.Dataview.Predicates)
 
 	if x.Rows {
 		p += "/rows"
 		if x.Row == nil {
 			return
 		}
-		p += "/row"
-		if x.Row.Name != "" {
-			p += fmt.Sprintf("[(@name=%q)]", x.Row.Name)
-		}
+		p += "/row" + nameString(x.Row.Name) + predicatesString(x.Row.Predicates)
 		if x.Column == nil {
 			return
 		}
@@ -447,202 +528,336 @@ func (x *XPath) String() (p string) {
 		if x.Column.Name != "" {
 			p += fmt.Sprintf("[(@column=%q)]", x.Column.Name)
 		}
+		p += predicatesString(x.Column.Predicates)
 	} else {
 		if x.Headline == nil {
 			return
 		}
 		p += "/headlines"
-		if x.Headline.Name != "" {
-			p += fmt.Sprintf("/cell[(@name=%q)]", x.Headline.Name)
+		if x.Headline.Name == "" && len(x.Headline.Predicates) == 0 {
+			return
 		}
+		p += "/cell" + nameString(x.Headline.Name) + predicatesString(x.Headline.Predicates)
 	}
 	return
 }
 
+func nameString(name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("[(@name=%q)]", name)
+}
+
+func predicatesString(predicates []*Predicate) (s string) {
+	for _, p := range predicates {
+		s += p.String()
+	}
+	return
+}
+
+// levels in the Geneos XPath hierarchy. The names of the elements at
+// levels below the dataview depend on whether the path is to table
+// rows or headlines.
+const (
+	levelGeneos = iota
+	levelGateway
+	levelDirectory
+	levelProbe
+	levelEntity
+	levelSampler
+	levelDataview
+	levelRowsHeadlines
+	levelRowHeadline
+	levelCell
+)
+
+var levelNames = []string{
+	"geneos",
+	"gateway",
+	"directory",
+	"probe",
+	"managedEntity",
+	"sampler",
+	"dataview",
+}
+
+// step is one element of a path, e.g. `//probe[(@name="p1")]`
+type step struct {
+	descendant bool
+	name       string
+	predicates []*Predicate
+}
+
 // Parse takes an absolute Geneos XPath and returns an XPath structure.
 //
-// A leading double slash, e.g. //probe[(@name="myprobe")], results in
-// preceding levels being filled-in and further processing continuing
-// from there. Because of the general purpose nature of the function
-// only levels down to //rows and //headlines are supported. If you need
-// a general path to a cell then you must use either //rows/row/cell or
-// //headlines/cell to ensure the returned path uses the correct
-// structure.
+// As the structure of Geneos XPaths is fixed, a double slash, e.g.
+// //probe[(@name="myprobe")] or /geneos/gateway//dataview, results in
+// the skipped levels being filled-in with empty elements and further
+// processing continuing from there. A path to a cell must include
+// either `rows` or `headlines`, e.g. //rows/row/cell or //headlines/cell,
+// to ensure the returned path uses the correct structure.
 //
-// Full wildcards, e.g. `//*`, are not supported as it is not possible
-// to determine the terminating level.
+// An element name of `*` matches the element at that level, e.g.
+// /geneos/gateway/directory/*/managedEntity, except immediately after a
+// double slash or where the level is ambiguous. Full wildcards, e.g.
+// `//*`, are not supported as it is not possible to determine the
+// terminating level.
 //
-// Support for predicates is limited. Currently all components
-// understand "name", e.g. //probe[(@name="probeName")], while
-// "managedEntity" supports multiple "attribute" predicates and table
-// cells (under "rows/row/cell") support "column" (which is used instead
-// of "name").
+// Each element can have any number of predicates, see [Predicate] for
+// the supported syntax. Simple equality predicates are stored in the
+// fields of the element, `@name` for all elements except table cells,
+// which use `@column`, `attr("NAME")` for Managed Entities and `@type`
+// for samplers. All other predicates are stored, in order, in the
+// Predicates field of the element.
 func Parse(s string) (xpath *XPath, err error) {
 	xpath = &XPath{}
 
-	parts := splitWithEscaping(s, '/', '\\')
-
 	// if the path is relative, handle it differently
 	if !strings.HasPrefix(s, "/") {
 		err = ErrRelativePath
 		return
 	}
 
-	// if the path is a wildcard, find the first level and prefix the parts[] with
-	// the higher levels
-	if strings.HasPrefix(s, "//") {
-		f := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
-		if len(f) == 0 {
-			err = ErrInvalidPath
-			return
-		}
-		parts = parts[2:]
-		switch f[0] {
-		case "gateway":
-			// simple case
-			parts = []string{"", "geneos", "gateway"}
-		case "rows", "headlines":
-			parts = append([]string{"dataview"}, parts...)
-			fallthrough
-		case "dataview":
-			parts = append([]string{"sampler"}, parts...)
-			fallthrough
-		case "sampler":
-			parts = append([]string{"managedEntity"}, parts...)
-			fallthrough
-		case "managedEntity":
-			parts = append([]string{"probe"}, parts...)
-			fallthrough
-		case "probe":
-			parts = append([]string{"", "geneos", "gateway", "directory"}, parts...)
-
-		}
-	}
-
-	// walk through path backwards, dropping through each case
-	switch p := len(parts); p {
-	case 11:
-		// table cell, check @column
-		if !strings.HasPrefix(parts[p-1], "cell") {
-			err = ErrInvalidPath
-			return
-		}
-		column, _ := getAttr(parts[p-1], "column")
-		xpath.Column = &Column{Name: column}
-		p--
-		fallthrough
-	case 10:
-		if strings.HasPrefix(parts[p-1], "row") {
-			xpath.Rows = true
-			row, _ := getAttr(parts[p-1], "name")
-			xpath.Row = &Row{Name: row}
-		} else if strings.HasPrefix(parts[p-1], "cell") {
-			headline, _ := getAttr(parts[p-1], "name")
-			xpath.Headline = &Headline{Name: headline}
-		} else {
-			err = ErrInvalidPath
-			return
-		}
-		p--
-		fallthrough
-	case 9:
-		if strings.HasPrefix(parts[p-1], "rows") {
-			// x.Dataview.Column = column
-		} else if strings.HasPrefix(parts[p-1], "headlines") {
-			//
+	steps, err := splitSteps(s)
+	if err != nil {
+		return
+	}
+
+	level := -1
+	var rows, headlines bool
+	for _, st := range steps {
+		var next int
+		if st.descendant {
+			if next, err = descendantLevel(st.name, level, rows, headlines); err != nil {
+				err = fmt.Errorf("%w in %q", err, s)
+				return
+			}
+			if next <= level {
+				err = fmt.Errorf("%w: %q cannot follow the previous element in %q", ErrInvalidPath, st.name, s)
+				return
+			}
 		} else {
-			err = ErrInvalidPath
-			return
+			next = level + 1
+			if st.name == "*" && next == levelRowsHeadlines {
+				err = fmt.Errorf("%w: wildcard for rows or headlines is ambiguous in %q", ErrInvalidPath, s)
+				return
+			}
+			if st.name != "*" {
+				if next == levelRowsHeadlines {
+					rows, headlines = st.name == "rows", st.name == "headlines"
+				}
+				if st.name != levelName(next, rows, headlines) {
+					err = fmt.Errorf("%w: unexpected element %q in %q", ErrInvalidPath, st.name, s)
+					return
+				}
+			}
 		}
-		p--
-		fallthrough
-	case 8:
-		// dataview
-		if !strings.HasPrefix(parts[p-1], "dataview") {
-			err = ErrInvalidPath
-			return
+		if next == levelRowsHeadlines || (next > levelRowsHeadlines && !rows && !headlines) {
+			rows, headlines = st.name == "rows" || st.name == "row", st.name == "headlines"
 		}
-		dataview, _ := getAttr(parts[p-1], "name")
-		xpath.Dataview = &Dataview{Name: dataview}
-		p--
-		fallthrough
-	case 7:
-		// sampler
-		if !strings.HasPrefix(parts[p-1], "sampler") {
-			err = ErrInvalidPath
+		if next == levelCell && !rows {
+			err = fmt.Errorf("%w: headlines have no lower level in %q", ErrInvalidPath, s)
 			return
 		}
-		var tp *string
-		if t, ok := getAttr(parts[p-1], "type"); ok {
-			tp = &t
+
+		// fill in any skipped levels
+		for l := level + 1; l < next; l++ {
+			if err = xpath.setLevel(l, rows, nil); err != nil {
+				return
+			}
 		}
-		name, _ := getAttr(parts[p-1], "name")
-		xpath.Sampler = &Sampler{
-			Name: name,
-			Type: tp,
+		if err = xpath.setLevel(next, rows, st.predicates); err != nil {
+			err = fmt.Errorf("%w in %q", err, s)
+			return
 		}
-		p--
+		level = next
+	}
+
+	return
+}
+
+// levelName returns the name of the element at level
+func levelName(level int, rows, headlines bool) string {
+	switch {
+	case level < levelRowsHeadlines:
+		return levelNames[level]
+	case level == levelRowsHeadlines && rows:
+		return "rows"
+	case level == levelRowsHeadlines && headlines:
+		return "headlines"
+	case level == levelRowHeadline && rows:
+		return "row"
+	case level == levelRowHeadline && headlines:
+		return "cell"
+	case level == levelCell && rows:
+		return "cell"
+	default:
+		return ""
+	}
+}
+
+// descendantLevel returns the level of element name following a double
+// slash after the element at level
+func descendantLevel(name string, level int, rows, headlines bool) (int, error) {
+	if i := slices.Index(levelNames, name); i != -1 {
+		return i, nil
+	}
+	switch name {
+	case "rows", "headlines":
+		return levelRowsHeadlines, nil
+	case "row":
+		return levelRowHeadline, nil
+	case "cell":
+		switch {
+		case rows:
+			return levelCell, nil
+		case headlines:
+			return levelRowHeadline, nil
+		}
+		return 0, fmt.Errorf("%w: //cell must follow rows or headlines, e.g. //rows/row/cell or //headlines/cell", ErrInvalidPath)
+	case "*":
+		return 0, fmt.Errorf("%w: wildcard after // is not supported", ErrInvalidPath)
+	default:
+		return 0, fmt.Errorf("%w: unknown element %q", ErrInvalidPath, name)
+	}
+}
+
+// setLevel sets the element of x for level with the predicates given
+func (x *XPath) setLevel(level int, rows bool, predicates []*Predicate) (err error) {
+	switch level {
+	case levelGateway:
+		x.Gateway = &Gateway{}
+		x.Gateway.Name, x.Gateway.Predicates = extractName(predicates, "name")
+	case levelProbe:
+		x.Probe = &Probe{}
+		x.Probe.Name, x.Probe.Predicates = extractName(predicates, "name")
+	case levelEntity:
+		x.Entity = &Entity{Attributes: map[string]string{}}
+		x.Entity.Name, predicates = extractName(predicates, "name")
+		for _, p := range predicates {
+			if k, v, ok := p.attribute(); ok {
+				if _, found := x.Entity.Attributes[k]; !found {
+					x.Entity.Attributes[k] = v
+					continue
+				}
+			}
+			x.Entity.Predicates = append(x.Entity.Predicates, p)
+		}
+	case levelSampler:
+		x.Sampler = &Sampler{}
+		x.Sampler.Name, predicates = extractName(predicates, "name")
+		for _, p := range predicates {
+			if k, v, ok := p.equals(); ok && k == "type" && x.Sampler.Type == nil {
+				x.Sampler.Type = &v
+				continue
+			}
+			x.Sampler.Predicates = append(x.Sampler.Predicates, p)
+		}
+	case levelDataview:
+		x.Dataview = &Dataview{}
+		x.Dataview.Name, x.Dataview.Predicates = extractName(predicates, "name")
+	case levelRowHeadline:
+		if rows {
+			x.Row = &Row{}
+			x.Row.Name, x.Row.Predicates = extractName(predicates, "name")
+		} else {
+			x.Headline = &Headline{}
+			x.Headline.Name, x.Headline.Predicates = extractName(predicates, "name")
+		}
+	case levelCell:
+		x.Column = &Column{}
+		x.Column.Name, x.Column.Predicates = extractName(predicates, "column")
+	case levelRowsHeadlines:
+		x.Rows = rows
 		fallthrough
-	case 6:
-		// entity
-		if !strings.HasPrefix(parts[p-1], "managedEntity") {
-			err = ErrInvalidPath
-			return
+	default:
+		if len(predicates) > 0 {
+			err = fmt.Errorf("%w: predicates not supported on %q", ErrInvalidPath, levelName(level, rows, !rows))
 		}
-		entity, _ := getAttr(parts[p-1], "name")
-		xpath.Entity = &Entity{
-			Name:       entity,
-			Attributes: getAttributes(parts[p-1]),
+	}
+	return
+}
+
+// extractName returns the value of the first predicate that is a simple
+// non-empty comparison of property with a string, and the remaining
+// predicates
+func extractName(predicates []*Predicate, property string) (name string, rest []*Predicate) {
+	for _, p := range predicates {
+		if k, v, ok := p.equals(); ok && name == "" && k == property && v != "" {
+			name = v
+			continue
 		}
-		p--
-		fallthrough
-	case 5:
-		// probe
-		if !strings.HasPrefix(parts[p-1], "probe") {
-			err = ErrInvalidPath
-			return
+		rest = append(rest, p)
+	}
+	return
+}
+
+// splitSteps splits the absolute path s into steps. Slashes and
+// brackets inside quoted strings are ignored and outside of strings a
+// backslash escapes the following character.
+func splitSteps(s string) (steps []step, err error) {
+	for i := 0; i < len(s); {
+		if s[i] != '/' {
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidPath, s[i:], s)
+		}
+		i++
+		var st step
+		if i < len(s) && s[i] == '/' {
+			st.descendant = true
+			i++
 		}
-		probe, _ := getAttr(parts[p-1], "name")
-		xpath.Probe = &Probe{Name: probe}
-		p--
-		fallthrough
-	case 4:
-		// directory
-		if parts[p-1] != "directory" {
-			err = ErrInvalidPath
-			return
+		var name strings.Builder
+		for i < len(s) && s[i] != '/' && s[i] != '[' {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			name.WriteByte(s[i])
+			i++
 		}
-		p--
-		fallthrough
-	case 3:
-		// gateway
-		if !strings.HasPrefix(parts[p-1], "gateway") {
-			err = ErrInvalidPath
-			return
+		st.name = name.String()
+		if st.name == "" {
+			return nil, fmt.Errorf("%w: empty element in %q", ErrInvalidPath, s)
 		}
-		gateway, _ := getAttr(parts[p-1], "name")
-		xpath.Gateway = &Gateway{Name: gateway}
-		p--
-		fallthrough
-	case 2:
-		// geneos
-		if parts[p-1] != "geneos" {
-			err = ErrInvalidPath
-			return
+		for i < len(s) && s[i] == '[' {
+			var n int
+			if n, err = predicateEnd(s[i:]); err != nil {
+				return nil, fmt.Errorf("%w in %q", err, s)
+			}
+			var p *Predicate
+			if p, err = ParsePredicate(s[i : i+n]); err != nil {
+				return
+			}
+			st.predicates = append(st.predicates, p)
+			i += n
 		}
-		p--
-		fallthrough
-	case 1:
-		// leading '/' stripped, must be empty
-		if parts[p-1] != "" {
-			err = ErrInvalidPath
-			return
+		steps = append(steps, st)
+	}
+	return
+}
+
+// predicateEnd returns the length of the predicate at the start of s,
+// including the brackets
+func predicateEnd(s string) (n int, err error) {
+	depth := 0
+	for n = 0; n < len(s); n++ {
+		switch s[n] {
+		case '"', '\'':
+			var l int
+			if _, l, err = readString(s[n:]); err != nil {
+				return
+			}
+			n += l - 1
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return n + 1, nil
+			}
 		}
-		return
-	default:
-		err = ErrInvalidPath
-		return
 	}
+	return 0, fmt.Errorf("%w: unterminated predicate", ErrInvalidPath)
 }
 
 // return Xpath as a string
@@ -661,58 +876,6 @@ func (x *XPath) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
-// split a string on separator (byte) except when separator is escaped
-// by the escape byte given. typical usage is for when an xpath element
-// has an escaped '/'
-func splitWithEscaping(s string, separator, escape byte) []string {
-	var token []byte
-	var tokens []string
-	for i := 0; i < len(s); i++ {
-		if s[i] == separator {
-			tokens = append(tokens, string(token))
-			token = token[:0]
-		} else if s[i] == escape && i+1 < len(s) {
-			i++
-			token = append(token, s[i])
-		} else {
-			token = append(token, s[i])
-		}
-	}
-	tokens = append(tokens, string(token))
-	return tokens
-}
-
-// extract the value of an attribute from the xpath component in the form
-// [(@attr="value")] - this just uses a regexp and applies to validation
-func getAttr(s string, attr string) (v string, ok bool) {
-	attrRE := regexp.MustCompile(fmt.Sprintf(`\[\(@%s="(.*?)\\{0}?"\)\]`, attr))
-	m := attrRE.FindStringSubmatch(s)
-	if m == nil {
-		return
-	}
-	if len(m) > 1 {
-		v = m[1]
-		ok = true
-	}
-	return
-}
-
-var attributeRE = regexp.MustCompile(`\[\(attr\("(.*?)\\{0}?"\)="(.*?)\\{0}?"\)\]`)
-
-// extract the attributes from the xpath managedEntity component in the form
-// [(attr("KEY")="value")] - this just uses a regexp and applies to validation
-func getAttributes(s string) (attrs map[string]string) {
-	attrs = map[string]string{}
-	m := attributeRE.FindAllStringSubmatch(s, -1)
-	for _, n := range m {
-		if len(n) != 3 {
-			continue
-		}
-		attrs[n[1]] = n[2]
-	}
-	return
-}
-
 // LookupValues returns a map of the components of x to their values,
 // suitable for use as an Expand LookupTable. Only components of x that
 // are set are added to the returned map and processing stops as soon as
@@ -0,0 +1,252 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpath
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const dataviewPath = "/geneos/gateway/directory/probe/managedEntity/sampler/dataview"
+
+// TestParseFormat checks that each path is written in the expected
+// canonical form and that the canonical form parses back to the same
+// XPath and string
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			`/geneos/gateway[(@name="GW")]/directory/probe[(@name="p1")]/managedEntity[(@name="e1")]/sampler[(@name="cpu")][(@type="")]/dataview[(@name="cpu")]/rows/row[(@name="_Total")]/cell[(@column="percentUtilisation")]`,
+			`/geneos/gateway[(@name="GW")]/directory/probe[(@name="p1")]/managedEntity[(@name="e1")]/sampler[(@name="cpu")][(@type="")]/dataview[(@name="cpu")]/rows/row[(@name="_Total")]/cell[(@column="percentUtilisation")]`,
+		},
+		{
+			`//managedEntity[(attr("Region")="EMEA")]//rows/row/cell[(@column="status")][(@severity>="warning")]`,
+			`/geneos/gateway/directory/probe/managedEntity[(attr("Region")="EMEA")]/sampler/dataview/rows/row/cell[(@column="status")][(@severity>="warning")]`,
+		},
+		{
+			`//probe[(@name="p1" or @name="p2")]`,
+			`/geneos/gateway/directory/probe[(@name="p1") or (@name="p2")]`,
+		},
+		{
+			`//managedEntity[not(attr("Env")="prod") and (param("X")!="1")]`,
+			`/geneos/gateway/directory/probe/managedEntity[not((attr("Env")="prod")) and (param("X")!="1")]`,
+		},
+		{
+			`//sampler[wild(@name, "cpu*")]//dataview[contains(@name,"disk")]`,
+			`/geneos/gateway/directory/probe/managedEntity/sampler[wild(@name,"cpu*")]/dataview[contains(@name,"disk")]`,
+		},
+		{
+			`//dataview//headlines/cell[(@name="samplingStatus")][starts-with(@value,"OK")]`,
+			dataviewPath + `/headlines/cell[(@name="samplingStatus")][starts-with(@value,"OK")]`,
+		},
+		{
+			`//rows/row[rowname="a" or rowname="b"]/cell[(@value>10) and (@value<=20.5)]`,
+			dataviewPath + `/rows/row[(rowname="a") or (rowname="b")]/cell[(@value>10) and (@value<=20.5)]`,
+		},
+		{
+			`//dataview[(@name="x")]//row//cell`,
+			dataviewPath + `[(@name="x")]/rows/row/cell`,
+		},
+		{
+			`//headlines//cell[(@severity="critical")]`,
+			dataviewPath + `/headlines/cell[(@severity="critical")]`,
+		},
+		{
+			`/geneos/gateway/directory/*/managedEntity`,
+			`/geneos/gateway/directory/probe/managedEntity`,
+		},
+		{
+			`//managedEntity[(@name="a\"b")]`,
+			`/geneos/gateway/directory/probe/managedEntity[(@name="a\"b")]`,
+		},
+		{
+			// attributes are sorted, a repeated attribute is a predicate
+			`//managedEntity[(attr("B")="2")][(attr("A")="1")][(attr("A")="3")]`,
+			`/geneos/gateway/directory/probe/managedEntity[(attr("A")="1")][(attr("B")="2")][(attr("A")="3")]`,
+		},
+		{
+			`//dataview[@name]`,
+			dataviewPath + `[@name]`,
+		},
+		{
+			`//sampler[(@type="Linux")][(@name="cpu")]`,
+			`/geneos/gateway/directory/probe/managedEntity/sampler[(@name="cpu")][(@type="Linux")]`,
+		},
+	}
+	for _, tt := range tests {
+		x, err := Parse(tt.path)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.path, err)
+			continue
+		}
+		got := x.String()
+		if got != tt.want {
+			t.Errorf("Parse(%s).String():\n got %s\nwant %s", tt.path, got, tt.want)
+			continue
+		}
+		y, err := Parse(got)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", got, err)
+			continue
+		}
+		if !reflect.DeepEqual(x, y) {
+			t.Errorf("Parse(%s) differs from Parse(%s)", got, tt.path)
+		}
+		if s := y.String(); s != got {
+			t.Errorf("second round trip of %s:\n got %s", got, s)
+		}
+	}
+}
+
+func TestHeadlinesFormat(t *testing.T) {
+	critical, err := ParsePredicate(`@severity="critical"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		x    *XPath
+		want string
+	}{
+		{NewHeadlinePath(""), dataviewPath + `/headlines`},
+		{NewHeadlinePath("samplingStatus"), dataviewPath + `/headlines/cell[(@name="samplingStatus")]`},
+		{New(&Headline{Predicates: []*Predicate{critical}}), dataviewPath + `/headlines/cell[(@severity="critical")]`},
+	}
+	for _, tt := range tests {
+		if got := tt.x.String(); got != tt.want {
+			t.Errorf("String():\n got %s\nwant %s", got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		err  error
+	}{
+		{`relative/path`, ErrRelativePath},
+		{`//cell[(@column="x")]`, ErrInvalidPath},
+		{`//dataview//cell`, ErrInvalidPath},
+		{`//*`, ErrInvalidPath},
+		{dataviewPath + `/*`, ErrInvalidPath},
+		{`//headlines/cell/cell`, ErrInvalidPath},
+		{`//sampler//probe`, ErrInvalidPath},
+		{`//rows[(@name="x")]`, ErrInvalidPath},
+		{`//probe[(@name="x")`, ErrInvalidPath},
+		{`//managedEntity[(@name="x") and]`, ErrInvalidPath},
+		{`//managedEntity[(@name="x"`, ErrInvalidPath},
+		{`//unknown`, ErrInvalidPath},
+		{`/geneos//`, ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		if x, err := Parse(tt.path); !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%s) = %v, %v, want error %v", tt.path, x, err, tt.err)
+		}
+	}
+}
+
+// TestPredicateFormat checks the canonical form of predicates and that
+// it parses back to the same predicate
+func TestPredicateFormat(t *testing.T) {
+	tests := []struct {
+		predicate string
+		want      string
+	}{
+		{`@name="x"`, `[(@name="x")]`},
+		{`[(@name='x')]`, `[(@name="x")]`},
+		{`[@value >= 10]`, `[(@value>=10)]`},
+		{`[@severity>"ok" and not(@value="")]`, `[(@severity>"ok") and not((@value=""))]`},
+		{`[(@a) or (@b)]`, `[@a or @b]`},
+		{`[@a="1" and (@b="2" or @c="3")]`, `[(@a="1") and ((@b="2") or (@c="3"))]`},
+		{`[(@a="1" and @b="2") or @c="3"]`, `[(@a="1") and (@b="2") or (@c="3")]`},
+		{`[param("Interval")<60]`, `[(param("Interval")<60)]`},
+		{`[wild(attr("Host"), "web-??-*")]`, `[wild(attr("Host"),"web-??-*")]`},
+		{`[rowname!="total"]`, `[(rowname!="total")]`},
+		{`[@value]`, `[@value]`},
+	}
+	for _, tt := range tests {
+		p, err := ParsePredicate(tt.predicate)
+		if err != nil {
+			t.Errorf("ParsePredicate(%s): %v", tt.predicate, err)
+			continue
+		}
+		got := p.String()
+		if got != tt.want {
+			t.Errorf("ParsePredicate(%s).String() = %s, want %s", tt.predicate, got, tt.want)
+			continue
+		}
+		q, err := ParsePredicate(got)
+		if err != nil {
+			t.Errorf("ParsePredicate(%s): %v", got, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, q) {
+			t.Errorf("ParsePredicate(%s) differs from ParsePredicate(%s)", got, tt.predicate)
+		}
+	}
+}
+
+func TestPredicateEval(t *testing.T) {
+	cell := Values{
+		Properties: map[string]string{
+			"name":     "cpu",
+			"column":   "percentUtilisation",
+			"rowname":  "_Total",
+			"value":    "91.5",
+			"severity": "critical",
+		},
+		Params:     map[string]string{"Interval": "20"},
+		Attributes: map[string]string{"Host": "web-01-eu", "Env": "prod"},
+	}
+	tests := []struct {
+		predicate string
+		want      bool
+	}{
+		{`@name="cpu"`, true},
+		{`@name!="cpu"`, false},
+		{`@value>90`, true},
+		{`@value>100`, false},
+		{`@value<=91.5`, true},
+		{`@severity="critical"`, true},
+		{`@severity="3"`, true},
+		{`@severity>"warning"`, true},
+		{`@severity<"warning"`, false},
+		{`rowname="_Total"`, true},
+		{`param("Interval")<60`, true},
+		{`attr("Env")="prod" and not(attr("Host")="db")`, true},
+		{`attr("Missing")="x" or @name="cpu"`, true},
+		{`wild(attr("Host"), "web-??-*")`, true},
+		{`wild(attr("Host"), "db*")`, false},
+		{`contains(@column, "Util")`, true},
+		{`starts-with(@column, "percent")`, true},
+		{`@value`, true},
+		{`@missing`, false},
+	}
+	for _, tt := range tests {
+		p, err := ParsePredicate(tt.predicate)
+		if err != nil {
+			t.Errorf("ParsePredicate(%s): %v", tt.predicate, err)
+			continue
+		}
+		if got := p.Eval(cell); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.predicate, got, tt.want)
+		}
+	}
+}
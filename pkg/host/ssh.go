@@ -32,7 +32,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
@@ -45,9 +44,6 @@ import (
 
 const userSSHdir = ".ssh"
 
-var sshSessions sync.Map
-var sftpSessions sync.Map
-
 // An SSHRemote a type that satisfies the Host interface for SSH
 // attached remote hosts
 type SSHRemote struct {
@@ -57,13 +53,15 @@ type SSHRemote struct {
 	port        uint16
 	password    []byte // cannot use *config.Secret because of import loop
 	keys        []string
+	maxSessions int
+	keepAlive   time.Duration
 	failed      error
 	lastAttempt time.Time
 }
 
 // NewSSHRemote returns a new SSHRemote with the given name and options.
-// The name is used as the key for caching SSH and SFTP sessions and so
-// should be unique for each remote host. Options are used to set the
+// The name is used as the key for pooling SSH and SFTP connections and
+// so should be unique for each remote host. Options are used to set the
 // hostname, port, username and authentication method (password or
 // private keys) for the remote host. If no options are given then the
 // local username is used and the hostname is set to the name of the
@@ -85,6 +83,7 @@ func evalOptions(r *SSHRemote, options ...any) {
 	} else {
 		r.username = os.Getenv("USER")
 	}
+	r.keepAlive = DefaultKeepAlive
 
 	for _, opt := range options {
 		switch o := opt.(type) {
@@ -225,8 +224,10 @@ func sshConnect(dest, username string, password []byte, keyfiles ...string) (cli
 }
 
 // DialSSH connects to a remote host using ssh and returns an *ssh.Client
-// on success. Each connection is cached and returned if found without
-// checking if it is still valid. To remove a session call Close()
+// on success. Connections are pooled by remote name and shared, and a
+// connection that has been lost, either because the remote closed it or
+// a keep-alive failed, is re-dialled transparently. To close the pooled
+// connection call Close()
 func (h *SSHRemote) DialSSH() (sc *ssh.Client, err error) {
 	if h == nil {
 		err = ErrInvalidArgs
@@ -246,77 +247,74 @@ func (h *SSHRemote) DialSSH() (sc *ssh.Client, err error) {
 	}
 
 	dest := fmt.Sprintf("%s:%d", h.hostname, h.port)
-	if val, ok := sshSessions.Load(h.name); ok {
-		sc = val.(*ssh.Client)
-	} else {
-		sc, err = sshConnect(dest, h.username, h.password, h.keys...)
-		if err != nil {
-			h.failed = err
-			h.lastAttempt = time.Now()
-			return sc, fmt.Errorf("%w (note: you MUST add remote keys manually to known_hosts)", err)
-		}
-		sshSessions.Store(h.name, sc)
+	sc, err = h.conn().ssh(func() (*ssh.Client, error) {
+		return sshConnect(dest, h.username, h.password, h.keys...)
+	}, h.keepAlive)
+	if err != nil {
+		h.failed = err
+		h.lastAttempt = time.Now()
+		return sc, fmt.Errorf("%w (note: you MUST add remote keys manually to known_hosts)", err)
 	}
 	return
 }
 
-// Close a remote host connection
+// Close the pooled remote host connection, including any SFTP client
 func (h *SSHRemote) Close() {
 	if h == nil {
 		return
 	}
 
-	h.CloseSFTP()
-
-	val, ok := sshSessions.Load(h.name)
-	if ok {
-		s := val.(*ssh.Client)
-		s.Close()
-		sshSessions.Delete(h.name)
-	}
+	h.conn().close()
 }
 
 // DialSFTP connects to the remote host using SSH and returns an
-// *sftp.Client is successful. The connection is cached and returned if
-// found, without checking if it is still valid.
+// *sftp.Client is successful. The client is shared by all users of the
+// pooled connection, with concurrent requests multiplexed over one SFTP
+// session, and is restarted if it or the SSH connection has been lost.
 func (h *SSHRemote) DialSFTP() (f *sftp.Client, err error) {
 	if h == nil {
 		err = ErrInvalidArgs
 		return
 	}
 
-	if val, ok := sftpSessions.Load(h.name); ok {
-		f = val.(*sftp.Client)
-		return
-	}
-
 	var s *ssh.Client
 	if s, err = h.DialSSH(); err != nil {
-		h.failed = err
-		h.lastAttempt = time.Now()
 		return
 	}
-	if f, err = sftp.NewClient(s); err != nil {
+	if f, err = h.conn().sftpClient(s); err != nil {
 		h.failed = err
 		h.lastAttempt = time.Now()
 		return
 	}
-	sftpSessions.Store(h.name, f)
 
 	return
 }
 
+// CloseSFTP closes the pooled SFTP client, leaving the SSH connection
+// open
 func (h *SSHRemote) CloseSFTP() {
 	if h == nil {
 		return
 	}
 
-	val, ok := sftpSessions.Load(h.name)
-	if ok {
-		f := val.(*sftp.Client)
-		f.Close()
-		sftpSessions.Delete(h.name)
+	h.conn().closeSFTP()
+}
+
+// withSFTP calls fn with the SFTP client for h and, if fn fails because
+// the connection was lost, calls it once more on a new connection. Only
+// use for operations that are safe to repeat.
+func (h *SSHRemote) withSFTP(fn func(s *sftp.Client) error) (err error) {
+	for range 2 {
+		var s *sftp.Client
+		if s, err = h.DialSFTP(); err != nil {
+			return
+		}
+		if err = fn(s); err == nil || !h.conn().lost(s, err) {
+			return
+		}
+		h.conn().closeSFTP()
 	}
+	return
 }
 
 // IsLocalhost returns true if h is local, which for SSH is always false
@@ -428,11 +426,11 @@ func (h *SSHRemote) Split(file string) (dir, base string) {
 }
 
 func (h *SSHRemote) Getwd() (dir string, err error) {
-	if s, err := h.DialSFTP(); err != nil {
-		return "", err
-	} else {
-		return s.Getwd()
-	}
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		dir, err = s.Getwd()
+		return
+	})
+	return
 }
 
 func (h *SSHRemote) Symlink(oldname, newname string) error {
@@ -443,12 +441,12 @@ func (h *SSHRemote) Symlink(oldname, newname string) error {
 	}
 }
 
-func (h *SSHRemote) Readlink(file string) (string, error) {
-	if s, err := h.DialSFTP(); err != nil {
-		return "", err
-	} else {
-		return s.ReadLink(file)
-	}
+func (h *SSHRemote) Readlink(file string) (link string, err error) {
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		link, err = s.ReadLink(file)
+		return
+	})
+	return
 }
 
 func (h *SSHRemote) Mkdir(p string, perm os.FileMode) error {
@@ -567,29 +565,32 @@ func (h *SSHRemote) Link(oldname, newname string) error {
 }
 
 // Stat wraps the os.Stat and sftp.Stat functions
-func (h *SSHRemote) Stat(name string) (fs.FileInfo, error) {
-	if s, err := h.DialSFTP(); err != nil {
-		return nil, err
-	} else {
-		return s.Stat(name)
-	}
+func (h *SSHRemote) Stat(name string) (fi fs.FileInfo, err error) {
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		fi, err = s.Stat(name)
+		return
+	})
+	return
 }
 
 // Lstat wraps the os.Lstat and sftp.Lstat functions
-func (h *SSHRemote) Lstat(name string) (fs.FileInfo, error) {
-	if s, err := h.DialSFTP(); err != nil {
-		return nil, err
-	} else {
-		return s.Lstat(name)
-	}
+func (h *SSHRemote) Lstat(name string) (fi fs.FileInfo, err error) {
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		fi, err = s.Lstat(name)
+		return
+	})
+	return
 }
 
-func (h *SSHRemote) Glob(pattern string) ([]string, error) {
-	if s, err := h.DialSFTP(); err != nil {
+func (h *SSHRemote) Glob(pattern string) (matches []string, err error) {
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		matches, err = s.Glob(pattern)
+		return
+	})
+	if err != nil {
 		return []string{}, err
-	} else {
-		return s.Glob(pattern)
 	}
+	return
 }
 
 func (h *SSHRemote) WriteFile(name string, data []byte, perm os.FileMode) (err error) {
@@ -609,37 +610,29 @@ func (h *SSHRemote) WriteFile(name string, data []byte, perm os.FileMode) (err e
 }
 
 func (h *SSHRemote) ReadFile(name string) (b []byte, err error) {
-	var s *sftp.Client
-	if s, err = h.DialSFTP(); err != nil {
+	err = h.withSFTP(func(s *sftp.Client) (err error) {
+		f, err := s.Open(name)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		b, err = io.ReadAll(f)
 		return
-	}
-	f, err := s.Open(name)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	st, err := f.Stat()
+	})
 	if err != nil {
 		return nil, err
 	}
-	// force a block read as /proc doesn't give sizes
-	sz := st.Size()
-	if sz == 0 {
-		sz = 8192
-	}
-	return io.ReadAll(f)
+	return
 }
 
 // ReadDir reads the named directory and returns all its directory
 // entries sorted by name.
 func (h *SSHRemote) ReadDir(name string) (dirs []os.DirEntry, err error) {
-	var s *sftp.Client
-	if s, err = h.DialSFTP(); err != nil {
+	var f []fs.FileInfo
+	if err = h.withSFTP(func(s *sftp.Client) (err error) {
+		f, err = s.ReadDir(name)
 		return
-	}
-	f, err := s.ReadDir(name)
-	if err != nil {
+	}); err != nil {
 		return nil, err
 	}
 	sort.Slice(f, func(i, j int) bool {
@@ -708,7 +701,7 @@ func (h *SSHRemote) GetFs() afero.Fs {
 // Signal sends a signal to the remote pid and returns nil on success or
 // os.ProcessDone if the process is not found.
 func (h *SSHRemote) Signal(pid int, signal syscall.Signal) (err error) {
-	sess, err := h.NewSession()
+	sess, err := h.NewPooledSession()
 	if err != nil {
 		return
 	}
@@ -730,35 +723,60 @@ func (h *SSHRemote) Signal(pid int, signal syscall.Signal) (err error) {
 	return
 }
 
-// NewSession wraps ssh.NewSession but does some retries
+// NewSession wraps ssh.NewSession but does some retries. The session
+// is opened on the pooled connection, which is re-dialled once if it
+// has been lost, but does not count towards the remote's session limit
+// (see [MaxSessions]). Use [SSHRemote.NewPooledSession] to wait for a
+// free slot.
 func (h *SSHRemote) NewSession() (sess *ssh.Session, err error) {
-	rem, err := h.DialSSH()
-	if err != nil {
-		err = fmt.Errorf("Start: %w during Dial()", err)
-		return
-	}
+	c := h.conn()
+	for range 2 {
+		var rem *ssh.Client
+		if rem, err = h.DialSSH(); err != nil {
+			err = fmt.Errorf("Start: %w during Dial()", err)
+			return
+		}
 
-	// the number of sessions is always limited by config on the remote
-	// server, but we don't know what that limit is, so retry a few
-	// times with a small delay
-	for range 10 {
-		sess, err = rem.NewSession()
-		if err == nil {
-			break
+		// the number of sessions is always limited by config on the
+		// remote server, but we don't know what that limit is, so
+		// retry a few times with a small delay
+		for range 10 {
+			sess, err = rem.NewSession()
+			if _, ok := errors.AsType[*ssh.OpenChannelError](err); !ok {
+				break
+			}
+			time.Sleep(250 * time.Millisecond)
 		}
-		var ocerr *ssh.OpenChannelError
-		if !errors.As(err, &ocerr) {
+		if _, ok := errors.AsType[*ssh.OpenChannelError](err); err == nil || ok {
 			break
 		}
-		time.Sleep(250 * time.Millisecond)
+		// any other error means the connection has gone, so drop it
+		// and try once more on a new one
+		c.drop(rem)
 	}
 	if err != nil {
 		err = fmt.Errorf("Start: %w during NewSession()", err)
 		return
 	}
+	c.sessionsOpened.Add(1)
 	return
 }
 
+// NewPooledSession is like [SSHRemote.NewSession] but first waits for
+// one of the remote's session slots (see [MaxSessions]). The caller
+// must call Close on the returned session to free the slot.
+func (h *SSHRemote) NewPooledSession() (sess *SSHSession, err error) {
+	c := h.conn()
+	c.acquire()
+
+	s, err := h.NewSession()
+	if err != nil {
+		c.release()
+		return
+	}
+	return &SSHSession{Session: s, conn: c}, nil
+}
+
 // Start starts a process on an SSH attached remote host h. It uses a
 // shell and backgrounds and redirects. May not work on all remotes and
 // for all processes. errfile has stdout/stderr appended to it, use
@@ -771,10 +789,11 @@ func (h *SSHRemote) Start(cmd *exec.Cmd, options ...ProcessOption) (pid int, err
 	po := evalProcessOptions(options...)
 	errfile := po.errfile
 
-	sess, err := h.NewSession()
+	sess, err := h.NewPooledSession()
 	if err != nil {
 		return
 	}
+	defer sess.Close()
 
 	// we have to convert cmd to a string ourselves as we have to quote any args
 	// with spaces (like "Demo Gateway")
@@ -821,10 +840,11 @@ func (h *SSHRemote) Run(cmd *exec.Cmd, options ...ProcessOption) (output []byte,
 	po := evalProcessOptions(options...)
 	errfile := po.errfile
 
-	sess, err := h.NewSession()
+	sess, err := h.NewPooledSession()
 	if err != nil {
 		return
 	}
+	defer sess.Close()
 
 	// we have to convert cmd to a string ourselves as we have to quote any args
 	// with spaces (like "Demo Gateway")
@@ -867,7 +887,7 @@ func (h *SSHRemote) Uname() (os, arch string, err error) {
 		err = errors.New("cannot run remote commands on windows")
 	}
 
-	sess, err := h.NewSession()
+	sess, err := h.NewPooledSession()
 	if err != nil {
 		return
 	}
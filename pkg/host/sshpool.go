@@ -0,0 +1,388 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultMaxSessions is the default limit on the number of
+	// concurrent SSH sessions (commands) per remote. OpenSSH allows 10
+	// channels per connection by default, and one is used by SFTP.
+	DefaultMaxSessions = 8
+
+	// DefaultKeepAlive is the default interval between keep-alive
+	// requests on idle pooled connections
+	DefaultKeepAlive = 30 * time.Second
+)
+
+// sshPool holds the shared connections to SSH remotes, keyed on the
+// remote name
+var sshPool sync.Map
+
+// sshConn is a pooled connection to a remote. The SSH client and the
+// SFTP client that runs over it are shared by all users of the remote,
+// with SFTP requests multiplexed over a single subsystem channel and
+// sessions limited to the size of the sessions channel.
+type sshConn struct {
+	name     string
+	dial     sync.Mutex // held while dialling, before mutex
+	mutex    sync.Mutex
+	client   *ssh.Client
+	done     chan struct{} // closed when client's connection ends
+	sftp     *sftp.Client
+	sftpDone chan struct{} // closed when sftp ends
+	since    time.Time
+	sessions chan struct{}
+
+	dials             atomic.Uint64
+	reconnects        atomic.Uint64
+	failures          atomic.Uint64
+	keepAliveFailures atomic.Uint64
+	sessionWaits      atomic.Uint64
+	sessionsOpened    atomic.Uint64
+}
+
+// SSHPoolStats are the metrics for one pooled remote connection, as
+// returned by [SSHPoolMetrics]
+type SSHPoolStats struct {
+	Name              string    `json:"name"`
+	Connected         bool      `json:"connected"`
+	ConnectedSince    time.Time `json:"connectedSince,omitzero"`
+	SFTP              bool      `json:"sftp"`
+	Dials             uint64    `json:"dials"`
+	Reconnects        uint64    `json:"reconnects"`
+	Failures          uint64    `json:"failures"`
+	KeepAliveFailures uint64    `json:"keepAliveFailures"`
+	ActiveSessions    int       `json:"activeSessions"`
+	MaxSessions       int       `json:"maxSessions"`
+	SessionsOpened    uint64    `json:"sessionsOpened"`
+	SessionWaits      uint64    `json:"sessionWaits"`
+}
+
+// MaxSessions sets the limit on the number of concurrent SSH sessions
+// to the remote. Callers of [SSHRemote.NewPooledSession] wait for a
+// free slot once the limit is reached. The limit is set when the remote
+// is first used, by whichever remote with the same name gets there
+// first. The default is [DefaultMaxSessions].
+func MaxSessions(n int) SSHOption {
+	return func(s *SSHRemote) {
+		s.maxSessions = n
+	}
+}
+
+// KeepAlive sets the interval between keep-alive requests to the
+// remote. A connection that does not answer within the interval is
+// closed and is re-dialled on next use. A zero or negative interval
+// turns off keep-alives. The default is [DefaultKeepAlive].
+func KeepAlive(interval time.Duration) SSHOption {
+	return func(s *SSHRemote) {
+		s.keepAlive = interval
+	}
+}
+
+// SSHPoolMetrics returns the metrics for all the pooled remote
+// connections, sorted by name
+func SSHPoolMetrics() (stats []SSHPoolStats) {
+	pool := map[string]*sshConn{}
+	sshPool.Range(func(key, value any) bool {
+		pool[key.(string)] = value.(*sshConn)
+		return true
+	})
+	for _, name := range slices.Sorted(maps.Keys(pool)) {
+		stats = append(stats, pool[name].stats())
+	}
+	return
+}
+
+// CloseSSHPool closes all pooled remote connections. Remotes can still
+// be used afterwards and re-dial as required.
+func CloseSSHPool() {
+	sshPool.Range(func(key, value any) bool {
+		value.(*sshConn).close()
+		return true
+	})
+}
+
+// conn returns the pooled connection for h, creating an unconnected
+// one if required
+func (h *SSHRemote) conn() *sshConn {
+	if c, ok := sshPool.Load(h.name); ok {
+		return c.(*sshConn)
+	}
+	max := h.maxSessions
+	if max <= 0 {
+		max = DefaultMaxSessions
+	}
+	c, _ := sshPool.LoadOrStore(h.name, &sshConn{
+		name:     h.name,
+		sessions: make(chan struct{}, max),
+	})
+	return c.(*sshConn)
+}
+
+// ssh returns the SSH client for the connection, calling dial to
+// connect if there is no client or the previous connection has ended.
+// Only one caller dials at a time, others wait and share the result.
+// The connection is not locked while dialling, so that metrics and
+// users of an existing connection are not held up.
+func (c *sshConn) ssh(dial func() (*ssh.Client, error), keepAlive time.Duration) (client *ssh.Client, err error) {
+	if client = c.current(); client != nil {
+		return
+	}
+
+	c.dial.Lock()
+	defer c.dial.Unlock()
+
+	c.mutex.Lock()
+	if c.client != nil && !closed(c.done) {
+		client = c.client
+		c.mutex.Unlock()
+		return
+	}
+	reconnect := c.client != nil
+	c.reset()
+	c.mutex.Unlock()
+
+	c.dials.Add(1)
+	if client, err = dial(); err != nil {
+		c.failures.Add(1)
+		return
+	}
+	if reconnect {
+		c.reconnects.Add(1)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+	if keepAlive > 0 {
+		go c.keepAlive(client, done, keepAlive)
+	}
+	c.mutex.Lock()
+	c.client, c.done, c.since = client, done, time.Now()
+	c.mutex.Unlock()
+	return
+}
+
+// current returns the SSH client for the connection, or nil if there is
+// no client or the connection has ended
+func (c *sshConn) current() *ssh.Client {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil && !closed(c.done) {
+		return c.client
+	}
+	return nil
+}
+
+// sftpClient returns the SFTP client running over the SSH client, which
+// must be the current one for the connection, starting a new SFTP
+// subsystem if there is none or the previous one has ended
+func (c *sshConn) sftpClient(client *ssh.Client) (f *sftp.Client, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if client != c.client {
+		return nil, sftp.ErrSSHFxConnectionLost
+	}
+	if c.sftp != nil && !closed(c.sftpDone) {
+		return c.sftp, nil
+	}
+	if f, err = sftp.NewClient(client); err != nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		f.Wait()
+		close(done)
+	}()
+	c.sftp, c.sftpDone = f, done
+	return
+}
+
+// keepAlive sends a keep-alive request every interval until the
+// connection ends. If a request fails or is not answered within the
+// interval then the client is closed, which ends the connection so that
+// the next caller re-dials.
+func (c *sshConn) keepAlive(client *ssh.Client, done chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		// the reply, even a failure, shows the server is responsive
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+		select {
+		case <-done:
+			return
+		case err := <-reply:
+			if err == nil {
+				continue
+			}
+		case <-time.After(interval):
+		}
+		c.keepAliveFailures.Add(1)
+		client.Close()
+		return
+	}
+}
+
+// acquire waits for a free session slot
+func (c *sshConn) acquire() {
+	select {
+	case c.sessions <- struct{}{}:
+	default:
+		c.sessionWaits.Add(1)
+		c.sessions <- struct{}{}
+	}
+}
+
+// release frees a session slot
+func (c *sshConn) release() {
+	<-c.sessions
+}
+
+// lost returns true if err shows that the SFTP client f, or the
+// connection under it, has gone away
+func (c *sshConn) lost(f *sftp.Client, err error) bool {
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, sftp.ErrSSHFxNoConnection) {
+		return true
+	}
+	if !errors.Is(err, io.EOF) {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return f != c.sftp || closed(c.sftpDone)
+}
+
+// drop closes client, if it is still the current client, and waits for
+// the connection to end so that the next caller re-dials
+func (c *sshConn) drop(client *ssh.Client) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if client != c.client || closed(c.done) {
+		return
+	}
+	client.Close()
+	<-c.done
+}
+
+// closeSFTP closes the SFTP client, if any
+func (c *sshConn) closeSFTP() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.sftp != nil {
+		c.sftp.Close()
+		c.sftp, c.sftpDone = nil, nil
+	}
+}
+
+// close closes the SFTP and SSH clients, if any
+func (c *sshConn) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.reset()
+}
+
+// reset closes and clears the clients. The caller must hold the mutex.
+func (c *sshConn) reset() {
+	if c.sftp != nil {
+		c.sftp.Close()
+	}
+	if c.client != nil {
+		c.client.Close()
+	}
+	c.client, c.done, c.sftp, c.sftpDone = nil, nil, nil, nil
+	c.since = time.Time{}
+}
+
+func (c *sshConn) stats() (s SSHPoolStats) {
+	c.mutex.Lock()
+	connected := c.client != nil && !closed(c.done)
+	s = SSHPoolStats{
+		Name:      c.name,
+		Connected: connected,
+		SFTP:      connected && c.sftp != nil && !closed(c.sftpDone),
+	}
+	if connected {
+		s.ConnectedSince = c.since
+	}
+	c.mutex.Unlock()
+
+	s.Dials = c.dials.Load()
+	s.Reconnects = c.reconnects.Load()
+	s.Failures = c.failures.Load()
+	s.KeepAliveFailures = c.keepAliveFailures.Load()
+	s.ActiveSessions = len(c.sessions)
+	s.MaxSessions = cap(c.sessions)
+	s.SessionsOpened = c.sessionsOpened.Load()
+	s.SessionWaits = c.sessionWaits.Load()
+	return
+}
+
+// closed returns true if done is nil or closed
+func closed(done chan struct{}) bool {
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// SSHSession is an SSH session from a pooled connection. The session
+// holds one of the remote's session slots until Close is called.
+type SSHSession struct {
+	*ssh.Session
+	release sync.Once
+	conn    *sshConn
+}
+
+// Close closes the session and returns its slot to the pool. It is
+// safe to call Close more than once.
+func (s *SSHSession) Close() (err error) {
+	err = s.Session.Close()
+	s.release.Do(s.conn.release)
+	return
+}
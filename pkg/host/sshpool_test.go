@@ -0,0 +1,405 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package host
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSSHServer is an in-process SSH server that accepts a password
+// and runs two commands: "echo", which writes "ok", and "sleep", which
+// does the same after a short delay
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+
+	mutex     sync.Mutex
+	conns     []net.Conn
+	active    int // running commands
+	maxActive int
+
+	keepAlives      atomic.Int64
+	ignoreKeepAlive atomic.Bool
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testSSHServer{
+		config: &ssh.ServerConfig{
+			PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+				if c.User() == "geneos" && string(password) == "secret" {
+					return nil, nil
+				}
+				return nil, ssh.ErrNoAuth
+			},
+		},
+		hostKey: signer.PublicKey(),
+	}
+	s.config.AddHostKey(signer)
+
+	if s.listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		s.listener.Close()
+		s.dropAll()
+	})
+	go s.serve()
+	return s
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.conns = append(s.conns, nc)
+		s.mutex.Unlock()
+		go s.handle(nc)
+	}
+}
+
+func (s *testSSHServer) handle(nc net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, s.config)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	go func() {
+		for req := range reqs {
+			if req.Type == "keepalive@openssh.com" {
+				s.keepAlives.Add(1)
+				if s.ignoreKeepAlive.Load() {
+					continue
+				}
+			}
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}()
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			nch.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, requests, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go s.session(ch, requests)
+	}
+}
+
+func (s *testSSHServer) session(ch ssh.Channel, requests <-chan *ssh.Request) {
+	for req := range requests {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		var exec struct{ Command string }
+		ssh.Unmarshal(req.Payload, &exec)
+		req.Reply(true, nil)
+
+		// count running commands, finishing before the client can see
+		// the exit status
+		s.mutex.Lock()
+		s.active++
+		s.maxActive = max(s.maxActive, s.active)
+		s.mutex.Unlock()
+		if exec.Command == "sleep" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		ch.Write([]byte("ok"))
+		s.mutex.Lock()
+		s.active--
+		s.mutex.Unlock()
+
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+		ch.Close()
+	}
+}
+
+// dropAll closes all the connections to the server
+func (s *testSSHServer) dropAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+// remote returns an SSHRemote for the server, with a new home directory
+// holding a known_hosts file for it. The pooled connection is closed
+// and removed at the end of the test.
+func (s *testSSHServer) remote(t *testing.T, options ...any) *SSHRemote {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if err := os.MkdirAll(filepath.Join(home, userSSHdir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	line := knownhosts.Line([]string{s.listener.Addr().String()}, s.hostKey)
+	if err := os.WriteFile(filepath.Join(home, userSSHdir, "known_hosts"), []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := s.listener.Addr().(*net.TCPAddr)
+	options = append([]any{
+		Hostname("127.0.0.1"),
+		Port(uint16(addr.Port)),
+		Username("geneos"),
+		Password([]byte("secret")),
+	}, options...)
+	r := NewSSHRemote(t.Name(), options...).(*SSHRemote)
+	t.Cleanup(func() {
+		r.Close()
+		sshPool.Delete(r.name)
+	})
+	return r
+}
+
+// poolStats returns the pool metrics for remote r
+func poolStats(t *testing.T, r *SSHRemote) SSHPoolStats {
+	t.Helper()
+	for _, s := range SSHPoolMetrics() {
+		if s.Name == r.name {
+			return s
+		}
+	}
+	t.Fatalf("no pool metrics for %s", r.name)
+	return SSHPoolStats{}
+}
+
+// waitFor polls cond until it returns true, failing the test after a
+// few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestSSHPooledSessions(t *testing.T) {
+	server := newTestSSHServer(t)
+	r := server.remote(t, MaxSessions(2), KeepAlive(0))
+
+	const sessions = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions)
+	for range sessions {
+		wg.Go(func() {
+			sess, err := r.NewPooledSession()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer sess.Close()
+			if out, err := sess.Output("sleep"); err != nil || string(out) != "ok" {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("session: %v", err)
+	}
+
+	server.mutex.Lock()
+	maxActive := server.maxActive
+	server.mutex.Unlock()
+	if maxActive != 2 {
+		t.Errorf("server ran %d concurrent commands, want the limit of 2", maxActive)
+	}
+
+	s := poolStats(t, r)
+	if s.Dials != 1 {
+		t.Errorf("dials = %d, want 1 shared connection", s.Dials)
+	}
+	if s.SessionsOpened != sessions {
+		t.Errorf("sessions opened = %d, want %d", s.SessionsOpened, sessions)
+	}
+	if s.SessionWaits == 0 {
+		t.Error("no session waits recorded")
+	}
+	if s.ActiveSessions != 0 || s.MaxSessions != 2 {
+		t.Errorf("active/max sessions = %d/%d, want 0/2", s.ActiveSessions, s.MaxSessions)
+	}
+}
+
+func TestSSHNewSession(t *testing.T) {
+	server := newTestSSHServer(t)
+	r := server.remote(t, MaxSessions(1), KeepAlive(0))
+
+	// hold the only slot, NewSession is outside the limit
+	pooled, err := r.NewPooledSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pooled.Close()
+
+	sess, err := r.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := sess.Output("echo")
+	sess.Close()
+	if err != nil || string(out) != "ok" {
+		t.Fatalf("Output() = %q, %v", out, err)
+	}
+
+	if s := poolStats(t, r); s.ActiveSessions != 1 || s.Dials != 1 {
+		t.Errorf("active sessions/dials = %d/%d, want 1/1", s.ActiveSessions, s.Dials)
+	}
+
+	// closing twice only frees the slot once
+	pooled.Close()
+	pooled.Close()
+	if s := poolStats(t, r); s.ActiveSessions != 0 {
+		t.Errorf("active sessions = %d after Close, want 0", s.ActiveSessions)
+	}
+}
+
+func TestSSHKeepAlive(t *testing.T) {
+	server := newTestSSHServer(t)
+	r := server.remote(t, KeepAlive(50*time.Millisecond))
+
+	if _, err := r.Run(exec.Command("echo")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "keep-alives", func() bool { return server.keepAlives.Load() >= 2 })
+	if s := poolStats(t, r); !s.Connected || s.KeepAliveFailures != 0 {
+		t.Fatalf("connected/keep-alive failures = %v/%d, want true/0", s.Connected, s.KeepAliveFailures)
+	}
+
+	// an unanswered keep-alive closes the connection
+	server.ignoreKeepAlive.Store(true)
+	waitFor(t, "keep-alive failure", func() bool {
+		s := poolStats(t, r)
+		return !s.Connected && s.KeepAliveFailures == 1
+	})
+	server.ignoreKeepAlive.Store(false)
+
+	// and the next session re-dials
+	if _, err := r.Run(exec.Command("echo")); err != nil {
+		t.Fatal(err)
+	}
+	if s := poolStats(t, r); !s.Connected || s.Dials != 2 || s.Reconnects != 1 {
+		t.Errorf("connected/dials/reconnects = %v/%d/%d, want true/2/1", s.Connected, s.Dials, s.Reconnects)
+	}
+}
+
+func TestSSHReconnect(t *testing.T) {
+	server := newTestSSHServer(t)
+	r := server.remote(t, KeepAlive(0))
+
+	for n := range 3 {
+		sess, err := r.NewPooledSession()
+		if err != nil {
+			t.Fatalf("session %d: %v", n, err)
+		}
+		out, err := sess.Output("echo")
+		sess.Close()
+		if err != nil || string(out) != "ok" {
+			t.Fatalf("session %d: Output() = %q, %v", n, out, err)
+		}
+		// the server drops the connection after each session
+		server.dropAll()
+	}
+
+	if s := poolStats(t, r); s.Dials != 3 || s.Reconnects != 2 || s.Failures != 0 {
+		t.Errorf("dials/reconnects/failures = %d/%d/%d, want 3/2/0", s.Dials, s.Reconnects, s.Failures)
+	}
+}
+
+// TestSSHDialUnlocked checks that the connection is not locked while
+// dialling, and that a second caller waits for the first to dial
+func TestSSHDialUnlocked(t *testing.T) {
+	c := &sshConn{name: "test", sessions: make(chan struct{}, 1)}
+	dialling, release := make(chan struct{}), make(chan struct{})
+	dial := func() (*ssh.Client, error) {
+		close(dialling)
+		<-release
+		return nil, errors.New("refused")
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.ssh(dial, 0)
+		errs <- err
+	}()
+	<-dialling
+	go func() {
+		// a second dial is only made after the first fails
+		_, err := c.ssh(func() (*ssh.Client, error) { return nil, errors.New("refused") }, 0)
+		errs <- err
+	}()
+
+	stats := make(chan SSHPoolStats)
+	go func() { stats <- c.stats() }()
+	select {
+	case s := <-stats:
+		if s.Dials != 1 || s.Connected {
+			t.Errorf("dials/connected while dialling = %d/%v, want 1/false", s.Dials, s.Connected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stats() blocked while dialling")
+	}
+
+	close(release)
+	for range 2 {
+		if err := <-errs; err == nil {
+			t.Error("ssh() returned no error for a failed dial")
+		}
+	}
+	if s := c.stats(); s.Dials != 2 || s.Failures != 2 {
+		t.Errorf("dials/failures = %d/%d, want 2/2", s.Dials, s.Failures)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// JSONReporter implements a Reporter that outputs reports as a JSON
+// array, one object per report, with the headlines, the column names in
+// order and the rows as objects keyed by column name.
+type JSONReporter struct {
+	reporterCommon
+	w       io.Writer
+	reports []*jsonReport
+	current *jsonReport
+}
+
+// jsonReport is the JSON encoding of a single report
+type jsonReport struct {
+	Name            string              `json:"name"`
+	Title           string              `json:"title,omitempty"`
+	Group           string              `json:"group,omitempty"`
+	Headlines       map[string]string   `json:"headlines,omitempty"`
+	Columns         []string            `json:"columns"`
+	Rows            []map[string]string `json:"rows"`
+	scrambleColumns []string
+}
+
+// ensure that *JSONReporter conforms to the Reporter interface
+var _ Reporter = (*JSONReporter)(nil)
+
+func init() {
+	registerReporter("json", newJSONReporter)
+}
+
+func newJSONReporter(format string, w io.Writer, options ...any) (Reporter, error) {
+	opts := evalReporterOptions(CollectOptions[ReporterOption](options...)...)
+	return &JSONReporter{
+		reporterCommon: reporterCommon{
+			format:        "json",
+			scrambleNames: opts.scrambleNames,
+		},
+		w: w,
+	}, nil
+}
+
+// Prepare starts a new report. Any previous report is kept until the
+// next call to Render.
+func (j *JSONReporter) Prepare(report Report) error {
+	j.current = &jsonReport{
+		Name:            report.Name,
+		Title:           report.Title,
+		Group:           report.Dataview.Group,
+		Columns:         []string{},
+		Rows:            []map[string]string{},
+		scrambleColumns: report.ScrambleColumns,
+	}
+	j.reports = append(j.reports, j.current)
+	return nil
+}
+
+// AddHeadline adds a headline to the current report
+func (j *JSONReporter) AddHeadline(name, value string) {
+	j.AddHeadlines(map[string]string{name: value})
+}
+
+// AddHeadlines adds multiple headlines to the current report
+func (j *JSONReporter) AddHeadlines(headlines map[string]string) {
+	if j.current == nil {
+		j.Prepare(Report{})
+	}
+	if j.current.Headlines == nil {
+		j.current.Headlines = map[string]string{}
+	}
+	maps.Copy(j.current.Headlines, headlines)
+}
+
+// UpdateTable sets the table of the current report, replacing any
+// existing rows
+func (j *JSONReporter) UpdateTable(columns []string, rows [][]string) {
+	if j.current == nil {
+		j.Prepare(Report{})
+	}
+	if j.scrambleNames {
+		scrambleColumns(columns, j.current.scrambleColumns, rows)
+	}
+	j.current.Columns = slices.Clone(columns)
+	j.current.Rows = make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		r := make(map[string]string, len(columns))
+		for i, c := range columns {
+			if i < len(row) {
+				r[c] = row[i]
+			}
+		}
+		j.current.Rows = append(j.current.Rows, r)
+	}
+}
+
+func (j *JSONReporter) Reset(report Report) (err error) {
+	// do nothing
+	return
+}
+
+// Render writes the reports prepared since the last call to Render as
+// a JSON array
+func (j *JSONReporter) Render() {
+	reports := j.reports
+	if reports == nil {
+		reports = []*jsonReport{}
+	}
+	enc := json.NewEncoder(j.w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(reports); err != nil {
+		log.Error("cannot encode reports", slog.Any("error", err))
+	}
+	j.reports, j.current = nil, nil
+}
+
+// Close will call Close on the writer if it has a Close method
+func (j *JSONReporter) Close() {
+	if c, ok := j.w.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func (j *JSONReporter) Extension() string {
+	return "json"
+}
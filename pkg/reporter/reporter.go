@@ -111,8 +111,8 @@ func registerReporter(format string, factory func(string, io.Writer, ...any) (Re
 }
 
 // NewReporter returns a reporter for type format, which must be one of
-// "toolkit", "csv", "tsv", "api", "dataview", "xlsx", "table" or
-// "html". If a destination writer is required for the reporter type,
+// "toolkit", "csv", "tsv", "api", "dataview", "xlsx", "table", "json"
+// or "html". If a destination writer is required for the reporter type,
 // then w should be the io.Writer to use. options are a list of options
 // of either ReporterOptions or the options for the selected reporter
 // type.
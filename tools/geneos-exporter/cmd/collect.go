@@ -0,0 +1,390 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+)
+
+// a target is one configured dataview path on one Gateway
+type target struct {
+	id       string
+	gateway  string
+	gwcf     *config.Config
+	conn     *commands.Connection
+	path     *xpath.XPath
+	interval time.Duration
+	timeout  time.Duration
+
+	prefix    string
+	headlines bool
+	severity  bool
+	columns   []string
+}
+
+// collector polls targets and holds the most recent samples from each
+type collector struct {
+	mu      sync.RWMutex
+	rules   []relabelRule
+	targets []*target
+	samples map[string][]sample
+	status  map[string]*targetStatus
+}
+
+// targetStatus records the result of the most recent poll of a target
+type targetStatus struct {
+	gateway  string
+	path     string
+	up       bool
+	duration time.Duration
+	last     time.Time
+	errors   int
+}
+
+// newCollector builds a collector from the configuration, creating one
+// target for each combination of Gateway and dataview path
+func newCollector(cf *config.Config) (c *collector, err error) {
+	c = &collector{
+		samples: map[string][]sample{},
+		status:  map[string]*targetStatus{},
+	}
+
+	if err = cf.UnmarshalKey("relabel", &c.rules, config.NoExpand()); err != nil {
+		return
+	}
+	for i := range c.rules {
+		if err = c.rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("relabel rule %d: %w", i, err)
+		}
+	}
+
+	var gateways []*config.Config
+	for i := 0; ; i++ {
+		g := config.Join("gateways", strconv.Itoa(i))
+		if !cf.IsSet(g) {
+			break
+		}
+		gateways = append(gateways, cf.Sub(g))
+	}
+	if len(gateways) == 0 {
+		return nil, errors.New("no gateways configured")
+	}
+
+	for i := 0; ; i++ {
+		d := config.Join("dataviews", strconv.Itoa(i))
+		if !cf.IsSet(d) {
+			break
+		}
+		dv := cf.Sub(d)
+
+		p := config.Get[string](dv, "path")
+		x, err := xpath.Parse(p)
+		if err != nil {
+			return nil, fmt.Errorf("dataview %d: invalid path %q: %w", i, p, err)
+		}
+		x = x.ResolveTo(&xpath.Dataview{})
+
+		only := config.Get[[]string](dv, "gateways")
+		for _, gw := range gateways {
+			name := config.Get[string](gw, "name")
+			if len(only) > 0 && !slices.Contains(only, name) {
+				continue
+			}
+			t := &target{
+				id:        fmt.Sprintf("%s:%d", name, i),
+				gateway:   name,
+				gwcf:      gw,
+				path:      x,
+				interval:  config.Get[time.Duration](dv, "interval", config.DefaultValue(config.Get[time.Duration](cf, "interval"))),
+				timeout:   config.Get[time.Duration](cf, "timeout"),
+				prefix:    config.Get[string](dv, "prefix", config.DefaultValue(config.Get[string](cf, "prefix"))),
+				headlines: config.Get[bool](dv, "headlines", config.DefaultValue(true)),
+				severity:  config.Get[bool](dv, "severity"),
+				columns:   config.Get[[]string](dv, "columns"),
+			}
+			if t.interval <= 0 {
+				t.interval = time.Minute
+			}
+			c.targets = append(c.targets, t)
+			c.status[t.id] = &targetStatus{gateway: name, path: p}
+		}
+	}
+	if len(c.targets) == 0 {
+		return nil, errors.New("no dataviews configured")
+	}
+
+	return
+}
+
+// run polls each target on its own interval until ctx is cancelled
+func (c *collector) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range c.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+			for {
+				c.poll(t)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(t)
+	}
+	wg.Wait()
+}
+
+// pollAll polls every target once
+func (c *collector) pollAll() {
+	var wg sync.WaitGroup
+	for _, t := range c.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			c.poll(t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+// poll fetches all the dataviews matching the target path and replaces
+// the samples for the target. On error the previous samples are
+// discarded so that stale values are not exported.
+func (c *collector) poll(t *target) {
+	start := time.Now()
+	samples, err := t.collect()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.status[t.id]
+	st.duration = time.Since(start)
+	st.last = start
+	st.up = err == nil
+	if err != nil {
+		log.Error("polling dataviews failed", slog.String("gateway", t.gateway), slog.String("path", st.path), slog.Any("error", err))
+		st.errors++
+		delete(c.samples, t.id)
+		return
+	}
+
+	var kept []sample
+	for _, s := range samples {
+		if !relabel(&s, c.rules) {
+			continue
+		}
+		s.labels[nameLabel] = metricName(s.labels[nameLabel])
+		kept = append(kept, s)
+	}
+	c.samples[t.id] = kept
+	log.Debug("polled dataviews", slog.String("gateway", t.gateway), slog.String("path", st.path), slog.Int("samples", len(kept)), slog.Duration("duration", st.duration))
+}
+
+// collect returns the samples for all dataviews matching the target
+// path, dialling the Gateway if there is no connection
+func (t *target) collect() (samples []sample, err error) {
+	if t.conn == nil {
+		if t.conn, err = dialGateway(t.gwcf, t.timeout); err != nil {
+			t.conn = nil
+			return
+		}
+	}
+
+	dataviews, err := t.conn.Match(t.path, 0)
+	if err != nil {
+		// force a new connection on the next poll
+		t.conn = nil
+		return
+	}
+
+	scope := commands.Scope{Value: true, Severity: t.severity}
+	for _, d := range dataviews {
+		dv, err := t.conn.Snapshot(d, "", scope)
+		if err != nil {
+			log.Debug("dataview snapshot failed", slog.String("dataview", d.String()), slog.Any("error", err))
+			continue
+		}
+		samples = append(samples, t.dataviewSamples(d, dv)...)
+	}
+	return
+}
+
+// dataviewSamples converts the dataview dv, at path d, into samples.
+// A column is treated as numeric if all its non-empty values are
+// numbers, otherwise each cell becomes an info metric with the value as
+// a label.
+func (t *target) dataviewSamples(d *xpath.XPath, dv *commands.Dataview) (samples []sample) {
+	base := map[string]string{"gateway": t.gateway}
+	if base["gateway"] == "" && d.Gateway != nil {
+		base["gateway"] = d.Gateway.Name
+	}
+	if d.Probe != nil {
+		base["probe"] = d.Probe.Name
+	}
+	if d.Entity != nil {
+		base["entity"] = d.Entity.Name
+	}
+	if d.Sampler != nil {
+		base["sampler"] = d.Sampler.Name
+		if d.Sampler.Type != nil {
+			base["type"] = *d.Sampler.Type
+		}
+	}
+	base["dataview"] = dv.Name
+
+	newSample := func(name string, value float64, kind metricKind, extra ...string) sample {
+		labels := make(map[string]string, len(base)+len(extra)/2+1)
+		for k, v := range base {
+			labels[k] = v
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			labels[extra[i]] = extra[i+1]
+		}
+		labels[nameLabel] = t.prefix + "_" + name + suffixes[kind]
+		return sample{labels: labels, value: value, kind: kind}
+	}
+
+	if t.headlines {
+		for _, h := range dv.HeadlineOrder {
+			if h == "samplingStatus" {
+				// always text, and better taken from a severity
+				continue
+			}
+			item := dv.Headlines[h]
+			if v, ok := parseValue(item.Value); ok {
+				samples = append(samples, newSample("headline_"+h, v, gauge))
+			} else if item.Value != "" {
+				samples = append(samples, newSample("headline_"+h, 1, info, "value", item.Value))
+			}
+		}
+	}
+
+	columns := slices.DeleteFunc(slices.Clone(dv.ColumnOrder), func(col string) bool {
+		return !t.wantColumn(col) || !inTable(dv, col)
+	})
+
+	for _, col := range columns {
+		numeric, empty := true, true
+		for _, row := range dv.RowOrder {
+			value := strings.TrimSpace(dv.Table[row][col].Value)
+			if value == "" {
+				continue
+			}
+			empty = false
+			if _, ok := parseValue(value); !ok {
+				numeric = false
+				break
+			}
+		}
+		if empty {
+			continue
+		}
+
+		for _, row := range dv.RowOrder {
+			item := dv.Table[row][col]
+			if numeric {
+				if v, ok := parseValue(item.Value); ok {
+					samples = append(samples, newSample(col, v, gauge, "row", row))
+				}
+			} else if item.Value != "" {
+				samples = append(samples, newSample(col, 1, info, "row", row, "value", item.Value))
+			}
+		}
+	}
+
+	if t.severity {
+		for _, row := range dv.RowOrder {
+			for _, col := range columns {
+				if v, ok := severityValues[strings.ToLower(dv.Table[row][col].Severity)]; ok {
+					samples = append(samples, newSample("severity", v, gauge, "row", row, "column", col))
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// inTable returns true if column has a cell in any row of dv. The first
+// column, which holds the row names, is not included in the cells.
+func inTable(dv *commands.Dataview, column string) bool {
+	for _, row := range dv.Table {
+		if _, ok := row[column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// wantColumn returns true if column matches one of the configured
+// column patterns, or if there are none
+func (t *target) wantColumn(column string) bool {
+	if len(t.columns) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(t.columns, func(p string) bool {
+		ok, _ := path.Match(p, column)
+		return ok
+	})
+}
+
+// gather returns the current samples for all targets plus the exporter
+// status metrics
+func (c *collector) gather(prefix string) (samples []sample) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, t := range c.targets {
+		samples = append(samples, c.samples[t.id]...)
+
+		st := c.status[t.id]
+		labels := func(name string) map[string]string {
+			return map[string]string{nameLabel: prefix + "_exporter_" + name, "gateway": st.gateway, "path": st.path}
+		}
+		up := 0.0
+		if st.up {
+			up = 1
+		}
+		samples = append(samples,
+			sample{labels: labels("up"), value: up},
+			sample{labels: labels("poll_duration_seconds"), value: st.duration.Seconds()},
+			sample{labels: labels("poll_errors_total"), value: float64(st.errors), kind: counter},
+		)
+		if !st.last.IsZero() {
+			samples = append(samples, sample{labels: labels("last_poll_timestamp_seconds"), value: float64(st.last.Unix())})
+		}
+	}
+	return
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+)
+
+// dialGateway connects to the Gateway configured in gw, which is one
+// element of the `gateways` list. Credentials are taken from the
+// configuration or, if no username is set, from the credentials file
+// using the Gateway name.
+func dialGateway(gw *config.Config, timeout time.Duration) (c *commands.Connection, err error) {
+	var password config.Secret
+
+	u := &url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s:%d", config.Get[string](gw, "host", config.DefaultValue("localhost")), config.Get[uint16](gw, "port", config.DefaultValue(7039))),
+	}
+
+	if config.Get[bool](gw, "use-tls") {
+		u.Scheme = "https"
+	}
+
+	username := config.Get[string](gw, "username")
+	name := config.Get[string](gw, "name")
+
+	if username != "" {
+		password = config.Get[config.Secret](gw, "password")
+		defer clear(password)
+	}
+
+	if username == "" {
+		var creds *config.Config
+		if name != "" {
+			creds = config.FindCreds("gateway:"+name, config.AppName("geneos"))
+		} else {
+			creds = config.FindCreds("gateway", config.AppName("geneos"))
+		}
+		if creds != nil {
+			username = config.Get[string](creds, "username")
+			password = config.Get[config.Secret](creds, "password")
+			defer clear(password)
+		}
+	}
+
+	return commands.DialGateway(u,
+		commands.SetBasicAuth(username, password),
+		commands.AllowInsecureCertificates(config.Get[bool](gw, "allow-insecure")),
+		commands.Timeout(timeout),
+	)
+}
@@ -0,0 +1,269 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// a sample is a single metric value with its labels. The metric name
+// is held in the "__name__" label until relabelling is complete.
+type sample struct {
+	labels map[string]string
+	value  float64
+	kind   metricKind
+}
+
+// metricKind is the metric type of a sample
+type metricKind int
+
+const (
+	gauge metricKind = iota
+	info
+	counter
+)
+
+// suffixes are the required metric name suffixes for each kind
+var suffixes = map[metricKind]string{
+	info:    "_info",
+	counter: "_total",
+}
+
+const nameLabel = "__name__"
+
+// relabelRule is a relabelling rule, following the same semantics as
+// Prometheus `relabel_configs`
+type relabelRule struct {
+	SourceLabels []string `mapstructure:"source-labels"`
+	Separator    string   `mapstructure:"separator"`
+	Regex        string   `mapstructure:"regex"`
+	TargetLabel  string   `mapstructure:"target-label"`
+	Replacement  string   `mapstructure:"replacement"`
+	Action       string   `mapstructure:"action"`
+
+	re *regexp.Regexp
+}
+
+// compile checks the rule, sets defaults and compiles the regular
+// expression, which is anchored at both ends
+func (r *relabelRule) compile() (err error) {
+	if r.Separator == "" {
+		r.Separator = ";"
+	}
+	if r.Regex == "" {
+		r.Regex = "(.*)"
+	}
+	if r.Replacement == "" {
+		r.Replacement = "$1"
+	}
+	if r.Action == "" {
+		r.Action = "replace"
+	}
+	r.Action = strings.ToLower(r.Action)
+
+	switch r.Action {
+	case "replace":
+		if r.TargetLabel == "" {
+			return fmt.Errorf("relabel action %q requires a target-label", r.Action)
+		}
+	case "keep", "drop", "labeldrop", "labelkeep":
+	default:
+		return fmt.Errorf("unknown relabel action %q", r.Action)
+	}
+
+	r.re, err = regexp.Compile("^(?:" + r.Regex + ")$")
+	return
+}
+
+// relabel applies rules to the labels of s, in order. It returns false
+// if the sample should be dropped.
+func relabel(s *sample, rules []relabelRule) bool {
+	for _, r := range rules {
+		var values []string
+		for _, l := range r.SourceLabels {
+			values = append(values, s.labels[l])
+		}
+		value := strings.Join(values, r.Separator)
+
+		switch r.Action {
+		case "keep":
+			if !r.re.MatchString(value) {
+				return false
+			}
+		case "drop":
+			if r.re.MatchString(value) {
+				return false
+			}
+		case "labeldrop":
+			maps.DeleteFunc(s.labels, func(k, _ string) bool {
+				return k != nameLabel && r.re.MatchString(k)
+			})
+		case "labelkeep":
+			maps.DeleteFunc(s.labels, func(k, _ string) bool {
+				return k != nameLabel && !r.re.MatchString(k)
+			})
+		default:
+			m := r.re.FindStringSubmatchIndex(value)
+			if m == nil {
+				continue
+			}
+			target := string(r.re.ExpandString(nil, r.TargetLabel, value, m))
+			replacement := string(r.re.ExpandString(nil, r.Replacement, value, m))
+			if replacement == "" {
+				delete(s.labels, target)
+				continue
+			}
+			s.labels[target] = replacement
+		}
+	}
+	return s.labels[nameLabel] != ""
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]+`)
+
+// metricName returns s as a valid metric name, with runs of invalid
+// characters replaced by a single underscore
+func metricName(s string) string {
+	s = strings.Trim(invalidNameChars.ReplaceAllString(s, "_"), "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+// parseValue returns the numeric value of a Geneos cell, allowing for
+// a trailing percent sign, and true if the value is numeric
+func parseValue(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// severityValues maps Geneos severities to metric values
+var severityValues = map[string]float64{
+	"undefined": 0,
+	"ok":        1,
+	"warning":   2,
+	"critical":  3,
+}
+
+// writeMetrics writes samples to w in the Prometheus text exposition
+// format or, if openMetrics is true, the OpenMetrics text format.
+// Samples are grouped into families by name, with families and samples
+// sorted to give stable output. Samples with a duplicate name and label
+// set are dropped.
+func writeMetrics(w io.Writer, samples []sample, openMetrics bool) (err error) {
+	type family struct {
+		kind    metricKind
+		samples map[string]float64
+	}
+	families := map[string]*family{}
+
+	for _, s := range samples {
+		name := s.labels[nameLabel]
+		if !strings.HasSuffix(name, suffixes[s.kind]) {
+			name += suffixes[s.kind]
+		}
+		f, ok := families[name]
+		if !ok {
+			f = &family{kind: s.kind, samples: map[string]float64{}}
+			families[name] = f
+		}
+		l := formatLabels(s.labels)
+		if _, ok := f.samples[l]; ok {
+			log.Debug("dropping duplicate sample", slog.String("name", name), slog.String("labels", l))
+			continue
+		}
+		f.samples[l] = s.value
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		f := families[name]
+		switch {
+		case f.kind == info && openMetrics:
+			_, err = fmt.Fprintf(w, "# TYPE %s info\n", strings.TrimSuffix(name, suffixes[info]))
+		case f.kind == counter && openMetrics:
+			_, err = fmt.Fprintf(w, "# TYPE %s counter\n", strings.TrimSuffix(name, suffixes[counter]))
+		case f.kind == counter:
+			_, err = fmt.Fprintf(w, "# TYPE %s counter\n", name)
+		default:
+			// info metrics are gauges in the Prometheus text format
+			_, err = fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		}
+		if err != nil {
+			return
+		}
+		for _, l := range slices.Sorted(maps.Keys(f.samples)) {
+			if _, err = fmt.Fprintf(w, "%s%s %s\n", name, l, formatValue(f.samples[l])); err != nil {
+				return
+			}
+		}
+	}
+
+	if openMetrics {
+		_, err = io.WriteString(w, "# EOF\n")
+	}
+	return
+}
+
+// formatLabels returns the labels, excluding those starting with a
+// double underscore, sorted by name in exposition format
+func formatLabels(labels map[string]string) string {
+	var l []string
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		l = append(l, fmt.Sprintf(`%s="%s"`, k, labelEscaper.Replace(labels[k])))
+	}
+	if len(l) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(l, ",") + "}"
+}
+
+// labelEscaper escapes label values as required by the exposition
+// formats
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	_ "embed"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+var configFile, logFile string
+var Debug bool
+
+var log = cordial.Logger
+
+func init() {
+	Cmd.PersistentFlags().StringVarP(&configFile, "conf", "c", "", "override config file")
+
+	Cmd.PersistentFlags().BoolVarP(&Debug, "debug", "d", false, "enable extra debug output")
+	Cmd.PersistentFlags().MarkHidden("debug")
+
+	// how to remove the help flag help text from the help output! Sigh...
+	Cmd.PersistentFlags().BoolP("help", "h", false, "Print usage")
+	Cmd.PersistentFlags().MarkHidden("help")
+
+	Cmd.Flags().SortFlags = false
+
+	cobra.OnInitialize(func() {
+		var l slog.Level
+		if Debug {
+			l = slog.LevelDebug
+		}
+		cordial.LogInit(cordial.ExecutableName(), cordial.SetLogLevel(l))
+		log.Debug("cordial 'geneos-exporter' running as executable", slog.String("executable", cordial.ExecutableName()), slog.String("version", cordial.VERSION))
+	})
+}
+
+//go:embed _docs/geneos-exporter.md
+var geneosExporterDescription string
+
+var Cmd = &cobra.Command{
+	Use:   "geneos-exporter",
+	Short: "Export Geneos Dataviews as Prometheus Metrics",
+	Long:  geneosExporterDescription,
+	CompletionOptions: cobra.CompletionOptions{
+		DisableDefaultCmd: true,
+	},
+	Version:           cordial.VERSION,
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	cordial.RenderHelpAsMD(Cmd)
+	err := Cmd.Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+//go:embed geneos-exporter.defaults.yaml
+var defaults []byte
+
+// LoadConfigFile reads in config file and ENV variables if set.
+func LoadConfigFile() (cf *config.Config) {
+	var err error
+
+	opts := []config.FileOption{
+		config.AppName("geneos"),
+		config.UseGlobal(),
+		config.Format("yaml"),
+		config.FilePath(configFile),
+		config.WithDefaults(defaults, "yaml"),
+		config.MustExist(),
+	}
+
+	cf, err = config.Read(cordial.ExecutableName(), opts...)
+	if err != nil {
+		log.Error("failed to load a configuration file", slog.String("file", cordial.ExecutableName()+".yaml"), slog.Any("error", err))
+		os.Exit(1)
+	}
+	log.Debug("loaded config file", slog.String("file", config.Path(cordial.ExecutableName(), opts...)))
+
+	return
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	_ "embed"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+var scrapeCmdOpenMetrics bool
+
+func init() {
+	Cmd.AddCommand(scrapeCmd)
+
+	scrapeCmd.Flags().BoolVarP(&scrapeCmdOpenMetrics, "openmetrics", "o", false, "Output in OpenMetrics format")
+
+	scrapeCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/scrape.md
+var scrapeDescription string
+
+var scrapeCmd = &cobra.Command{
+	Use:          "scrape",
+	Short:        "Poll all dataviews once and write the metrics to stdout",
+	Long:         scrapeDescription,
+	SilenceUsage: true,
+	RunE: func(command *cobra.Command, args []string) (err error) {
+		cf := LoadConfigFile()
+
+		c, err := newCollector(cf)
+		if err != nil {
+			return
+		}
+		c.pollAll()
+
+		return writeMetrics(os.Stdout, c.gather(config.Get[string](cf, "prefix")), scrapeCmdOpenMetrics)
+	},
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/process"
+)
+
+var daemon bool
+
+func init() {
+	Cmd.AddCommand(startCmd)
+
+	startCmd.Flags().BoolVarP(&daemon, "daemon", "D", false, "Daemonise the exporter process")
+	startCmd.PersistentFlags().StringVarP(&logFile, "logfile", "l", "-", "Write logs to `file`. Use '-' for console or "+os.DevNull+" for none")
+
+	startCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/start.md
+var startDescription string
+
+var startCmd = &cobra.Command{
+	Use:          "start",
+	Short:        "Run the exporter",
+	Long:         startDescription,
+	SilenceUsage: true,
+	Run: func(command *cobra.Command, args []string) {
+		if daemon {
+			var logArgs []string
+
+			if logFile == "-" {
+				logArgs = append(logArgs, "--logfile", cordial.ExecutableName()+".log")
+			}
+
+			if err := process.Daemon(os.Stdout, logArgs, nil, "-D", "--daemon"); err != nil {
+				log.Error("failed to daemonise process", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}
+
+		var l slog.Level = slog.LevelInfo
+		if Debug {
+			l = slog.LevelDebug
+		}
+
+		cf := LoadConfigFile()
+
+		log = cordial.LogInit(cordial.ExecutableName(),
+			cordial.SetLogLevel(l),
+			cordial.SetLogfile(logFile),
+			cordial.LumberjackOptions(&lumberjack.Logger{
+				Filename:   logFile,
+				MaxSize:    config.Get[int](cf, cf.Join("server", "logs", "max-size")),
+				MaxBackups: config.Get[int](cf, cf.Join("server", "logs", "max-backups")),
+				MaxAge:     config.Get[int](cf, cf.Join("server", "logs", "stale-after")),
+				Compress:   config.Get[bool](cf, cf.Join("server", "logs", "compress")),
+			}),
+			cordial.RotateOnStart(config.Get[bool](cf, cf.Join("server", "logs", "rotate-on-start"))),
+		)
+
+		c, err := newCollector(cf)
+		if err != nil {
+			log.Error("invalid configuration", slog.Any("error", err))
+			os.Exit(1)
+		}
+		go c.run(context.Background())
+
+		if err = startExporter(cf, c); err != nil {
+			log.Error("failed to start server", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// openMetricsType is the content type for the OpenMetrics text format,
+// used when the client includes it in the Accept header
+const openMetricsType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+const textType = "text/plain; version=0.0.4; charset=utf-8"
+
+func startExporter(cf *config.Config, c *collector) error {
+	listen := config.Get[string](cf, cf.Join("server", "listen"))
+	metricsPath := config.Get[string](cf, cf.Join("server", "path"))
+	prefix := config.Get[string](cf, "prefix")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", openMetricsType)
+		} else {
+			w.Header().Set("Content-Type", textType)
+		}
+		if err := writeMetrics(w, c.gather(prefix), openMetrics); err != nil {
+			log.Debug("writing metrics failed", slog.Any("error", err))
+		}
+	})
+
+	log.Info("starting exporter", slog.String("listen", listen), slog.String("path", metricsPath))
+
+	if !config.Get[bool](cf, cf.Join("server", "tls", "enabled")) {
+		return http.ListenAndServe(listen, mux)
+	}
+
+	certPEM := config.Get[[]byte](cf, cf.Join("server", "tls", "certificate"))
+	keyPEM := config.Get[[]byte](cf, cf.Join("server", "tls", "private-key"))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:    listen,
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		},
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	return srv.Serve(tls.NewListener(ln, srv.TLSConfig))
+}
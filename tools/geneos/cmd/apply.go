@@ -0,0 +1,792 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/host"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+	"github.com/itrs-group/cordial/tools/geneos/internal/values"
+)
+
+var applyCmdFile string
+var applyCmdDryRun, applyCmdPrune, applyCmdForce bool
+
+func init() {
+	Cmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&applyCmdFile, "file", "f", "", "Manifest `PATH|URL|-` describing the desired state")
+	applyCmd.Flags().BoolVarP(&applyCmdDryRun, "dry-run", "n", false, "Show the plan but do not make any changes")
+	applyCmd.Flags().BoolVarP(&applyCmdPrune, "prune", "P", false, "Delete instances on manifest hosts that are not in the manifest")
+	applyCmd.Flags().BoolVarP(&applyCmdForce, "force", "F", false, "Allow changes to, and deletion of, protected instances")
+
+	applyCmd.MarkFlagRequired("file")
+
+	applyCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/apply.md
+var applyCmdDescription string
+
+var applyCmd = &cobra.Command{
+	Use:     "apply [flags] -f FILE",
+	GroupID: CommandGroupConfig,
+	Short:   "Apply A Desired State Manifest",
+	Long:    applyCmdDescription,
+	Example: `
+geneos apply -f estate.yaml --dry-run
+geneos apply -f estate.yaml
+geneos apply -f https://example.com/estate.yaml --prune
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		CmdGlobal:               "false",
+		CmdRequireHome:          "true",
+		CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(cmd *cobra.Command, _ []string) (err error) {
+		manifest, err := readApplyManifest(applyCmdFile)
+		if err != nil {
+			return
+		}
+
+		plan, err := manifest.plan(applyCmdPrune)
+		if err != nil {
+			return
+		}
+
+		if len(plan) == 0 {
+			fmt.Println("no changes required")
+			return
+		}
+
+		plan.Write(os.Stdout)
+		if applyCmdDryRun {
+			return
+		}
+		fmt.Println()
+
+		plan.Execute().Report(os.Stdout)
+		return
+	},
+}
+
+// ApplyManifest is the desired state of a Geneos estate
+type ApplyManifest struct {
+	// Hosts are the names of the hosts managed by the manifest. They
+	// must already be configured using `geneos host add`. Instances
+	// may only be placed on these hosts and, with --prune, instances on
+	// these hosts not in the manifest are deleted.
+	Hosts []string `mapstructure:"hosts"`
+
+	// Templates are written to the `templates` directory of the
+	// component type on each host
+	Templates []ApplyTemplate `mapstructure:"templates"`
+
+	// Defaults are merged into each instance, with instance settings
+	// taking precedence
+	Defaults ApplyInstance `mapstructure:"defaults"`
+
+	Instances []ApplyInstance `mapstructure:"instances"`
+}
+
+// ApplyTemplate is a template file to install for a component type
+type ApplyTemplate struct {
+	Type   string   `mapstructure:"type"`
+	File   string   `mapstructure:"file"`
+	Source string   `mapstructure:"source"`
+	Hosts  []string `mapstructure:"hosts"`
+}
+
+// ApplyInstance is the desired state of a single instance
+type ApplyInstance struct {
+	Type       string            `mapstructure:"type"`
+	Name       string            `mapstructure:"name"`
+	Host       string            `mapstructure:"host"`
+	State      string            `mapstructure:"state"` // "present" (the default) or "absent"
+	Version    string            `mapstructure:"version"`
+	Port       uint16            `mapstructure:"port"`
+	Template   string            `mapstructure:"template"`
+	Keyfile    string            `mapstructure:"keyfile"`
+	KeyfileCRC string            `mapstructure:"keycrc"`
+	Insecure   *bool             `mapstructure:"insecure"`
+	Start      *bool             `mapstructure:"start"`
+	Parameters map[string]string `mapstructure:"parameters"`
+	Envs       []string          `mapstructure:"env"`
+}
+
+func readApplyManifest(source string) (manifest *ApplyManifest, err error) {
+	b, err := geneos.ReadAll(source)
+	if err != nil {
+		return
+	}
+
+	cf, err := config.Read("apply",
+		config.UseDefaults(false),
+		config.Reader(bytes.NewReader(b)),
+		config.Format("yaml"),
+	)
+	if err != nil {
+		return
+	}
+
+	manifest = &ApplyManifest{}
+	if err = cf.UnmarshalKey("hosts", &manifest.Hosts); err != nil {
+		return
+	}
+	if err = cf.UnmarshalKey("templates", &manifest.Templates); err != nil {
+		return
+	}
+	if err = cf.UnmarshalKey("defaults", &manifest.Defaults); err != nil {
+		return
+	}
+	if err = cf.UnmarshalKey("instances", &manifest.Instances); err != nil {
+		return
+	}
+
+	if len(manifest.Hosts) == 0 {
+		manifest.Hosts = []string{geneos.LOCALHOST}
+	}
+	for n, i := range manifest.Instances {
+		manifest.Instances[n] = manifest.Defaults.merge(i)
+	}
+	return
+}
+
+// merge returns i with any unset values taken from defaults d
+func (d ApplyInstance) merge(i ApplyInstance) ApplyInstance {
+	if i.Host == "" {
+		i.Host = d.Host
+	}
+	if i.Host == "" {
+		i.Host = geneos.LOCALHOST
+	}
+	if i.State == "" {
+		i.State = d.State
+	}
+	if i.Version == "" {
+		i.Version = d.Version
+	}
+	if i.Template == "" {
+		i.Template = d.Template
+	}
+	if i.Keyfile == "" && i.KeyfileCRC == "" {
+		i.Keyfile, i.KeyfileCRC = d.Keyfile, d.KeyfileCRC
+	}
+	if i.Insecure == nil {
+		i.Insecure = d.Insecure
+	}
+	if i.Start == nil {
+		i.Start = d.Start
+	}
+
+	params := maps.Clone(d.Parameters)
+	if params == nil {
+		params = make(map[string]string)
+	}
+	maps.Copy(params, i.Parameters)
+	i.Parameters = params
+
+	i.Envs = append(slices.Clone(d.Envs), i.Envs...)
+	return i
+}
+
+// ApplyAction is a single planned change
+type ApplyAction struct {
+	Action   string // "template", "create", "update", "rebuild", "start" or "delete"
+	Host     *geneos.Host
+	Type     *geneos.Component
+	Name     string
+	Details  []string
+	Instance geneos.Instance // existing instance, nil for "create" and "template"
+
+	desired  *ApplyInstance
+	template []byte
+	params   []string
+}
+
+// ApplyPlan is the ordered list of actions required to converge on a
+// manifest
+type ApplyPlan []*ApplyAction
+
+// actionOrder is the order in which actions are run on each host
+var actionOrder = []string{"template", "delete", "create", "update", "rebuild", "start"}
+
+// plan compares the manifest with the existing instances and returns
+// the actions required. If prune is true then instances on managed
+// hosts that are not in the manifest are deleted.
+func (m *ApplyManifest) plan(prune bool) (plan ApplyPlan, err error) {
+	var hosts []*geneos.Host
+	for _, name := range m.Hosts {
+		h := geneos.GetHost(name)
+		if h == nil || !h.Exists() {
+			return nil, fmt.Errorf("%w: host %q is not configured, use `geneos host add` first", geneos.ErrInvalidArgs, name)
+		}
+		hosts = append(hosts, h)
+	}
+
+	// templates, and which host/types need a rebuild as a result.
+	// templates are also written for the instance `template` setting,
+	// so that existing instances can be changed to use them.
+	rebuild := make(map[string]bool)
+	templates := make(map[string]*ApplyAction)
+	addTemplate := func(h *geneos.Host, ct *geneos.Component, file string, content []byte) error {
+		dest := path.Join(h.PathTo(ct, "templates"), file)
+		if a, ok := templates[dest]; ok {
+			if !bytes.Equal(a.template, content) {
+				return fmt.Errorf("%w: different sources for %s template %s:%s", geneos.ErrInvalidArgs, ct, h, dest)
+			}
+			return nil
+		}
+		if existing, err := h.ReadFile(dest); err == nil && bytes.Equal(existing, content) {
+			return nil
+		}
+		a := &ApplyAction{
+			Action:   "template",
+			Host:     h,
+			Type:     ct,
+			Name:     file,
+			Details:  []string{"write " + dest},
+			template: content,
+		}
+		templates[dest] = a
+		plan = append(plan, a)
+		rebuild[h.String()+":"+ct.String()] = true
+		return nil
+	}
+
+	for _, t := range m.Templates {
+		ct := geneos.ParseComponent(t.Type)
+		if ct == nil {
+			return nil, fmt.Errorf("%w: unknown component type %q for template", geneos.ErrInvalidArgs, t.Type)
+		}
+		if t.File == "" || t.Source == "" {
+			return nil, fmt.Errorf("%w: template for %s requires both file and source", geneos.ErrInvalidArgs, ct)
+		}
+		content, err := geneos.ReadAll(t.Source)
+		if err != nil {
+			return nil, err
+		}
+		th := hosts
+		if len(t.Hosts) > 0 {
+			th = nil
+			for _, name := range t.Hosts {
+				h := geneos.GetHost(name)
+				if !slices.Contains(hosts, h) {
+					return nil, fmt.Errorf("%w: template host %q is not in the manifest hosts", geneos.ErrInvalidArgs, name)
+				}
+				th = append(th, h)
+			}
+		}
+		for _, h := range th {
+			if err = addTemplate(h, ct, t.File, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	managed := make(map[string]bool)
+	sources := make(map[string][]byte)
+
+	// unchanged instances are rebuilt if any template for their type
+	// changes, which is only known once all instances are checked
+	var unchanged []*ApplyAction
+
+	for n := range m.Instances {
+		d := &m.Instances[n]
+		ct := geneos.ParseComponent(d.Type)
+		if ct == nil {
+			return nil, fmt.Errorf("%w: unknown component type %q for instance %q", geneos.ErrInvalidArgs, d.Type, d.Name)
+		}
+		if d.Name == "" || !instance.ValidName(d.Name) {
+			return nil, fmt.Errorf("%w: invalid instance name %q", geneos.ErrInvalidArgs, d.Name)
+		}
+		h := geneos.GetHost(d.Host)
+		if !slices.Contains(hosts, h) {
+			return nil, fmt.Errorf("%w: host %q for %s %q is not in the manifest hosts", geneos.ErrInvalidArgs, d.Host, ct, d.Name)
+		}
+
+		existing := instance.Instances(h, ct, instance.MatchNames(d.Name))
+
+		if d.State == "absent" {
+			for _, i := range existing {
+				plan = append(plan, &ApplyAction{Action: "delete", Host: h, Type: ct, Name: d.Name, Instance: i})
+			}
+			continue
+		}
+		if d.State != "" && d.State != "present" {
+			return nil, fmt.Errorf("%w: invalid state %q for %s %q", geneos.ErrInvalidArgs, d.State, ct, d.Name)
+		}
+
+		if d.Template != "" && len(ct.Templates) > 0 {
+			content, ok := sources[d.Template]
+			if !ok {
+				_, src := templateSource(d.Template)
+				if content, err = geneos.ReadAll(src); err != nil {
+					return nil, err
+				}
+				sources[d.Template] = content
+			}
+			if err = addTemplate(h, ct, templateFile(d.Template), content); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(existing) == 0 {
+			plan = append(plan, &ApplyAction{Action: "create", Host: h, Type: ct, Name: d.Name, desired: d})
+			continue
+		}
+
+		for _, i := range existing {
+			managed[i.String()] = true
+
+			params, details, err := d.changes(i)
+			if err != nil {
+				return nil, err
+			}
+			if len(details) > 0 {
+				plan = append(plan, &ApplyAction{Action: "update", Host: h, Type: ct, Name: d.Name, Instance: i, Details: details, desired: d, params: params})
+			} else {
+				unchanged = append(unchanged, &ApplyAction{Action: "rebuild", Host: h, Type: ct, Name: d.Name, Instance: i, Details: []string{"template changed"}})
+			}
+			if d.Start != nil && *d.Start && !instance.IsRunning(i) && !instance.IsDisabled(i) {
+				plan = append(plan, &ApplyAction{Action: "start", Host: h, Type: ct, Name: d.Name, Instance: i})
+			}
+		}
+	}
+
+	for _, a := range unchanged {
+		if rebuild[a.Host.String()+":"+a.Type.String()] {
+			plan = append(plan, a)
+		}
+	}
+
+	if prune {
+		for _, h := range hosts {
+			for _, i := range instance.Instances(h, nil) {
+				if managed[i.String()] || i.Type() == &geneos.RootComponent {
+					continue
+				}
+				if slices.ContainsFunc(plan, func(a *ApplyAction) bool { return a.Instance == i }) {
+					continue
+				}
+				plan = append(plan, &ApplyAction{Action: "delete", Host: h, Type: i.Type(), Name: i.Name(), Instance: i, Details: []string{"not in manifest"}})
+			}
+		}
+	}
+
+	slices.SortStableFunc(plan, func(a, b *ApplyAction) int {
+		if c := strings.Compare(a.Host.String(), b.Host.String()); c != 0 {
+			return c
+		}
+		return slices.Index(actionOrder, a.Action) - slices.Index(actionOrder, b.Action)
+	})
+	return
+}
+
+// changes returns the parameters, as NAME=VALUE pairs, that must be set
+// on instance i to match d along with a human readable list of all
+// changes, including any missing environment variables
+func (d *ApplyInstance) changes(i geneos.Instance) (params []string, details []string, err error) {
+	cf := i.Config()
+
+	check := func(key, want string) {
+		if have := config.Get[string](cf, key, config.NoExpand()); have != want {
+			params = append(params, key+"="+want)
+			details = append(details, fmt.Sprintf("%s: %q -> %q", key, have, want))
+		}
+	}
+
+	if d.Version != "" {
+		check("version", d.Version)
+	}
+	if d.Port != 0 {
+		check("port", fmt.Sprint(d.Port))
+	}
+
+	keyfile := ""
+	switch {
+	case d.KeyfileCRC != "":
+		keyfile = i.Type().Shared(i.Host(), "keyfiles", strings.TrimSuffix(d.KeyfileCRC, ".aes")+".aes")
+	case d.Keyfile != "":
+		kf := config.KeyFile(config.ResolveHome(d.Keyfile))
+		kv, err := kf.Read(host.Localhost)
+		if err != nil {
+			return nil, nil, err
+		}
+		crc, err := kv.ChecksumString()
+		if err != nil {
+			return nil, nil, err
+		}
+		keyfile = i.Type().Shared(i.Host(), "keyfiles", crc+".aes")
+	}
+	if keyfile != "" {
+		check("keyfile", keyfile)
+	}
+
+	// the template is set in the same form as the existing value, a
+	// file name or a full path, as this differs between types
+	if d.Template != "" && len(i.Type().Templates) > 0 {
+		key := cf.Join("config", "template")
+		want := templateFile(d.Template)
+		if path.IsAbs(config.Get[string](cf, key, config.NoExpand())) {
+			want = i.Host().PathTo(i.Type(), "templates", want)
+		}
+		check(key, want)
+	}
+
+	if d.Insecure != nil {
+		switch secure := hasCertificate(i); {
+		case *d.Insecure && secure:
+			details = append(details, "insecure: remove certificate")
+		case !*d.Insecure && !secure:
+			details = append(details, "create certificate")
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(d.Parameters)) {
+		check(k, d.Parameters[k])
+	}
+
+	if len(d.Envs) > 0 {
+		have := config.Get[[]string](cf, "env", config.NoExpand())
+		for _, e := range d.Envs {
+			if !slices.Contains(have, e) {
+				details = append(details, "env: "+e)
+			}
+		}
+	}
+	return
+}
+
+// templateSource splits an instance template setting into the
+// destination file name, if given as a "NAME=" prefix, and the source,
+// in the same way as `geneos.ImportSource`
+func templateSource(template string) (name, source string) {
+	if dst, src, ok := strings.Cut(template, "="); ok && !strings.Contains(dst, "://") {
+		return dst, src
+	}
+	return "", template
+}
+
+// templateFile returns the file name that an instance template setting
+// is written to in the templates directory
+func templateFile(template string) string {
+	name, source := templateSource(template)
+	if name != "" {
+		return path.Base(name)
+	}
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" && u.Host != "" {
+		return path.Base(u.Path)
+	}
+	return filepath.Base(config.ResolveHome(source))
+}
+
+// hasCertificate returns true if instance i has a certificate
+// configured, in either the current or the old style parameter
+func hasCertificate(i geneos.Instance) bool {
+	cf := i.Config()
+	return config.Get[string](cf, cf.Join(instance.TLSBASE, instance.CERTIFICATE), config.NoExpand()) != "" ||
+		config.Get[string](cf, instance.CERTIFICATE, config.NoExpand()) != ""
+}
+
+// Write outputs the plan as a table to w
+func (plan ApplyPlan) Write(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 3, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Action\tHost\tType\tName\tDetails\n")
+	for _, a := range plan {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", a.Action, a.Host, a.Type, a.Name, strings.Join(a.Details, ", "))
+	}
+	tw.Flush()
+}
+
+// Execute runs the plan. Each host is handled in parallel while the
+// actions on a host are run in the order given by actionOrder. Actions
+// on existing instances use instance.DoInstances and so run
+// concurrently.
+func (plan ApplyPlan) Execute() (rs responses.GeneralResponses) {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	rs = make(responses.GeneralResponses)
+	hosts := make(map[*geneos.Host]ApplyPlan)
+	for _, a := range plan {
+		hosts[a.Host] = append(hosts[a.Host], a)
+	}
+
+	for h, actions := range hosts {
+		wg.Add(1)
+		go func(h *geneos.Host, actions ApplyPlan) {
+			defer wg.Done()
+			hr := actions.executeHost(h)
+			mutex.Lock()
+			maps.Copy(rs, hr)
+			mutex.Unlock()
+		}(h, actions)
+	}
+	wg.Wait()
+	return
+}
+
+// executeHost runs the actions, which must all be for host h, in order.
+// If a template cannot be written then the rebuilds of instances of
+// that type, and updates that change to that template, are skipped and
+// return the error.
+func (plan ApplyPlan) executeHost(h *geneos.Host) (rs responses.GeneralResponses) {
+	rs = make(responses.GeneralResponses)
+	failed := make(map[string]error) // key is "TYPE/FILE"
+
+	for _, action := range actionOrder {
+		var instances []geneos.Instance
+		var params = make(map[geneos.Instance]*ApplyAction)
+
+		for _, a := range plan {
+			if a.Action != action {
+				continue
+			}
+			switch a.Action {
+			case "template":
+				dir := h.PathTo(a.Type, "templates")
+				resp := &responses.General{}
+				rs[h.String()+":"+path.Join(dir, a.Name)] = resp
+				err := h.MkdirAll(dir, 0775)
+				if err == nil {
+					err = h.WriteFile(path.Join(dir, a.Name), a.template, 0664)
+				}
+				if err != nil {
+					resp.Err = fmt.Errorf("%s template %q not written to %s:%s: %w", a.Type, a.Name, h, dir, err)
+					failed[a.Type.String()+"/"+a.Name] = resp.Err
+					continue
+				}
+				resp.Completed = append(resp.Completed, fmt.Sprintf("%s template %q written to %s:%s", a.Type, a.Name, h, dir))
+			case "create":
+				resp := a.create()
+				rs[resp.Instance.String()] = resp
+			default:
+				if err := a.templateFailed(failed); err != nil {
+					resp := responses.New[responses.General](a.Instance)
+					resp.Err = fmt.Errorf("%s skipped: %w", a.Action, err)
+					rs[a.Instance.String()] = resp
+					continue
+				}
+				instances = append(instances, a.Instance)
+				params[a.Instance] = a
+			}
+		}
+
+		if len(instances) == 0 {
+			continue
+		}
+
+		var f func(geneos.Instance, ...any) *responses.General
+		switch action {
+		case "delete":
+			f = applyDelete
+		case "update":
+			f = applyUpdate
+		case "rebuild":
+			f = applyRebuild
+		case "start":
+			f = applyStart
+		}
+		for k, resp := range instance.DoInstances(instances, f, params) {
+			if prev, ok := rs[k]; ok {
+				resp = responses.MergeResponse(prev, resp)
+			}
+			rs[k] = resp
+		}
+	}
+	return
+}
+
+// templateFailed returns the template write error that a, a rebuild or
+// an update that changes the template, depends on, if any
+func (a *ApplyAction) templateFailed(failed map[string]error) error {
+	switch {
+	case a.Action == "rebuild":
+		for k, err := range failed {
+			if strings.HasPrefix(k, a.Type.String()+"/") {
+				return err
+			}
+		}
+	case a.Action == "update" && a.desired != nil && a.desired.Template != "":
+		return failed[a.Type.String()+"/"+templateFile(a.desired.Template)]
+	}
+	return nil
+}
+
+// create adds a new instance using the same steps as `geneos add`
+func (a *ApplyAction) create() (resp *responses.General) {
+	d := a.desired
+
+	// an instance that does not exist is still returned, for the name
+	i, err := instance.GetWithHost(a.Host, a.Type, d.Name)
+	if i == nil {
+		i = a.Type.New(d.Name + "@" + a.Host.String())
+	}
+	resp = responses.New[responses.General](i)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Err = err
+		return
+	}
+
+	extras := values.Values{}
+	for _, k := range slices.Sorted(maps.Keys(d.Parameters)) {
+		extras.Params = append(extras.Params, k+"="+d.Parameters[k])
+	}
+	for _, e := range d.Envs {
+		if err := extras.Envs.Set(e); err != nil {
+			resp.Err = err
+			return
+		}
+	}
+
+	options := []AddOption{
+		Template(d.Template),
+		Insecure(d.Insecure != nil && *d.Insecure),
+		Keyfile(d.Keyfile),
+		KeyfileCRC(d.KeyfileCRC),
+		StartAfterAdd(d.Start != nil && *d.Start),
+	}
+	if d.Version != "" {
+		options = append(options, Base(d.Version))
+	}
+
+	// AddInstance reports an existing instance without an error
+	if resp.Err = AddInstance(a.Type, d.Name+"@"+a.Host.String(), d.Port, extras, options...); resp.Err == nil {
+		resp.Completed = append(resp.Completed, "created")
+	}
+	responses.Finished(resp)
+	return
+}
+
+func applyAction(i geneos.Instance, args []any) *ApplyAction {
+	if len(args) != 1 {
+		return nil
+	}
+	actions, ok := args[0].(map[geneos.Instance]*ApplyAction)
+	if !ok {
+		return nil
+	}
+	return actions[i]
+}
+
+func applyUpdate(i geneos.Instance, args ...any) (resp *responses.General) {
+	a := applyAction(i, args)
+	if a == nil {
+		resp = responses.New[responses.General](i)
+		resp.Err = fmt.Errorf("%w: no action for instance", geneos.ErrInvalidArgs)
+		return
+	}
+	if instance.IsProtected(i) && !applyCmdForce {
+		resp = responses.New[responses.General](i)
+		resp.Err = geneos.ErrProtected
+		return
+	}
+
+	v := values.Values{Params: a.params}
+	if d := a.desired; d != nil {
+		for _, e := range d.Envs {
+			v.Envs.Set(e)
+		}
+	}
+
+	// import a keyfile first so that the path set is valid
+	if a.desired != nil && a.desired.Keyfile != "" && a.desired.KeyfileCRC == "" {
+		if _, _, err := geneos.ImportSharedKey(i.Host(), i.Type(), a.desired.Keyfile); err != nil {
+			resp = responses.New[responses.General](i)
+			resp.Err = err
+			return
+		}
+	}
+
+	// certificates are added or removed in the instance configuration,
+	// which is then written along with the other changes by setValues
+	if d := a.desired; d != nil && d.Insecure != nil {
+		switch secure := hasCertificate(i); {
+		case *d.Insecure && secure:
+			cf := i.Config()
+			for _, key := range []string{
+				cf.Join(instance.TLSBASE, instance.CERTIFICATE),
+				cf.Join(instance.TLSBASE, instance.PRIVATEKEY),
+				instance.CERTIFICATE,
+				instance.PRIVATEKEY,
+			} {
+				config.Delete(cf, key)
+			}
+		case !*d.Insecure && !secure:
+			if resp = instance.NewCertificate(i); resp.Err != nil {
+				return
+			}
+		}
+	}
+
+	resp = setValues(i, v)
+	if resp.Err == nil {
+		resp.Completed = append([]string{"updated " + strings.Join(a.Details, ", ")}, resp.Completed...)
+	}
+	return
+}
+
+func applyRebuild(i geneos.Instance, _ ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+	if resp.Err = i.Rebuild(false); resp.Err != nil {
+		if errors.Is(resp.Err, geneos.ErrNotSupported) {
+			resp.Err = nil
+		}
+		return
+	}
+	resp.Completed = append(resp.Completed, "configuration rebuilt")
+	return responses.MergeResponse(resp, ReloadInstance(i))
+}
+
+func applyStart(i geneos.Instance, _ ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+	if resp.Err = instance.Start(i); resp.Err != nil {
+		if errors.Is(resp.Err, os.ErrProcessDone) {
+			resp.Err = nil
+		}
+		return
+	}
+	resp.Completed = append(resp.Completed, "started")
+	return
+}
+
+func applyDelete(i geneos.Instance, _ ...any) (resp *responses.General) {
+	return removeInstance(i, true, applyCmdForce)
+}
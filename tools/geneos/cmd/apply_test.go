@@ -0,0 +1,108 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+func TestApplyManifestBoolDefaults(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(manifest, []byte(`
+defaults:
+  start: true
+  insecure: true
+instances:
+  - type: gateway
+    name: inherit
+  - type: gateway
+    name: override
+    start: false
+    insecure: false
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := readApplyManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Instances) != 2 {
+		t.Fatalf("got %d instances, want 2", len(m.Instances))
+	}
+
+	for _, tc := range []struct {
+		i               ApplyInstance
+		start, insecure bool
+	}{
+		{m.Instances[0], true, true},
+		{m.Instances[1], false, false},
+	} {
+		if tc.i.Start == nil || *tc.i.Start != tc.start {
+			t.Errorf("%s: start = %v, want %v", tc.i.Name, tc.i.Start, tc.start)
+		}
+		if tc.i.Insecure == nil || *tc.i.Insecure != tc.insecure {
+			t.Errorf("%s: insecure = %v, want %v", tc.i.Name, tc.i.Insecure, tc.insecure)
+		}
+	}
+}
+
+func TestApplyTemplateFile(t *testing.T) {
+	for _, tc := range []struct {
+		template, want string
+	}{
+		{"./templates/gateway.setup.xml.gotmpl", "gateway.setup.xml.gotmpl"},
+		{"/path/to/my.gotmpl", "my.gotmpl"},
+		{"local.gotmpl=./templates/gateway.setup.xml.gotmpl", "local.gotmpl"},
+		{"https://example.com/templates/remote.gotmpl", "remote.gotmpl"},
+		{"https://example.com/templates/remote.gotmpl?version=2", "remote.gotmpl"},
+		{"named.gotmpl=https://example.com/templates/remote.gotmpl", "named.gotmpl"},
+	} {
+		if got := templateFile(tc.template); got != tc.want {
+			t.Errorf("templateFile(%q) = %q, want %q", tc.template, got, tc.want)
+		}
+	}
+}
+
+func TestApplyTemplateFailed(t *testing.T) {
+	gateway := &geneos.Component{Name: "gateway"}
+	netprobe := &geneos.Component{Name: "netprobe"}
+	failed := map[string]error{"gateway/bad.gotmpl": errors.New("write failed")}
+
+	for _, tc := range []struct {
+		name   string
+		a      *ApplyAction
+		failed bool
+	}{
+		{"rebuild same type", &ApplyAction{Action: "rebuild", Type: gateway}, true},
+		{"rebuild other type", &ApplyAction{Action: "rebuild", Type: netprobe}, false},
+		{"update to failed template", &ApplyAction{Action: "update", Type: gateway, desired: &ApplyInstance{Template: "./bad.gotmpl"}}, true},
+		{"update to other template", &ApplyAction{Action: "update", Type: gateway, desired: &ApplyInstance{Template: "./good.gotmpl"}}, false},
+		{"update without template", &ApplyAction{Action: "update", Type: gateway, desired: &ApplyInstance{}}, false},
+		{"start", &ApplyAction{Action: "start", Type: gateway}, false},
+	} {
+		if err := tc.a.templateFailed(failed); (err != nil) != tc.failed {
+			t.Errorf("%s: templateFailed() = %v, want failed %v", tc.name, err, tc.failed)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	_ "embed"
 	"errors"
@@ -42,6 +43,7 @@ import (
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/config"
 	"github.com/itrs-group/cordial/pkg/host"
+	"github.com/itrs-group/cordial/tools/geneos/internal/backup"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
 	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
@@ -54,7 +56,11 @@ var backupCmdIncludeAES, backupCmdIncludeTLS bool
 var backupCmdIncludeDatetime bool
 var backupCmdLimitSize, backupCmdCompression string
 
-var maxsize int64
+var backupCmdIncremental bool
+var backupCmdKeep int
+var backupCmdMaxAge time.Duration
+var backupCmdPolicies []string
+var backupCmdSchedule bool
 
 func init() {
 	Cmd.AddCommand(backupCmd)
@@ -64,7 +70,9 @@ func init() {
 a file in the current directory with a name based on the contents of the
 archive. If DEST is a directory or has a "/" suffix then the file is
 written to that directory using the same naming format as if no file was
-given. Directories are created as required.`,
+given. Directories are created as required. DEST can also be on a
+configured remote host, as "HOST:PATH", or in an S3 compatible object
+store, as "s3://BUCKET/PREFIX".`,
 	)
 
 	backupCmd.Flags().BoolVarP(&backupCmdIncludeDatetime, "datetime", "D", false,
@@ -93,9 +101,30 @@ e.g. "2MiB". "0" (zero) means no limit to file sizes.`,
 		"Include AES key files.",
 	)
 	backupCmd.Flags().BoolVar(&backupCmdIncludeTLS, "tls", false,
-		"Include certificates, private keys and certificate chains.",
+		"Include certificates, private keys and certificate chains.\n",
 	)
 
+	backupCmd.Flags().BoolVarP(&backupCmdIncremental, "incremental", "I", false,
+		`Only include files changed since the last full archive in the
+output directory. Requires --output to be a directory.`,
+	)
+	backupCmd.Flags().IntVar(&backupCmdKeep, "keep", 0,
+		"Keep only the newest `N` full archives, and their incremental\narchives, in the output directory",
+	)
+	backupCmd.Flags().DurationVar(&backupCmdMaxAge, "max-age", 0,
+		"Remove archives older than `DURATION` from the output directory.\nThe newest full archive is always kept.\n",
+	)
+
+	backupCmd.Flags().StringSliceVarP(&backupCmdPolicies, "policy", "P", nil,
+		"Run the backup policy `NAME` from the configuration. Repeat\nfor more than one policy.",
+	)
+	backupCmd.Flags().BoolVar(&backupCmdSchedule, "schedule", false,
+		"Run the selected backup policies, or all policies if none are\ngiven, on their schedules until interrupted",
+	)
+
+	backupCmd.MarkFlagsMutuallyExclusive("policy", "output")
+	backupCmd.MarkFlagsMutuallyExclusive("schedule", "output")
+
 	backupCmd.Flags().SortFlags = false
 }
 
@@ -108,6 +137,27 @@ var compression = map[string]string{
 	"none":  "",
 }
 
+// backupOptions are the settings for one backup run, either from the
+// command line or from a backup policy
+type backupOptions struct {
+	output        string
+	compression   string
+	datetime      bool
+	includeAll    bool
+	includeShared bool
+	includeAES    bool
+	includeTLS    bool
+	maxsize       int64
+
+	// set overrides the auto-generated archive set name, which is the
+	// archive file name without datetime and suffixes
+	set string
+
+	incremental bool
+	keep        int
+	maxAge      time.Duration
+}
+
 var backupCmd = &cobra.Command{
 	Use:     "backup [flags] [all] | [TYPE] [NAME...]",
 	Aliases: []string{"save"},
@@ -117,6 +167,9 @@ var backupCmd = &cobra.Command{
 	Example: strings.ReplaceAll(`
 geneos backup gateway gw1
 geneos backup all
+geneos backup all -o /backups/ --incremental --keep 4
+geneos backup --policy nightly
+geneos backup --schedule
 `, "|", "`"),
 	SilenceUsage: true,
 	Annotations: map[string]string{
@@ -127,13 +180,24 @@ geneos backup all
 		CmdNonInstanceArgsError:  "true",
 	},
 	RunE: func(command *cobra.Command, _ []string) (err error) {
-		var archive string
-
 		ct, names, _, err := FetchArgs(command)
 		if err != nil {
 			return err
 		}
 
+		if backupCmdSchedule {
+			return scheduleBackups(backupCmdPolicies)
+		}
+
+		if len(backupCmdPolicies) > 0 {
+			for _, name := range backupCmdPolicies {
+				if err = runBackupPolicy(name, backupCmdIncremental); err != nil {
+					return
+				}
+			}
+			return
+		}
+
 		if ct == nil && len(names) == 0 {
 			return command.Usage()
 		}
@@ -144,172 +208,305 @@ geneos backup all
 			h = geneos.LOCAL
 		}
 
-		suffix, ok := compression[backupCmdCompression]
-		if !ok {
-			return fmt.Errorf("invalid compression type, select one of: %s", strings.Join(slices.Collect(maps.Keys(compression)), ", "))
+		opts := backupOptions{
+			output:        backupCmdOutput,
+			compression:   backupCmdCompression,
+			datetime:      backupCmdIncludeDatetime,
+			includeAll:    backupCmdIncludeAll,
+			includeShared: backupCmdIncludeShared,
+			includeAES:    backupCmdIncludeAES,
+			includeTLS:    backupCmdIncludeTLS,
+			incremental:   backupCmdIncremental,
+			keep:          backupCmdKeep,
+			maxAge:        backupCmdMaxAge,
 		}
 
-		if !backupCmdIncludeAll {
-			maxsize, err = units.ParseStrictBytes(backupCmdLimitSize)
+		if !opts.includeAll {
+			opts.maxsize, err = units.ParseStrictBytes(backupCmdLimitSize)
 			if err != nil {
 				return fmt.Errorf("invalid size: %w", err)
 			}
 		}
 
-		if backupCmdOutput != "" {
-			if strings.HasSuffix(backupCmdOutput, "/") {
-				// user has asked for a directory, so try to create it as well
-				archive = backupCmdOutput
-				os.MkdirAll(archive, 0664)
-			} else {
-				// check for existing file, else try to create the directories above it
-				st, err := os.Stat(backupCmdOutput)
-				if err == nil {
-					// file (or directory) exists, use it
-					archive = backupCmdOutput
-					// destination may be an existing directory, in which case append a '/'
-					if st.IsDir() {
-						archive += "/"
-					}
-				} else {
-					if errors.Is(err, os.ErrNotExist) {
-						archive = backupCmdOutput
-						os.MkdirAll(filepath.Dir(archive), 0775)
-					} else {
-						return err
-					}
-				}
-			}
+		return runBackup(h, ct, names, opts)
+	},
+}
+
+// runBackup writes an archive of the instances on host h of type ct
+// (all types if nil) and names using opts
+func runBackup(h *geneos.Host, ct *geneos.Component, names []string, opts backupOptions) (err error) {
+	// archive is the file name in store, or "-" for STDOUT
+	var store backup.Store
+	var archive string
+
+	suffix, ok := compression[opts.compression]
+	if !ok {
+		return fmt.Errorf("invalid compression type, select one of: %s", strings.Join(slices.Collect(maps.Keys(compression)), ", "))
+	}
+
+	managed := opts.incremental || opts.keep > 0 || opts.maxAge > 0
+
+	switch opts.output {
+	case "-":
+		archive = "-"
+	case "":
+		// the current directory
+		if store, _, err = backup.Location("./"); err != nil {
+			return
+		}
+	default:
+		// a local or remote directory or file, directories are created
+		// as required
+		if store, archive, err = backup.Location(opts.output); err != nil {
+			return
 		}
+	}
 
-		if archive == "" || strings.HasSuffix(archive, "/") {
-			var i geneos.Instance
+	if managed && (store == nil || archive != "") {
+		return errors.New("incremental backups and retention require --output to be a directory")
+	}
 
+	set := opts.set
+	auto := store != nil && archive == ""
+	if auto {
+		var i geneos.Instance
+
+		if set == "" {
 			// build archive name, starting with executable
-			archive += cordial.ExecutableName()
+			set = cordial.ExecutableName()
 
 			// include host name if not local
 			if h != geneos.LOCAL {
-				archive += "-" + h.String()
+				set += "-" + h.String()
 			}
 
 			// include component name if given on command line
 			if ct != nil {
-				archive += "-" + ct.String()
+				set += "-" + ct.String()
 			}
+		}
 
-			instances := instance.Instances(h, ct, instance.MatchNames(names...))
-			switch len(instances) {
-			case 0:
-				return fmt.Errorf("no matching instances found")
-			case 1:
-				i = instances[0]
+		instances := instance.Instances(h, ct, instance.MatchNames(names...))
+		switch len(instances) {
+		case 0:
+			return fmt.Errorf("no matching instances found")
+		case 1:
+			i = instances[0]
+			if opts.set == "" {
 				if ct == nil {
-					archive += "-" + i.Type().String()
-				}
-				archive += "-" + strings.ReplaceAll(i.Name(), " ", "_")
-			default:
-				if len(names) == 1 {
-					archive += "-" + strings.ReplaceAll(names[0], " ", "_")
+					set += "-" + i.Type().String()
 				}
+				set += "-" + strings.ReplaceAll(i.Name(), " ", "_")
 			}
-
-			if backupCmdIncludeDatetime {
-				archive += "-" + time.Now().Local().Format("20060102150405")
-			}
-
-			archive += ".tar"
-			if suffix != "" {
-				archive += suffix
+		default:
+			if len(names) == 1 && opts.set == "" {
+				set += "-" + strings.ReplaceAll(names[0], " ", "_")
 			}
 		}
 
-		// contents is a list of files so that instances do not duplicate shared contents
-		var contents []string
-		resp := instance.Do(h, ct, names, getInstanceFilePaths, &contents)
+		archive = set
 
-		out2 := os.Stderr
-		if archive != "-" {
-			out2 = os.Stdout
+		// managed archives always have a datetime, so that they can be
+		// kept side by side
+		if opts.datetime || managed {
+			archive += "-" + time.Now().Local().Format(backup.ArchiveTimeFormat)
 		}
-		resp.Report(out2)
-		if backupCmdIncludeShared {
-			fmt.Println("matching shared directories also included")
+	} else if archive != "-" {
+		set = strings.TrimSuffix(archive, ".tar"+suffix)
+	}
+
+	// find the full archive to base an incremental on, falling back to
+	// a full archive if there is none
+	var base *backup.Archive
+	if opts.incremental {
+		b, err := backup.Latest(store, set)
+		switch {
+		case err == nil:
+			base = &b
+			archive += "-" + backup.Incremental
+		case errors.Is(err, os.ErrNotExist):
+			fmt.Println("no full archive found, writing a full archive")
+		default:
+			return err
 		}
+	}
 
-		slices.Sort(contents)
-		contents = slices.Compact(contents)
+	if auto {
+		archive += ".tar" + suffix
+	}
 
-		var out *os.File
-		if archive == "-" {
-			out = os.Stdout
-		} else {
-			out, err = os.Create(archive)
-			if err != nil {
-				return
+	// contents is a list of files so that instances do not duplicate shared contents
+	var contents []string
+	resp := instance.Do(h, ct, names, getInstanceFilePaths, &contents, &opts)
+
+	out2 := os.Stderr
+	if archive != "-" {
+		out2 = os.Stdout
+	}
+	resp.Report(out2)
+	if opts.includeShared {
+		fmt.Fprintln(out2, "matching shared directories also included")
+	}
+
+	slices.Sort(contents)
+	contents = slices.Compact(contents)
+
+	m := backupManifest(h, set, base)
+
+	if archive == "-" {
+		return writeBackup(h, os.Stdout, opts.compression, contents, m, base)
+	}
+
+	// remote stores may only complete the write on Close
+	w, err := store.Create(archive)
+	if err != nil {
+		return
+	}
+	err = writeBackup(h, w, opts.compression, contents, m, base)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+
+	if base != nil {
+		var changed int
+		for name, f := range m.Files {
+			if !f.InBase && !strings.HasSuffix(name, "/") {
+				changed++
 			}
-			defer out.Close()
 		}
+		fmt.Fprintf(out2, "incremental archive written to %s, %d changed files since %s\n", store.Path(archive), changed, store.Path(base.Name))
+	} else {
+		fmt.Fprintln(out2, "archive written to", store.Path(archive))
+	}
 
-		var w io.WriteCloser
+	if opts.keep > 0 || opts.maxAge > 0 {
+		removed, err := backup.Retain(store, set, opts.keep, opts.maxAge, time.Now())
+		for _, r := range removed {
+			fmt.Fprintln(out2, "removed expired archive", r)
+		}
+		if err != nil {
+			return err
+		}
+	}
 
-		switch backupCmdCompression {
-		case "gzip":
-			w, err = gzip.NewWriterLevel(out, gzip.BestCompression)
-			if err != nil {
-				return
-			}
-			defer w.Close()
-		case "bzip2":
-			w, err = bzip2.NewWriter(out, &bzip2.WriterConfig{Level: 9})
-			if err != nil {
-				return
-			}
-			defer w.Close()
-		case "none":
-			w = out
+	return nil
+}
+
+// backupManifest returns an empty manifest for an archive of files
+// from host h. If base is not nil then the manifest is for an
+// incremental archive based on it.
+func backupManifest(h *geneos.Host, set string, base *backup.Archive) (m *backup.Manifest) {
+	kind := backup.Full
+	if base != nil {
+		kind = backup.Incremental
+	}
+	m = backup.NewManifest(set, kind, h.String())
+	if base != nil {
+		m.Base = base.Name
+		m.BaseCreated = base.Manifest.Created
+	}
+	return
+}
+
+// writeBackup writes an archive of contents from host h to out,
+// followed by the manifest m as the last entry. Each file is read once
+// and the checksum in the manifest is of the same data written to the
+// archive, so files that change during the backup are still
+// consistent. If base is not nil then files unchanged since base are
+// marked as such in the manifest and not written. out is not closed.
+func writeBackup(h *geneos.Host, out io.Writer, compression string, contents []string, m *backup.Manifest, base *backup.Archive) (err error) {
+	var w io.WriteCloser
+
+	switch compression {
+	case "gzip":
+		w, err = gzip.NewWriterLevel(out, gzip.BestCompression)
+		if err != nil {
+			return
+		}
+	case "bzip2":
+		w, err = bzip2.NewWriter(out, &bzip2.WriterConfig{Level: 9})
+		if err != nil {
+			return
 		}
+	case "none":
+		w = nopWriteCloser{out}
+	}
 
-		tw := tar.NewWriter(w)
-		defer tw.Close()
+	tw := tar.NewWriter(w)
 
-		root := h.PathTo()
-		for _, f := range contents {
-			p := path.Join(root, f)
-			fi, err := h.Stat(p)
-			if err != nil {
+	root := h.PathTo()
+	for _, f := range contents {
+		p := path.Join(root, f)
+		fi, err := h.Stat(p)
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() && !fi.IsDir() {
+			return fmt.Errorf("%s is not a regular file or directory", p)
+		}
+		entry := backup.File{
+			Mode:    fi.Mode(),
+			ModTime: fi.ModTime(),
+		}
+
+		var b []byte
+		if !fi.IsDir() {
+			if b, err = h.ReadFile(p); err != nil {
 				return err
 			}
-			if !fi.Mode().IsRegular() && !fi.IsDir() {
-				return fmt.Errorf("%s is not a regular file or directory", p)
-			}
-			uid, gid := host.GetFileOwner(h, fi)
-			th := &tar.Header{
-				Format:  tar.FormatUnknown,
-				Name:    f,
-				Size:    fi.Size(),
-				Mode:    int64(fi.Mode()),
-				ModTime: fi.ModTime(),
-				Uid:     uid,
-				Gid:     gid,
+			entry.Size = int64(len(b))
+			if entry.SHA256, err = backup.Checksum(bytes.NewReader(b)); err != nil {
+				return err
 			}
-			tw.WriteHeader(th)
-			if !fi.IsDir() {
-				b, err := h.ReadFile(p)
-				if err != nil {
-					return err
-				}
-				if _, err = tw.Write(b); err != nil {
-					return err
-				}
+			if base != nil && !base.Manifest.Changed(f, entry.SHA256) {
+				entry.InBase = true
+				m.Files[f] = entry
+				continue
 			}
 		}
+		m.Files[f] = entry
+
+		uid, gid := host.GetFileOwner(h, fi)
+		th := &tar.Header{
+			Format:  tar.FormatUnknown,
+			Name:    f,
+			Size:    entry.Size,
+			Mode:    int64(fi.Mode()),
+			ModTime: fi.ModTime(),
+			Uid:     uid,
+			Gid:     gid,
+		}
+		if err = tw.WriteHeader(th); err != nil {
+			return err
+		}
+		if _, err = tw.Write(b); err != nil {
+			return err
+		}
+	}
 
-		fmt.Fprintln(out2, "archive written to", archive)
-		return nil
-	},
+	if err = m.Write(tw); err != nil {
+		return
+	}
+
+	// close in order and before returning so that the archive is
+	// complete for retention checks
+	if err = tw.Close(); err != nil {
+		return
+	}
+	return w.Close()
 }
 
+// nopWriteCloser is a WriteCloser that does not close the underlying
+// writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 // getInstanceFilePaths returns a list of paths to backup for the
 // instance and returns the results in the string slice pointer in
 // params[0].
@@ -321,7 +518,7 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 	cf := i.Config()
 	ct := i.Type()
 
-	if len(params) == 0 {
+	if len(params) < 2 {
 		resp.Err = geneos.ErrInvalidArgs
 		return
 	}
@@ -331,8 +528,13 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 		resp.Err = geneos.ErrInvalidArgs
 		return
 	}
+	opts, ok := params[1].(*backupOptions)
+	if !ok {
+		resp.Err = geneos.ErrInvalidArgs
+		return
+	}
 
-	if !backupCmdIncludeAll {
+	if !opts.includeAll {
 		ignore := strings.Split(config.Get[string](config.Global(), ct.CleanList, config.DefaultValue(ct.ConfigAliases[ct.CleanList])), ":")
 		ignore = append(ignore, strings.Split(config.Get[string](config.Global(), ct.PurgeList, config.DefaultValue(ct.ConfigAliases[ct.PurgeList])), ":")...)
 		if geneos.RootComponent.CleanList != "" {
@@ -342,10 +544,10 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 			ignore = append(ignore, filepath.SplitList(geneos.RootComponent.PurgeList)...)
 		}
 
-		if !backupCmdIncludeAES {
+		if !opts.includeAES {
 			ignore = append(ignore, "*.aes", "keyfiles/")
 		}
-		if !backupCmdIncludeTLS {
+		if !opts.includeTLS {
 			ignore = append(ignore, "*.pem", "*.key", "*.crt")
 		}
 
@@ -359,10 +561,10 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 	}
 
 	var ignoreSecure []string
-	if !backupCmdIncludeAES {
+	if !opts.includeAES {
 		ignoreSecure = append(ignoreSecure, "keyfile", "prevkeyfile")
 	}
-	if !backupCmdIncludeTLS {
+	if !opts.includeTLS {
 		ignoreSecure = append(ignoreSecure,
 			// legacy path parameters
 			instance.CERTIFICATE,
@@ -388,6 +590,7 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 		contents,
 		ignoreDirs,
 		ignoreFiles,
+		opts,
 	); err != nil {
 		// missing dirs and inaccessible files are probably not errors
 		i.Log().Debug("error walking instance home directory", slog.Any("error", err))
@@ -396,11 +599,11 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 	resp.Completed = []string{"included in backup"}
 
 	// add global tls directory, in all cases
-	if err := walkDir(i.Host(), i.Host().PathTo("tls")+"/", "tls", contents, ignoreDirs, []string{}); err != nil {
+	if err := walkDir(i.Host(), i.Host().PathTo("tls")+"/", "tls", contents, ignoreDirs, []string{}, opts); err != nil {
 		i.Log().Debug("error walking global TLS directory", slog.Any("error", err))
 	}
 
-	if !backupCmdIncludeShared {
+	if !opts.includeShared {
 		return
 	}
 
@@ -414,6 +617,7 @@ func getInstanceFilePaths(i geneos.Instance, params ...any) (resp *responses.Gen
 			contents,
 			ignoreDirs,
 			ignoreFiles,
+			opts,
 		); err != nil {
 			// missing dirs and inaccessible files are probably not errors
 			i.Log().Debug("error walking shared directory", slog.Any("error", err))
@@ -428,7 +632,7 @@ var contentsMutex sync.Mutex
 // walkDir walks the given directory on host h, adding files and directories
 // to contents slice, using relative as the base path. ignoreDirs and ignoreFiles
 // are patterns to skip. walkDir is concurrency safe for the contents slice.
-func walkDir(h *geneos.Host, dir, relative string, contents *[]string, ignoreDirs, ignoreFiles []string) error {
+func walkDir(h *geneos.Host, dir, relative string, contents *[]string, ignoreDirs, ignoreFiles []string, opts *backupOptions) error {
 	return h.WalkDir(dir, func(file string, di fs.DirEntry, err error) error {
 		if err != nil {
 			log.Debug("error walking directory", slog.Any("error", err), slog.String("dir", dir))
@@ -440,7 +644,7 @@ func walkDir(h *geneos.Host, dir, relative string, contents *[]string, ignoreDir
 		}
 		switch {
 		case fi.IsDir():
-			if !backupCmdIncludeAll {
+			if !opts.includeAll {
 				for _, ig := range ignoreDirs {
 					if match, _ := filepath.Match(ig, file); match {
 						return fs.SkipDir
@@ -454,13 +658,13 @@ func walkDir(h *geneos.Host, dir, relative string, contents *[]string, ignoreDir
 		case fi.Mode()&fs.ModeSymlink != 0:
 			log.Debug("ignoring symlink", slog.String("file", file))
 		default:
-			if !backupCmdIncludeAll {
+			if !opts.includeAll {
 				for _, ig := range ignoreFiles {
 					if match, _ := filepath.Match(ig, file); match {
 						return nil
 					}
 				}
-				if maxsize != 0 && fi.Size() > maxsize {
+				if opts.maxsize != 0 && fi.Size() > opts.maxsize {
 					log.Debug("skipping large file", slog.String("file", filepath.Join(relative, file)))
 					return nil
 				}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/alecthomas/units"
+	"github.com/go-co-op/gocron/v2"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/backup"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+)
+
+// runBackupPolicy runs the backup policy name once, writing an
+// incremental archive if incremental is true
+func runBackupPolicy(name string, incremental bool) (err error) {
+	p, err := backup.GetPolicy(config.Global(), name)
+	if err != nil {
+		return
+	}
+	return runPolicy(p, incremental)
+}
+
+// runPolicy runs the backup policy p once
+func runPolicy(p *backup.Policy, incremental bool) (err error) {
+	h := geneos.LOCAL
+	if p.Host != "" {
+		if h = geneos.GetHost(p.Host); h == nil || h == geneos.ALL {
+			return fmt.Errorf("backup policy %q: invalid host %q", p.Name, p.Host)
+		}
+	}
+
+	var ct *geneos.Component
+	if p.Type != "" {
+		if ct = geneos.ParseComponent(p.Type); ct == nil {
+			return fmt.Errorf("backup policy %q: invalid component type %q", p.Name, p.Type)
+		}
+	}
+
+	// expand name patterns, "all" selects all instances
+	var names []string
+	if !slices.Contains(p.Names, "all") {
+		for _, i := range instance.Instances(h, ct) {
+			for _, pattern := range p.Names {
+				if match, _ := path.Match(pattern, i.Name()); match {
+					names = append(names, i.Name())
+					break
+				}
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("backup policy %q: no matching instances found", p.Name)
+		}
+	}
+
+	opts := backupOptions{
+		output:        p.Output,
+		compression:   p.Compress,
+		includeAll:    p.All,
+		includeShared: p.Shared,
+		includeAES:    p.AES,
+		includeTLS:    p.TLS,
+		set:           cordial.ExecutableName() + "-" + p.Name,
+		incremental:   incremental,
+		keep:          p.Keep,
+		maxAge:        p.MaxAge,
+	}
+	if !strings.HasSuffix(opts.output, "/") {
+		opts.output += "/"
+	}
+	if !opts.includeAll {
+		if opts.maxsize, err = units.ParseStrictBytes(p.Size); err != nil {
+			return fmt.Errorf("backup policy %q: invalid size: %w", p.Name, err)
+		}
+	}
+
+	// a policy always writes a datetime stamped archive
+	opts.datetime = true
+
+	return runBackup(h, ct, names, opts)
+}
+
+// scheduleBackups runs the backup policies names, or all policies if
+// names is empty, on their full and incremental schedules until
+// interrupted. Only one backup runs at a time.
+func scheduleBackups(names []string) (err error) {
+	policies, err := backup.Policies(config.Global())
+	if err != nil {
+		return
+	}
+	if len(names) == 0 {
+		for name := range policies {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no backup policies configured")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sched, err := gocron.NewScheduler(gocron.WithLimitConcurrentJobs(1, gocron.LimitModeWait))
+	if err != nil {
+		return
+	}
+
+	var jobs []gocron.Job
+	for _, name := range names {
+		p, err := backup.GetPolicy(config.Global(), name)
+		if err != nil {
+			return err
+		}
+		for _, s := range []struct {
+			spec        string
+			incremental bool
+		}{
+			{p.Full, false},
+			{p.Incremental, true},
+		} {
+			if s.spec == "" {
+				continue
+			}
+			kind := backup.Full
+			if s.incremental {
+				kind = backup.Incremental
+			}
+			job, err := sched.NewJob(
+				gocron.CronJob(s.spec, false),
+				gocron.NewTask(scheduledBackup, p, s.incremental),
+				gocron.WithName(p.Name+" "+kind),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+			if err != nil {
+				return fmt.Errorf("backup policy %q: %s schedule %q: %w", p.Name, kind, s.spec, err)
+			}
+			jobs = append(jobs, job)
+		}
+	}
+
+	sched.Start()
+	defer sched.Shutdown()
+
+	for _, job := range jobs {
+		next, _ := job.NextRun()
+		fmt.Printf("%s backup next runs at %s\n", job.Name(), next.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	<-ctx.Done()
+	return
+}
+
+// scheduledBackup is the scheduler task for a policy, which logs
+// errors as there is no caller to return them to
+func scheduledBackup(p *backup.Policy, incremental bool) {
+	if err := runPolicy(p, incremental); err != nil {
+		log.Error("scheduled backup failed", slog.String("policy", p.Name), slog.Any("error", err))
+	}
+}
@@ -21,6 +21,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -69,14 +70,22 @@ var deleteCmd = &cobra.Command{
 }
 
 func deleteInstance(i geneos.Instance, _ ...any) (resp *responses.General) {
+	return removeInstance(i, deleteCmdStop, deleteCmdForce)
+}
+
+// removeInstance deletes the instance i, including any systemd units.
+// If stop is true then a running instance is stopped first, otherwise
+// running instances are only stopped and deleted if force is true.
+// Protected instances are only deleted if force is true.
+func removeInstance(i geneos.Instance, stop, force bool) (resp *responses.General) {
 	resp = responses.New[responses.General](i)
 
-	if instance.IsProtected(i) && !deleteCmdForce {
+	if instance.IsProtected(i) && !force {
 		resp.Err = geneos.ErrProtected
 		return
 	}
 
-	if deleteCmdStop {
+	if stop {
 		if i.Type() != &geneos.RootComponent {
 			if err := instance.Stop(i, true, false); err != nil && !errors.Is(err, os.ErrProcessDone) {
 				resp.Err = err
@@ -85,12 +94,20 @@ func deleteInstance(i geneos.Instance, _ ...any) (resp *responses.General) {
 		}
 	}
 
-	if !instance.IsRunning(i) || deleteCmdForce {
+	if !instance.IsRunning(i) || force {
 		if instance.IsRunning(i) {
 			if resp.Err = instance.Stop(i, true, false); resp.Err != nil {
 				return
 			}
 		}
+		// a unit that cannot be removed does not stop the delete
+		units, err := instance.RemoveUnits(i)
+		if err != nil {
+			i.Log().Warn("cannot remove systemd units", slog.Any("error", err))
+		}
+		for _, u := range units {
+			resp.Completed = append(resp.Completed, "removed systemd unit "+u)
+		}
 		if resp.Err = i.Host().RemoveAll(i.Home()); resp.Err != nil {
 			return
 		}
@@ -34,6 +34,7 @@ import (
 	"github.com/itrs-group/cordial/pkg/config"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/profiles"
 	"github.com/itrs-group/cordial/tools/geneos/internal/values"
 )
 
@@ -47,12 +48,17 @@ var deployCmdArchive, deployCmdVersion, deployCmdOverride string
 var deployCmdPassword, deployCmdBundlePassword config.Secret
 var deployCmdImportFiles values.Filename
 var deployCmdKeyfile string
+var deployCmdProfile string
+var deployCmdProfileVars values.NameValues
 var deployCmdExtras = values.Values{}
 
 func init() {
 	Cmd.AddCommand(deployCmd)
 
 	deployCmd.Flags().StringVarP(&deployCmdGeneosHome, "geneos", "D", "", "Installation directory. Prompted if not given and not found\nin existing user configuration or environment ${`GENEOS_HOME`}")
+	deployCmd.Flags().StringVar(&deployCmdProfile, "profile", "", "Deploy all the instances in deployment profile `NAME`.\nIf TYPE is also given then only instances of that type")
+	deployCmd.Flags().Var(&deployCmdProfileVars, "profile-var", "Set a profile variable in the format `NAME=VALUE`\n(Repeat as required)")
+
 	deployCmd.Flags().BoolVarP(&deployCmdStart, "start", "S", false, "Start new instance after creation")
 	deployCmd.Flags().BoolVarP(&deployCmdLogs, "log", "l", false, "Start created instance and follow logs.\n(Implies --start to start the instance)")
 
@@ -106,7 +112,7 @@ func init() {
 var deployCmdDescription string
 
 var deployCmd = &cobra.Command{
-	Use:     "deploy [flags] TYPE [NAME] [KEY=VALUE...]",
+	Use:     "deploy [flags] [TYPE] [NAME] [KEY=VALUE...]",
 	GroupID: CommandGroupConfig,
 	Short:   "Deploy a new Geneos instance",
 	Long:    deployCmdDescription,
@@ -125,6 +131,13 @@ var deployCmd = &cobra.Command{
 			return err
 		}
 
+		if deployCmdProfile != "" {
+			if len(names) > 0 || len(params) > 0 {
+				return fmt.Errorf("%w: only a TYPE can be given with --profile", geneos.ErrInvalidArgs)
+			}
+			return deployProfile(ct)
+		}
+
 		if ct == nil {
 			fmt.Println("component type must be given for a deployment")
 			return nil
@@ -155,49 +168,8 @@ var deployCmd = &cobra.Command{
 
 		name = fmt.Sprintf("%s:%s@%s", pkgct, local, h)
 
-		if h == geneos.LOCAL {
-			if geneos.LocalRoot() == "" {
-				// make best guess
-				if deployCmdGeneosHome == "" {
-					var input, root string
-					if root, err = config.UserHomeDir(); err != nil {
-						log.Warn("cannot find user home directory", slog.Any("error", err))
-					}
-					if path.Base(root) != cordial.ExecutableName() {
-						root = path.Join(root, cordial.ExecutableName())
-					}
-					if input, err = config.ReadUserInputLine("Geneos Directory (default %q): ", root); err == nil {
-						if strings.TrimSpace(input) != "" {
-							log.Debug("set root to", slog.String("root", input))
-							root = input
-						}
-					}
-					err = nil
-					if path.Base(root) == cordial.ExecutableName() {
-						deployCmdGeneosHome = root
-					} else {
-						deployCmdGeneosHome = path.Join(root, cordial.ExecutableName())
-					}
-				}
-
-				// create base install
-				deployCmdGeneosHome, _ = h.Abs(deployCmdGeneosHome)
-				config.Set(config.Global(), cordial.ExecutableName(), deployCmdGeneosHome)
-				if err = geneos.SaveGlobalConfig(cordial.ExecutableName()); err != nil {
-					return err
-				}
-
-				// recreate LOCAL to load "geneos" and others
-				geneos.LOCAL = nil
-				geneos.LOCAL = geneos.NewHost(geneos.LOCALHOST)
-				h = geneos.LOCAL
-			}
-		} else {
-			basedir := config.Get[string](h.Config, cordial.ExecutableName())
-			if deployCmdGeneosHome != "" && deployCmdGeneosHome != basedir {
-				fmt.Printf("Geneos location given with --geneos/-D must be the same as configured for remote host %s\n", h)
-				return nil
-			}
+		if h, err = deployCheckRoot(h); err != nil || h == nil {
+			return
 		}
 
 		// make root component directories, in case this is first instance
@@ -241,40 +213,12 @@ var deployCmd = &cobra.Command{
 		version, _ := geneos.CurrentVersion(h, pkgct, deployCmdBase)
 		log.Debug("version", slog.String("version", version))
 		if version == "unknown" || (deployCmdVersion != "latest" && deployCmdVersion != version) {
-			if !deployCmdLocal && deployCmdUsername != "" && deployCmdPassword == nil {
-				deployCmdPassword, err = config.ReadPasswordInput(false, 0)
-				if err == config.ErrNotInteractive {
-					err = fmt.Errorf("%w and password required", err)
-					return
-				}
-				// ok to defer clear here as the options are used before returning to caller
-				defer clear(deployCmdPassword)
-			}
-
-			options := []geneos.PackageOption{
-				geneos.Version(deployCmdVersion),
-				geneos.Basename(deployCmdBase),
-				geneos.UseRoot(config.Get[string](h.Config, cordial.ExecutableName())),
-				geneos.LocalOnly(deployCmdLocal),
-				geneos.NoSave(deployCmdNoSave || deployCmdLocal),
-				geneos.OverrideVersion(deployCmdOverride),
-				geneos.Password(deployCmdPassword),
-				geneos.Username(deployCmdUsername),
-				geneos.Headers(deployCmdExtras.Headers...),
-			}
-			if deployCmdArchive != "" {
-				options = append(options,
-					geneos.Source(deployCmdArchive),
-				)
-			}
-
-			if deployCmdSnapshot {
-				deployCmdNexus = true
-				options = append(options, geneos.UseNexusSnapshots())
-			}
-			if deployCmdNexus {
-				options = append(options, geneos.UseNexus())
+			var options []geneos.PackageOption
+			if options, err = deployPackageOptions(h); err != nil {
+				return
 			}
+			// ok to defer clear here as the options are used before returning to caller
+			defer clear(deployCmdPassword)
 
 			log.Debug("installing", slog.String("host", h.String()), slog.String("package", pkgct.String()))
 
@@ -289,17 +233,10 @@ var deployCmd = &cobra.Command{
 
 		// TLS
 
-		if !deployCmdInsecure {
-			if deployCmdSigningBundle != "" {
-				if err = geneos.TLSImportBundle(deployCmdSigningBundle, "", deployCmdBundlePassword); err != nil {
-					return err
-				}
-			} else {
-				if err = geneos.TLSInit(h.Hostname(), false, certs.DefaultKeyType); err != nil {
-					return
-				}
-			}
+		if err = deployTLS(h); err != nil {
+			return
 		}
+
 		// we are installed and ready to go, drop through to code from `add`
 		i, err := instance.GetWithHost(h, ct, name)
 		if err != nil && !errors.Is(err, fs.ErrNotExist) {
@@ -467,3 +404,147 @@ var deployCmd = &cobra.Command{
 		return
 	},
 }
+
+// deployProfile deploys the instances in the profile given with
+// --profile, optionally only those of type ct, on the host given with
+// --host or the local host
+func deployProfile(ct *geneos.Component) (err error) {
+	h := geneos.GetHost(Hostname)
+	if h == geneos.ALL {
+		h = geneos.LOCAL
+	}
+
+	if h, err = deployCheckRoot(h); err != nil || h == nil {
+		return
+	}
+
+	p, err := profiles.Get(deployCmdProfile, ProfileVars(h, "", deployCmdProfileVars))
+	if err != nil {
+		return
+	}
+
+	if err = geneos.RootComponent.MakeDirs(h); err != nil {
+		return
+	}
+
+	options, err := deployPackageOptions(h)
+	if err != nil {
+		return
+	}
+	defer clear(deployCmdPassword)
+
+	if err = deployTLS(h); err != nil {
+		return
+	}
+
+	return DeployProfile(h, p, ct, deployCmdStart || deployCmdLogs, options...)
+}
+
+// deployCheckRoot checks the Geneos installation directory for host h.
+// If h is the local host and there is no directory configured then one
+// is created, prompting the user if not given with --geneos, and the
+// recreated local host is returned. For a remote host, if the directory
+// given does not match the configuration then a message is printed and
+// a nil host returned.
+func deployCheckRoot(h *geneos.Host) (*geneos.Host, error) {
+	if h != geneos.LOCAL {
+		basedir := config.Get[string](h.Config, cordial.ExecutableName())
+		if deployCmdGeneosHome != "" && deployCmdGeneosHome != basedir {
+			fmt.Printf("Geneos location given with --geneos/-D must be the same as configured for remote host %s\n", h)
+			return nil, nil
+		}
+		return h, nil
+	}
+
+	if geneos.LocalRoot() != "" {
+		return h, nil
+	}
+
+	// make best guess
+	if deployCmdGeneosHome == "" {
+		var input string
+		root, err := config.UserHomeDir()
+		if err != nil {
+			log.Warn("cannot find user home directory", slog.Any("error", err))
+		}
+		if path.Base(root) != cordial.ExecutableName() {
+			root = path.Join(root, cordial.ExecutableName())
+		}
+		if input, err = config.ReadUserInputLine("Geneos Directory (default %q): ", root); err == nil {
+			if strings.TrimSpace(input) != "" {
+				log.Debug("set root to", slog.String("root", input))
+				root = input
+			}
+		}
+		if path.Base(root) == cordial.ExecutableName() {
+			deployCmdGeneosHome = root
+		} else {
+			deployCmdGeneosHome = path.Join(root, cordial.ExecutableName())
+		}
+	}
+
+	// create base install
+	deployCmdGeneosHome, _ = h.Abs(deployCmdGeneosHome)
+	config.Set(config.Global(), cordial.ExecutableName(), deployCmdGeneosHome)
+	if err := geneos.SaveGlobalConfig(cordial.ExecutableName()); err != nil {
+		return nil, err
+	}
+
+	// recreate LOCAL to load "geneos" and others
+	geneos.LOCAL = nil
+	geneos.LOCAL = geneos.NewHost(geneos.LOCALHOST)
+	return geneos.LOCAL, nil
+}
+
+// deployPackageOptions returns the package options for installing
+// releases on host h from the command line flags, prompting for a
+// download password if required. The caller should clear
+// deployCmdPassword once the options have been used.
+func deployPackageOptions(h *geneos.Host) (options []geneos.PackageOption, err error) {
+	if !deployCmdLocal && deployCmdUsername != "" && deployCmdPassword == nil {
+		deployCmdPassword, err = config.ReadPasswordInput(false, 0)
+		if err == config.ErrNotInteractive {
+			err = fmt.Errorf("%w and password required", err)
+			return
+		}
+		err = nil
+	}
+
+	options = []geneos.PackageOption{
+		geneos.Version(deployCmdVersion),
+		geneos.Basename(deployCmdBase),
+		geneos.UseRoot(config.Get[string](h.Config, cordial.ExecutableName())),
+		geneos.LocalOnly(deployCmdLocal),
+		geneos.NoSave(deployCmdNoSave || deployCmdLocal),
+		geneos.OverrideVersion(deployCmdOverride),
+		geneos.Password(deployCmdPassword),
+		geneos.Username(deployCmdUsername),
+		geneos.Headers(deployCmdExtras.Headers...),
+	}
+	if deployCmdArchive != "" {
+		options = append(options,
+			geneos.Source(deployCmdArchive),
+		)
+	}
+
+	if deployCmdSnapshot {
+		deployCmdNexus = true
+		options = append(options, geneos.UseNexusSnapshots())
+	}
+	if deployCmdNexus {
+		options = append(options, geneos.UseNexus())
+	}
+	return
+}
+
+// deployTLS initialises the TLS subsystem for host h, or imports the
+// signing bundle given on the command line, unless --insecure is set
+func deployTLS(h *geneos.Host) (err error) {
+	if deployCmdInsecure {
+		return
+	}
+	if deployCmdSigningBundle != "" {
+		return geneos.TLSImportBundle(deployCmdSigningBundle, "", deployCmdBundlePassword)
+	}
+	return geneos.TLSInit(h.Hostname(), false, certs.DefaultKeyType)
+}
@@ -0,0 +1,288 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+type diffCmdType struct {
+	Type   string `json:"type,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Host   string `json:"host,omitempty"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Key    string `json:"key"`
+	Change string `json:"change"`
+	Old    any    `json:"old,omitempty"`
+	New    any    `json:"new,omitempty"`
+}
+
+var diffCmdJSON, diffCmdIndent bool
+
+func init() {
+	Cmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVarP(&diffCmdJSON, "json", "j", false, "Output JSON")
+	diffCmd.Flags().BoolVarP(&diffCmdIndent, "pretty", "i", false, "Output indented JSON")
+
+	diffCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/diff.md
+var diffCmdDescription string
+
+var diffCmd = &cobra.Command{
+	Use:          "diff [flags] [TYPE] NAME [REV [REV]]",
+	GroupID:      CommandGroupConfig,
+	Short:        "Compare Instance Configuration Revisions",
+	Long:         diffCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		CmdGlobal:               "false",
+		CmdRequireHome:          "true",
+		CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(cmd *cobra.Command, _ []string) (err error) {
+		ct, args, _, err := FetchArgs(cmd)
+		if err != nil {
+			return
+		}
+
+		// revision numbers are also valid instance names, so split
+		// them off the end of the names
+		var names []string
+		var revs []int
+		for _, a := range args {
+			if rev, err := strconv.Atoi(a); err == nil && len(names) > 0 {
+				revs = append(revs, rev)
+				continue
+			}
+			if len(revs) > 0 {
+				return fmt.Errorf("%w: revisions must follow the instance name", geneos.ErrInvalidArgs)
+			}
+			names = append(names, a)
+		}
+		if len(names) == 0 {
+			return geneos.ErrInvalidArgs
+		}
+		if len(revs) > 2 {
+			return fmt.Errorf("%w: at most two revisions can be given", geneos.ErrInvalidArgs)
+		}
+
+		resp := instance.Do(geneos.GetHost(Hostname), ct, names, diffInstance, revs)
+		if diffCmdJSON || diffCmdIndent {
+			resp.Formatted(os.Stdout, "json", nil, nil, responses.IndentJSON(diffCmdIndent))
+			return
+		}
+		resp.Report(os.Stdout)
+		return
+	},
+}
+
+// diffInstance compares two versions of the configuration of instance
+// i. params[0] is a slice of zero, one or two revisions. With no
+// revisions the current configuration is compared to the most recent
+// revision that differs from it, with one the revision is compared to
+// the current configuration and with two the first revision is compared
+// to the second.
+func diffInstance(i geneos.Instance, params ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+
+	if len(params) == 0 {
+		resp.Err = geneos.ErrInvalidArgs
+		return
+	}
+	revs, ok := params[0].([]int)
+	if !ok {
+		resp.Err = geneos.ErrNotSupported
+		return
+	}
+
+	current, err := i.Host().ReadFile(instance.ComponentFilepath(i))
+	if err != nil {
+		resp.Err = err
+		return
+	}
+
+	var fromLabel, toLabel string
+	var from, to string
+
+	switch len(revs) {
+	case 0:
+		latest, err := instance.LatestRevision(i)
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		if latest == 0 {
+			resp.Err = fmt.Errorf("%w: no history", instance.ErrNoRevision)
+			return
+		}
+		r, err := instance.GetRevision(i, latest)
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		if r.Config == string(current) && latest > 1 {
+			if r, err = instance.GetRevision(i, latest-1); err != nil {
+				resp.Err = err
+				return
+			}
+		}
+		fromLabel, from = fmt.Sprintf("revision %d", r.Revision), r.Config
+		toLabel, to = "current", string(current)
+	case 1:
+		r, err := instance.GetRevision(i, revs[0])
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		fromLabel, from = fmt.Sprintf("revision %d", r.Revision), r.Config
+		toLabel, to = "current", string(current)
+	default:
+		r1, err := instance.GetRevision(i, revs[0])
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		r2, err := instance.GetRevision(i, revs[1])
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		fromLabel, from = fmt.Sprintf("revision %d", r1.Revision), r1.Config
+		toLabel, to = fmt.Sprintf("revision %d", r2.Revision), r2.Config
+	}
+
+	changes, err := diffConfigs(from, to)
+	if err != nil {
+		resp.Err = err
+		return
+	}
+
+	resp.ResultText = append(resp.ResultText, fmt.Sprintf("%s: %s -> %s", i, fromLabel, toLabel))
+	if len(changes) == 0 {
+		resp.ResultText = append(resp.ResultText, "  no changes")
+	}
+	for _, c := range changes {
+		c.Type, c.Name, c.Host = i.Type().String(), i.Name(), i.Host().String()
+		c.From, c.To = fromLabel, toLabel
+		if diffCmdJSON || diffCmdIndent {
+			resp.Values = append(resp.Values, c)
+			continue
+		}
+
+		switch c.Change {
+		case "added":
+			resp.ResultText = append(resp.ResultText, fmt.Sprintf("+ %s: %s", c.Key, diffValue(c.New)))
+		case "removed":
+			resp.ResultText = append(resp.ResultText, fmt.Sprintf("- %s: %s", c.Key, diffValue(c.Old)))
+		default:
+			resp.ResultText = append(resp.ResultText, fmt.Sprintf("~ %s: %s -> %s", c.Key, diffValue(c.Old), diffValue(c.New)))
+		}
+	}
+	return
+}
+
+// diffConfigs returns the key level differences between the two JSON
+// configurations from and to, sorted by key
+func diffConfigs(from, to string) (changes []diffCmdType, err error) {
+	var a, b map[string]any
+	if err = json.Unmarshal([]byte(from), &a); err != nil {
+		return nil, fmt.Errorf("cannot parse old configuration: %w", err)
+	}
+	if err = json.Unmarshal([]byte(to), &b); err != nil {
+		return nil, fmt.Errorf("cannot parse new configuration: %w", err)
+	}
+	fa, fb := map[string]any{}, map[string]any{}
+	flattenConfig("", a, fa)
+	flattenConfig("", b, fb)
+
+	keys := slices.Collect(maps.Keys(fa))
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		va, inA := fa[k]
+		vb, inB := fb[k]
+		switch {
+		case !inA:
+			changes = append(changes, diffCmdType{Key: k, Change: "added", New: vb})
+		case !inB:
+			changes = append(changes, diffCmdType{Key: k, Change: "removed", Old: va})
+		case diffValue(va) != diffValue(vb):
+			changes = append(changes, diffCmdType{Key: k, Change: "changed", Old: va, New: vb})
+		}
+	}
+	return
+}
+
+// flattenConfig adds the leaf values in v to flat, using the config
+// delimiter "::" between map keys and "[N]" suffixes for array elements
+func flattenConfig(prefix string, v any, flat map[string]any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 && prefix != "" {
+			flat[prefix] = t
+		}
+		for k, e := range t {
+			if prefix != "" {
+				k = prefix + "::" + k
+			}
+			flattenConfig(k, e, flat)
+		}
+	case []any:
+		if len(t) == 0 {
+			flat[prefix] = t
+		}
+		for n, e := range t {
+			flattenConfig(fmt.Sprintf("%s[%d]", prefix, n), e, flat)
+		}
+	default:
+		flat[prefix] = t
+	}
+}
+
+// diffValue returns a compact string representation of a configuration
+// value
+func diffValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strconv.Quote(t)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+var commandCmdUsername string
+var commandCmdPassword config.Secret
+var commandCmdJSON, commandCmdIndent bool
+var commandCmdFormat string
+
+func init() {
+	gatewayCmd.AddCommand(commandCmd)
+
+	commandCmd.PersistentFlags().StringVarP(&commandCmdUsername, "username", "u", "", "Username")
+	commandCmd.PersistentFlags().BoolVarP(&commandCmdJSON, "json", "j", false, "Output JSON")
+	commandCmd.PersistentFlags().BoolVarP(&commandCmdIndent, "pretty", "i", false, "Output indented JSON")
+	commandCmd.PersistentFlags().StringVarP(&commandCmdFormat, "format", "F", "", "Output a table in `FORMAT`, one of 'table', 'csv', 'tsv', 'toolkit', 'markdown', 'html' or 'xlsx'")
+
+	commandCmd.MarkFlagsMutuallyExclusive("json", "pretty", "format")
+	commandCmd.PersistentFlags().SortFlags = false
+}
+
+//go:embed _docs/command.md
+var commandCmdDescription string
+
+var commandCmd = &cobra.Command{
+	Use:          "command",
+	Short:        "Run Commands on Running Gateways",
+	Long:         commandCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		return command.Help()
+	},
+}
+
+// commandResult is the result of a command against one target
+type commandResult struct {
+	Gateway string `json:"gateway"`
+	XPath   string `json:"xpath"`
+	Command string `json:"command,omitempty"`
+	Target  string `json:"target"`
+	Status  string `json:"status"`
+	Stdout  string `json:"stdout,omitempty"`
+	Stderr  string `json:"stderr,omitempty"`
+	ExecLog string `json:"execLog,omitempty"`
+}
+
+// commandFunc is called for each XPath given on the command line
+// against the Gateway instance i, using the connection gw, and returns
+// the results for each target
+type commandFunc func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) ([]commandResult, error)
+
+// commandArgs returns the gateway names and parsed XPaths from the
+// command line
+func commandArgs(command *cobra.Command) (ct *geneos.Component, names []string, xpaths []*xpath.XPath, err error) {
+	ct, names, params, err := cmd.FetchArgs(command)
+	if err != nil {
+		return
+	}
+	if ct == nil {
+		ct = geneos.ParseComponent("gateway")
+	} else if !ct.IsA("gateway") {
+		err = fmt.Errorf("%w: commands are only valid for gateways", geneos.ErrInvalidArgs)
+		return
+	}
+	if len(names) == 0 {
+		err = fmt.Errorf("%w: no gateway name(s) supplied. Use a NAME of \"all\" as an explicit wildcard", geneos.ErrInvalidArgs)
+		return
+	}
+	if len(params) == 0 {
+		err = fmt.Errorf("%w: no xpath(s) supplied", geneos.ErrInvalidArgs)
+		return
+	}
+	for _, p := range params {
+		x, err := xpath.Parse(p)
+		if err != nil {
+			return ct, names, nil, fmt.Errorf("%w: %q: %w", geneos.ErrInvalidArgs, p, err)
+		}
+		xpaths = append(xpaths, x)
+	}
+
+	// prompt for a password once for all gateways, unless one is in
+	// the user or global configuration
+	cf := config.Global()
+	if commandCmdUsername == "" {
+		commandCmdUsername = config.Get[string](cf, cf.Join("snapshot", "username"))
+	}
+	commandCmdPassword = config.Get[config.Secret](cf, cf.Join("snapshot", "password"))
+	if commandCmdUsername != "" && commandCmdPassword == nil {
+		if commandCmdPassword, err = config.ReadPasswordInput(false, 0); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// commandDo connects to each gateway and calls fn for each XPath,
+// returning the responses with one table row per target
+func commandDo(ct *geneos.Component, names []string, xpaths []*xpath.XPath, fn commandFunc) responses.GeneralResponses {
+	return instance.Do(geneos.GetHost(cmd.Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
+		resp = responses.New[responses.General](i)
+
+		if instance.CompareVersion(i, "5.14") <= 0 {
+			resp.Err = fmt.Errorf("%s is too old (5.14 or above required)", i)
+			return
+		}
+		gw, err := cmd.GatewayConnection(i, commandCmdUsername, commandCmdPassword)
+		if err != nil {
+			resp.Err = err
+			return
+		}
+		for _, x := range xpaths {
+			results, err := fn(i, gw, x)
+			if err != nil {
+				resp.Err = errors.Join(resp.Err, fmt.Errorf("%s: %w", x, err))
+				continue
+			}
+			for _, r := range results {
+				resp.Values = append(resp.Values, r)
+				// results without a target are for the whole XPath
+				target := r.Target
+				if target == "" {
+					target = r.XPath
+				}
+				resp.Dataview.Table = append(resp.Dataview.Table, []string{
+					r.Gateway,
+					target,
+					r.Command,
+					r.Status,
+					oneLine(r.Stdout + r.Stderr),
+				})
+			}
+		}
+		return
+	})
+}
+
+// newCommandResult returns a commandResult for instance i, the XPath x
+// given on the command line and the matching target
+func newCommandResult(i geneos.Instance, x, target *xpath.XPath, command string) commandResult {
+	return commandResult{
+		Gateway: i.String(),
+		XPath:   x.String(),
+		Command: command,
+		Target:  target.String(),
+	}
+}
+
+// commandReport writes the results in resps to stdout in the selected
+// format and logs any errors. An error is returned if any command
+// failed, so that the exit status can be checked in scripts.
+func commandReport(resps responses.GeneralResponses, headings []string) (err error) {
+	if headings == nil {
+		headings = []string{"Gateway", "Target", "Command", "Status", "Output"}
+	}
+
+	switch {
+	case commandCmdJSON, commandCmdIndent:
+		err = resps.Formatted(os.Stdout, "json", nil, nil, responses.IndentJSON(commandCmdIndent))
+	case commandCmdFormat != "":
+		err = resps.Formatted(os.Stdout, commandCmdFormat, headings, nil)
+	default:
+		err = resps.Formatted(os.Stdout, "column", headings, nil)
+	}
+	if err != nil {
+		return
+	}
+
+	var failed int
+	for _, k := range slices.Sorted(maps.Keys(resps)) {
+		resp := resps[k]
+		if resp.Err != nil {
+			resp.Instance.Log().Error("command failed", slog.Any("error", resp.Err))
+			failed++
+		}
+		for _, v := range resp.Values {
+			if r, ok := v.(commandResult); ok && r.Status == "error" {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d command(s) failed", failed)
+	}
+	return
+}
+
+// oneLine joins the lines of s for table output
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
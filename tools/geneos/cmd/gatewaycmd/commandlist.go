@@ -0,0 +1,115 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// the internal commands checked by `command list` when no commands
+// are given on the command line
+var commandListDefaults = []string{
+	"/PLUGIN:sampleNow",
+	"/PLUGIN:lastSampleInfo",
+	"/SNOOZE:manual",
+	"/SNOOZE:manualAllMe",
+	"/SNOOZE:time",
+	"/SNOOZE:timeAllMe",
+	"/SNOOZE:unsnooze",
+	"/SNOOZE:unsnoozeAllMe",
+	"/SNOOZE:info",
+}
+
+var commandListCmdCommands []string
+var commandListCmdTargets bool
+
+func init() {
+	commandCmd.AddCommand(commandListCmd)
+
+	commandListCmd.Flags().StringArrayVarP(&commandListCmdCommands, "command", "c", nil, "Check `COMMAND`, instead of the built-in list. Repeat as required")
+	commandListCmd.Flags().BoolVarP(&commandListCmdTargets, "targets", "t", false, "List each target instead of the number of targets")
+
+	commandListCmd.Flags().SortFlags = false
+}
+
+var commandListCmd = &cobra.Command{
+	Use:     "list [flags] [gateway] [NAME...] XPATH...",
+	Aliases: []string{"ls"},
+	Short:   "List Commands Available for XPaths",
+	Long: `Check which commands can be run against the data items matching each XPATH, and how many targets each command has.
+
+By default the internal sample and snooze commands are checked. Use ` + "`--command`/`-c`" + ` to check other commands, such as user-defined commands, by their full name. Commands without any targets are not shown.`,
+	Example: `
+geneos gateway command list Demo '//probe[(@name="localhost")]//sampler'
+geneos gateway command list -t -c "/SNOOZE:manualAllMe" all '//dataview[(@name="CPU")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:                "false",
+		cmd.CmdRequireHome:           "true",
+		cmd.CmdWildcardNames:         "true",
+		cmd.CmdAllInstancesMustMatch: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, xpaths, err := commandArgs(command)
+		if err != nil {
+			return
+		}
+		cmds := commandListCmdCommands
+		if len(cmds) == 0 {
+			cmds = commandListDefaults
+		}
+
+		resps := commandDo(ct, names, xpaths, func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) (results []commandResult, err error) {
+			for _, c := range cmds {
+				targets, err := gw.CommandTargets(c, x)
+				if err != nil {
+					return results, err
+				}
+				if len(targets) == 0 {
+					continue
+				}
+				if !commandListCmdTargets {
+					results = append(results, commandResult{
+						Gateway: i.String(),
+						XPath:   x.String(),
+						Command: c,
+						Status:  fmt.Sprintf("%d targets", len(targets)),
+					})
+					continue
+				}
+				for _, t := range targets {
+					r := newCommandResult(i, x, t, c)
+					r.Status = "available"
+					results = append(results, r)
+				}
+			}
+			return
+		})
+
+		return commandReport(resps, nil)
+	},
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var commandRunCmdCommand string
+var commandRunCmdArgs []string
+var commandRunCmdDryRun bool
+
+func init() {
+	commandCmd.AddCommand(commandRunCmd)
+
+	commandRunCmd.Flags().StringVarP(&commandRunCmdCommand, "command", "c", "", "Run `COMMAND`, using the full command name (required)")
+	commandRunCmd.Flags().StringArrayVarP(&commandRunCmdArgs, "arg", "a", nil, "Command argument as `[N=]VALUE`, numbered from 1 in order if N is not given. Repeat as required")
+	commandRunCmd.Flags().BoolVarP(&commandRunCmdDryRun, "dry-run", "n", false, "Show the targets but do not run the command")
+
+	commandRunCmd.MarkFlagRequired("command")
+	commandRunCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/command_run.md
+var commandRunCmdDescription string
+
+var commandRunCmd = &cobra.Command{
+	Use:   "run [flags] [gateway] [NAME...] XPATH...",
+	Short: "Run a Command Against Matching Data Items",
+	Long:  commandRunCmdDescription,
+	Example: `
+geneos gateway command run -c "/PLUGIN:lastSampleInfo" Demo '//probe[(@name="localhost")]//sampler[(@name="CPU")]'
+geneos gateway command run -c "Restart Service" -a 1=httpd all '//dataview[(@name="Processes")]/rows/row[(@name="httpd")]/cell[(@column="status")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:                "false",
+		cmd.CmdRequireHome:           "true",
+		cmd.CmdWildcardNames:         "true",
+		cmd.CmdAllInstancesMustMatch: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		args, err := commandRunArgs(commandRunCmdArgs)
+		if err != nil {
+			return
+		}
+		ct, names, xpaths, err := commandArgs(command)
+		if err != nil {
+			return
+		}
+
+		resps := commandDo(ct, names, xpaths, func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) (results []commandResult, err error) {
+			if commandRunCmdDryRun {
+				targets, err := gw.CommandTargets(commandRunCmdCommand, x)
+				for _, t := range targets {
+					r := newCommandResult(i, x, t, commandRunCmdCommand)
+					r.Status = "dry-run"
+					results = append(results, r)
+				}
+				return results, err
+			}
+
+			crs, err := gw.RunCommandAll(commandRunCmdCommand, x, args...)
+			for _, cr := range crs {
+				r := newCommandResult(i, x, cr.Target, commandRunCmdCommand)
+				r.Status = cr.Status
+				r.Stdout = strings.TrimSpace(cr.Stdout)
+				r.Stderr = strings.TrimSpace(cr.Stderr)
+				r.ExecLog = strings.TrimSpace(cr.ExecLog)
+				results = append(results, r)
+			}
+			return
+		})
+		return commandReport(resps, nil)
+	},
+}
+
+// commandRunArgs converts the `--arg` flag values into command
+// arguments
+func commandRunArgs(values []string) (args []commands.Args, err error) {
+	for n, v := range values {
+		index := n + 1
+		if k, val, found := strings.Cut(v, "="); found {
+			if index, err = strconv.Atoi(k); err != nil || index < 1 {
+				return nil, fmt.Errorf("%w: invalid argument number in %q", geneos.ErrInvalidArgs, v)
+			}
+			v = val
+		}
+		args = append(args, commands.Arg(index, v))
+	}
+	return
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+const sampleNowCommand = "/PLUGIN:sampleNow"
+
+var commandSampleCmdDryRun bool
+
+func init() {
+	commandCmd.AddCommand(commandSampleCmd)
+
+	commandSampleCmd.Flags().BoolVarP(&commandSampleCmdDryRun, "dry-run", "n", false, "Show the samplers but do not sample them")
+	commandSampleCmd.Flags().SortFlags = false
+}
+
+var commandSampleCmd = &cobra.Command{
+	Use:   "sample [flags] [gateway] [NAME...] XPATH...",
+	Short: "Force Samplers to Sample Now",
+	Long: `Force an immediate sample of the samplers matching each XPATH on the selected Gateways, using the internal ` + "`" + sampleNowCommand + "`" + ` command.
+
+Use ` + "`--dry-run`/`-n`" + ` to check the matching samplers first.`,
+	Example: `
+geneos gateway command sample Demo '//probe[(@name="localhost")]//sampler[(@name="CPU")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:                "false",
+		cmd.CmdRequireHome:           "true",
+		cmd.CmdWildcardNames:         "true",
+		cmd.CmdAllInstancesMustMatch: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, xpaths, err := commandArgs(command)
+		if err != nil {
+			return
+		}
+		resps := commandDo(ct, names, xpaths, func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) (results []commandResult, err error) {
+			targets, err := gw.CommandTargets(sampleNowCommand, x)
+			if err != nil {
+				return
+			}
+			for _, t := range targets {
+				r := newCommandResult(i, x, t, sampleNowCommand)
+				r.Status = "dry-run"
+				if !commandSampleCmdDryRun {
+					if err := gw.SampleNow(t); err != nil {
+						r.Status = "error"
+						r.Stderr = err.Error()
+					} else {
+						r.Status = "sampled"
+					}
+				}
+				results = append(results, r)
+			}
+			return
+		})
+		return commandReport(resps, nil)
+	},
+}
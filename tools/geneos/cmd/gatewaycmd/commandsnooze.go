@@ -0,0 +1,138 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var commandSnoozeCmdReason, commandUnsnoozeCmdReason string
+var commandSnoozeCmdDuration time.Duration
+var commandSnoozeCmdDryRun, commandUnsnoozeCmdDryRun bool
+
+func init() {
+	commandCmd.AddCommand(commandSnoozeCmd)
+	commandCmd.AddCommand(commandUnsnoozeCmd)
+
+	commandSnoozeCmd.Flags().StringVarP(&commandSnoozeCmdReason, "reason", "r", "", "Snooze `REASON`, shown to users of the Gateway")
+	commandSnoozeCmd.Flags().DurationVarP(&commandSnoozeCmdDuration, "duration", "D", 0, "Snooze for `DURATION`, rounded up to whole minutes.\nDefault is a manual snooze that must be removed with unsnooze")
+	commandSnoozeCmd.Flags().BoolVarP(&commandSnoozeCmdDryRun, "dry-run", "n", false, "Show the targets but do not snooze them")
+	commandSnoozeCmd.Flags().SortFlags = false
+
+	commandUnsnoozeCmd.Flags().StringVarP(&commandUnsnoozeCmdReason, "reason", "r", "", "Unsnooze `REASON`, shown to users of the Gateway")
+	commandUnsnoozeCmd.Flags().BoolVarP(&commandUnsnoozeCmdDryRun, "dry-run", "n", false, "Show the targets but do not unsnooze them")
+	commandUnsnoozeCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/command_snooze.md
+var commandSnoozeCmdDescription string
+
+var commandSnoozeCmd = &cobra.Command{
+	Use:   "snooze [flags] [gateway] [NAME...] XPATH...",
+	Short: "Snooze Matching Items",
+	Long:  commandSnoozeCmdDescription,
+	Example: `
+geneos gateway command snooze -r "patching" -D 2h Demo '//probe[(@name="server1")]'
+geneos gateway command snooze -n -r "known issue" all '//managedEntity[(@name="web01")]//dataview[(@name="Disk")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:                "false",
+		cmd.CmdRequireHome:           "true",
+		cmd.CmdWildcardNames:         "true",
+		cmd.CmdAllInstancesMustMatch: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, xpaths, err := commandArgs(command)
+		if err != nil {
+			return
+		}
+		resps := commandDo(ct, names, xpaths, func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) ([]commandResult, error) {
+			return commandEachMatch(i, gw, x, "snooze", commandSnoozeCmdDryRun, func(t *xpath.XPath) (status string, err error) {
+				if commandSnoozeCmdDuration > 0 {
+					return "snoozed for " + commandSnoozeCmdDuration.String(), gw.SnoozeFor(t, commandSnoozeCmdDuration, commandSnoozeCmdReason)
+				}
+				return "snoozed", gw.SnoozeManual(t, commandSnoozeCmdReason)
+			})
+		})
+		return commandReport(resps, nil)
+	},
+}
+
+var commandUnsnoozeCmd = &cobra.Command{
+	Use:   "unsnooze [flags] [gateway] [NAME...] XPATH...",
+	Short: "Unsnooze Matching Items",
+	Long: `Unsnooze the gateways, probes, managed entities, samplers, dataviews, headlines or cells matching each XPATH on the selected Gateways.
+
+Use ` + "`--dry-run`/`-n`" + ` to check the matching items first. Authentication is the same as for the other ` + "`gateway command`" + ` commands.`,
+	Example: `
+geneos gateway command unsnooze -r "patching complete" Demo '//probe[(@name="server1")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:                "false",
+		cmd.CmdRequireHome:           "true",
+		cmd.CmdWildcardNames:         "true",
+		cmd.CmdAllInstancesMustMatch: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, xpaths, err := commandArgs(command)
+		if err != nil {
+			return
+		}
+		resps := commandDo(ct, names, xpaths, func(i geneos.Instance, gw *commands.Connection, x *xpath.XPath) ([]commandResult, error) {
+			return commandEachMatch(i, gw, x, "unsnooze", commandUnsnoozeCmdDryRun, func(t *xpath.XPath) (string, error) {
+				return "unsnoozed", gw.Unsnooze(t, commandUnsnoozeCmdReason)
+			})
+		})
+		return commandReport(resps, nil)
+	},
+}
+
+// commandEachMatch calls fn for each item matching x on the Gateway
+// and returns a result for each, labelled with action. If dryrun is
+// true then fn is not called.
+func commandEachMatch(i geneos.Instance, gw *commands.Connection, x *xpath.XPath, action string, dryrun bool, fn func(t *xpath.XPath) (string, error)) (results []commandResult, err error) {
+	targets, err := gw.Match(x, 0)
+	if err != nil {
+		return
+	}
+	for _, t := range targets {
+		r := newCommandResult(i, x, t, action)
+		r.Status = "dry-run"
+		if !dryrun {
+			status, err := fn(t)
+			if err != nil {
+				r.Status = "error"
+				r.Stderr = err.Error()
+			} else {
+				r.Status = status
+			}
+		}
+		results = append(results, r)
+	}
+	return
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	setup "github.com/itrs-group/cordial/pkg/geneos"
+	"github.com/itrs-group/cordial/pkg/reporter"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var diffCmdJSON, diffCmdIndent bool
+var diffCmdFormat string
+
+func init() {
+	gatewayCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVarP(&diffCmdJSON, "json", "j", false, "Output JSON")
+	diffCmd.Flags().BoolVarP(&diffCmdIndent, "pretty", "i", false, "Output indented JSON")
+	diffCmd.Flags().StringVarP(&diffCmdFormat, "format", "F", "", "Output a table in `FORMAT`, one of 'table', 'csv', 'tsv', 'toolkit', 'markdown', 'html' or 'xlsx'")
+
+	diffCmd.MarkFlagsMutuallyExclusive("json", "pretty", "format")
+	diffCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/diff.md
+var diffCmdDescription string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [flags] OLD NEW",
+	Short: "Compare Two Gateway Setup Files",
+	Long:  diffCmdDescription,
+	Example: `
+geneos gateway diff old/gateway.setup.xml new/gateway.setup.xml
+geneos gateway diff -F toolkit merged-prod.xml merged-uat.xml
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "false",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		_, args, params, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+		args = append(args, params...)
+		if len(args) != 2 {
+			return fmt.Errorf("%w: two setup files must be given", geneos.ErrInvalidArgs)
+		}
+
+		from, err := readSetup(args[0])
+		if err != nil {
+			return
+		}
+		to, err := readSetup(args[1])
+		if err != nil {
+			return
+		}
+
+		diffs := setup.DiffGateways(from, to)
+
+		switch {
+		case diffCmdJSON, diffCmdIndent:
+			if diffs == nil {
+				diffs = []setup.SetupDifference{}
+			}
+			j := json.NewEncoder(os.Stdout)
+			j.SetEscapeHTML(false)
+			if diffCmdIndent {
+				j.SetIndent("", "    ")
+			}
+			return j.Encode(diffs)
+		case diffCmdFormat != "":
+			r, err := reporter.NewReporter(diffCmdFormat, os.Stdout)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			if err = r.Prepare(reporter.Report{Name: "gateway-diff", Title: "Gateway Setup Differences"}); err != nil {
+				return err
+			}
+			r.AddHeadlines(map[string]string{
+				"old":     args[0],
+				"new":     args[1],
+				"changes": fmt.Sprint(len(diffs)),
+			})
+			r.UpdateTable(diffTable(diffs))
+			r.Render()
+		default:
+			writeDiffText(os.Stdout, diffs)
+		}
+		return
+	},
+}
+
+func readSetup(file string) (g *setup.Gateway, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if g, err = setup.ReadGateway(f); err != nil {
+		log.Debug("reading gateway setup", slog.String("file", file), slog.Any("error", err))
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return
+}
+
+// diffTable returns the columns and rows for a reporter, with one row
+// per changed field
+func diffTable(diffs []setup.SetupDifference) (columns []string, rows [][]string) {
+	columns = []string{"id", "section", "name", "change", "field", "old", "new"}
+	for n, d := range diffs {
+		if len(d.Details) == 0 {
+			rows = append(rows, []string{fmt.Sprint(n + 1), d.Section, d.Name, d.Change, "", "", ""})
+			continue
+		}
+		for m, f := range d.Details {
+			rows = append(rows, []string{fmt.Sprintf("%d.%d", n+1, m+1), d.Section, d.Name, d.Change, f.Field, f.Old, f.New})
+		}
+	}
+	return
+}
+
+func writeDiffText(w io.Writer, diffs []setup.SetupDifference) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "no differences")
+		return
+	}
+	for _, d := range diffs {
+		switch d.Change {
+		case setup.DiffAdded:
+			fmt.Fprintf(w, "+ %s %q\n", d.Section, d.Name)
+		case setup.DiffRemoved:
+			fmt.Fprintf(w, "- %s %q\n", d.Section, d.Name)
+		default:
+			fmt.Fprintf(w, "~ %s %q\n", d.Section, d.Name)
+			for _, f := range d.Details {
+				switch {
+				case f.Old == "":
+					fmt.Fprintf(w, "    + %s: %s\n", f.Field, f.New)
+				case f.New == "":
+					fmt.Fprintf(w, "    - %s: %s\n", f.Field, f.Old)
+				default:
+					fmt.Fprintf(w, "    ~ %s: %s -> %s\n", f.Field, f.Old, f.New)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewaycmd groups commands that work with Gateway setup
+// files and running Gateways
+package gatewaycmd
+
+import (
+	_ "embed"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+)
+
+var log = cordial.Logger
+
+func init() {
+	cmd.Cmd.AddCommand(gatewayCmd)
+}
+
+//go:embed README.md
+var gatewayCmdDescription string
+
+var gatewayCmd = &cobra.Command{
+	Use:          "gateway",
+	GroupID:      cmd.CommandGroupSubsystems,
+	Short:        "Gateway Setup and Control Operations",
+	Long:         gatewayCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "false",
+	},
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		return command.Help()
+	},
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	setup "github.com/itrs-group/cordial/pkg/geneos"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/pkg/reporter"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var matchCmdJSON, matchCmdIndent, matchCmdUnmatched bool
+var matchCmdFormat string
+
+func init() {
+	gatewayCmd.AddCommand(matchCmd)
+
+	matchCmd.Flags().BoolVarP(&matchCmdUnmatched, "unmatched", "u", false, "Only show targets that match nothing")
+
+	matchCmd.Flags().BoolVarP(&matchCmdJSON, "json", "j", false, "Output JSON")
+	matchCmd.Flags().BoolVarP(&matchCmdIndent, "pretty", "i", false, "Output indented JSON")
+	matchCmd.Flags().StringVarP(&matchCmdFormat, "format", "F", "", "Output a table in `FORMAT`, one of 'table', 'csv', 'tsv', 'toolkit', 'markdown', 'html' or 'xlsx'")
+
+	matchCmd.MarkFlagsMutuallyExclusive("json", "pretty", "format")
+	matchCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/match.md
+var matchCmdDescription string
+
+var matchCmd = &cobra.Command{
+	Use:   "match [flags] SETUP [XPATH...]",
+	Short: "Match Rule Targets or XPaths Against A Gateway Setup File",
+	Long:  matchCmdDescription,
+	Example: `
+geneos gateway match merged-prod.xml
+geneos gateway match -u -F markdown merged-prod.xml
+geneos gateway match gateway.setup.xml '//managedEntity[(attr("Region")="EMEA")]/sampler[(@name="CPU")]'
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "false",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		_, args, params, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+		args = append(args, params...)
+		if len(args) == 0 {
+			return fmt.Errorf("%w: a setup file must be given", geneos.ErrInvalidArgs)
+		}
+
+		g, err := readSetup(args[0])
+		if err != nil {
+			return
+		}
+
+		var targets []setup.TargetMatches
+		if len(args) > 1 {
+			d := setup.NewDirectory(g)
+			for _, x := range args[1:] {
+				targets = append(targets, d.MatchTarget(x))
+			}
+		} else {
+			targets = setup.MatchRuleTargets(g)
+		}
+
+		var unmatched int
+		var selected []setup.TargetMatches
+		for _, t := range targets {
+			if len(t.Matches) == 0 {
+				unmatched++
+			} else if matchCmdUnmatched {
+				continue
+			}
+			selected = append(selected, t)
+		}
+
+		switch {
+		case matchCmdJSON, matchCmdIndent:
+			if selected == nil {
+				selected = []setup.TargetMatches{}
+			}
+			j := json.NewEncoder(os.Stdout)
+			j.SetEscapeHTML(false)
+			if matchCmdIndent {
+				j.SetIndent("", "    ")
+			}
+			return j.Encode(selected)
+		case matchCmdFormat != "":
+			r, err := reporter.NewReporter(matchCmdFormat, os.Stdout)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			if err = r.Prepare(reporter.Report{Name: "gateway-match", Title: "Gateway Target Matches"}); err != nil {
+				return err
+			}
+			r.AddHeadlines(map[string]string{
+				"setup":     args[0],
+				"targets":   fmt.Sprint(len(targets)),
+				"unmatched": fmt.Sprint(unmatched),
+			})
+			r.UpdateTable(matchTable(selected))
+			r.Render()
+		default:
+			writeMatchText(os.Stdout, selected)
+			fmt.Printf("%d target(s), %d match nothing\n", len(targets), unmatched)
+		}
+		return
+	},
+}
+
+// matchTable returns the columns and rows for a reporter, with one row
+// per matching item and one row for each target that matches nothing
+func matchTable(targets []setup.TargetMatches) (columns []string, rows [][]string) {
+	columns = []string{"id", "rule", "target", "matches", "probe", "entity", "sampler", "type", "error"}
+	for n, t := range targets {
+		if len(t.Matches) == 0 {
+			rows = append(rows, []string{fmt.Sprint(n + 1), t.Rule, t.Target, "0", "", "", "", "", t.Error})
+			continue
+		}
+		for m, x := range t.Matches {
+			probe, entity, sampler, stype := matchNames(x)
+			rows = append(rows, []string{fmt.Sprintf("%d.%d", n+1, m+1), t.Rule, t.Target, fmt.Sprint(len(t.Matches)), probe, entity, sampler, stype, ""})
+		}
+	}
+	return
+}
+
+// matchNames returns the names of the items in x
+func matchNames(x *xpath.XPath) (probe, entity, sampler, stype string) {
+	l := x.LookupValues()
+	return l["probe"], l["entity"], l["sampler"], l["type"]
+}
+
+func writeMatchText(w io.Writer, targets []setup.TargetMatches) {
+	var rule string
+	for _, t := range targets {
+		if t.Rule != "" && t.Rule != rule {
+			fmt.Fprintf(w, "rule %q\n", t.Rule)
+			rule = t.Rule
+		}
+		fmt.Fprintf(w, "  %s\n", t.Target)
+		switch {
+		case t.Error != "":
+			fmt.Fprintf(w, "    ! %s\n", t.Error)
+		case len(t.Matches) == 0:
+			fmt.Fprintln(w, "    ! matches nothing")
+		default:
+			for _, x := range t.Matches {
+				probe, entity, sampler, stype := matchNames(x)
+				switch {
+				case x.IsGateway():
+					fmt.Fprintf(w, "    gateway %q\n", x.Gateway.Name)
+				case x.IsProbe():
+					fmt.Fprintf(w, "    probe %q\n", probe)
+				case x.IsEntity():
+					fmt.Fprintf(w, "    probe %q entity %q\n", probe, entity)
+				default:
+					fmt.Fprintf(w, "    probe %q entity %q sampler %q type %q\n", probe, entity, sampler, stype)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+type historyCmdType struct {
+	Type     string    `json:"type,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	Revision int       `json:"revision"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Command  string    `json:"command,omitempty"`
+}
+
+var historyCmdJSON, historyCmdCSV, historyCmdIndent, historyCmdToolkit bool
+
+func init() {
+	Cmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().BoolVarP(&historyCmdJSON, "json", "j", false, "Output JSON")
+	historyCmd.Flags().BoolVarP(&historyCmdIndent, "pretty", "i", false, "Output indented JSON")
+	historyCmd.Flags().BoolVarP(&historyCmdCSV, "csv", "c", false, "Output CSV")
+	historyCmd.Flags().BoolVarP(&historyCmdToolkit, "toolkit", "t", false, "Output Toolkit formatted CSV")
+
+	historyCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/history.md
+var historyCmdDescription string
+
+var historyCmd = &cobra.Command{
+	Use:          "history [flags] [TYPE] [NAME...]",
+	GroupID:      CommandGroupConfig,
+	Short:        "Show Instance Configuration History",
+	Long:         historyCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		CmdGlobal:               "true",
+		CmdRequireHome:          "true",
+		CmdWildcardNames:        "true",
+		CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(cmd *cobra.Command, _ []string) (err error) {
+		ct, names, _, err := FetchArgs(cmd)
+		if err != nil {
+			return
+		}
+
+		resp := instance.Do(geneos.GetHost(Hostname), ct, names, historyInstance)
+
+		switch {
+		case historyCmdJSON, historyCmdIndent:
+			resp.Formatted(os.Stdout, "json", nil, nil, responses.IndentJSON(historyCmdIndent))
+		case historyCmdToolkit:
+			resp.Formatted(os.Stdout, "toolkit", []string{
+				"ID",
+				"type",
+				"name",
+				"host",
+				"revision",
+				"time",
+				"user",
+				"command",
+			}, nil)
+		case historyCmdCSV:
+			resp.Formatted(os.Stdout, "csv", []string{
+				"Type",
+				"Name",
+				"Host",
+				"Revision",
+				"Time",
+				"User",
+				"Command",
+			}, nil)
+		default:
+			resp.Formatted(os.Stdout, "column", []string{
+				"Type",
+				"Name",
+				"Host",
+				"Revision",
+				"Time",
+				"User",
+				"Command",
+			}, nil)
+		}
+		return
+	},
+}
+
+func historyInstance(i geneos.Instance, _ ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+
+	revisions, err := instance.Revisions(i)
+	if err != nil {
+		resp.Err = err
+		return
+	}
+
+	for _, r := range revisions {
+		resp.Values = append(resp.Values, historyCmdType{
+			Type:     i.Type().String(),
+			Name:     i.Name(),
+			Host:     i.Host().String(),
+			Revision: r.Revision,
+			Time:     r.Time,
+			User:     r.User,
+			Command:  r.Command,
+		})
+
+		var row []string
+		if historyCmdToolkit {
+			row = append(row, fmt.Sprintf("%s:%d", instance.IDString(i), r.Revision))
+		}
+		row = append(row,
+			i.Type().String(),
+			i.Name(),
+			i.Host().String(),
+			fmt.Sprint(r.Revision),
+			r.Time.Local().Format(time.RFC3339),
+			r.User,
+			r.Command,
+		)
+		resp.Dataview.Table = append(resp.Dataview.Table, row)
+	}
+	return
+}
@@ -27,7 +27,9 @@ import (
 	"os"
 	"os/user"
 	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -35,8 +37,10 @@ import (
 	"github.com/itrs-group/cordial/pkg/certs"
 	"github.com/itrs-group/cordial/pkg/config"
 	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/cmd/pscmd"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/profiles"
 	"github.com/itrs-group/cordial/tools/geneos/internal/restore"
 	"github.com/itrs-group/cordial/tools/geneos/internal/values"
 )
@@ -49,6 +53,8 @@ var initCmdName, initCmdSigningBundle, initCmdImportKey, initCmdGatewayTemplate,
 var initCmdDLUsername string
 var initCmdNoInstall, initCmdTLS bool
 var initCmdBundlePassword config.Secret
+var initCmdProfile string
+var initCmdProfileVars values.NameValues
 
 // initCmdExtras is shared between all `init` commands as they share common
 // flags (for now)
@@ -65,6 +71,9 @@ func init() {
 	initCmd.Flags().StringVarP(&initCmdRestore, "restore", "R", "", "Restore from backup file `PATH`")
 	initCmd.Flags().BoolVarP(&initCmdNoInstall, "no-install", "X", false, "Don't install any releases after restore")
 
+	initCmd.Flags().StringVar(&initCmdProfile, "profile", "", "Create the instances in deployment profile `NAME`.\nDefaults to the profiles default setting, normally \"none\"")
+	initCmd.Flags().Var(&initCmdProfileVars, "profile-var", "Set a profile variable in the format `NAME=VALUE`\n(Repeat as required)")
+
 	initCmd.PersistentFlags().BoolVarP(&initCmdLogs, "log", "l", false, "Follow logs after starting instance(s)")
 	initCmd.PersistentFlags().BoolVarP(&initCmdForce, "force", "F", false, "Ignore existing directories and files and overwrite")
 	initCmd.PersistentFlags().StringVarP(&initCmdName, "name", "n", "", "Use name for instances and configurations instead of the hostname")
@@ -137,6 +146,17 @@ geneos init
 		// merge params into args as there may be a directory path in there
 		args = append(args, params...)
 
+		if initCmdProfile != "" {
+			// check the profile exists before doing anything else
+			names, _, err := profiles.Names()
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(names, initCmdProfile) {
+				return fmt.Errorf("%w: profile %q not found", geneos.ErrNotExist, initCmdProfile)
+			}
+		}
+
 		options, err := initProcessArgs(command, log, args, initCmdExtras)
 		if err != nil {
 			return err
@@ -199,10 +219,34 @@ geneos init
 			return
 		}
 
-		return
+		return initProfile(geneos.LOCAL, options...)
 	},
 }
 
+// initProfile creates and starts the instances in the deployment
+// profile given with --profile, or the default profile, on host h
+func initProfile(h *geneos.Host, options ...geneos.PackageOption) (err error) {
+	p, err := profiles.Get(initCmdProfile, cmd.ProfileVars(h, initCmdName, initCmdProfileVars))
+	if err != nil {
+		return
+	}
+	if len(p.Components) == 0 {
+		return
+	}
+
+	if err = cmd.DeployProfile(h, p, nil, false, options...); err != nil {
+		return
+	}
+
+	e := []string{}
+	if err = cmd.Start(nil, initCmdLogs, true, e); err != nil {
+		return
+	}
+	time.Sleep(time.Second * 2)
+	pscmd.CommandPS(nil, e, e)
+	return
+}
+
 // alias for old `geneos init tls` command
 var initTLSCmd = &cobra.Command{
 	Use:          "tls",
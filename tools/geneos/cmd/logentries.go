@@ -0,0 +1,380 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+// logCmdEntry is a log entry with the details of the instance and log
+// file it came from
+type logCmdEntry struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Host string `json:"host"`
+	Log  string `json:"log"`
+	File string `json:"file"`
+	instance.LogEntry
+
+	inst geneos.Instance
+}
+
+// logCmdSummaryType is the summary of the log entries for one instance
+type logCmdSummaryType struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Host      string    `json:"host"`
+	Entries   int       `json:"entries"`
+	Fatal     int       `json:"fatal"`
+	Errors    int       `json:"errors"`
+	Warnings  int       `json:"warnings"`
+	First     time.Time `json:"first,omitzero"`
+	Last      time.Time `json:"last,omitzero"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// logCmdStructured returns true if any of the options that need the
+// log entries to be parsed are set
+func logCmdStructured() bool {
+	return logCmdSince != "" || logCmdUntil != "" || logCmdSeverity != "" ||
+		logCmdMerge || logCmdJSON || logCmdIndent || logCmdSummary
+}
+
+// logCmdFilter holds the parsed filter options
+type logCmdFilter struct {
+	since, until time.Time
+	severity     instance.Severity
+}
+
+func newLogCmdFilter() (f logCmdFilter, err error) {
+	now := time.Now()
+	if logCmdSince != "" {
+		if f.since, err = parseLogCmdTime(logCmdSince, now); err != nil {
+			return
+		}
+	}
+	if logCmdUntil != "" {
+		if f.until, err = parseLogCmdTime(logCmdUntil, now); err != nil {
+			return
+		}
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && f.until.Before(f.since) {
+		err = fmt.Errorf("%w: --until is before --since", geneos.ErrInvalidArgs)
+		return
+	}
+	if logCmdSeverity != "" {
+		f.severity, err = instance.ParseSeverity(logCmdSeverity)
+	}
+	return
+}
+
+// match returns true if entry e passes all the filters
+func (f logCmdFilter) match(e instance.LogEntry) bool {
+	if !f.since.IsZero() || !f.until.IsZero() {
+		if e.Time.IsZero() {
+			return false
+		}
+		if !f.since.IsZero() && e.Time.Before(f.since) {
+			return false
+		}
+		if !f.until.IsZero() && e.Time.After(f.until) {
+			return false
+		}
+	}
+	if f.severity != instance.SeverityUnknown && e.Severity < f.severity {
+		return false
+	}
+	if logCmdMatch != "" && !strings.Contains(e.Text, logCmdMatch) {
+		return false
+	}
+	if logCmdIgnore != "" && strings.Contains(e.Text, logCmdIgnore) {
+		return false
+	}
+	return true
+}
+
+var logCmdTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseLogCmdTime parses the --since and --until values. s can be a
+// duration before now, e.g. "90m" or "2d", a date and time, a date or
+// a time today. Times without a zone are local.
+func parseLogCmdTime(s string, now time.Time) (t time.Time, err error) {
+	if d, ok := strings.CutSuffix(s, "d"); ok {
+		if days, err := strconv.Atoi(d); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range logCmdTimeLayouts {
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			y, m, d := now.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
+		}
+	}
+	return t, fmt.Errorf("%w: cannot parse time %q", geneos.ErrInvalidArgs, s)
+}
+
+// logCmdFiles returns the log files selected by the command line
+// options for instance i, mapped to the kind of log
+func logCmdFiles(i geneos.Instance) (files map[string]string) {
+	files = map[string]string{}
+	if logCmdStderr {
+		files[instance.ComponentFilepath(i, "txt")] = "STDERR"
+	}
+	if !logCmdNoNormal {
+		files[instance.LogFilePath(i)] = "instance"
+	}
+	if logCmdCALog && i.Type().IsA("netprobe") {
+		files[instance.PathTo(i, "calogfile")] = "CA"
+	}
+	return
+}
+
+// logEntriesInstance reads and filters the log entries for instance
+// i. The filter is passed as the first parameter. The entries are
+// returned as a slice of logCmdEntry in the response Value.
+func logEntriesInstance(i geneos.Instance, params ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+
+	if len(params) == 0 {
+		resp.Err = geneos.ErrInvalidArgs
+		return
+	}
+	filter, ok := params[0].(logCmdFilter)
+	if !ok {
+		resp.Err = geneos.ErrInvalidArgs
+		return
+	}
+
+	var results []logCmdEntry
+	files := logCmdFiles(i)
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		entries, err := readLogEntries(i, path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			resp.Err = err
+			return
+		}
+
+		var selected []logCmdEntry
+		for _, e := range entries {
+			if !filter.match(e) {
+				continue
+			}
+			selected = append(selected, logCmdEntry{
+				Type:     i.Type().String(),
+				Name:     i.Name(),
+				Host:     i.Host().String(),
+				Log:      files[path],
+				File:     path,
+				LogEntry: e,
+				inst:     i,
+			})
+		}
+
+		// like tail, only keep the last entries unless the whole file
+		// or a time range is asked for
+		if !logCmdSummary && !logCmdCat && filter.since.IsZero() && filter.until.IsZero() && len(selected) > logCmdLines {
+			selected = selected[len(selected)-logCmdLines:]
+		}
+		results = append(results, selected...)
+	}
+	resp.Value = results
+	return
+}
+
+func readLogEntries(i geneos.Instance, path string) (entries []instance.LogEntry, err error) {
+	f, err := i.Host().Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	return instance.ParseLogs(f)
+}
+
+// logsStructured implements the `logs` command options that parse the
+// log entries, writing the results to w
+func logsStructured(w io.Writer, ct *geneos.Component, names []string) (err error) {
+	filter, err := newLogCmdFilter()
+	if err != nil {
+		return
+	}
+
+	resps := instance.Do(geneos.GetHost(Hostname), ct, names, logEntriesInstance, filter)
+
+	var entries []logCmdEntry
+	for _, k := range slices.Sorted(maps.Keys(resps)) {
+		resp := resps[k]
+		if resp.Err != nil {
+			resp.Instance.Log().Error("cannot read log files", slog.Any("error", resp.Err))
+			continue
+		}
+		if e, ok := resp.Value.([]logCmdEntry); ok {
+			entries = append(entries, e...)
+		}
+	}
+
+	if logCmdSummary {
+		return logsSummary(w, resps)
+	}
+
+	if logCmdMerge {
+		slices.SortStableFunc(entries, func(a, b logCmdEntry) int {
+			return a.Time.Compare(b.Time)
+		})
+	}
+
+	if logCmdJSON || logCmdIndent {
+		if entries == nil {
+			entries = []logCmdEntry{}
+		}
+		j := json.NewEncoder(w)
+		j.SetEscapeHTML(false)
+		if logCmdIndent {
+			j.SetIndent("", "    ")
+		}
+		return j.Encode(entries)
+	}
+
+	var last string
+	for _, e := range entries {
+		switch {
+		case logCmdNoHeaders:
+			fmt.Fprintln(w, e.Text)
+		case logCmdMerge:
+			fmt.Fprintln(w, boldWhite.Sprint(e.inst.String()), e.Text)
+		default:
+			if last != e.inst.String()+":"+e.File {
+				if last != "" {
+					fmt.Fprintln(w)
+				}
+				boldWhite.Fprintf(w, "===> %s %s <===\n", e.inst, e.File)
+				last = e.inst.String() + ":" + e.File
+			}
+			fmt.Fprintln(w, e.Text)
+		}
+	}
+	return
+}
+
+// logsSummary writes a summary of the entries in each response to w
+func logsSummary(w io.Writer, resps responses.GeneralResponses) error {
+	for _, resp := range resps {
+		if resp.Err != nil {
+			continue
+		}
+		entries, _ := resp.Value.([]logCmdEntry)
+		i := resp.Instance
+		s := logCmdSummaryType{
+			Type: i.Type().String(),
+			Name: i.Name(),
+			Host: i.Host().String(),
+		}
+		var lastError time.Time
+		for _, e := range entries {
+			s.Entries++
+			switch e.Severity {
+			case instance.SeverityFatal:
+				s.Fatal++
+			case instance.SeverityError:
+				s.Errors++
+			case instance.SeverityWarning:
+				s.Warnings++
+			}
+			if e.Time.IsZero() {
+				continue
+			}
+			if s.First.IsZero() || e.Time.Before(s.First) {
+				s.First = e.Time
+			}
+			if e.Time.After(s.Last) {
+				s.Last = e.Time
+			}
+			if e.Severity >= instance.SeverityError && !e.Time.Before(lastError) {
+				lastError = e.Time
+				s.LastError, _, _ = strings.Cut(e.Message, "\n")
+			}
+		}
+		resp.Value = s
+		resp.Dataview.Table = [][]string{{
+			s.Type,
+			s.Name,
+			s.Host,
+			fmt.Sprint(s.Entries),
+			fmt.Sprint(s.Fatal),
+			fmt.Sprint(s.Errors),
+			fmt.Sprint(s.Warnings),
+			logCmdSummaryTime(s.First),
+			logCmdSummaryTime(s.Last),
+			s.LastError,
+		}}
+	}
+
+	if logCmdJSON || logCmdIndent {
+		return resps.Formatted(w, "json", nil, nil, responses.IndentJSON(logCmdIndent))
+	}
+	return resps.Formatted(w, "column", []string{
+		"Type",
+		"Name",
+		"Host",
+		"Entries",
+		"Fatal",
+		"Errors",
+		"Warnings",
+		"First",
+		"Last",
+		"Last Error",
+	}, nil)
+}
+
+func logCmdSummaryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
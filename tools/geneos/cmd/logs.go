@@ -43,6 +43,8 @@ var logCmdLines int
 var logCmdStderr, logCmdNoNormal, logCmdCALog, logCmdFollow, logCmdCat bool
 var logCmdMatch, logCmdIgnore string
 var logCmdNoHeaders bool
+var logCmdSince, logCmdUntil, logCmdSeverity string
+var logCmdMerge, logCmdJSON, logCmdIndent, logCmdSummary bool
 
 type files struct {
 	instance geneos.Instance
@@ -68,8 +70,20 @@ func init() {
 	logsCmd.Flags().StringVarP(&logCmdMatch, "match", "g", "", "Match lines with STRING")
 	logsCmd.Flags().StringVarP(&logCmdIgnore, "ignore", "v", "", "Match lines without STRING")
 
+	logsCmd.Flags().StringVarP(&logCmdSince, "since", "s", "", "Only show entries at or after `TIME`, a date/time or a duration ago like 2h")
+	logsCmd.Flags().StringVarP(&logCmdUntil, "until", "u", "", "Only show entries at or before `TIME`, a date/time or a duration ago")
+	logsCmd.Flags().StringVarP(&logCmdSeverity, "severity", "S", "", "Only show entries with a `SEVERITY` of at least\ndebug, info, warn, error or fatal")
+	logsCmd.Flags().BoolVarP(&logCmdMerge, "merge", "m", false, "Merge entries from all instances into one time ordered stream")
+	logsCmd.Flags().BoolVarP(&logCmdSummary, "summary", "y", false, "Summarise error and warning counts per instance")
+	logsCmd.Flags().BoolVarP(&logCmdJSON, "json", "j", false, "Output JSON")
+	logsCmd.Flags().BoolVarP(&logCmdIndent, "pretty", "i", false, "Output indented JSON")
+
 	logsCmd.MarkFlagsMutuallyExclusive("match", "ignore")
 	logsCmd.MarkFlagsMutuallyExclusive("cat", "follow")
+	for _, f := range []string{"since", "until", "severity", "merge", "summary", "json", "pretty"} {
+		logsCmd.MarkFlagsMutuallyExclusive("follow", f)
+	}
+	logsCmd.MarkFlagsMutuallyExclusive("merge", "summary")
 
 	logsCmd.Flags().SortFlags = false
 }
@@ -102,10 +116,14 @@ var logsCmd = &cobra.Command{
 			logCmdCat = true
 		}
 
-		if !term.IsTerminal(int(os.Stdout.Fd())) {
+		if !term.IsTerminal(int(os.Stdout.Fd())) || logCmdJSON || logCmdIndent {
 			color.NoColor = true
 		}
 
+		if logCmdStructured() {
+			return logsStructured(os.Stdout, ct, names)
+		}
+
 		switch {
 		case logCmdCat:
 			instance.Do(geneos.GetHost(Hostname), ct, names, logCatInstance).Report(os.Stdout, responses.SkipOnErr(false), responses.IgnoreErrs(fs.ErrNotExist))
@@ -0,0 +1,111 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenancecmd groups the commands that run and control the
+// maintenance window scheduler
+package maintenancecmd
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var log = cordial.Logger
+
+var maintenanceCmdSchedule string
+
+func init() {
+	cmd.Cmd.AddCommand(maintenanceCmd)
+
+	maintenanceCmd.PersistentFlags().StringVarP(&maintenanceCmdSchedule, "schedule", "f", "", "Read windows from schedule `FILE`, default `maintenance/windows.yaml`\nunder the Geneos home directory")
+}
+
+//go:embed README.md
+var maintenanceCmdDescription string
+
+var maintenanceCmd = &cobra.Command{
+	Use:          "maintenance",
+	GroupID:      cmd.CommandGroupSubsystems,
+	Short:        "Snooze Gateway Items During Maintenance Windows",
+	Long:         maintenanceCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		return command.Help()
+	},
+}
+
+// the scheduler files are kept in a `maintenance` directory under the
+// local Geneos home directory
+func scheduleFile() string {
+	if maintenanceCmdSchedule != "" {
+		return maintenanceCmdSchedule
+	}
+	return geneos.LOCAL.PathTo("maintenance", "windows.yaml")
+}
+
+func pidFile() string {
+	return geneos.LOCAL.PathTo("maintenance", "maintenance.pid")
+}
+
+func stateFile() string {
+	return geneos.LOCAL.PathTo("maintenance", "state.json")
+}
+
+func logFile() string {
+	return geneos.LOCAL.PathTo("maintenance", "maintenance.log")
+}
+
+// schedulerPID returns the PID of the running scheduler, or an error
+// wrapping geneos.ErrNotRunning if there is none
+func schedulerPID() (pid int, err error) {
+	b, err := geneos.LOCAL.ReadFile(pidFile())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("maintenance scheduler %w", geneos.ErrNotRunning)
+		}
+		return
+	}
+	if pid, err = strconv.Atoi(strings.TrimSpace(string(b))); err != nil {
+		return 0, fmt.Errorf("invalid PID file %q: %w", pidFile(), err)
+	}
+	p, err := os.FindProcess(pid)
+	if err == nil {
+		err = p.Signal(syscall.Signal(0))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("maintenance scheduler %w", geneos.ErrNotRunning)
+	}
+	return
+}
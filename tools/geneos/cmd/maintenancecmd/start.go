@@ -0,0 +1,121 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenancecmd
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/process"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/maintenance"
+)
+
+var startCmdDaemon bool
+var startCmdLogfile string
+var startCmdInterval time.Duration
+
+func init() {
+	maintenanceCmd.AddCommand(startCmd)
+
+	startCmd.Flags().BoolVarP(&startCmdDaemon, "daemon", "D", false, "Run the scheduler in the background")
+	startCmd.Flags().StringVarP(&startCmdLogfile, "logfile", "l", "", "Write logs to `FILE`, default STDERR or the\n`maintenance.log` file when run in the background")
+	startCmd.Flags().DurationVarP(&startCmdInterval, "interval", "i", 30*time.Second, "Check the schedule every `DURATION`")
+
+	startCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/start.md
+var startCmdDescription string
+
+var startCmd = &cobra.Command{
+	Use:   "start [flags]",
+	Short: "Run the Maintenance Window Scheduler",
+	Long:  startCmdDescription,
+	Example: `
+geneos maintenance start --daemon
+geneos maintenance start -D -f /etc/geneos/windows.yaml
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		// check the schedule before starting, so errors are reported
+		// to the user and not just to the log file
+		if _, err = maintenance.ReadSchedule(scheduleFile()); err != nil {
+			return
+		}
+
+		if pid, err := schedulerPID(); err == nil && pid != os.Getpid() {
+			return fmt.Errorf("maintenance scheduler already running with PID %d", pid)
+		}
+
+		if startCmdDaemon {
+			var logArgs []string
+			if startCmdLogfile == "" {
+				logArgs = append(logArgs, "--logfile", logFile())
+			}
+			if err = geneos.LOCAL.MkdirAll(path.Dir(logFile()), 0775); err != nil {
+				return
+			}
+			return process.Daemon(os.Stdout, logArgs, nil, "-D", "--daemon")
+		}
+
+		if startCmdLogfile != "" {
+			cordial.LogInit("cordial", cordial.SetLogfile(startCmdLogfile))
+		}
+
+		if err = geneos.LOCAL.MkdirAll(path.Dir(pidFile()), 0775); err != nil {
+			return
+		}
+		if err = geneos.LOCAL.WriteFile(pidFile(), []byte(strconv.Itoa(os.Getpid())+"\n"), 0664); err != nil {
+			return
+		}
+		defer geneos.LOCAL.Remove(pidFile())
+
+		s, err := maintenance.New(geneos.GetHost(cmd.Hostname), maintenance.Options{
+			ScheduleFile: scheduleFile(),
+			StateFile:    stateFile(),
+			Interval:     startCmdInterval,
+			Dial: func(i geneos.Instance) (maintenance.Snoozer, error) {
+				return cmd.GatewayConnection(i, "", nil)
+			},
+		})
+		if err != nil {
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return s.Run(ctx)
+	},
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenancecmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/reporter"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/maintenance"
+)
+
+var statusCmdJSON, statusCmdIndent, statusCmdSnoozed bool
+var statusCmdFormat string
+var statusCmdUpcoming time.Duration
+
+func init() {
+	maintenanceCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().DurationVarP(&statusCmdUpcoming, "upcoming", "u", 7*24*time.Hour, "Show windows starting within `DURATION` as upcoming")
+	statusCmd.Flags().BoolVarP(&statusCmdSnoozed, "snoozed", "s", false, "Show the items snoozed by the scheduler instead of the windows")
+
+	statusCmd.Flags().BoolVarP(&statusCmdJSON, "json", "j", false, "Output JSON")
+	statusCmd.Flags().BoolVarP(&statusCmdIndent, "pretty", "i", false, "Output indented JSON")
+	statusCmd.Flags().StringVarP(&statusCmdFormat, "format", "F", "table", "Output a table in `FORMAT`, one of 'table', 'csv', 'tsv', 'toolkit', 'markdown', 'html' or 'xlsx'")
+
+	statusCmd.MarkFlagsMutuallyExclusive("json", "pretty", "format")
+	statusCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/status.md
+var statusCmdDescription string
+
+var statusCmd = &cobra.Command{
+	Use:     "status [flags]",
+	Aliases: []string{"list", "ls"},
+	Short:   "Show Maintenance Windows and Scheduler Status",
+	Long:    statusCmdDescription,
+	Example: `
+geneos maintenance status
+geneos maintenance status --snoozed -F csv
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		schedule, err := maintenance.ReadSchedule(scheduleFile())
+		if err != nil {
+			return
+		}
+
+		state := "running"
+		pid, err := schedulerPID()
+		if err != nil {
+			log.Debug("maintenance scheduler", slog.Any("error", err))
+			state = "stopped"
+			pid = 0
+		}
+		st, err := maintenance.ReadState(stateFile())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		if st.Snoozed == nil {
+			st.Snoozed = []*maintenance.Snoozed{}
+		}
+
+		statuses := maintenance.Statuses(schedule.Windows, st, time.Now(), statusCmdUpcoming)
+		if statuses == nil {
+			statuses = []maintenance.WindowStatus{}
+		}
+
+		switch {
+		case statusCmdJSON, statusCmdIndent:
+			j := json.NewEncoder(os.Stdout)
+			j.SetEscapeHTML(false)
+			if statusCmdIndent {
+				j.SetIndent("", "    ")
+			}
+			if statusCmdSnoozed {
+				return j.Encode(st.Snoozed)
+			}
+			return j.Encode(struct {
+				State   string                     `json:"state"`
+				PID     int                        `json:"pid"`
+				Updated time.Time                  `json:"updated,omitzero"`
+				Windows []maintenance.WindowStatus `json:"windows"`
+			}{state, pid, st.Updated, statuses})
+		default:
+			r, err := reporter.NewReporter(statusCmdFormat, os.Stdout)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			if err = r.Prepare(reporter.Report{Name: "maintenance", Title: "Maintenance Windows"}); err != nil {
+				return err
+			}
+			r.AddHeadlines(map[string]string{
+				"state":    state,
+				"pid":      fmt.Sprint(pid),
+				"schedule": scheduleFile(),
+				"updated":  statusTime(st.Updated),
+			})
+			if statusCmdSnoozed {
+				r.UpdateTable(snoozedTable(st))
+			} else {
+				r.UpdateTable(statusTable(statuses))
+			}
+			r.Render()
+		}
+		return nil
+	},
+}
+
+// statusTable returns the columns and rows for a reporter, with one row
+// per window
+func statusTable(statuses []maintenance.WindowStatus) (columns []string, rows [][]string) {
+	columns = []string{"window", "status", "start", "end", "repeat", "gateways", "targets", "snoozed", "reason"}
+	for _, ws := range statuses {
+		gateways := strings.Join(ws.Gateways, ", ")
+		if gateways == "" {
+			gateways = "all"
+		}
+		rows = append(rows, []string{
+			ws.Name,
+			ws.Status,
+			statusTime(ws.Start),
+			statusTime(ws.End),
+			string(ws.Repeat),
+			gateways,
+			fmt.Sprint(ws.Targets),
+			fmt.Sprint(ws.Snoozed),
+			ws.Reason,
+		})
+	}
+	return
+}
+
+// snoozedTable returns the columns and rows for a reporter, with one
+// row per snoozed item
+func snoozedTable(st maintenance.State) (columns []string, rows [][]string) {
+	columns = []string{"item", "gateway", "target", "windows", "since", "until"}
+	for _, sn := range st.Snoozed {
+		rows = append(rows, []string{
+			sn.Gateway + " " + sn.Target,
+			sn.Gateway,
+			sn.Target,
+			strings.Join(sn.Windows, ", "),
+			statusTime(sn.Since),
+			statusTime(sn.Until),
+		})
+	}
+	return
+}
+
+func statusTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
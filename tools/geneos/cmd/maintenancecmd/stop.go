@@ -0,0 +1,65 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenancecmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+)
+
+func init() {
+	maintenanceCmd.AddCommand(stopCmd)
+}
+
+var stopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "Stop the Maintenance Window Scheduler",
+	Long:         "Stop the running scheduler. Items snoozed for windows that are still active stay snoozed and are unsnoozed by the scheduler after it is started again, if their windows have ended.",
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		pid, err := schedulerPID()
+		if err != nil {
+			return
+		}
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return
+		}
+		if err = p.Signal(syscall.SIGTERM); err != nil {
+			return
+		}
+		for range 20 {
+			time.Sleep(250 * time.Millisecond)
+			if _, err := schedulerPID(); err != nil {
+				fmt.Printf("maintenance scheduler with PID %d stopped\n", pid)
+				return nil
+			}
+		}
+		return fmt.Errorf("maintenance scheduler with PID %d did not stop", pid)
+	},
+}
@@ -0,0 +1,310 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkgcmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/process"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+)
+
+// releaseLink is the base link for one package type on one host
+type releaseLink struct {
+	host *geneos.Host
+	ct   *geneos.Component
+}
+
+// path returns the full path to the base link
+func (l releaseLink) path(base string) string {
+	return path.Join(l.host.PathTo("packages", l.ct.String()), base)
+}
+
+// packageType returns the package component type for instance i,
+// which is the `pkgtype` parameter if set, otherwise the parent type
+// for components that share another's packages, otherwise the
+// instance type.
+func packageType(i geneos.Instance) *geneos.Component {
+	if pt := config.Get[string](i.Config(), "pkgtype"); pt != "" {
+		if ct := geneos.ParseComponent(pt); ct != nil {
+			return ct
+		}
+	}
+	ct := i.Type()
+	if ct.ParentType != nil && len(ct.PackageTypes) > 0 {
+		return ct.ParentType
+	}
+	return ct
+}
+
+// rollingOptions control the rolling update
+type rollingOptions struct {
+	base     string
+	batch    int
+	timeout  time.Duration
+	rollback bool
+	force    bool
+}
+
+// rollingUpdate updates the base links for component ct on host h, as
+// geneos.Update does, and then restarts the running instances in
+// batches of opts.batch, waiting for each instance in a batch to be
+// live before moving on to the next batch.
+//
+// Gateways in a standby pair, see standbyPartner, are never restarted
+// in the same batch as their partner, and all the first sides of these
+// pairs are restarted before any of the second sides.
+//
+// If any instance in a batch fails to start, or is not live before
+// opts.timeout, then, if opts.rollback is set, the base links are
+// returned to the previous release and all instances already restarted
+// are restarted again on that release.
+func rollingUpdate(h *geneos.Host, ct *geneos.Component, instances []geneos.Instance, opts rollingOptions, options ...geneos.PackageOption) (err error) {
+	// record the existing release for each base link in use by the
+	// instances, so that we can roll back
+	previous := make(map[releaseLink]string)
+	for _, i := range instances {
+		l := releaseLink{host: i.Host(), ct: packageType(i)}
+		if _, ok := previous[l]; ok {
+			continue
+		}
+		if previous[l], err = l.host.Readlink(l.path(opts.base)); err != nil {
+			log.Debug("cannot read existing base link", slog.String("link", l.path(opts.base)), slog.Any("error", err))
+			previous[l] = ""
+			err = nil
+		}
+	}
+
+	// update the links only, the running instances are left on the old
+	// release until they are restarted below
+	if err = geneos.Update(h, ct, options...); err != nil {
+		return
+	}
+
+	// only instances whose release has changed need a restart
+	instances = slices.DeleteFunc(slices.Clone(instances), func(i geneos.Instance) bool {
+		l := releaseLink{host: i.Host(), ct: packageType(i)}
+		current, _ := l.host.Readlink(l.path(opts.base))
+		return current == previous[l]
+	})
+	if len(instances) == 0 {
+		fmt.Println("no running instances require a restart")
+		return
+	}
+
+	batches := rollingBatches(instances, opts.batch)
+
+	var done []geneos.Instance
+	for n, batch := range batches {
+		fmt.Printf("batch %d/%d: restarting %d instance(s)\n", n+1, len(batches), len(batch))
+		if err = restartBatch(batch, opts); err == nil {
+			done = append(done, batch...)
+			continue
+		}
+
+		fmt.Printf("batch %d/%d failed: %s\n", n+1, len(batches), err)
+		if !opts.rollback {
+			return
+		}
+		return errors.Join(err, rollback(previous, append(done, batch...), opts))
+	}
+
+	fmt.Printf("rolling update complete, %d instance(s) restarted\n", len(done))
+	return
+}
+
+// rollingBatches splits instances into batches of up to size instances.
+// Standby pairs of gateways are split so that the first of each pair
+// found is restarted in the first phase and the partner in the second,
+// with batches never spanning the two phases. Gateways that are not
+// in a pair are reported, as restarting them interrupts service.
+func rollingBatches(instances []geneos.Instance, size int) (batches [][]geneos.Instance) {
+	if size < 1 {
+		size = 1
+	}
+
+	var first, second []geneos.Instance
+	for _, i := range instances {
+		if slices.Contains(second, i) {
+			continue
+		}
+		first = append(first, i)
+		partner, suspected := standbyPartner(i, instances)
+		switch {
+		case partner == nil:
+			if i.Type().IsA("gateway") && config.Get[string](i.Config(), "standby") == "" {
+				log.Warn("gateway has no standby pairing, set the `standby` parameter if it is one side of a hot standby pair", slog.String("gateway", i.String()))
+			}
+		case slices.Contains(first, partner):
+			// already in the first phase as the partner of another
+		default:
+			if suspected {
+				fmt.Printf("%s and %s have the same name on different hosts and are treated as a standby pair\n", i, partner)
+			}
+			second = append(second, partner)
+		}
+	}
+
+	for _, phase := range [][]geneos.Instance{first, second} {
+		for chunk := range slices.Chunk(phase, size) {
+			batches = append(batches, chunk)
+		}
+	}
+	return
+}
+
+// standbyPartner returns the standby partner of gateway i in
+// instances, or nil if there is none. The partner is the gateway named
+// in the `standby` parameter of i, or the gateway whose `standby`
+// parameter names i, either as a plain name for an instance on the
+// same host or as NAME@HOST.
+//
+// If neither side has a `standby` parameter then a gateway with the
+// same name on another host, as created by `geneos copy gateway NAME
+// @HOST`, is returned as a suspected partner, with suspected set to
+// true.
+func standbyPartner(i geneos.Instance, instances []geneos.Instance) (partner geneos.Instance, suspected bool) {
+	if !i.Type().IsA("gateway") {
+		return
+	}
+
+	names := func(a, b geneos.Instance) bool {
+		standby := config.Get[string](a.Config(), "standby")
+		if standby == "" {
+			return false
+		}
+		h, _, name := instance.ParseName(standby, a.Host())
+		return b.Host() == h && b.Name() == name
+	}
+
+	paired := config.Get[string](i.Config(), "standby") != ""
+	for _, p := range instances {
+		if p == i || p.Type() != i.Type() {
+			continue
+		}
+		if names(i, p) || names(p, i) {
+			return p, false
+		}
+	}
+	if paired {
+		// the partner is not in instances
+		return
+	}
+
+	for _, p := range instances {
+		if p != i && p.Type() == i.Type() && p.Name() == i.Name() && p.Host() != i.Host() &&
+			config.Get[string](p.Config(), "standby") == "" {
+			return p, true
+		}
+	}
+	return
+}
+
+// restartBatch stops and starts all the instances in batch and then
+// waits for each to be live. All instances are restarted before any
+// checks are made.
+func restartBatch(batch []geneos.Instance, opts rollingOptions) (err error) {
+	var errs []error
+	for _, i := range batch {
+		if err := instance.Stop(i, opts.force, false); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			errs = append(errs, fmt.Errorf("%s: %w", i, err))
+			continue
+		}
+		if err := instance.Start(i); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, i := range batch {
+		if err := waitLive(i, opts.timeout); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", i, err))
+			continue
+		}
+		fmt.Printf("%s is live\n", i)
+	}
+	return errors.Join(errs...)
+}
+
+// waitLive waits up to timeout for instance i to be running and, if
+// it has a configured port, for that port to be in the listening ports
+// of the process.
+func waitLive(i geneos.Instance, timeout time.Duration) error {
+	port := config.Get[int](i.Config(), "port")
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pid, err := instance.GetLivePID(i)
+		if err == nil && (port == 0 || slices.Contains(process.ListeningPorts(i.Host(), pid), port)) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("not running after %s: %w", timeout, err)
+			}
+			return fmt.Errorf("not listening on port %d after %s", port, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// rollback returns each base link to its previous release, if that is
+// still one of the installed releases, and then restarts the instances.
+func rollback(previous map[releaseLink]string, instances []geneos.Instance, opts rollingOptions) (err error) {
+	var errs []error
+
+	for l, version := range previous {
+		if version == "" {
+			continue
+		}
+		releases, err := geneos.InstalledReleases(l.host, l.ct)
+		if err != nil || !slices.Contains(releases, version) {
+			errs = append(errs, fmt.Errorf("cannot roll back %s on %s: release %q is not installed", l.ct, l.host, version))
+			continue
+		}
+		p := l.path(opts.base)
+		if current, _ := l.host.Readlink(p); current == version {
+			continue
+		}
+		if err = l.host.Remove(p); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = l.host.Symlink(version, p); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("%s release on %s %q rolled back to %s\n", l.ct, l.host, opts.base, version)
+	}
+
+	// restart everything touched so far, as a single batch
+	if err = restartBatch(instances, opts); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
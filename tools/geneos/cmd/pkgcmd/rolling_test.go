@@ -0,0 +1,117 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkgcmd
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// testInstance is a minimal instance with only a name, host, type and
+// configuration
+type testInstance struct {
+	name string
+	host *geneos.Host
+	ct   *geneos.Component
+	cf   *config.Config
+}
+
+var (
+	testGateway  = &geneos.Component{Name: "gateway"}
+	testNetprobe = &geneos.Component{Name: "netprobe"}
+	testHost1    = &geneos.Host{}
+	testHost2    = &geneos.Host{}
+)
+
+func newTestInstance(ct *geneos.Component, name string, h *geneos.Host, standby string) *testInstance {
+	cf := config.New()
+	if standby != "" {
+		config.Set(cf, "standby", standby)
+	}
+	return &testInstance{name: name, host: h, ct: ct, cf: cf}
+}
+
+func (t *testInstance) Config() *config.Config         { return t.cf }
+func (t *testInstance) SetConfig(cf *config.Config)    { t.cf = cf }
+func (t *testInstance) Name() string                   { return t.name }
+func (t *testInstance) Home() string                   { return "" }
+func (t *testInstance) Type() *geneos.Component        { return t.ct }
+func (t *testInstance) Host() *geneos.Host             { return t.host }
+func (t *testInstance) Log() *slog.Logger              { return slog.Default() }
+func (t *testInstance) String() string                 { return t.ct.String() + " " + t.name }
+func (t *testInstance) Load() error                    { return nil }
+func (t *testInstance) Unload() error                  { return nil }
+func (t *testInstance) Loaded() time.Time              { return time.Time{} }
+func (t *testInstance) SetLoaded(time.Time)            {}
+func (t *testInstance) Add(string, uint16, bool) error { return nil }
+func (t *testInstance) Command(bool) ([]string, []string, string, error) {
+	return nil, nil, "", nil
+}
+func (t *testInstance) Reload() error      { return nil }
+func (t *testInstance) Rebuild(bool) error { return nil }
+
+func TestStandbyPartner(t *testing.T) {
+	primary := newTestInstance(testGateway, "GW1", testHost1, "GW1-standby")
+	standby := newTestInstance(testGateway, "GW1-standby", testHost1, "")
+	gw2a := newTestInstance(testGateway, "GW2", testHost1, "")
+	gw2b := newTestInstance(testGateway, "GW2", testHost2, "")
+	named := newTestInstance(testGateway, "GW3", testHost1, "elsewhere")
+	other := newTestInstance(testGateway, "GW3", testHost2, "")
+	probe1 := newTestInstance(testNetprobe, "p1", testHost1, "")
+	probe2 := newTestInstance(testNetprobe, "p1", testHost2, "")
+	instances := []geneos.Instance{primary, standby, gw2a, gw2b, named, other, probe1, probe2}
+
+	for _, tc := range []struct {
+		name      string
+		i         geneos.Instance
+		partner   geneos.Instance
+		suspected bool
+	}{
+		{"standby parameter", primary, standby, false},
+		{"named by partner", standby, primary, false},
+		{"same name on another host", gw2a, gw2b, true},
+		{"same name, other side", gw2b, gw2a, true},
+		{"standby parameter not in update", named, nil, false},
+		{"partner has a standby parameter", other, nil, false},
+		{"not a gateway", probe1, nil, false},
+	} {
+		partner, suspected := standbyPartner(tc.i, instances)
+		if partner != tc.partner || suspected != tc.suspected {
+			t.Errorf("%s: standbyPartner() = %v, %v, want %v, %v", tc.name, partner, suspected, tc.partner, tc.suspected)
+		}
+	}
+}
+
+func TestRollingBatches(t *testing.T) {
+	primary := newTestInstance(testGateway, "GW1", testHost1, "GW1-standby")
+	standby := newTestInstance(testGateway, "GW1-standby", testHost1, "")
+	gw2a := newTestInstance(testGateway, "GW2", testHost1, "")
+	gw2b := newTestInstance(testGateway, "GW2", testHost2, "")
+	probe := newTestInstance(testNetprobe, "p1", testHost1, "")
+
+	batches := rollingBatches([]geneos.Instance{standby, gw2a, probe, primary, gw2b}, 2)
+	want := [][]geneos.Instance{{standby, gw2a}, {probe}, {primary, gw2b}}
+	if !slices.EqualFunc(batches, want, slices.Equal) {
+		t.Errorf("rollingBatches() = %v, want %v", batches, want)
+	}
+}
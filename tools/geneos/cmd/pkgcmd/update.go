@@ -24,6 +24,7 @@ import (
 	"io/fs"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -36,6 +37,9 @@ import (
 
 var updateCmdBase, updateCmdVersion string
 var updateCmdForce, updateCmdRestart, updateCmdInstall bool
+var updateCmdRolling, updateCmdNoRollback bool
+var updateCmdBatch int
+var updateCmdTimeout time.Duration
 
 func init() {
 	packageCmd.AddCommand(updateCmd)
@@ -50,6 +54,11 @@ func init() {
 
 	updateCmd.Flags().BoolVarP(&updateCmdForce, "force", "F", false, "Will also update and restart protected instances")
 
+	updateCmd.Flags().BoolVar(&updateCmdRolling, "rolling", false, "Restart running instances in batches, waiting for each batch\nto be live before continuing")
+	updateCmd.Flags().IntVar(&updateCmdBatch, "batch", 1, "Number of instances to restart in each batch of a rolling update")
+	updateCmd.Flags().DurationVar(&updateCmdTimeout, "timeout", 60*time.Second, "How long to wait for each instance to be live in a rolling update")
+	updateCmd.Flags().BoolVar(&updateCmdNoRollback, "no-rollback", false, "Do not roll back to the previous release if a rolling update fails")
+
 	updateCmd.Flags().SortFlags = false
 }
 
@@ -66,6 +75,7 @@ geneos package update gateway -b active_prod
 geneos package update gateway -b active_dev -V 5.11
 geneos package update
 geneos package update netprobe --version 5.13.2
+geneos package update gateway --rolling --batch 2 --timeout 2m
 `, "|", "`"),
 	SilenceUsage: true,
 	Annotations: map[string]string{
@@ -139,6 +149,27 @@ geneos package update netprobe --version 5.13.2
 			log.Debug("instances to restart", slog.Any("instances", instances))
 		}
 
+		if updateCmdRolling {
+			// only running instances are restarted in a rolling update
+			running := []geneos.Instance{}
+			for _, i := range instances {
+				if instance.IsRunning(i) {
+					running = append(running, i)
+				}
+			}
+			return rollingUpdate(h, ct, running, rollingOptions{
+				base:     updateCmdBase,
+				batch:    updateCmdBatch,
+				timeout:  updateCmdTimeout,
+				rollback: !updateCmdNoRollback,
+				force:    updateCmdForce,
+			},
+				geneos.Version(version),
+				geneos.Basename(updateCmdBase),
+				geneos.Force(true),
+			)
+		}
+
 		return geneos.Update(h, ct,
 			geneos.Version(version),
 			geneos.Basename(updateCmdBase),
@@ -0,0 +1,133 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strings"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/profiles"
+	"github.com/itrs-group/cordial/tools/geneos/internal/values"
+)
+
+// ProfileVars returns the built-in variables available when expanding
+// a deployment profile for host h, plus any NAME=VALUE pairs in extra,
+// which override the built-in ones. name is the value of `${name}`
+// and defaults to the hostname.
+//
+//   - `${hostname}` - the hostname of h
+//   - `${host}` - the geneos host name of h, e.g. `localhost`
+//   - `${name}` - name, or the hostname if empty
+//   - `${geneos}` - the Geneos installation directory on h
+//   - `${user}` - the current user name
+func ProfileVars(h *geneos.Host, name string, extra []string) (vars map[string]string) {
+	if name == "" {
+		name = h.Hostname()
+	}
+	vars = map[string]string{
+		"hostname": h.Hostname(),
+		"host":     h.String(),
+		"name":     name,
+		"geneos":   config.Get[string](h.Config, cordial.ExecutableName()),
+	}
+	if u, err := user.Current(); err == nil {
+		vars["user"] = u.Username
+	}
+	for _, e := range extra {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			vars[k] = v
+		}
+	}
+	return
+}
+
+// DeployProfile creates the instances in profile p on host h. If ct is
+// not nil then only instances of that type are created. The release
+// for each component type used is installed, if not already, using
+// options. Existing instances are left unchanged. If start is true then
+// each new instance is started after it is created.
+func DeployProfile(h *geneos.Host, p *profiles.Profile, ct *geneos.Component, start bool, options ...geneos.PackageOption) (err error) {
+	installed := map[*geneos.Component]bool{}
+
+	for _, pct := range p.Components {
+		if ct != nil && !pct.IsA(ct.Name) {
+			continue
+		}
+		for _, pi := range p.Instances[pct] {
+			ih, pkgct, _ := instance.ParseName(pi.Name, h)
+			if ih == geneos.ALL {
+				ih = h
+			}
+			if pkgct == nil {
+				if pct.ParentType != nil && len(pct.PackageTypes) > 0 {
+					pkgct = pct.ParentType
+				} else {
+					pkgct = pct
+				}
+			}
+
+			if !installed[pkgct] {
+				if err = pkgct.MakeDirs(ih); err != nil {
+					return
+				}
+				if err = geneos.Install(ih, pkgct, options...); err != nil && !errors.Is(err, fs.ErrExist) {
+					return fmt.Errorf("profile %q: installing %s: %w", p.Name, pkgct, err)
+				}
+				err = nil
+				installed[pkgct] = true
+			}
+
+			var extras values.Values
+			extras, err = pi.Values()
+			if err != nil {
+				return fmt.Errorf("profile %q: %s %q: %w", p.Name, pct, pi.Name, err)
+			}
+
+			name := pi.Name
+			if !strings.Contains(name, "@") {
+				name += "@" + ih.String()
+			}
+
+			base := pi.Version
+			if base == "" {
+				base = "active_prod"
+			}
+
+			if err = AddInstance(pct, name, pi.Port, extras,
+				Template(pi.Template),
+				Base(base),
+				Insecure(pi.Insecure),
+				CertBundle(pi.CertBundle),
+				Keyfile(pi.Keyfile),
+				KeyfileCRC(pi.KeyfileCRC),
+				Imports(pi.Imports),
+				StartAfterAdd(start),
+			); err != nil {
+				return fmt.Errorf("profile %q: %s %q: %w", p.Name, pct, pi.Name, err)
+			}
+		}
+	}
+	return
+}
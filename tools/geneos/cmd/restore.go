@@ -26,17 +26,19 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/tools/geneos/internal/backup"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
 	"github.com/itrs-group/cordial/tools/geneos/internal/restore"
 )
 
 var restoreCmdCompression string
-var restoreCmdShared, restoreCmdList bool
+var restoreCmdShared, restoreCmdList, restoreCmdVerify bool
 
 func init() {
 	Cmd.AddCommand(restoreCmd)
@@ -47,6 +49,8 @@ func init() {
 
 	restoreCmd.Flags().BoolVarP(&restoreCmdList, "list", "l", false, "list the contents of the archive(s)")
 
+	restoreCmd.Flags().BoolVarP(&restoreCmdVerify, "verify", "V", false, "verify the archive(s), and any full archive an incremental\narchive is based on, against their manifests and do not restore")
+
 	restoreCmd.Flags().SortFlags = false
 }
 
@@ -69,6 +73,7 @@ var restoreCmd = &cobra.Command{
 	Example: strings.ReplaceAll(`
 geneos restore backup.tgz
 geneos restore gateway ABC x.tgz
+geneos restore --verify /backups/geneos-nightly-20260101020000-incremental.tar.gz
 `, "|", "`"),
 	SilenceUsage: true,
 	Annotations: map[string]string{
@@ -86,6 +91,10 @@ geneos restore gateway ABC x.tgz
 
 		names = append(names, params...)
 
+		if restoreCmdVerify {
+			return verifyArchives(args)
+		}
+
 		if !restoreCmdList && len(args) == 0 {
 			return command.Usage()
 		}
@@ -167,3 +176,36 @@ geneos restore gateway ABC x.tgz
 		return
 	},
 }
+
+// verifyArchives checks each of the archive files against its manifest
+// and reports the result
+func verifyArchives(files []string) (err error) {
+	if len(files) == 0 {
+		return fmt.Errorf("no archives given")
+	}
+	var errs []error
+	for _, f := range files {
+		s, name, err := backup.Location(f)
+		if err != nil {
+			fmt.Printf("%s: %s\n", f, err)
+			errs = append(errs, err)
+			continue
+		}
+		m, err := backup.Verify(s, name, restoreCmdCompression)
+		if err != nil {
+			fmt.Printf("%s: verification failed: %s\n", f, err)
+			errs = append(errs, err)
+			continue
+		}
+		switch m.Kind {
+		case backup.Incremental:
+			fmt.Printf("%s: %s archive of %d entries created %s, based on %s, verified\n", f, m.Kind, len(m.Files), m.Created.Local().Format(time.RFC3339), m.Base)
+		default:
+			fmt.Printf("%s: %s archive of %d entries created %s, verified\n", f, m.Kind, len(m.Files), m.Created.Local().Format(time.RFC3339))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d archives failed verification", len(errs), len(files))
+	}
+	return
+}
@@ -35,11 +35,14 @@ import (
 )
 
 var revertCmdExecutables bool
+var revertCmdRevision int
 
 func init() {
 	Cmd.AddCommand(revertCmd)
 
 	revertCmd.Flags().BoolVarP(&revertCmdExecutables, "executables", "X", false, "Revert 'ctl' executables")
+	revertCmd.Flags().IntVarP(&revertCmdRevision, "revision", "r", 0, "Roll back instance configurations to revision `REV`, see `geneos history`")
+	revertCmd.MarkFlagsMutuallyExclusive("executables", "revision")
 	revertCmd.Flags().SortFlags = false
 }
 
@@ -47,7 +50,7 @@ func init() {
 var revertCmdDescription string
 
 var revertCmd = &cobra.Command{
-	Use:          "revert [--executables|-X] | [TYPE] [NAME...]",
+	Use:          "revert [--executables|-X] | [--revision|-r REV] [TYPE] [NAME...]",
 	GroupID:      CommandGroupConfig,
 	Short:        "Revert Migrated Instance Configuration",
 	Long:         revertCmdDescription,
@@ -69,6 +72,17 @@ var revertCmd = &cobra.Command{
 			revertCommands()
 			return
 		}
+		if revertCmdRevision > 0 {
+			instance.Do(geneos.GetHost(Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
+				if instance.IsProtected(i) {
+					resp = responses.New[responses.General](i)
+					resp.Err = geneos.ErrProtected
+					return
+				}
+				return instance.Rollback(i, revertCmdRevision)
+			}).Report(os.Stdout)
+			return
+		}
 		instance.Do(geneos.GetHost(Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
 			resp = responses.New[responses.General](i)
 
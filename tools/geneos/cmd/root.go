@@ -27,12 +27,14 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/host"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 )
 
@@ -50,6 +52,9 @@ var UserKeyFile = geneos.DefaultUserKeyfile
 
 var debug, quiet bool
 
+// sshMetrics is set by the --ssh-metrics flag
+var sshMetrics bool
+
 var GeneosUnsetError = errors.New(strings.ReplaceAll(`Geneos location not set.
 
 You can do one of the following:
@@ -86,6 +91,7 @@ func init() {
 	Cmd.PersistentFlags().MarkHidden("debug")
 	Cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "quiet mode")
 	Cmd.PersistentFlags().MarkHidden("quiet")
+	Cmd.PersistentFlags().BoolVar(&sshMetrics, "ssh-metrics", false, "Write SSH connection metrics for the remote hosts used to STDERR on exit")
 
 	// how to remove the help flag help text from the help output! Sigh...
 	Cmd.PersistentFlags().BoolP("help", "h", false, "Print usage")
@@ -277,11 +283,31 @@ func Execute() {
 	cordial.RenderHelpAsMD(Cmd)
 
 	err := Cmd.Execute()
+	closeRemotes()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// closeRemotes logs the SSH connection pool metrics for each remote
+// used, at debug level, and writes them to STDERR if the --ssh-metrics
+// flag is set, and closes the connections
+func closeRemotes() {
+	metrics := host.SSHPoolMetrics()
+	for _, s := range metrics {
+		log.Debug("ssh pool", slog.Any("remote", s))
+	}
+	if sshMetrics && len(metrics) > 0 {
+		w := tabwriter.NewWriter(os.Stderr, 3, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "Remote\tConnected\tDials\tReconnects\tFailures\tKeepAliveFailures\tSessionsOpened\tMaxSessions\tSessionWaits\n")
+		for _, s := range metrics {
+			fmt.Fprintf(w, "%s\t%v\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", s.Name, s.Connected, s.Dials, s.Reconnects, s.Failures, s.KeepAliveFailures, s.SessionsOpened, s.MaxSessions, s.SessionWaits)
+		}
+		w.Flush()
+	}
+	host.CloseSSHPool()
+}
+
 // catch misspelling and abbreviations of common flags
 func cmdNormalizeFunc(f *pflag.FlagSet, name string) pflag.NormalizedName {
 	switch name {
@@ -357,11 +383,7 @@ func initConfig() {
 	geneos.InitHosts(cordial.ExecutableName())
 	log.Debug("hosts loaded")
 
-	// TODO ignore profiles for now
-	// _, err = profiles.Load()
-	// if err != nil {
-	// 	log.Fatal().Err(err).Msg("failed to load profiles")
-	// }
+	// deployment profiles are loaded on demand by `init` and `deploy`
 }
 
 // RunE runs a command in a sub-package to avoid import loops. It is
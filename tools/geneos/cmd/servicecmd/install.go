@@ -0,0 +1,86 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicecmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+var installCmdSystem bool
+
+func init() {
+	serviceCmd.AddCommand(installCmd)
+
+	installCmd.Flags().BoolVarP(&installCmdSystem, "system", "S", false, "Install system units instead of user units")
+	installCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/install.md
+var installCmdDescription string
+
+var installCmd = &cobra.Command{
+	Use:   "install [flags] [TYPE] [NAME...]",
+	Short: "Install systemd Units for Instances",
+	Long:  installCmdDescription,
+	Example: `
+geneos service install gateway
+sudo geneos service install --system --allow-root netprobe localhost
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:               "true",
+		cmd.CmdRequireHome:          "true",
+		cmd.CmdWildcardNames:        "true",
+		cmd.CmdAllowRoot:            "true",
+		cmd.CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, _, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+		instance.Do(geneos.GetHost(cmd.Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
+			resp = responses.New[responses.General](i)
+			if i.Type() == &geneos.RootComponent {
+				return
+			}
+			p, err := instance.InstallUnit(i, installCmdSystem)
+			if p != "" {
+				resp.Completed = append(resp.Completed, fmt.Sprintf("wrote %s", i.Host().HostPath(p)))
+			}
+			if err != nil {
+				resp.Err = err
+				return
+			}
+			if instance.IsAutoStart(i) && !instance.IsDisabled(i) {
+				resp.Completed = append(resp.Completed, "enabled")
+			}
+			return
+		}).Report(os.Stdout)
+		return
+	},
+}
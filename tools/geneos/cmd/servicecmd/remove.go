@@ -0,0 +1,67 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicecmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+func init() {
+	serviceCmd.AddCommand(removeCmd)
+}
+
+var removeCmd = &cobra.Command{
+	Use:     "remove [TYPE] [NAME...]",
+	Aliases: []string{"rm", "uninstall"},
+	Short:   "Remove systemd Units for Instances",
+	Long: `Disable and remove any system and user systemd units for the matching instances.
+
+Instances are not stopped, so if an instance was started by systemd it keeps running until stopped with ` + "`geneos stop`" + `.`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:               "false",
+		cmd.CmdRequireHome:          "true",
+		cmd.CmdWildcardNames:        "true",
+		cmd.CmdAllowRoot:            "true",
+		cmd.CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, _, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+		instance.Do(geneos.GetHost(cmd.Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
+			resp = responses.New[responses.General](i)
+			units, err := instance.RemoveUnits(i)
+			for _, u := range units {
+				resp.Completed = append(resp.Completed, fmt.Sprintf("removed %s", i.Host().HostPath(u)))
+			}
+			resp.Err = err
+			return
+		}).Report(os.Stdout)
+		return
+	},
+}
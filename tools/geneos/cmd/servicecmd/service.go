@@ -0,0 +1,52 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicecmd groups the commands that manage systemd units for
+// instances
+package servicecmd
+
+import (
+	_ "embed"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+)
+
+func init() {
+	cmd.Cmd.AddCommand(serviceCmd)
+}
+
+//go:embed README.md
+var serviceCmdDescription string
+
+var serviceCmd = &cobra.Command{
+	Use:          "service",
+	GroupID:      cmd.CommandGroupSubsystems,
+	Short:        "Manage systemd Units for Instances",
+	Long:         serviceCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		return command.Help()
+	},
+}
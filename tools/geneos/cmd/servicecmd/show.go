@@ -0,0 +1,79 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicecmd
+
+import (
+	_ "embed"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+var showCmdSystem bool
+
+func init() {
+	serviceCmd.AddCommand(showCmd)
+
+	showCmd.Flags().BoolVarP(&showCmdSystem, "system", "S", false, "Show system units instead of user units")
+	showCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/show.md
+var showCmdDescription string
+
+var showCmd = &cobra.Command{
+	Use:          "show [flags] [TYPE] [NAME...]",
+	Short:        "Show systemd Units for Instances",
+	Long:         showCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:               "true",
+		cmd.CmdRequireHome:          "true",
+		cmd.CmdWildcardNames:        "true",
+		cmd.CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, _, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+		instance.Do(geneos.GetHost(cmd.Hostname), ct, names, func(i geneos.Instance, _ ...any) (resp *responses.General) {
+			resp = responses.New[responses.General](i)
+			unit, env, err := instance.SystemdUnit(i, showCmdSystem, true)
+			if err != nil {
+				resp.Err = err
+				return
+			}
+			p, _ := instance.UnitPath(i, showCmdSystem)
+			resp.ResultText = append([]string{"# " + i.Host().HostPath(p)}, strings.Split(strings.TrimSpace(unit), "\n")...)
+			resp.ResultText = append(resp.ResultText, "", "# "+i.Host().HostPath(instance.UnitEnvPath(i)))
+			if env != "" {
+				resp.ResultText = append(resp.ResultText, strings.Split(strings.TrimSpace(env), "\n")...)
+			}
+			resp.ResultText = append(resp.ResultText, "")
+			return
+		}).Report(os.Stdout)
+		return
+	},
+}
@@ -18,11 +18,15 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -38,6 +42,8 @@ import (
 var snapshotCmdValue, snapshotCmdSeverity, snapshotCmdSnooze, snapshotCmdUserAssignment, snapshotCmdXpathsonly bool
 var snapshotCmdMaxitems int
 var snapshotCmdUsername string
+var snapshotCmdWatch time.Duration
+var snapshotCmdHook string
 var snapshotCmdPassword config.Secret
 
 func init() {
@@ -55,6 +61,11 @@ func init() {
 	snapshotCmd.Flags().IntVarP(&snapshotCmdMaxitems, "limit", "l", 0, "limit matching items to display. default is unlimited. results unsorted.")
 	snapshotCmd.Flags().BoolVarP(&snapshotCmdXpathsonly, "xpaths", "x", false, "just show matching xpaths")
 
+	snapshotCmd.Flags().DurationVarP(&snapshotCmdWatch, "watch", "w", 0, "Re-snapshot matching dataviews at this `INTERVAL` and output\nchanges as JSON lines until interrupted")
+	snapshotCmd.Flags().StringVar(&snapshotCmdHook, "hook", "", "Run `PROGRAM` for each change in severity in watch mode")
+
+	snapshotCmd.MarkFlagsMutuallyExclusive("watch", "xpaths")
+
 	snapshotCmd.Flags().SortFlags = false
 }
 
@@ -112,6 +123,22 @@ var snapshotCmd = &cobra.Command{
 			defer clear(snapshotCmdPassword)
 		}
 
+		if snapshotCmdWatch > 0 {
+			// hooks are run on changes of severity
+			if snapshotCmdHook != "" {
+				snapshotCmdSeverity = true
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			sw := newSnapshotWatcher(ctx, os.Stdout, snapshotCmdHook)
+			instance.Do(geneos.GetHost(Hostname), ct, names, snapshotWatchInstance, params, sw).Report(os.Stdout)
+			sw.hooks.Wait()
+			return
+		} else if snapshotCmdHook != "" {
+			fmt.Println("--hook is only valid with --watch")
+			return
+		}
+
 		instance.Do(geneos.GetHost(Hostname), ct, names, snapshotInstance, params).Report(os.Stdout, responses.IndentJSON(true))
 	},
 }
@@ -143,46 +170,8 @@ func snapshotInstance(i geneos.Instance, params ...any) (resp *responses.General
 			continue
 		}
 
-		// always try to use auth details in per-instance config,
-		// default to from the command line or user/global config or
-		// credentials file
-		username := config.Get[string](i.Config(), config.Join("snapshot", "username"))
-		password := config.Get[config.Secret](i.Config(), config.Join("snapshot", "password"))
-		defer clear(password)
-
-		if username == "" {
-			username = snapshotCmdUsername
-		}
-
-		if password == nil {
-			password = snapshotCmdPassword
-		}
-
-		// if username is still unset then look for credentials
-		//
-		// credential domain is gateway:NAME or gateway:* for wildcard
-		if username == "" {
-			creds := config.FindCreds(i.Type().String()+":"+i.Name(), config.AppName(cordial.ExecutableName()))
-			if creds != nil {
-				username = config.Get[string](creds, "username")
-				password = config.Get[config.Secret](creds, "password")
-				defer clear(password)
-			} else {
-				if creds = config.FindCreds(i.Type().String()+":*", config.AppName(cordial.ExecutableName())); creds != nil {
-					username = config.Get[string](creds, "username")
-					password = config.Get[config.Secret](creds, "password")
-					defer clear(password)
-				}
-			}
-		}
-
-		i.Log().Debug("dialling", slog.Any("url", gatewayURL(i)))
 		var gw *commands.Connection
-		gw, resp.Err = commands.DialGateway(
-			gatewayURL(i),
-			commands.AllowInsecureCertificates(true),
-			commands.SetBasicAuth(username, password),
-		)
+		gw, resp.Err = GatewayConnection(i, snapshotCmdUsername, snapshotCmdPassword)
 		if resp.Err != nil {
 			return
 		}
@@ -222,6 +211,45 @@ func snapshotInstance(i geneos.Instance, params ...any) (resp *responses.General
 	return
 }
 
+// GatewayConnection dials the REST Commands API of gateway instance i.
+//
+// The credentials are taken from the instance parameters
+// `snapshot::username` and `snapshot::password` if set, otherwise
+// username and password are used. If there is still no username then
+// any saved credentials for the domain `gateway:NAME` or `gateway:*`
+// are used.
+func GatewayConnection(i geneos.Instance, username string, password config.Secret) (gw *commands.Connection, err error) {
+	if u := config.Get[string](i.Config(), config.Join("snapshot", "username")); u != "" {
+		username = u
+	}
+	if p := config.Get[config.Secret](i.Config(), config.Join("snapshot", "password")); p != nil {
+		defer clear(p)
+		password = p
+	}
+
+	// if username is still unset then look for credentials
+	//
+	// credential domain is gateway:NAME or gateway:* for wildcard
+	if username == "" {
+		creds := config.FindCreds(i.Type().String()+":"+i.Name(), config.AppName(cordial.ExecutableName()))
+		if creds == nil {
+			creds = config.FindCreds(i.Type().String()+":*", config.AppName(cordial.ExecutableName()))
+		}
+		if creds != nil {
+			username = config.Get[string](creds, "username")
+			password = config.Get[config.Secret](creds, "password")
+			defer clear(password)
+		}
+	}
+
+	i.Log().Debug("dialling", slog.Any("url", gatewayURL(i)))
+	return commands.DialGateway(
+		gatewayURL(i),
+		commands.AllowInsecureCertificates(true),
+		commands.SetBasicAuth(username, password),
+	)
+}
+
 func gatewayURL(i geneos.Instance) (u *url.URL) {
 	if !instance.IsA(i, "gateway") {
 		return
@@ -0,0 +1,226 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/geneos/commands"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+// snapshotHookTimeout is how long a hook may run before it is killed
+const snapshotHookTimeout = 30 * time.Second
+
+// snapshotEvent is one line of output in watch mode
+type snapshotEvent struct {
+	Time     time.Time `json:"time"`
+	Gateway  string    `json:"gateway"`
+	Dataview string    `json:"dataview"`
+	commands.Delta
+}
+
+// snapshotWatcher holds the state shared by the watch loops for each
+// gateway
+type snapshotWatcher struct {
+	ctx   context.Context
+	hook  string
+	mutex sync.Mutex
+	enc   *json.Encoder
+	hooks sync.WaitGroup
+}
+
+func newSnapshotWatcher(ctx context.Context, w io.Writer, hook string) *snapshotWatcher {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &snapshotWatcher{
+		ctx:  ctx,
+		hook: hook,
+		enc:  enc,
+	}
+}
+
+// emit writes each delta as a JSON line and runs the hook, if any, for
+// changes in severity
+func (sw *snapshotWatcher) emit(i geneos.Instance, view string, deltas []commands.Delta) {
+	now := time.Now()
+	for _, d := range deltas {
+		ev := snapshotEvent{
+			Time:     now,
+			Gateway:  i.Name(),
+			Dataview: view,
+			Delta:    d,
+		}
+		sw.mutex.Lock()
+		err := sw.enc.Encode(ev)
+		sw.mutex.Unlock()
+		if err != nil {
+			i.Log().Error("cannot write change", slog.Any("error", err))
+		}
+		if sw.hook != "" && d.SeverityChanged() {
+			sw.hooks.Go(func() { sw.runHook(i, ev) })
+		}
+	}
+}
+
+// runHook runs the hook for event ev. The event is passed as JSON on
+// STDIN and the main fields are also set in the environment.
+func (sw *snapshotWatcher) runHook(i geneos.Instance, ev snapshotEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotHookTimeout)
+	defer cancel()
+
+	in, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	var oldSeverity, newSeverity, value string
+	if ev.Old != nil {
+		oldSeverity = ev.Old.Severity
+	}
+	if ev.New != nil {
+		newSeverity, value = ev.New.Severity, ev.New.Value
+	}
+
+	cmd := exec.CommandContext(ctx, sw.hook)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Env = append(os.Environ(),
+		"GENEOS_GATEWAY="+ev.Gateway,
+		"GENEOS_DATAVIEW="+ev.Dataview,
+		"GENEOS_CHANGE="+ev.Change,
+		"GENEOS_ROW="+ev.Row,
+		"GENEOS_COLUMN="+ev.Column,
+		"GENEOS_HEADLINE="+ev.Headline,
+		"GENEOS_OLD_SEVERITY="+oldSeverity,
+		"GENEOS_NEW_SEVERITY="+newSeverity,
+		"GENEOS_VALUE="+value,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		i.Log().Error("hook failed", slog.String("hook", sw.hook), slog.Any("error", err), slog.String("output", string(out)))
+	}
+}
+
+// snapshotWatchInstance re-snapshots the dataviews matching the paths
+// in params on gateway i every interval, emitting the changes, until
+// the watcher context is cancelled. The first pass reports each
+// dataview as added. Dataviews are only reported as removed after a
+// pass with no errors, so that a gateway restart does not look like
+// every dataview going away.
+func snapshotWatchInstance(i geneos.Instance, params ...any) (resp *responses.General) {
+	resp = responses.New[responses.General](i)
+
+	if len(params) != 2 {
+		resp.Err = geneos.ErrInvalidArgs
+		return
+	}
+	paths, ok := params[0].([]string)
+	if !ok {
+		panic("wrong type")
+	}
+	sw, ok := params[1].(*snapshotWatcher)
+	if !ok {
+		panic("wrong type")
+	}
+
+	if instance.CompareVersion(i, "5.14") <= 0 {
+		resp.Err = fmt.Errorf("%s is too old (5.14 or above required)", i)
+		return
+	}
+
+	var dataviews []*xpath.XPath
+	for _, path := range paths {
+		x, err := xpath.Parse(path)
+		if err != nil {
+			resp.Err = fmt.Errorf("%w: %q: %w", geneos.ErrInvalidArgs, path, err)
+			return
+		}
+		dataviews = append(dataviews, x.ResolveTo(&xpath.Dataview{}))
+	}
+
+	gw, err := GatewayConnection(i, snapshotCmdUsername, snapshotCmdPassword)
+	if err != nil {
+		resp.Err = err
+		return
+	}
+
+	scope := commands.Scope{
+		Value:          snapshotCmdValue,
+		Severity:       snapshotCmdSeverity,
+		Snooze:         snapshotCmdSnooze,
+		UserAssignment: snapshotCmdUserAssignment,
+	}
+
+	previous := map[string]*commands.Dataview{}
+	ticker := time.NewTicker(snapshotCmdWatch)
+	defer ticker.Stop()
+
+	for {
+		seen := map[string]bool{}
+		failed := false
+		for _, d := range dataviews {
+			views, err := gw.Match(d, 0)
+			if err != nil {
+				i.Log().Warn("cannot match dataviews", slog.Any("xpath", d), slog.Any("error", err))
+				failed = true
+				continue
+			}
+			if snapshotCmdMaxitems > 0 && len(views) > snapshotCmdMaxitems {
+				views = views[0:snapshotCmdMaxitems]
+			}
+			for _, view := range views {
+				key := view.String()
+				seen[key] = true
+				data, err := gw.Snapshot(view, "", scope)
+				if err != nil {
+					i.Log().Warn("cannot snapshot dataview", slog.String("xpath", key), slog.Any("error", err))
+					failed = true
+					continue
+				}
+				sw.emit(i, key, commands.DiffDataviews(previous[key], data))
+				previous[key] = data
+			}
+		}
+		if !failed {
+			for _, key := range slices.Sorted(maps.Keys(previous)) {
+				if !seen[key] {
+					sw.emit(i, key, commands.DiffDataviews(previous[key], nil))
+					delete(previous, key)
+				}
+			}
+		}
+
+		select {
+		case <-sw.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supervisecmd
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/process"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/supervisor"
+)
+
+var startCmdDaemon, startCmdNoPortCheck bool
+var startCmdLogfile, startCmdPolicy string
+var startCmdRetries int
+var startCmdDelay, startCmdMaxDelay, startCmdInterval, startCmdGrace, startCmdReset time.Duration
+
+func init() {
+	superviseCmd.AddCommand(startCmd)
+
+	startCmd.Flags().BoolVarP(&startCmdDaemon, "daemon", "D", false, "Run the supervisor in the background")
+	startCmd.Flags().StringVarP(&startCmdLogfile, "logfile", "l", "", "Write logs to `FILE`, default STDERR or the\n`supervise.log` file when run in the background")
+
+	startCmd.Flags().StringVarP(&startCmdPolicy, "policy", "p", string(supervisor.PolicyOnFailure), "Default restart `POLICY`, one of on-failure, always or no")
+	startCmd.Flags().IntVarP(&startCmdRetries, "retries", "r", 5, "Default number of consecutive restarts before giving up, 0 for no limit")
+	startCmd.Flags().DurationVar(&startCmdDelay, "delay", 5*time.Second, "Default initial back-off `DURATION` between restarts")
+	startCmd.Flags().DurationVar(&startCmdMaxDelay, "max-delay", 5*time.Minute, "Default maximum back-off `DURATION` between restarts")
+
+	startCmd.Flags().DurationVarP(&startCmdInterval, "interval", "i", 15*time.Second, "Check instances every `DURATION`")
+	startCmd.Flags().DurationVar(&startCmdGrace, "grace", time.Minute, "Wait `DURATION` after an instance starts before checking its port")
+	startCmd.Flags().DurationVar(&startCmdReset, "reset", 10*time.Minute, "Reset the retry count after an instance is healthy for `DURATION`")
+	startCmd.Flags().BoolVar(&startCmdNoPortCheck, "no-port-check", false, "Do not check that instances are listening on their ports")
+
+	startCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/start.md
+var startCmdDescription string
+
+var startCmd = &cobra.Command{
+	Use:   "start [flags] [TYPE] [NAME...]",
+	Short: "Run the Supervisor",
+	Long:  startCmdDescription,
+	Example: `
+geneos supervise start --daemon
+geneos supervise start -D --policy always gateway
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:               "true",
+		cmd.CmdRequireHome:          "true",
+		cmd.CmdWildcardNames:        "true",
+		cmd.CmdNonInstanceArgsError: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		ct, names, _, err := cmd.FetchArgs(command)
+		if err != nil {
+			return
+		}
+
+		policy, err := supervisor.ParsePolicy(startCmdPolicy)
+		if err != nil {
+			return
+		}
+
+		if pid, err := supervisorPID(); err == nil && pid != os.Getpid() {
+			return fmt.Errorf("supervisor already running with PID %d", pid)
+		}
+
+		if startCmdDaemon {
+			var logArgs []string
+			if startCmdLogfile == "" {
+				logArgs = append(logArgs, "--logfile", logFile())
+			}
+			if err = geneos.LOCAL.MkdirAll(path.Dir(logFile()), 0775); err != nil {
+				return
+			}
+			return process.Daemon(os.Stdout, logArgs, nil, "-D", "--daemon")
+		}
+
+		if startCmdLogfile != "" {
+			cordial.LogInit("cordial", cordial.SetLogfile(startCmdLogfile))
+		}
+
+		if err = geneos.LOCAL.MkdirAll(path.Dir(pidFile()), 0775); err != nil {
+			return
+		}
+		if err = geneos.LOCAL.WriteFile(pidFile(), []byte(strconv.Itoa(os.Getpid())+"\n"), 0664); err != nil {
+			return
+		}
+		defer geneos.LOCAL.Remove(pidFile())
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return supervisor.New(geneos.GetHost(cmd.Hostname), ct, names, supervisor.Options{
+			Policy:     policy,
+			Retries:    startCmdRetries,
+			Delay:      startCmdDelay,
+			MaxDelay:   startCmdMaxDelay,
+			Interval:   startCmdInterval,
+			Grace:      startCmdGrace,
+			Reset:      startCmdReset,
+			PortCheck:  !startCmdNoPortCheck,
+			StatusFile: statusFile(),
+		}).Run(ctx)
+	},
+}
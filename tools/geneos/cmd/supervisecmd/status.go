@@ -0,0 +1,161 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supervisecmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/pkg/reporter"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/supervisor"
+)
+
+var statusCmdJSON, statusCmdIndent bool
+var statusCmdFormat string
+
+func init() {
+	superviseCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVarP(&statusCmdJSON, "json", "j", false, "Output JSON")
+	statusCmd.Flags().BoolVarP(&statusCmdIndent, "pretty", "i", false, "Output indented JSON")
+	statusCmd.Flags().StringVarP(&statusCmdFormat, "format", "F", "table", "Output a table in `FORMAT`, one of 'table', 'csv', 'tsv', 'toolkit', 'markdown', 'html' or 'xlsx'")
+
+	statusCmd.MarkFlagsMutuallyExclusive("json", "pretty", "format")
+	statusCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/status.md
+var statusCmdDescription string
+
+var statusCmd = &cobra.Command{
+	Use:   "status [flags]",
+	Short: "Show Supervisor Status",
+	Long:  statusCmdDescription,
+	Example: `
+geneos supervise status
+geneos supervise status -F toolkit
+`,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		state := "running"
+		pid, err := supervisorPID()
+		if err != nil {
+			log.Debug("supervisor", slog.Any("error", err))
+			state = "stopped"
+		}
+
+		report, err := supervisor.ReadReport(statusFile())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		if state == "running" && report.PID != pid {
+			// the report is from an earlier supervisor
+			report = supervisor.Report{PID: pid}
+		}
+		if state == "stopped" {
+			report.PID = 0
+		}
+
+		switch {
+		case statusCmdJSON, statusCmdIndent:
+			if report.Instances == nil {
+				report.Instances = []supervisor.Status{}
+			}
+			j := json.NewEncoder(os.Stdout)
+			j.SetEscapeHTML(false)
+			if statusCmdIndent {
+				j.SetIndent("", "    ")
+			}
+			return j.Encode(struct {
+				State string `json:"state"`
+				supervisor.Report
+			}{state, report})
+		default:
+			r, err := reporter.NewReporter(statusCmdFormat, os.Stdout)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			if err = r.Prepare(reporter.Report{Name: "supervisor", Title: "Supervisor Status"}); err != nil {
+				return err
+			}
+			r.AddHeadlines(map[string]string{
+				"state":   state,
+				"pid":     fmt.Sprint(report.PID),
+				"started": statusTime(report.Started),
+				"updated": statusTime(report.Updated),
+			})
+			r.UpdateTable(statusTable(report))
+			r.Render()
+		}
+		return nil
+	},
+}
+
+// statusTable returns the columns and rows for a reporter, with one row
+// per instance
+func statusTable(report supervisor.Report) (columns []string, rows [][]string) {
+	columns = []string{"instance", "type", "name", "host", "policy", "state", "pid", "port", "listening", "restarts", "retries", "lastStart", "nextAttempt", "lastError"}
+	for _, s := range report.Instances {
+		pid := ""
+		if s.PID != 0 {
+			pid = fmt.Sprint(s.PID)
+		}
+		port := ""
+		if s.Port != 0 {
+			port = fmt.Sprint(s.Port)
+		}
+		rows = append(rows, []string{
+			s.Type + ":" + s.Name + "@" + s.Host,
+			s.Type,
+			s.Name,
+			s.Host,
+			string(s.Policy),
+			string(s.State),
+			pid,
+			port,
+			fmt.Sprint(s.Listening),
+			fmt.Sprint(s.Restarts),
+			fmt.Sprint(s.Retries),
+			statusTime(s.LastStart),
+			statusTime(s.NextAttempt),
+			s.LastError,
+		})
+	}
+	return
+}
+
+func statusTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
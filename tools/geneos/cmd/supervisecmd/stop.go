@@ -0,0 +1,65 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supervisecmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+)
+
+func init() {
+	superviseCmd.AddCommand(stopCmd)
+}
+
+var stopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "Stop the Supervisor",
+	Long:         "Stop the running supervisor. Supervised instances are not stopped.",
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	RunE: func(command *cobra.Command, _ []string) (err error) {
+		pid, err := supervisorPID()
+		if err != nil {
+			return
+		}
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return
+		}
+		if err = p.Signal(syscall.SIGTERM); err != nil {
+			return
+		}
+		for range 20 {
+			time.Sleep(250 * time.Millisecond)
+			if _, err := supervisorPID(); err != nil {
+				fmt.Printf("supervisor with PID %d stopped\n", pid)
+				return nil
+			}
+		}
+		return fmt.Errorf("supervisor with PID %d did not stop", pid)
+	},
+}
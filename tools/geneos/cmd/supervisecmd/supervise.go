@@ -0,0 +1,100 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supervisecmd groups the commands that run and control the
+// instance supervisor
+package supervisecmd
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/tools/geneos/cmd"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+var log = cordial.Logger
+
+func init() {
+	cmd.Cmd.AddCommand(superviseCmd)
+}
+
+//go:embed README.md
+var superviseCmdDescription string
+
+var superviseCmd = &cobra.Command{
+	Use:          "supervise",
+	GroupID:      cmd.CommandGroupSubsystems,
+	Short:        "Supervise Instances and Restart Them on Failure",
+	Long:         superviseCmdDescription,
+	SilenceUsage: true,
+	Annotations: map[string]string{
+		cmd.CmdGlobal:      "false",
+		cmd.CmdRequireHome: "true",
+	},
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		return command.Help()
+	},
+}
+
+// the supervisor files are kept in a `supervise` directory under the
+// local Geneos home directory
+func pidFile() string {
+	return geneos.LOCAL.PathTo("supervise", "supervise.pid")
+}
+
+func statusFile() string {
+	return geneos.LOCAL.PathTo("supervise", "status.json")
+}
+
+func logFile() string {
+	return geneos.LOCAL.PathTo("supervise", "supervise.log")
+}
+
+// supervisorPID returns the PID of the running supervisor, or an error
+// wrapping geneos.ErrNotRunning if there is none
+func supervisorPID() (pid int, err error) {
+	b, err := geneos.LOCAL.ReadFile(pidFile())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("supervisor %w", geneos.ErrNotRunning)
+		}
+		return
+	}
+	if pid, err = strconv.Atoi(strings.TrimSpace(string(b))); err != nil {
+		return 0, fmt.Errorf("invalid PID file %q: %w", pidFile(), err)
+	}
+	p, err := os.FindProcess(pid)
+	if err == nil {
+		err = p.Signal(syscall.Signal(0))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("supervisor %w", geneos.ErrNotRunning)
+	}
+	return
+}
@@ -0,0 +1,269 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Archive is a backup archive in a store. Name, Kind and Created are
+// taken from the archive name, so that archives can be listed without
+// reading them, and Manifest is only set by Latest.
+type Archive struct {
+	Name     string
+	Kind     string
+	Created  time.Time
+	Manifest *Manifest
+}
+
+// ArchiveTimeFormat is the format of the creation time in the names of
+// archives written by `geneos backup` for incremental backups and
+// retention, in local time
+const ArchiveTimeFormat = "20060102150405"
+
+// parseArchiveName returns the archive for name if it is an archive in
+// the set set, named in the form SET-DATETIME[-incremental].tar[.gz|.bz2]
+func parseArchiveName(name, set string) (a Archive, ok bool) {
+	rest, ok := strings.CutPrefix(name, set+"-")
+	if !ok {
+		return
+	}
+	stamp, ext, ok := strings.Cut(rest, ".tar")
+	if !ok || !slices.Contains([]string{"", ".gz", ".bz2"}, ext) {
+		return a, false
+	}
+	a.Kind = Full
+	if s, ok := strings.CutSuffix(stamp, "-"+Incremental); ok {
+		stamp, a.Kind = s, Incremental
+	}
+	if len(stamp) != len(ArchiveTimeFormat) {
+		return a, false
+	}
+	created, err := time.ParseInLocation(ArchiveTimeFormat, stamp, time.Local)
+	if err != nil {
+		return a, false
+	}
+	a.Name, a.Created = name, created
+	return a, true
+}
+
+// List returns the archives in store s that belong to the archive set
+// set, newest first. Only the names of the archives are used and the
+// archives themselves are not read.
+func List(s Store, set string) (archives []Archive, err error) {
+	names, err := s.List()
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		if a, ok := parseArchiveName(name, set); ok {
+			archives = append(archives, a)
+		}
+	}
+	slices.SortFunc(archives, func(a, b Archive) int {
+		if c := b.Created.Compare(a.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(b.Name, a.Name)
+	})
+	return
+}
+
+// Latest returns the newest full archive in the archive set set in
+// store s, with its manifest. Full archives without a readable manifest
+// are skipped. If there is no full archive then os.ErrNotExist is
+// returned.
+func Latest(s Store, set string) (archive Archive, err error) {
+	archives, err := List(s, set)
+	if err != nil {
+		return
+	}
+	for _, a := range archives {
+		if a.Kind != Full {
+			continue
+		}
+		if a.Manifest, err = ReadManifest(s, a.Name, ""); err != nil {
+			log.Debug("skipping archive", slog.String("archive", s.Path(a.Name)), slog.Any("error", err))
+			continue
+		}
+		if a.Manifest.Set != set {
+			continue
+		}
+		return a, nil
+	}
+	err = os.ErrNotExist
+	return
+}
+
+// Verify reads the whole of the archive name in store s, decompressed
+// using compression, and checks each entry against the archive's
+// manifest, returning the manifest if all entries are present with the
+// right checksums. If the archive is incremental then the full archive
+// it is based on, which must be in the same store, is also verified
+// and checked to be the one the incremental archive was made against.
+func Verify(s Store, name, compression string) (m *Manifest, err error) {
+	if m, err = verify(s, name, compression); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path(name), err)
+	}
+	if m.Kind != Incremental {
+		return
+	}
+
+	bm, err := verify(s, m.Base, compression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base archive %s: %w", ErrChain, s.Path(m.Base), err)
+	}
+	if bm.Kind != Full || bm.Set != m.Set || !bm.Created.Equal(m.BaseCreated) {
+		return nil, fmt.Errorf("%w: %s is not the base of %s", ErrChain, s.Path(m.Base), s.Path(name))
+	}
+	for file, f := range m.Files {
+		if !f.InBase {
+			continue
+		}
+		if bf, ok := bm.Files[file]; !ok || bf.SHA256 != f.SHA256 {
+			return nil, fmt.Errorf("%w: %s not found in base archive %s", ErrChain, file, s.Path(m.Base))
+		}
+	}
+	return
+}
+
+// verify checksums each entry in the archive as it is read and then
+// checks them against the manifest, which follows the entries
+func verify(s Store, name, compression string) (m *Manifest, err error) {
+	r, err := Open(s, name, compression)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	sums := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if len(sums) == 0 && m == nil && notTar(err) {
+			return nil, ErrNoManifest
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == ManifestName {
+			if m, err = decodeManifest(tr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		sums[hdr.Name] = ""
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if sums[hdr.Name], err = Checksum(tr); err != nil {
+			return nil, err
+		}
+	}
+	if m == nil {
+		return nil, ErrNoManifest
+	}
+
+	for name, sum := range sums {
+		f, ok := m.Files[name]
+		if !ok || f.InBase {
+			return nil, fmt.Errorf("%s not in manifest", name)
+		}
+		if sum != f.SHA256 {
+			return nil, fmt.Errorf("%w: %s", ErrChecksum, name)
+		}
+	}
+	for name, f := range m.Files {
+		if _, ok := sums[name]; !f.InBase && !ok {
+			return nil, fmt.Errorf("%s missing from archive", name)
+		}
+	}
+	return
+}
+
+// OpenChain returns a reader for an uncompressed tar stream of the
+// contents of the archive name in store s, decompressed using
+// compression, with manifest m. If the archive is incremental then the
+// stream is the reassembled chain; all the entries in the incremental
+// archive followed by those in the full archive that are listed in the
+// incremental manifest as unchanged. Files that were in the full
+// archive but were removed before the incremental backup are not
+// included.
+//
+// Manifest entries are not included in the stream, for full or
+// incremental archives. The archives should be checked with Verify
+// first.
+func OpenChain(s Store, name string, m *Manifest, compression string) (r io.ReadCloser, err error) {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := copyEntries(tw, s, name, compression, func(string) bool { return true })
+		if err == nil && m.Kind == Incremental {
+			err = copyEntries(tw, s, m.Base, compression, func(name string) bool {
+				f, ok := m.Files[name]
+				return ok && f.InBase
+			})
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// copyEntries copies the entries, other than the manifest, from the
+// archive name in store s to tw where include returns true for the
+// entry name
+func copyEntries(tw *tar.Writer, s Store, name, compression string, include func(name string) bool) (err error) {
+	r, err := Open(s, name, compression)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name == ManifestName || !include(hdr.Name) {
+			continue
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err = io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
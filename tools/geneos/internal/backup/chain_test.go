@@ -0,0 +1,202 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory Store
+type memStore map[string][]byte
+
+type memFile struct {
+	*bytes.Buffer
+	s    memStore
+	name string
+}
+
+func (f memFile) Close() error {
+	f.s[f.name] = f.Bytes()
+	return nil
+}
+
+func (s memStore) Create(name string) (io.WriteCloser, error) {
+	return memFile{Buffer: &bytes.Buffer{}, s: s, name: name}, nil
+}
+
+func (s memStore) Open(name string) (io.ReadCloser, error) {
+	b, ok := s[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s memStore) Remove(name string) error {
+	delete(s, name)
+	return nil
+}
+
+func (s memStore) List() ([]string, error) {
+	return slices.Sorted(maps.Keys(s)), nil
+}
+
+func (s memStore) Path(name string) string {
+	return "mem:" + name
+}
+
+// writeTestArchive writes a gzipped archive of files, in name order,
+// to s with the manifest m as the last entry. Files already in m as
+// InBase are not written and checksums already in m are kept.
+func writeTestArchive(t *testing.T, s memStore, name string, m *Manifest, files map[string]string) {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(zw)
+	for _, f := range slices.Sorted(maps.Keys(files)) {
+		content := files[f]
+		sum, _ := Checksum(strings.NewReader(content))
+		if m.Files[f].InBase {
+			continue
+		}
+		if m.Files[f].SHA256 != "" {
+			sum = m.Files[f].SHA256
+		}
+		m.Files[f] = File{Size: int64(len(content)), Mode: 0644, SHA256: sum}
+		if err := tw.WriteHeader(&tar.Header{Name: f, Size: int64(len(content)), Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Write(tw); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	zw.Close()
+	s[name] = b.Bytes()
+}
+
+// readEntries returns the contents of the entries in the tar stream r
+func readEntries(t *testing.T, r io.ReadCloser) map[string]string {
+	t.Helper()
+	defer r.Close()
+	entries := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[hdr.Name] = string(b)
+	}
+}
+
+func TestManifestLast(t *testing.T) {
+	s := memStore{}
+	m := NewManifest("test", Full, "localhost")
+	files := map[string]string{"gateway/gateways/a/gateway.json": "a", "netprobe/netprobes/b/netprobe.json": "b"}
+	writeTestArchive(t, s, "test-full.tar.gz", m, files)
+
+	rm, err := ReadManifest(s, "test-full.tar.gz", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.Files) != 2 || rm.Kind != Full {
+		t.Errorf("ReadManifest() = %v", rm)
+	}
+
+	vm, err := Verify(s, "test-full.tar.gz", "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenChain(s, "test-full.tar.gz", vm, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntries(t, r); !maps.Equal(got, files) {
+		t.Errorf("OpenChain() entries = %v, want %v without the manifest", got, files)
+	}
+
+	// the wrong compression, or no compression, is not an archive with
+	// a manifest
+	if _, err = ReadManifest(s, "test-full.tar.gz", "none"); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("ReadManifest() with wrong compression error = %v, want ErrNoManifest", err)
+	}
+	s["plain.txt"] = []byte("not a tar file")
+	if _, err = Verify(s, "plain.txt", ""); !errors.Is(err, ErrNoManifest) {
+		t.Errorf("Verify() of a text file error = %v, want ErrNoManifest", err)
+	}
+
+	// an archive whose contents do not match the manifest
+	bad := NewManifest("test", Full, "localhost")
+	bad.Files["gateway/gateways/a/gateway.json"] = File{SHA256: strings.Repeat("0", 64)}
+	writeTestArchive(t, s, "bad.tar.gz", bad, files)
+	if _, err = Verify(s, "bad.tar.gz", ""); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Verify() of a changed file error = %v, want ErrChecksum", err)
+	}
+}
+
+func TestIncrementalChain(t *testing.T) {
+	s := memStore{}
+	full := NewManifest("test", Full, "localhost")
+	full.Created = time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	writeTestArchive(t, s, "test-1.tar.gz", full, map[string]string{"a": "a1", "b": "b1", "c": "c1"})
+
+	inc := NewManifest("test", Incremental, "localhost")
+	inc.Base, inc.BaseCreated = "test-1.tar.gz", full.Created
+	inc.Files["a"] = File{Size: 2, Mode: 0644, SHA256: full.Files["a"].SHA256, InBase: true}
+	writeTestArchive(t, s, "test-2.tar.gz", inc, map[string]string{"a": "a1", "b": "b2"})
+
+	m, err := Verify(s, "test-2.tar.gz", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenChain(s, "test-2.tar.gz", m, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": "a1", "b": "b2"}
+	if got := readEntries(t, r); !maps.Equal(got, want) {
+		t.Errorf("OpenChain() entries = %v, want %v", got, want)
+	}
+
+	// a changed base breaks the chain
+	writeTestArchive(t, s, "test-1.tar.gz", NewManifest("test", Full, "localhost"), map[string]string{"a": "a1"})
+	if _, err = Verify(s, "test-2.tar.gz", ""); !errors.Is(err, ErrChain) {
+		t.Errorf("Verify() with a replaced base error = %v, want ErrChain", err)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup provides the manifest, incremental chain and
+// retention support for archives written by `geneos backup` and read
+// by `geneos restore`.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+
+	"github.com/itrs-group/cordial"
+)
+
+var log = cordial.Logger
+
+// ManifestName is the name of the manifest entry, which is the last
+// entry in an archive written by `geneos backup`
+const ManifestName = ".geneos-backup.json"
+
+// ManifestVersion is the current manifest format version
+const ManifestVersion = 1
+
+// Archive kinds
+const (
+	Full        = "full"
+	Incremental = "incremental"
+)
+
+var (
+	ErrNoManifest = errors.New("archive has no manifest")
+	ErrChecksum   = errors.New("checksum mismatch")
+	ErrChain      = errors.New("broken incremental chain")
+)
+
+// Manifest describes the contents of a backup archive. For incremental
+// archives the manifest lists all the files that were selected at the
+// time of the backup, with those unchanged since the full archive it
+// is based on marked as InBase and not included in the archive itself.
+type Manifest struct {
+	Version int       `json:"version"`
+	Set     string    `json:"set"`
+	Kind    string    `json:"kind"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host"`
+
+	// Base and BaseCreated identify the full archive that an
+	// incremental archive depends on. Base is the file name of the
+	// full archive, which must be in the same directory.
+	Base        string    `json:"base,omitempty"`
+	BaseCreated time.Time `json:"base-created,omitzero"`
+
+	Files map[string]File `json:"files"`
+}
+
+// File is the manifest entry for one file or directory. Directory
+// names have a trailing "/" and no checksum.
+type File struct {
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mtime"`
+	SHA256  string      `json:"sha256,omitempty"`
+	InBase  bool        `json:"in-base,omitempty"`
+}
+
+// NewManifest returns an empty manifest of kind for the archive set
+// set, for files from host
+func NewManifest(set, kind, host string) *Manifest {
+	return &Manifest{
+		Version: ManifestVersion,
+		Set:     set,
+		Kind:    kind,
+		Created: time.Now().UTC().Truncate(time.Second),
+		Host:    host,
+		Files:   map[string]File{},
+	}
+}
+
+// Checksum returns the hex encoded SHA256 checksum of the contents of r
+func Checksum(r io.Reader) (sum string, err error) {
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Changed returns true if the file name with checksum sum is not in
+// the manifest m or has a different checksum.
+func (m *Manifest) Changed(name, sum string) bool {
+	if m == nil {
+		return true
+	}
+	f, ok := m.Files[name]
+	return !ok || f.SHA256 != sum
+}
+
+// Write writes the manifest as the next entry in tw
+func (m *Manifest) Write(tw *tar.Writer) (err error) {
+	b, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return
+	}
+	if err = tw.WriteHeader(&tar.Header{
+		Name:    ManifestName,
+		Size:    int64(len(b)),
+		Mode:    0644,
+		ModTime: m.Created,
+	}); err != nil {
+		return
+	}
+	_, err = tw.Write(b)
+	return
+}
+
+// ReadManifest returns the manifest from the archive name in store s,
+// decompressed using compression. As the manifest is written after the
+// files it describes the whole archive is read. If the archive has no
+// manifest, or is not a tar archive, then ErrNoManifest is returned.
+func ReadManifest(s Store, name, compression string) (m *Manifest, err error) {
+	r, err := Open(s, name, compression)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	for n := 0; ; n++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, ErrNoManifest
+		}
+		if n == 0 && notTar(err) {
+			log.Debug("not a tar archive", slog.String("archive", s.Path(name)), slog.Any("error", err))
+			return nil, ErrNoManifest
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == ManifestName {
+			return decodeManifest(tr)
+		}
+	}
+}
+
+// notTar returns true if err, from reading the first header, shows
+// that the stream is not a tar archive
+func notTar(err error) bool {
+	return errors.Is(err, tar.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// decodeManifest decodes the manifest from the current entry in tr
+func decodeManifest(tr *tar.Reader) (m *Manifest, err error) {
+	m = &Manifest{}
+	if err = json.NewDecoder(tr).Decode(m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	if m.Version > ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return
+}
+
+// Open opens the archive name in store s and returns a reader for the
+// uncompressed tar stream, decompressed using compression
+func Open(s Store, name, compression string) (r io.ReadCloser, err error) {
+	f, err := s.Open(name)
+	if err != nil {
+		return
+	}
+	if r, err = Decompress(f, compression, name); err != nil {
+		f.Close()
+		return
+	}
+	return readCloser{Reader: r, closers: []io.Closer{r, f}}, nil
+}
+
+// Decompress returns a reader for the uncompressed contents of r.
+// compression is one of "gzip", "bzip2" or "none" or, if empty, is
+// taken from the suffix of the file name archive.
+func Decompress(r io.Reader, compression, archive string) (io.ReadCloser, error) {
+	if compression == "" {
+		switch {
+		case strings.HasSuffix(archive, ".gz"), strings.HasSuffix(archive, ".tgz"):
+			compression = "gzip"
+		case strings.HasSuffix(archive, ".bz2"):
+			compression = "bzip2"
+		default:
+			compression = "none"
+		}
+	}
+	switch compression {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "bzip2":
+		return bzip2.NewReader(r, nil)
+	case "none":
+		return io.NopCloser(r), nil
+	default:
+		return nil, fmt.Errorf("unknown decompression type %s", compression)
+	}
+}
+
+// readCloser closes all of closers, in order
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() (err error) {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// Policy is a named backup policy, configured under
+// `backup::policies::NAME` in the program configuration
+type Policy struct {
+	Name string `mapstructure:"-"`
+
+	// Host, Type and Names select the instances to back up, as for the
+	// command line. Names defaults to "all".
+	Host  string   `mapstructure:"host"`
+	Type  string   `mapstructure:"type"`
+	Names []string `mapstructure:"names"`
+
+	// Output is the directory that archives are written to
+	Output string `mapstructure:"output"`
+
+	// Compress is one of "gzip", "bzip2" or "none"
+	Compress string `mapstructure:"compress"`
+
+	// Full and Incremental are cron specs for full and incremental
+	// backups. Incremental backups run before any full backup fall
+	// back to full backups.
+	Full        string `mapstructure:"full"`
+	Incremental string `mapstructure:"incremental"`
+
+	// Keep is the number of full archives, with their incremental
+	// archives, to retain. MaxAge is the age after which archives are
+	// removed. Zero values disable the limit.
+	Keep   int           `mapstructure:"keep"`
+	MaxAge time.Duration `mapstructure:"max-age"`
+
+	// These are the same as the `geneos backup` options of the same
+	// names
+	All    bool   `mapstructure:"all"`
+	Shared bool   `mapstructure:"shared"`
+	AES    bool   `mapstructure:"aes"`
+	TLS    bool   `mapstructure:"tls"`
+	Size   string `mapstructure:"size"`
+}
+
+// Policies returns all the backup policies configured in cf
+func Policies(cf *config.Config) (policies map[string]*Policy, err error) {
+	policies = map[string]*Policy{}
+	if err = cf.UnmarshalKey(cf.Join("backup", "policies"), &policies, config.NoExpand()); err != nil {
+		return
+	}
+	for name, p := range policies {
+		p.Name = name
+		if len(p.Names) == 0 {
+			p.Names = []string{"all"}
+		}
+		if p.Compress == "" {
+			p.Compress = "gzip"
+		}
+		if p.Size == "" {
+			p.Size = "2MiB"
+		}
+	}
+	return
+}
+
+// GetPolicy returns the backup policy name from cf
+func GetPolicy(cf *config.Config, name string) (p *Policy, err error) {
+	policies, err := Policies(cf)
+	if err != nil {
+		return
+	}
+	p, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("backup policy %q not found", name)
+	}
+	if p.Output == "" {
+		return nil, fmt.Errorf("backup policy %q has no output directory", name)
+	}
+	return
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"time"
+)
+
+// Retain removes archives in the archive set set from store s that
+// fall outside the retention limits and returns the locations of the
+// archives removed. The kind and age of each archive are taken from
+// its name, so archives are not read.
+//
+// If keep is greater than zero then only the newest keep full archives
+// are retained. If maxAge is greater than zero then archives, full or
+// incremental, created more than maxAge before now are removed. The
+// newest full archive is never removed and incremental archives are
+// always removed along with the full archive they are based on.
+func Retain(s Store, set string, keep int, maxAge time.Duration, now time.Time) (removed []string, err error) {
+	archives, err := List(s, set)
+	if err != nil {
+		return
+	}
+
+	expired := func(a Archive) bool {
+		return maxAge > 0 && now.Sub(a.Created) > maxAge
+	}
+
+	// full archives are newest first, keep the first regardless
+	kept := map[string]bool{}
+	var fulls int
+	for _, a := range archives {
+		if a.Kind != Full {
+			continue
+		}
+		fulls++
+		if fulls == 1 || ((keep <= 0 || fulls <= keep) && !expired(a)) {
+			kept[a.Name] = true
+		}
+	}
+
+	var errs []error
+	for _, a := range archives {
+		switch a.Kind {
+		case Full:
+			if kept[a.Name] {
+				continue
+			}
+		default:
+			base, found := baseOf(a, archives)
+			if found && kept[base] && !expired(a) {
+				continue
+			}
+		}
+		if err := s.Remove(a.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, s.Path(a.Name))
+	}
+	return removed, errors.Join(errs...)
+}
+
+// baseOf returns the name of the full archive in archives, which are
+// newest first, that the incremental archive a is based on. This is the
+// newest full archive created no later than a, as incremental archives
+// are always made against the newest full archive.
+func baseOf(a Archive, archives []Archive) (string, bool) {
+	for _, b := range archives {
+		if b.Kind == Full && !b.Created.After(a.Created) {
+			return b.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// countingStore counts the archives opened
+type countingStore struct {
+	memStore
+	opened []string
+}
+
+func (s *countingStore) Open(name string) (io.ReadCloser, error) {
+	s.opened = append(s.opened, name)
+	return s.memStore.Open(name)
+}
+
+func testArchiveName(set string, created time.Time, kind string) string {
+	name := set + "-" + created.Format(ArchiveTimeFormat)
+	if kind == Incremental {
+		name += "-" + Incremental
+	}
+	return name + ".tar.gz"
+}
+
+func TestListAndRetain(t *testing.T) {
+	s := &countingStore{memStore: memStore{}}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+
+	full1 := testArchiveName("geneos", now.Add(-3*day), Full)
+	inc1 := testArchiveName("geneos", now.Add(-3*day+time.Hour), Incremental)
+	full2 := testArchiveName("geneos", now.Add(-day), Full)
+	inc2 := testArchiveName("geneos", now.Add(-day+time.Hour), Incremental)
+	for _, name := range []string{full1, inc1, full2, inc2} {
+		s.memStore[name] = nil
+	}
+	// other sets sharing the prefix and other files are ignored
+	s.memStore[testArchiveName("geneos-gateway", now, Full)] = nil
+	s.memStore["geneos-notes.tar.gz"] = nil
+	s.memStore["geneos-20261001120000.zip"] = nil
+
+	archives, err := List(s, "geneos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, a := range archives {
+		names = append(names, a.Name)
+	}
+	if want := []string{inc2, full2, inc1, full1}; !slices.Equal(names, want) {
+		t.Errorf("List() = %q, want %q", names, want)
+	}
+	if archives[0].Kind != Incremental || archives[1].Kind != Full || !archives[1].Created.Equal(now.Add(-day)) {
+		t.Errorf("List() archives = %+v", archives[:2])
+	}
+	if len(s.opened) != 0 {
+		t.Errorf("List() opened %q", s.opened)
+	}
+
+	// only the newest full archive is read for its manifest
+	writeTestArchive(t, s.memStore, full2, NewManifest("geneos", Full, "localhost"), map[string]string{"a": "a"})
+	latest, err := Latest(s, "geneos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Name != full2 || latest.Manifest == nil || !slices.Equal(s.opened, []string{full2}) {
+		t.Errorf("Latest() = %q, opened %q", latest.Name, s.opened)
+	}
+
+	// keeping one full archive removes the older one and its
+	// incremental, without reading any archives
+	s.opened = nil
+	removed, err := Retain(s, "geneos", 1, 0, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(removed)
+	if want := []string{s.Path(full1), s.Path(inc1)}; !slices.Equal(removed, want) {
+		t.Errorf("Retain() removed %q, want %q", removed, want)
+	}
+	if len(s.opened) != 0 {
+		t.Errorf("Retain() opened %q", s.opened)
+	}
+
+	// the newest full archive is kept however old it is, but not its
+	// expired incremental
+	removed, err = Retain(s, "geneos", 0, 22*time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{s.Path(inc2)}; !slices.Equal(removed, want) {
+		t.Errorf("Retain() with max age removed %q, want %q", removed, want)
+	}
+
+	delete(s.memStore, full2)
+	if _, err = Latest(s, "geneos"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Latest() with no full archives error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,439 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// emptySHA256 is the hex encoded SHA256 checksum of an empty payload
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3PartSize is the default size of each part of a multipart upload.
+// Archives no larger than this are uploaded in a single request.
+const s3PartSize = 64 << 20
+
+// s3MaxParts is the most parts allowed in a multipart upload, which
+// sets a larger part size for very large archives
+const s3MaxParts = 10000
+
+// s3Store is a bucket and prefix in an S3 compatible object store.
+// Requests use path-style addressing, under any path in the endpoint
+// URL, and are signed with AWS Signature Version 4.
+type s3Store struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	bucket    string
+	prefix    string
+	partSize  int64 // s3PartSize if zero
+}
+
+// s3Location returns an s3Store for an s3://BUCKET/PREFIX location.
+// The endpoint, region and credentials are taken from the `backup::s3`
+// settings in the program configuration, falling back to the standard
+// AWS environment variables.
+func s3Location(loc string) (s Store, name string, err error) {
+	u, err := url.Parse(loc)
+	if err != nil {
+		return
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("%s: no bucket given", loc)
+	}
+
+	cf := config.Global()
+	store := &s3Store{
+		client:    &http.Client{Timeout: config.Get[time.Duration](cf, cf.Join("backup", "s3", "timeout"), config.DefaultValue(5*time.Minute))},
+		region:    config.Get[string](cf, cf.Join("backup", "s3", "region"), config.DefaultValue(firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"))),
+		accessKey: config.Get[string](cf, cf.Join("backup", "s3", "access-key-id"), config.DefaultValue(os.Getenv("AWS_ACCESS_KEY_ID"))),
+		secretKey: config.Get[string](cf, cf.Join("backup", "s3", "secret-access-key"), config.DefaultValue(os.Getenv("AWS_SECRET_ACCESS_KEY"))),
+		token:     config.Get[string](cf, cf.Join("backup", "s3", "session-token"), config.DefaultValue(os.Getenv("AWS_SESSION_TOKEN"))),
+		bucket:    u.Host,
+	}
+	if store.region == "" {
+		store.region = "us-east-1"
+	}
+	if store.accessKey == "" || store.secretKey == "" {
+		return nil, "", fmt.Errorf("%s: no S3 credentials configured", loc)
+	}
+
+	endpoint := config.Get[string](cf, cf.Join("backup", "s3", "endpoint"), config.DefaultValue(firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")))
+	if endpoint == "" {
+		endpoint = "https://s3." + store.region + ".amazonaws.com"
+	}
+	if store.endpoint, err = url.Parse(endpoint); err != nil {
+		return nil, "", fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		store.prefix = key
+		return store, "", nil
+	}
+	if dir := path.Dir(key); dir != "." {
+		store.prefix = dir + "/"
+	}
+	return store, path.Base(key), nil
+}
+
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Create returns a writer that buffers the archive in a local temporary
+// file and uploads it to the object store when closed
+func (s *s3Store) Create(name string) (io.WriteCloser, error) {
+	f, err := os.CreateTemp("", "geneos-backup-*")
+	if err != nil {
+		return nil, err
+	}
+	return &s3Upload{store: s, key: s.prefix + name, file: f, hash: sha256.New()}, nil
+}
+
+func (s *s3Store) Open(name string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, s.prefix+name, nil, nil, 0, emptySHA256)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Store) Remove(name string) error {
+	resp, err := s.do(http.MethodDelete, s.prefix+name, nil, nil, 0, emptySHA256)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listBucketResult is the subset of the ListObjectsV2 response used
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the names of the objects directly under the store prefix
+func (s *s3Store) List() (names []string, err error) {
+	query := url.Values{
+		"list-type": []string{"2"},
+		"prefix":    []string{s.prefix},
+		"delimiter": []string{"/"},
+	}
+	for {
+		resp, err := s.do(http.MethodGet, "", query, nil, 0, emptySHA256)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			if name := strings.TrimPrefix(c.Key, s.prefix); name != "" && !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s *s3Store) Path(name string) string {
+	return "s3://" + s.bucket + "/" + s.prefix + name
+}
+
+// do makes a signed request for key in the store's bucket and returns
+// the response if the status is 2xx. On other statuses the response
+// body is closed and returned in the error, with 404 wrapping
+// fs.ErrNotExist.
+func (s *s3Store) do(method, key string, query url.Values, body io.Reader, size int64, payloadHash string) (resp *http.Response, err error) {
+	// keep any path on the endpoint, for gateways under a prefix
+	uri := strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + "/" + s3Encode(s.bucket, false)
+	if key != "" {
+		uri += "/" + s3Encode(key, false)
+	}
+	rawQuery := s3Query(query)
+
+	u := s.endpoint.Scheme + "://" + s.endpoint.Host + uri
+	if rawQuery != "" {
+		u += "?" + rawQuery
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return
+	}
+	req.ContentLength = size
+	s.sign(req, uri, rawQuery, payloadHash, time.Now().UTC())
+
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	if resp.StatusCode < 300 {
+		return
+	}
+
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("%s %s: %s %s", method, s.Path(strings.TrimPrefix(key, s.prefix)), resp.Status, strings.TrimSpace(string(b)))
+	if resp.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return nil, err
+}
+
+// sign adds the Signature Version 4 headers to req, where uri and
+// rawQuery are the canonical, already encoded, path and query
+func (s *s3Store) sign(req *http.Request, uri, rawQuery, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", amzDate)
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.token != "" {
+		req.Header.Set("x-amz-security-token", s.token)
+		signed = append(signed, "x-amz-security-token")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signed {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		rawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, k := range []string{date, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, k)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Encode URI encodes s as required for Signature Version 4, leaving
+// "/" unencoded unless encodeSlash is true
+func s3Encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Query returns the canonical query string for query, which is also
+// used as the request query string
+func s3Query(query url.Values) string {
+	var params []string
+	for k, vs := range query {
+		for _, v := range vs {
+			params = append(params, s3Encode(k, true)+"="+s3Encode(v, true))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// s3Upload buffers an archive in a temporary file, calculating the
+// checksum as it goes, and uploads it on Close
+type s3Upload struct {
+	store *s3Store
+	key   string
+	file  *os.File
+	hash  hash.Hash
+	size  int64
+}
+
+func (u *s3Upload) Write(p []byte) (n int, err error) {
+	n, err = u.file.Write(p)
+	u.hash.Write(p[:n])
+	u.size += int64(n)
+	return
+}
+
+// Close uploads the archive, using a multipart upload if it is larger
+// than the part size, as single requests are limited to 5GB
+func (u *s3Upload) Close() (err error) {
+	defer os.Remove(u.file.Name())
+	defer u.file.Close()
+
+	partSize := u.store.partSize
+	if partSize <= 0 {
+		partSize = s3PartSize
+	}
+	if u.size > partSize {
+		return u.multipart(max(partSize, (u.size+s3MaxParts-1)/s3MaxParts))
+	}
+
+	if _, err = u.file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	resp, err := u.store.do(http.MethodPut, u.key, nil, u.file, u.size, hex.EncodeToString(u.hash.Sum(nil)))
+	if err != nil {
+		return
+	}
+	return resp.Body.Close()
+}
+
+// s3Part is a completed part of a multipart upload
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// multipart uploads the archive in parts of partSize. On any error the
+// upload is aborted so that the parts already uploaded are not kept by
+// the object store.
+func (u *s3Upload) multipart(partSize int64) (err error) {
+	s := u.store
+
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	resp, err := s.do(http.MethodPost, u.key, url.Values{"uploads": []string{""}}, nil, 0, emptySHA256)
+	if err != nil {
+		return
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiate)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	upload := url.Values{"uploadId": []string{initiate.UploadID}}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		if resp, err := s.do(http.MethodDelete, u.key, upload, nil, 0, emptySHA256); err == nil {
+			resp.Body.Close()
+		} else {
+			log.Debug("cannot abort multipart upload", slog.String("key", u.key), slog.Any("error", err))
+		}
+	}()
+
+	var complete struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}
+	for n, offset := 1, int64(0); offset < u.size; n, offset = n+1, offset+partSize {
+		part := io.NewSectionReader(u.file, offset, min(partSize, u.size-offset))
+		var sum string
+		if sum, err = Checksum(part); err != nil {
+			return
+		}
+		if _, err = part.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		query := url.Values{"partNumber": []string{strconv.Itoa(n)}, "uploadId": upload["uploadId"]}
+		if resp, err = s.do(http.MethodPut, u.key, query, part, part.Size(), sum); err != nil {
+			return
+		}
+		resp.Body.Close()
+		complete.Parts = append(complete.Parts, s3Part{PartNumber: n, ETag: resp.Header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(body)
+	if resp, err = s.do(http.MethodPost, u.key, upload, bytes.NewReader(body), int64(len(body)), hex.EncodeToString(sum[:])); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// errors completing the upload can be returned with a 200 status
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err = xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return
+	}
+	if result.XMLName.Local == "Error" {
+		err = fmt.Errorf("POST %s: %s %s", s.Path(strings.TrimPrefix(u.key, s.prefix)), result.Code, result.Message)
+	}
+	return
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// Store is a directory-like location that archives are written to and
+// read from. Names are relative to the store.
+type Store interface {
+	Create(name string) (io.WriteCloser, error)
+	Open(name string) (io.ReadCloser, error)
+	Remove(name string) error
+
+	// List returns the names of all the files in the store
+	List() ([]string, error)
+
+	// Path returns the full location of name, for messages
+	Path(name string) string
+}
+
+// Location parses loc and returns the store for the directory part and
+// the file name, if any. loc can be:
+//
+//   - a local path
+//   - a path on a configured `geneos host` in the form HOST:PATH
+//   - an S3 compatible object store URL in the form s3://BUCKET/PREFIX
+//
+// If loc ends in a "/", or is an existing directory, then name is
+// empty. For S3 locations only a trailing "/", or a bucket without a
+// prefix, indicate a directory.
+func Location(loc string) (s Store, name string, err error) {
+	if strings.HasPrefix(loc, "s3://") {
+		return s3Location(loc)
+	}
+
+	h := geneos.LOCAL
+	p := loc
+	if hostname, rest, ok := strings.Cut(loc, ":"); ok && !strings.Contains(hostname, "/") {
+		if rh := geneos.GetHost(hostname); rh != nil && rh != geneos.ALL && rh.Exists() {
+			h, p = rh, rest
+		}
+	}
+	if p == "" {
+		p = "."
+	}
+
+	if strings.HasSuffix(p, "/") {
+		return &hostStore{h: h, dir: path.Clean(p)}, "", nil
+	}
+	if st, err := h.Stat(p); err == nil && st.IsDir() {
+		return &hostStore{h: h, dir: p}, "", nil
+	}
+	if h == geneos.LOCAL {
+		return &hostStore{h: h, dir: filepath.Dir(p)}, filepath.Base(p), nil
+	}
+	return &hostStore{h: h, dir: path.Dir(p)}, path.Base(p), nil
+}
+
+// hostStore is a directory on a geneos host, local or remote
+type hostStore struct {
+	h   *geneos.Host
+	dir string
+}
+
+func (s *hostStore) join(name string) string {
+	if s.h == geneos.LOCAL {
+		return filepath.Join(s.dir, name)
+	}
+	return path.Join(s.dir, name)
+}
+
+// Create creates the file name, and the directory if required
+func (s *hostStore) Create(name string) (io.WriteCloser, error) {
+	if err := s.h.MkdirAll(s.dir, 0775); err != nil {
+		return nil, err
+	}
+	return s.h.Create(s.join(name), 0644)
+}
+
+func (s *hostStore) Open(name string) (io.ReadCloser, error) {
+	return s.h.Open(s.join(name))
+}
+
+func (s *hostStore) Remove(name string) error {
+	return s.h.Remove(s.join(name))
+}
+
+func (s *hostStore) List() (names []string, err error) {
+	entries, err := s.h.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	return
+}
+
+func (s *hostStore) Path(name string) string {
+	return s.h.HostPath(s.join(name))
+}
+
+// IsLocal returns true if s is a directory on the local host
+func IsLocal(s Store) bool {
+	hs, ok := s.(*hostStore)
+	return ok && hs.h == geneos.LOCAL
+}
+
+// SpoolStore reads archives from a Store through local temporary
+// copies, so that each archive is only fetched once however many times
+// it is opened, for example to read the manifest, verify it and then
+// restore it. Other methods use the underlying Store. Close removes the
+// copies.
+type SpoolStore struct {
+	Store
+
+	mutex sync.Mutex
+	dir   string
+	files map[string]string
+}
+
+// Spool returns a SpoolStore for s
+func Spool(s Store) *SpoolStore {
+	return &SpoolStore{Store: s, files: make(map[string]string)}
+}
+
+// Open returns a reader for the local copy of name, fetching it from
+// the underlying Store the first time
+func (s *SpoolStore) Open(name string) (io.ReadCloser, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := s.files[name]; ok {
+		return os.Open(p)
+	}
+
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "geneos-archive-*")
+		if err != nil {
+			return nil, err
+		}
+		s.dir = dir
+	}
+
+	r, err := s.Store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f, err := os.CreateTemp(s.dir, "archive-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	s.files[name] = f.Name()
+	return f, nil
+}
+
+// Close removes all the local copies
+func (s *SpoolStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.dir == "" {
+		return nil
+	}
+	s.files = make(map[string]string)
+	dir := s.dir
+	s.dir = ""
+	return os.RemoveAll(dir)
+}
@@ -52,6 +52,12 @@ var log = cordial.Logger
 // them disabled
 const DisableExtension = "disabled"
 
+// StoppedExtension is the suffix of the file written to the instance
+// directory when an instance is stopped by a user, and removed when it
+// is next started, so that a supervisor can tell a deliberate stop
+// from a failure
+const StoppedExtension = "stopped"
+
 // Initialise a Geneos environment by creating a directory structure and
 // then it calls the initialisation functions for each component type
 // registered.
@@ -29,6 +29,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/config"
@@ -490,6 +491,8 @@ func LoadHostConfig() {
 			host.Port(config.Get[uint16](v, "port", config.DefaultValue(22))),
 			host.Password(config.Get[config.Secret](v, "password")),
 			host.PrivateKeyFiles(config.Get[[]string](v, "privatekeys")...),
+			host.MaxSessions(config.Get[int](v, "maxsessions", config.DefaultValue(host.DefaultMaxSessions))),
+			host.KeepAlive(config.Get[time.Duration](v, "keepalive", config.DefaultValue(host.DefaultKeepAlive))),
 		)
 
 		hosts.Store(name, &Host{
@@ -277,6 +277,12 @@ func write(i geneos.Instance) (err error) {
 
 	lpKeys := slices.Collect(maps.Keys(i.Type().LegacyParameters))
 
+	// keep the configuration from before the first recorded revision,
+	// as it is about to be overwritten. history is best effort.
+	if err := recordOriginal(i); err != nil {
+		i.Log().Debug("recording original config failed", slog.Any("error", err))
+	}
+
 	if err = i.Config().Write(i.Type().Name,
 		config.Host(i.Host()),
 		config.SearchDirs(Home(i)),
@@ -292,6 +298,11 @@ func write(i geneos.Instance) (err error) {
 		i.SetLoaded(st.ModTime())
 	}
 
+	// history is best effort, a failure should not fail the write
+	if err := recordRevision(i); err != nil {
+		i.Log().Debug("recording config revision failed", slog.Any("error", err))
+	}
+
 	return
 }
 
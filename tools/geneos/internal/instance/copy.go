@@ -223,6 +223,9 @@ func Copy(ct *geneos.Component, source, destination string, options ...CopyOptio
 		return
 	}
 
+	// regenerate any systemd units for the new instance
+	copyUnits(src, newdst, opts.move)
+
 	if stopped {
 		return Start(newdst)
 	}
@@ -0,0 +1,261 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/user"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/responses"
+)
+
+// HistoryDir is the name of the directory, in the instance home
+// directory, that holds the configuration history
+const HistoryDir = ".history"
+
+// ErrNoRevision is returned when a requested revision does not exist
+var ErrNoRevision = errors.New("no such revision")
+
+// Revision is a snapshot of the instance configuration file, recorded
+// each time the configuration is written
+type Revision struct {
+	Revision int       `json:"revision"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Command  string    `json:"command"`
+	Config   string    `json:"config"`
+}
+
+// historyPath returns the path to the file for revision rev
+func historyPath(i geneos.Instance, rev int) string {
+	return path.Join(i.Home(), HistoryDir, fmt.Sprintf("%06d.json", rev))
+}
+
+// revisionNumbers returns the revision numbers recorded for instance
+// i, in ascending order
+func revisionNumbers(i geneos.Instance) (revs []int, err error) {
+	entries, err := i.Host().ReadDir(path.Join(i.Home(), HistoryDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	for _, e := range entries {
+		n, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if rev, err := strconv.Atoi(n); err == nil {
+			revs = append(revs, rev)
+		}
+	}
+	slices.Sort(revs)
+	return
+}
+
+// Revisions returns all the recorded revisions of the configuration of
+// instance i, oldest first
+func Revisions(i geneos.Instance) (revisions []Revision, err error) {
+	revs, err := revisionNumbers(i)
+	if err != nil {
+		return
+	}
+	for _, rev := range revs {
+		r, err := GetRevision(i, rev)
+		if err != nil {
+			i.Log().Debug("skipping unreadable revision", slog.Int("revision", rev), slog.Any("error", err))
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+	return
+}
+
+// GetRevision returns revision rev of the configuration of instance i
+func GetRevision(i geneos.Instance, rev int) (r Revision, err error) {
+	b, err := i.Host().ReadFile(historyPath(i, rev))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w %d", ErrNoRevision, rev)
+		}
+		return
+	}
+	err = json.Unmarshal(b, &r)
+	return
+}
+
+// LatestRevision returns the most recent revision number for instance
+// i, or zero if there is no history
+func LatestRevision(i geneos.Instance) (rev int, err error) {
+	revs, err := revisionNumbers(i)
+	if err != nil || len(revs) == 0 {
+		return
+	}
+	return revs[len(revs)-1], nil
+}
+
+// recordOriginal saves the existing configuration file of instance i
+// as the first revision, before it is overwritten for the first time,
+// so that the configuration from before the history was started can
+// be rolled back to. Nothing is saved if there is already a history or
+// no configuration file.
+func recordOriginal(i geneos.Instance) (err error) {
+	revs, err := revisionNumbers(i)
+	if err != nil || len(revs) > 0 {
+		return
+	}
+	p := ComponentFilepath(i)
+	st, err := i.Host().Stat(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	b, err := i.Host().ReadFile(p)
+	if err != nil {
+		return
+	}
+	return saveRevision(i, nil, Revision{
+		Revision: 1,
+		Time:     st.ModTime().UTC().Truncate(time.Second),
+		Command:  "(configuration before history)",
+		Config:   string(b),
+	})
+}
+
+// recordRevision saves the current contents of the configuration file
+// of instance i as a new revision, unless it is unchanged from the
+// latest revision.
+func recordRevision(i geneos.Instance) (err error) {
+	b, err := i.Host().ReadFile(ComponentFilepath(i))
+	if err != nil {
+		return
+	}
+
+	revs, err := revisionNumbers(i)
+	if err != nil {
+		return
+	}
+
+	rev := 1
+	if len(revs) > 0 {
+		latest := revs[len(revs)-1]
+		if r, err := GetRevision(i, latest); err == nil && r.Config == string(b) {
+			return nil
+		}
+		rev = latest + 1
+	}
+
+	return saveRevision(i, revs, Revision{
+		Revision: rev,
+		Time:     time.Now().UTC().Truncate(time.Second),
+		User:     currentUser(),
+		Command:  commandLine(),
+		Config:   string(b),
+	})
+}
+
+// saveRevision writes r to the history of instance i, which already
+// has the revisions revs. The number of revisions kept is limited by
+// the global `historylimit` setting, default 50, with zero meaning no
+// limit.
+func saveRevision(i geneos.Instance, revs []int, r Revision) (err error) {
+	h := i.Host()
+
+	j, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return
+	}
+	if err = h.MkdirAll(path.Join(i.Home(), HistoryDir), 0775); err != nil {
+		return
+	}
+	if err = h.WriteFile(historyPath(i, r.Revision), j, 0664); err != nil {
+		return
+	}
+
+	// prune oldest revisions
+	limit := config.Get[int](config.Global(), "historylimit", config.DefaultValue(50))
+	revs = append(revs, r.Revision)
+	if limit > 0 && len(revs) > limit {
+		for _, old := range revs[:len(revs)-limit] {
+			if err := h.Remove(historyPath(i, old)); err != nil {
+				i.Log().Debug("cannot remove old revision", slog.Int("revision", old), slog.Any("error", err))
+			}
+		}
+	}
+	return nil
+}
+
+// Rollback writes revision rev of the configuration of instance i back
+// to the instance configuration file and then, as for Write, saves it
+// as a new revision and rebuilds and reloads the instance. Options are
+// passed to Write.
+func Rollback(i geneos.Instance, rev int, options ...ConfigOption) (resp *responses.General) {
+	r, err := GetRevision(i, rev)
+	if err != nil {
+		resp = responses.New[responses.General](i)
+		resp.Err = err
+		return
+	}
+	if err = i.Host().WriteFile(ComponentFilepath(i), []byte(r.Config), 0664); err != nil {
+		resp = responses.New[responses.General](i)
+		resp.Err = err
+		return
+	}
+	// loading merges into the existing configuration, so drop the
+	// cached instance and start again with a fresh one
+	i.Unload()
+	ni, err := GetWithHost(i.Host(), i.Type(), i.Name())
+	if err != nil {
+		resp = responses.New[responses.General](i)
+		resp.Err = err
+		return
+	}
+	resp = Write(ni, options...)
+	resp.Completed = slices.Insert(resp.Completed, 0, fmt.Sprintf("rolled back to revision %d", rev))
+	return
+}
+
+// currentUser returns the name of the user running the program
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// commandLine returns the command line of the running program, using
+// the base name of the executable
+func commandLine() string {
+	return strings.Join(append([]string{cordial.ExecutableName()}, os.Args[1:]...), " ")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instance
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRecordOriginal(t *testing.T) {
+	i := &testInstance{name: "history", home: t.TempDir()}
+	write := func(config string) {
+		t.Helper()
+		if err := os.WriteFile(ComponentFilepath(i), []byte(config), 0664); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no configuration file, nothing to save
+	if err := recordOriginal(i); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := revisionNumbers(i); len(revs) != 0 {
+		t.Fatalf("revisions %v saved without a configuration file", revs)
+	}
+
+	// the existing file is saved before it is first overwritten, and
+	// only once
+	write(`{"port": 7039}`)
+	for range 2 {
+		if err := recordOriginal(i); err != nil {
+			t.Fatal(err)
+		}
+		write(`{"port": 7040}`)
+		if err := recordRevision(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := Revisions(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revisions))
+	}
+	if revisions[0].Revision != 1 || revisions[0].Config != `{"port": 7039}` {
+		t.Errorf("first revision = %+v, want the original configuration", revisions[0])
+	}
+	if revisions[1].Revision != 2 || revisions[1].Config != `{"port": 7040}` {
+		t.Errorf("second revision = %+v, want the new configuration", revisions[1])
+	}
+}
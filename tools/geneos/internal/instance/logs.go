@@ -0,0 +1,208 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// Severity is the severity of a log entry. The zero value is used for
+// lines where no severity can be found.
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+var severityNames = map[Severity]string{
+	SeverityUnknown: "",
+	SeverityDebug:   "DEBUG",
+	SeverityInfo:    "INFO",
+	SeverityWarning: "WARN",
+	SeverityError:   "ERROR",
+	SeverityFatal:   "FATAL",
+}
+
+// String returns the canonical name of the severity, e.g. "WARN"
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalJSON encodes the severity as its name
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// ParseSeverity returns the Severity for the case-insensitive name s.
+// The names used by the various Geneos components are all recognised,
+// e.g. both "WARN" and "WARNING" or "FATAL" and "CRITICAL". An error is
+// returned if s is not recognised.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE", "DEBUG", "FINE", "FINER", "FINEST":
+		return SeverityDebug, nil
+	case "INFO", "NOTE", "NOTICE", "CONFIG":
+		return SeverityInfo, nil
+	case "WARN", "WARNING":
+		return SeverityWarning, nil
+	case "ERROR", "ERR", "SEVERE":
+		return SeverityError, nil
+	case "FATAL", "CRITICAL", "CRIT":
+		return SeverityFatal, nil
+	default:
+		return SeverityUnknown, fmt.Errorf("%w: unknown severity %q", geneos.ErrInvalidArgs, s)
+	}
+}
+
+// LogEntry is a single entry from an instance log file. Lines that do
+// not start with a recognised timestamp, such as Java stack traces, are
+// treated as continuations of the previous entry and are appended to
+// its Message and Text, separated by newlines.
+type LogEntry struct {
+	Time      time.Time `json:"time,omitzero"`
+	Severity  Severity  `json:"severity"`
+	Component string    `json:"component,omitempty"`
+	Message   string    `json:"message"`
+
+	// Text is the original text of the entry
+	Text string `json:"-"`
+}
+
+// timestamps in the formats used by Geneos components
+const logTimestamp = `(\d{4}-\d\d-\d\d[ T]\d\d:\d\d:\d\d(?:[.,]\d+)?(?:Z|[+-]\d\d:?\d\d)?)`
+
+var (
+	// Gateway, Netprobe, licd and other C++ components:
+	//
+	//   2026-01-02 10:11:12.123+0000 INFO: GatewayStartupManager Message
+	cppLogLine = regexp.MustCompile(`^` + logTimestamp + `\s+(?:\[[^\]]*\]\s+)?([A-Za-z]+):\s+(.*)$`)
+
+	// Webserver, Collection Agent and other Java components:
+	//
+	//   2026-01-02 10:11:12,123 INFO  [main] com.itrsgroup.Class - Message
+	javaLogLine = regexp.MustCompile(`^` + logTimestamp + `\s+(?:\[[^\]]*\]\s+)?([A-Z]+)\s+(?:\[[^\]]*\]\s+)?(?:(\S+)\s+-\s+)?(.*)$`)
+
+	// older C++ components, without a year:
+	//
+	//   <Fri Jan  2 10:11:12>  INFO: Message
+	oldLogLine = regexp.MustCompile(`^<(\w{3} \w{3} [ \d]\d \d\d:\d\d:\d\d)>\s+([A-Za-z]+):\s+(.*)$`)
+)
+
+var logTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseLogTime parses a timestamp matched by logTimestamp. Timestamps
+// without a zone are in local time.
+func parseLogTime(s string) (t time.Time, ok bool) {
+	s = strings.Replace(s, ",", ".", 1)
+	for _, layout := range logTimeLayouts {
+		var err error
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return
+}
+
+// ParseLogLine parses a single log line in one of the formats used by
+// Geneos components. ok is false if the line does not start a new
+// entry.
+func ParseLogLine(line string) (entry LogEntry, ok bool) {
+	var t time.Time
+
+	if m := cppLogLine.FindStringSubmatch(line); m != nil {
+		if t, ok = parseLogTime(m[1]); ok {
+			entry = LogEntry{Time: t, Message: m[3], Text: line}
+			entry.Severity, _ = ParseSeverity(m[2])
+			// the first word is the component, if there is more
+			if c, msg, found := strings.Cut(m[3], " "); found && c != "" {
+				entry.Component, entry.Message = c, strings.TrimSpace(msg)
+			}
+			return
+		}
+	}
+
+	if m := javaLogLine.FindStringSubmatch(line); m != nil {
+		if sev, err := ParseSeverity(m[2]); err == nil {
+			if t, ok = parseLogTime(m[1]); ok {
+				return LogEntry{Time: t, Severity: sev, Component: m[3], Message: m[4], Text: line}, true
+			}
+		}
+	}
+
+	if m := oldLogLine.FindStringSubmatch(line); m != nil {
+		var err error
+		if t, err = time.ParseInLocation("Mon Jan _2 15:04:05", m[1], time.Local); err == nil {
+			now := time.Now()
+			t = t.AddDate(now.Year(), 0, 0)
+			// a date in the future must be from last year
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			entry = LogEntry{Time: t, Message: m[3], Text: line}
+			entry.Severity, _ = ParseSeverity(m[2])
+			return entry, true
+		}
+	}
+
+	return LogEntry{}, false
+}
+
+// ParseLogs reads all the log entries from r. Any lines before the
+// first recognised entry are returned as a single entry with a zero
+// Time and an unknown severity.
+func ParseLogs(r io.Reader) (entries []LogEntry, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if entry, ok := ParseLogLine(line); ok {
+			entries = append(entries, entry)
+			continue
+		}
+		if len(entries) == 0 {
+			entries = append(entries, LogEntry{Message: line, Text: line})
+			continue
+		}
+		last := &entries[len(entries)-1]
+		last.Message += "\n" + line
+		last.Text += "\n" + line
+	}
+	err = scanner.Err()
+	return
+}
@@ -104,6 +104,7 @@ func Start(i geneos.Instance, opts ...any) error {
 	if err != nil {
 		return err
 	}
+	i.Host().Remove(ComponentFilepath(i, geneos.StoppedExtension))
 
 	fmt.Printf("%s started with PID %d\n", i, pid)
 	return nil
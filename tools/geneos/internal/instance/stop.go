@@ -19,6 +19,7 @@ package instance
 
 import (
 	"errors"
+	"log/slog"
 	"os"
 	"syscall"
 	"time"
@@ -39,6 +40,23 @@ func Stop(i geneos.Instance, force, kill bool) (err error) {
 		return os.ErrProcessDone
 	}
 
+	// mark the instance stopped before signalling it, so that a
+	// supervisor does not see it exit first, and remove the marker if
+	// the stop fails
+	markStopped(i)
+	defer func() {
+		if err != nil {
+			i.Host().Remove(ComponentFilepath(i, geneos.StoppedExtension))
+		}
+	}()
+
+	// an instance started by systemd is stopped through it, otherwise
+	// the unit restarts it
+	var stopped bool
+	if stopped, err = stopUnits(i, kill); stopped || err != nil {
+		return
+	}
+
 	if !kill {
 		if err = Signal(i, syscall.SIGTERM); err == os.ErrProcessDone {
 			return nil
@@ -70,3 +88,20 @@ func Stop(i geneos.Instance, force, kill bool) (err error) {
 	}
 	return
 }
+
+// markStopped records that the instance was stopped deliberately
+func markStopped(i geneos.Instance) {
+	f, err := i.Host().Create(ComponentFilepath(i, geneos.StoppedExtension), 0664)
+	if err != nil {
+		i.Log().Debug("cannot create stopped marker", slog.Any("error", err))
+		return
+	}
+	f.Close()
+}
+
+// WasStopped returns true if instance i was last stopped with Stop and
+// has not been started since
+func WasStopped(i geneos.Instance) bool {
+	st, err := i.Host().Stat(ComponentFilepath(i, geneos.StoppedExtension))
+	return err == nil && st.Mode().IsRegular()
+}
@@ -0,0 +1,332 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// systemd unit files are written to these directories. User units are
+// relative to the home directory of the user on the host.
+const (
+	systemUnitDir = "/etc/systemd/system"
+	userUnitDir   = ".config/systemd/user"
+)
+
+// UnitName returns the systemd unit name for instance i, e.g.
+// `geneos-gateway-Demo\x20Gateway.service`. Characters that are not
+// valid in unit names are escaped in the same way as `systemd-escape`.
+func UnitName(i geneos.Instance) string {
+	var b strings.Builder
+	for n, c := range []byte(i.Name()) {
+		switch {
+		case c == '/':
+			b.WriteByte('-')
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == ':', c == '_', c == '-', c == '.' && n > 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return "geneos-" + i.Type().String() + "-" + b.String() + ".service"
+}
+
+// UnitPath returns the path to the systemd unit file for instance i on
+// its host. If system is true then the path is for a system unit,
+// otherwise a user unit.
+func UnitPath(i geneos.Instance, system bool) (p string, err error) {
+	if system {
+		return path.Join(systemUnitDir, UnitName(i)), nil
+	}
+	var home string
+	if i.Host().IsLocalhost() {
+		home, err = os.UserHomeDir()
+	} else {
+		// the remote working directory is the login directory
+		home, err = i.Host().Getwd()
+	}
+	if err != nil {
+		return
+	}
+	return path.Join(home, userUnitDir, UnitName(i)), nil
+}
+
+// UnitEnvPath returns the path to the environment file used by the
+// systemd units for instance i, in the instance directory
+func UnitEnvPath(i geneos.Instance) string {
+	return ComponentFilepath(i, "env")
+}
+
+// SystemdUnit returns the text of a systemd unit for instance i, built
+// from the same command line, environment and working directory as
+// used by Start, and the contents of the environment file the unit
+// reads, at UnitEnvPath. The environment is kept out of the unit as it
+// may contain decoded secrets. If system is true then the unit is for
+// the system service manager and runs as the instance user, otherwise
+// it is a user unit.
+//
+// If noDecode is set then secure environment variables are not
+// decoded, so that the environment can be shown to a user without
+// revealing secrets, but the resulting environment should not be
+// installed.
+func SystemdUnit(i geneos.Instance, system, noDecode bool) (unit, env string, err error) {
+	cmd, err := BuildCmd(i, noDecode)
+	if err != nil {
+		return
+	}
+	if cmd == nil {
+		return "", "", fmt.Errorf("BuildCmd() returned nil")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated by geneos for %s\n", IDString(i))
+	fmt.Fprintf(&b, "# changes are lost when the unit is next generated\n\n")
+
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=Geneos %s %s\n", i.Type(), systemdEscape(i.Name()))
+	fmt.Fprintf(&b, "Wants=network-online.target\n")
+	fmt.Fprintf(&b, "After=network-online.target\n\n")
+
+	fmt.Fprintf(&b, "[Service]\n")
+	fmt.Fprintf(&b, "Type=simple\n")
+	if system {
+		username := config.Get[string](i.Config(), "user")
+		if username == "" {
+			username = i.Host().Username()
+		}
+		if username != "" {
+			fmt.Fprintf(&b, "User=%s\n", username)
+		}
+	}
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(cmd.Dir, false))
+	fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdEscape(UnitEnvPath(i)))
+	execStart := []string{systemdQuote(cmd.Path, true)}
+	for _, a := range cmd.Args[1:] {
+		execStart = append(execStart, systemdQuote(a, true))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(execStart, " "))
+
+	errfile := ComponentFilepath(i, "txt")
+	fmt.Fprintf(&b, "StandardOutput=append:%s\n", systemdEscape(errfile))
+	fmt.Fprintf(&b, "StandardError=append:%s\n", systemdEscape(errfile))
+	fmt.Fprintf(&b, "LimitCORE=infinity\n")
+	if cpus := config.Get[string](i.Config(), "cpus"); cpus != "" {
+		fmt.Fprintf(&b, "CPUAffinity=%s\n", strings.ReplaceAll(cpus, ",", " "))
+	}
+	fmt.Fprintf(&b, "Restart=on-failure\n")
+	fmt.Fprintf(&b, "RestartSec=10\n\n")
+
+	fmt.Fprintf(&b, "[Install]\n")
+	if system {
+		fmt.Fprintf(&b, "WantedBy=multi-user.target\n")
+	} else {
+		fmt.Fprintf(&b, "WantedBy=default.target\n")
+	}
+
+	var e strings.Builder
+	for _, v := range cmd.Env {
+		name, value, _ := strings.Cut(v, "=")
+		fmt.Fprintf(&e, "%s=%s\n", name, envQuote(value))
+	}
+
+	return b.String(), e.String(), nil
+}
+
+// systemdEscape escapes specifiers in s
+func systemdEscape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// systemdQuote quotes s, if required, for use as a value in a unit
+// file. If command is true then s is part of a command line and `$`
+// is also escaped.
+func systemdQuote(s string, command bool) string {
+	s = systemdEscape(s)
+	if command {
+		s = strings.ReplaceAll(s, "$", "$$")
+	}
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// envQuote quotes s, if required, for use as a value in an environment
+// file
+func envQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\$`#;") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		if strings.ContainsRune("\"\\$`", c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// UnitInstalled returns true if there is a systemd unit installed for
+// instance i. If system is true then check for a system unit,
+// otherwise a user unit.
+func UnitInstalled(i geneos.Instance, system bool) bool {
+	p, err := UnitPath(i, system)
+	if err != nil {
+		return false
+	}
+	st, err := i.Host().Stat(p)
+	return err == nil && st.Mode().IsRegular()
+}
+
+// InstallUnit writes a systemd unit for instance i, and the environment
+// file it reads, and reloads the service manager. If system is true then a system unit is installed,
+// otherwise a user unit. The unit is enabled if the instance is set to
+// autostart and is not disabled, otherwise it is disabled. The unit
+// file is written even if systemctl fails, which is returned as an
+// error.
+func InstallUnit(i geneos.Instance, system bool) (p string, err error) {
+	unit, env, err := SystemdUnit(i, system, false)
+	if err != nil {
+		return
+	}
+	if p, err = UnitPath(i, system); err != nil {
+		return
+	}
+	h := i.Host()
+	// the environment may contain decoded secrets
+	if err = h.WriteFile(UnitEnvPath(i), []byte(env), 0600); err != nil {
+		return
+	}
+	if err = h.MkdirAll(path.Dir(p), 0775); err != nil {
+		return
+	}
+	if err = h.WriteFile(p, []byte(unit), 0644); err != nil {
+		return
+	}
+
+	if err = systemctl(i, system, "daemon-reload"); err != nil {
+		return
+	}
+	if IsAutoStart(i) && !IsDisabled(i) {
+		err = systemctl(i, system, "enable", UnitName(i))
+	} else {
+		err = systemctl(i, system, "disable", UnitName(i))
+	}
+	return
+}
+
+// RemoveUnits removes any system and user systemd units for instance
+// i, disabling them first, and their environment file, and returns the
+// paths of the units removed. Units are not stopped.
+func RemoveUnits(i geneos.Instance) (paths []string, err error) {
+	for _, system := range []bool{false, true} {
+		if !UnitInstalled(i, system) {
+			continue
+		}
+		p, _ := UnitPath(i, system)
+		if err := systemctl(i, system, "disable", UnitName(i)); err != nil {
+			i.Log().Debug("disabling unit", slog.String("unit", p), slog.Any("error", err))
+		}
+		if err = i.Host().Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		paths = append(paths, p)
+		if err := systemctl(i, system, "daemon-reload"); err != nil {
+			i.Log().Debug("reloading units", slog.Any("error", err))
+		}
+	}
+	if len(paths) > 0 {
+		if err = i.Host().Remove(UnitEnvPath(i)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+	return paths, nil
+}
+
+// stopUnits stops instance i through systemd if it is running under an
+// active user or system unit, as otherwise the unit would restart it.
+// If kill is true then the unit is sent a SIGKILL first. stopped is
+// true if an active unit was found.
+func stopUnits(i geneos.Instance, kill bool) (stopped bool, err error) {
+	for _, system := range []bool{false, true} {
+		if !UnitInstalled(i, system) || systemctl(i, system, "is-active", "--quiet", UnitName(i)) != nil {
+			continue
+		}
+		stopped = true
+		if kill {
+			if err = systemctl(i, system, "kill", "--signal=SIGKILL", UnitName(i)); err != nil {
+				return
+			}
+		}
+		// stopping a killed unit cancels its restart
+		if err = systemctl(i, system, "stop", UnitName(i)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// copyUnits installs units for instance dst in the same scopes as
+// those installed for src, after a copy or move. If move is true then
+// the units for src are removed. Errors are logged, as the instance
+// itself has been copied.
+func copyUnits(src, dst geneos.Instance, move bool) {
+	for _, system := range []bool{false, true} {
+		if !UnitInstalled(src, system) {
+			continue
+		}
+		if p, err := InstallUnit(dst, system); err != nil {
+			dst.Log().Warn("cannot install systemd unit", slog.String("unit", p), slog.Any("error", err))
+		}
+	}
+	if move {
+		if _, err := RemoveUnits(src); err != nil {
+			src.Log().Warn("cannot remove systemd unit", slog.Any("error", err))
+		}
+	}
+}
+
+// systemctl runs the systemctl command with args on the host of
+// instance i, for the system or user service manager
+func systemctl(i geneos.Instance, system bool, args ...string) (err error) {
+	if !system {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := exec.Command("systemctl", args...)
+	out, err := i.Host().Run(cmd)
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return
+}
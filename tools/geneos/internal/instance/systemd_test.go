@@ -0,0 +1,180 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instance
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+)
+
+// testInstance is a minimal local instance, enough to build a command
+// and a systemd unit without any files or systemd
+type testInstance struct {
+	name string
+	home string
+	cf   *config.Config
+	args []string
+	env  []string
+}
+
+var testGateway = &geneos.Component{Name: "gateway"}
+
+func (t *testInstance) Config() *config.Config      { return t.cf }
+func (t *testInstance) SetConfig(cf *config.Config) { t.cf = cf }
+func (t *testInstance) Name() string                { return t.name }
+func (t *testInstance) Home() string                { return t.home }
+func (t *testInstance) Type() *geneos.Component     { return testGateway }
+func (t *testInstance) Host() *geneos.Host          { return geneos.NewHost(geneos.LOCALHOST) }
+func (t *testInstance) Log() *slog.Logger           { return slog.Default() }
+func (t *testInstance) String() string              { return t.Type().String() + " " + t.name }
+func (t *testInstance) Load() error                 { return nil }
+func (t *testInstance) Unload() error               { return nil }
+func (t *testInstance) Loaded() time.Time           { return time.Time{} }
+func (t *testInstance) SetLoaded(time.Time)         {}
+func (t *testInstance) Add(string, uint16, bool) error {
+	return nil
+}
+func (t *testInstance) Command(bool) ([]string, []string, string, error) {
+	return t.args, t.env, t.home, nil
+}
+func (t *testInstance) Reload() error      { return nil }
+func (t *testInstance) Rebuild(bool) error { return nil }
+
+func TestUnitName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Demo", `geneos-gateway-Demo.service`},
+		{"Demo Gateway", `geneos-gateway-Demo\x20Gateway.service`},
+		{"prod/eu", `geneos-gateway-prod-eu.service`},
+		{"gw_1:a-b.c", `geneos-gateway-gw_1:a-b.c.service`},
+		{".hidden", `geneos-gateway-\x2ehidden.service`},
+		{`100%$"'\`, `geneos-gateway-100\x25\x24\x22\x27\x5c.service`},
+		{"café", `geneos-gateway-caf\xc3\xa9.service`},
+	}
+	for _, tt := range tests {
+		if got := UnitName(&testInstance{name: tt.name}); got != tt.want {
+			t.Errorf("UnitName(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		s       string
+		command bool
+		want    string
+	}{
+		{"plain", false, `plain`},
+		{"", false, `""`},
+		{"two words", false, `"two words"`},
+		{"tab\there", false, "\"tab\there\""},
+		{"100%", false, `100%%`},
+		{"%h/%n", true, `%%h/%%n`},
+		{"$HOME", false, `$HOME`},
+		{"$HOME", true, `$$HOME`},
+		{"${HOME}/x y", true, `"$${HOME}/x y"`},
+		{`say "hi"`, true, `"say \"hi\""`},
+		{`it's`, false, `"it's"`},
+		{`C:\geneos`, false, `"C:\\geneos"`},
+		{"a;b", true, `"a;b"`},
+		{`50% "$x"`, true, `"50%% \"$$x\""`},
+	}
+	for _, tt := range tests {
+		if got := systemdQuote(tt.s, tt.command); got != tt.want {
+			t.Errorf("systemdQuote(%q, %v) = %s, want %s", tt.s, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	t.Setenv("HOME", "/home/geneos")
+
+	cf := config.New()
+	config.Set(cf, "program", "/opt/geneos/packages/gateway/active_prod/gateway2.linux_64")
+	config.Set(cf, "user", "geneos")
+	config.Set(cf, "cpus", "0,1")
+	config.Set(cf, "env", []string{"GREETING=hello world", "RATE=100%", `QUOTE="$x"`})
+	i := &testInstance{
+		name: "Demo 100%",
+		home: "/opt/geneos/gateway/gateways/Demo 100%",
+		cf:   cf,
+		args: []string{"Demo 100%", "-resources-dir", "/opt/geneos/resources", "-setup", "$HOME/setup.xml"},
+	}
+
+	want := `# generated by geneos for gateway:Demo 100%
+# changes are lost when the unit is next generated
+
+[Unit]
+Description=Geneos gateway Demo 100%%
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+User=geneos
+WorkingDirectory="/opt/geneos/gateway/gateways/Demo 100%%"
+EnvironmentFile=/opt/geneos/gateway/gateways/Demo 100%%/gateway.env
+ExecStart=/opt/geneos/packages/gateway/active_prod/gateway2.linux_64 "Demo 100%%" -resources-dir /opt/geneos/resources -setup $$HOME/setup.xml
+StandardOutput=append:/opt/geneos/gateway/gateways/Demo 100%%/gateway.txt
+StandardError=append:/opt/geneos/gateway/gateways/Demo 100%%/gateway.txt
+LimitCORE=infinity
+CPUAffinity=0 1
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`
+	wantEnv := `GREETING="hello world"
+RATE=100%
+QUOTE="\"\$x\""
+PATH=/usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin
+HOME=/home/geneos
+`
+	got, env, err := SystemdUnit(i, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("system unit:\n%s\nwant:\n%s", got, want)
+	}
+	if env != wantEnv {
+		t.Errorf("environment file:\n%s\nwant:\n%s", env, wantEnv)
+	}
+
+	// user units have no User and are wanted by the default target
+	got, _, err = SystemdUnit(i, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"User=", "multi-user.target"} {
+		if strings.Contains(got, s) {
+			t.Errorf("user unit contains %q:\n%s", s, got)
+		}
+	}
+	if !strings.HasSuffix(got, "[Install]\nWantedBy=default.target\n") {
+		t.Errorf("user unit not wanted by default.target:\n%s", got)
+	}
+}
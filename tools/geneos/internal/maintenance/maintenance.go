@@ -0,0 +1,472 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance applies scheduled maintenance windows to running
+// Gateways.
+//
+// Windows are read from a YAML schedule file. While a window is active
+// its targets are snoozed on the selected Gateways using the REST
+// Commands API and when it ends they are unsnoozed. The items snoozed
+// are saved in a state file so that overlapping windows, changes to
+// the schedule and restarts are handled: an item stays snoozed while
+// any window covering it is active and items snoozed before a restart
+// are unsnoozed once their windows have ended. Items that were not
+// snoozed by the scheduler are never unsnoozed.
+package maintenance
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/geneos/api"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+	"github.com/itrs-group/cordial/pkg/rest"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+)
+
+var log = cordial.Logger
+
+// Snoozer is the part of the Gateway REST Commands API used to apply
+// windows. It is satisfied by commands.Connection.
+type Snoozer interface {
+	SnoozeManual(target *xpath.XPath, info string) error
+	Unsnooze(target *xpath.XPath, info string) error
+}
+
+// Options control the scheduler
+type Options struct {
+	// ScheduleFile is the YAML file with the windows, which is read
+	// again before each check so that changes are applied without a
+	// restart
+	ScheduleFile string
+
+	// StateFile records the items snoozed by the scheduler
+	StateFile string
+
+	// Interval is the time between checks
+	Interval time.Duration
+
+	// Dial returns a connection to the REST Commands API of a Gateway
+	Dial func(i geneos.Instance) (Snoozer, error)
+}
+
+// Snoozed is an item snoozed by the scheduler
+type Snoozed struct {
+	Gateway string    `json:"gateway"`
+	Target  string    `json:"target"`
+	Windows []string  `json:"windows"`
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"`
+
+	reason string
+}
+
+// State is the saved state of the scheduler
+type State struct {
+	PID     int        `json:"pid"`
+	Started time.Time  `json:"started"`
+	Updated time.Time  `json:"updated"`
+	Snoozed []*Snoozed `json:"snoozed"`
+}
+
+// Scheduler applies the windows in a schedule file
+type Scheduler struct {
+	h       *geneos.Host
+	opts    Options
+	started time.Time
+
+	schedule *Schedule
+	snoozed  map[string]*Snoozed
+
+	client    api.APIClient
+	clientURL string
+	dataview  *api.Dataview
+	headlines map[string]bool
+}
+
+// New returns a Scheduler for the Gateways on host h, loading the
+// items snoozed by any earlier run from the state file
+func New(h *geneos.Host, opts Options) (s *Scheduler, err error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	s = &Scheduler{
+		h:       h,
+		opts:    opts,
+		snoozed: map[string]*Snoozed{},
+	}
+	st, err := ReadState(opts.StateFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, sn := range st.Snoozed {
+		s.snoozed[snoozeKey(sn.Gateway, sn.Target)] = sn
+	}
+	return s, nil
+}
+
+func snoozeKey(gateway, target string) string {
+	return gateway + " " + target
+}
+
+// Run checks the schedule every interval until ctx is cancelled. Items
+// snoozed for windows that are still active are left snoozed when Run
+// returns.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.started = time.Now()
+	log.Info("maintenance scheduler started", slog.Int("pid", os.Getpid()), slog.String("schedule", s.opts.ScheduleFile), slog.Duration("interval", s.opts.Interval))
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check(time.Now())
+		select {
+		case <-ctx.Done():
+			log.Info("maintenance scheduler stopped", slog.Int("snoozed", len(s.snoozed)))
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check applies the schedule at time now, snoozing the targets of
+// windows that have started and unsnoozing those of windows that have
+// ended, then saves the state and publishes the dataview
+func (s *Scheduler) Check(now time.Time) {
+	sched, err := ReadSchedule(s.opts.ScheduleFile)
+	if err != nil {
+		if s.schedule == nil {
+			log.Error("cannot read schedule", slog.Any("error", err))
+			return
+		}
+		log.Error("cannot read schedule, using last good copy", slog.Any("error", err))
+		sched = s.schedule
+	}
+	s.schedule = sched
+
+	gatewayCT := geneos.ParseComponent("gateway")
+	gateways := map[string]geneos.Instance{}
+	for _, i := range instance.Instances(s.h, gatewayCT) {
+		gateways[instance.IDString(i)] = i
+	}
+
+	// the items that should be snoozed now
+	wanted := map[string]*Snoozed{}
+	for _, w := range sched.Windows {
+		o, ok := w.Current(now)
+		if !ok {
+			continue
+		}
+		for _, i := range instance.Instances(s.h, gatewayCT, instance.MatchNames(w.Gateways...)) {
+			for _, t := range w.Targets {
+				key := snoozeKey(instance.IDString(i), t.String())
+				sn, ok := wanted[key]
+				if !ok {
+					sn = &Snoozed{Gateway: instance.IDString(i), Target: t.String(), Since: now, reason: w.Reason}
+					wanted[key] = sn
+				}
+				sn.Windows = append(sn.Windows, w.Name)
+				if o.End.After(sn.Until) {
+					sn.Until = o.End
+				}
+			}
+		}
+	}
+
+	conns := map[string]Snoozer{}
+	dial := func(id string) (c Snoozer, err error) {
+		if c, ok := conns[id]; ok {
+			return c, nil
+		}
+		if c, err = s.opts.Dial(gateways[id]); err != nil {
+			return
+		}
+		conns[id] = c
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(wanted)) {
+		sn := wanted[key]
+		if cur, ok := s.snoozed[key]; ok {
+			// already snoozed, maybe by another window
+			cur.Windows, cur.Until = sn.Windows, sn.Until
+			continue
+		}
+		c, err := dial(sn.Gateway)
+		if err == nil {
+			err = snooze(c, sn)
+		}
+		if err != nil {
+			log.Error("cannot snooze", slog.String("gateway", sn.Gateway), slog.String("target", sn.Target), slog.Any("error", err))
+			continue
+		}
+		log.Info("snoozed", slog.String("gateway", sn.Gateway), slog.String("target", sn.Target), slog.Any("windows", sn.Windows), slog.Time("until", sn.Until))
+		s.snoozed[key] = sn
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(s.snoozed)) {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		sn := s.snoozed[key]
+		if _, ok := gateways[sn.Gateway]; !ok {
+			log.Warn("gateway no longer exists, forgetting snoozed item", slog.String("gateway", sn.Gateway), slog.String("target", sn.Target))
+			delete(s.snoozed, key)
+			continue
+		}
+		c, err := dial(sn.Gateway)
+		if err == nil {
+			err = unsnooze(c, sn)
+		}
+		if err != nil {
+			log.Error("cannot unsnooze", slog.String("gateway", sn.Gateway), slog.String("target", sn.Target), slog.Any("error", err))
+			continue
+		}
+		log.Info("unsnoozed", slog.String("gateway", sn.Gateway), slog.String("target", sn.Target), slog.Any("windows", sn.Windows))
+		delete(s.snoozed, key)
+	}
+
+	if err = s.writeState(now); err != nil {
+		log.Error("cannot write state", slog.String("file", s.opts.StateFile), slog.Any("error", err))
+	}
+	if err = s.publish(sched, now); err != nil {
+		log.Error("cannot publish dataview", slog.String("url", sched.Netprobe.URL), slog.Any("error", err))
+	}
+}
+
+func snooze(c Snoozer, sn *Snoozed) error {
+	x, err := xpath.Parse(sn.Target)
+	if err != nil {
+		return err
+	}
+	info := fmt.Sprintf("maintenance window %s until %s", strings.Join(sn.Windows, ", "), sn.Until.Format(time.RFC3339))
+	if sn.reason != "" {
+		info += ": " + sn.reason
+	}
+	return c.SnoozeManual(x, info)
+}
+
+func unsnooze(c Snoozer, sn *Snoozed) error {
+	x, err := xpath.Parse(sn.Target)
+	if err != nil {
+		return err
+	}
+	return c.Unsnooze(x, "maintenance window "+strings.Join(sn.Windows, ", ")+" ended")
+}
+
+// writeState saves the snoozed items to the state file, replacing the
+// file so readers never see a partial state
+func (s *Scheduler) writeState(now time.Time) (err error) {
+	if s.opts.StateFile == "" {
+		return
+	}
+	st := State{
+		PID:     os.Getpid(),
+		Started: s.started,
+		Updated: now,
+		Snoozed: []*Snoozed{},
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.snoozed)) {
+		st.Snoozed = append(st.Snoozed, s.snoozed[key])
+	}
+	b, err := json.MarshalIndent(st, "", "    ")
+	if err != nil {
+		return
+	}
+	if err = geneos.LOCAL.MkdirAll(path.Dir(s.opts.StateFile), 0775); err != nil {
+		return
+	}
+	tmp := s.opts.StateFile + ".tmp"
+	if err = geneos.LOCAL.WriteFile(tmp, b, 0664); err != nil {
+		return
+	}
+	return geneos.LOCAL.Rename(tmp, s.opts.StateFile)
+}
+
+// ReadState reads the state saved by a scheduler
+func ReadState(file string) (st State, err error) {
+	b, err := geneos.LOCAL.ReadFile(file)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &st)
+	return
+}
+
+// WindowStatus is the status of a window at a point in time
+type WindowStatus struct {
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Start    time.Time `json:"start,omitzero"`
+	End      time.Time `json:"end,omitzero"`
+	Repeat   Repeat    `json:"repeat,omitempty"`
+	Gateways []string  `json:"gateways,omitempty"`
+	Targets  int       `json:"targets"`
+	Snoozed  int       `json:"snoozed"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// Statuses returns the status of each window at time now. The Status
+// is "active" for a current window, "upcoming" if the next occurrence
+// starts within the upcoming duration, "scheduled" for later
+// occurrences and "finished" when there are no more occurrences. The
+// Start and End are of the current or next occurrence. Snoozed is the
+// number of items snoozed by each window in the state st.
+func Statuses(windows []*Window, st State, now time.Time, upcoming time.Duration) (statuses []WindowStatus) {
+	for _, w := range windows {
+		ws := WindowStatus{
+			Name:     w.Name,
+			Repeat:   w.Repeat,
+			Gateways: w.Gateways,
+			Targets:  len(w.Targets),
+			Reason:   w.Reason,
+		}
+		for _, sn := range st.Snoozed {
+			if slices.Contains(sn.Windows, w.Name) {
+				ws.Snoozed++
+			}
+		}
+		if o, ok := w.Next(now, upcoming); ok {
+			ws.Start, ws.End = o.Start, o.End
+			ws.Status = "upcoming"
+			if o.Active(now) {
+				ws.Status = "active"
+			}
+		} else if o, ok := w.Next(now, 400*24*time.Hour); ok {
+			ws.Start, ws.End = o.Start, o.End
+			ws.Status = "scheduled"
+		} else {
+			ws.Status = "finished"
+		}
+		statuses = append(statuses, ws)
+	}
+	return
+}
+
+// publish updates the dataview with the active and upcoming windows,
+// if a Netprobe is configured
+func (s *Scheduler) publish(sched *Schedule, now time.Time) (err error) {
+	np := sched.Netprobe
+	if np.URL == "" {
+		return
+	}
+	if s.client == nil || s.clientURL != np.URL {
+		if s.client, err = newClient(np); err != nil {
+			return
+		}
+		s.clientURL = np.URL
+		s.dataview = nil
+	}
+	if s.dataview == nil || s.dataview.Name != np.Dataview {
+		if s.dataview, err = api.NewDataview(s.client, np.Entity, np.Sampler, np.Type, "", np.Dataview); err != nil {
+			return
+		}
+		s.headlines = map[string]bool{}
+	}
+	dv := s.dataview
+
+	st := State{}
+	for _, key := range slices.Sorted(maps.Keys(s.snoozed)) {
+		st.Snoozed = append(st.Snoozed, s.snoozed[key])
+	}
+	table := [][]string{{"window", "status", "start", "end", "repeat", "gateways", "targets", "snoozed", "reason"}}
+	var active int
+	for _, ws := range Statuses(sched.Windows, st, now, np.Upcoming) {
+		if ws.Status != "active" && ws.Status != "upcoming" {
+			continue
+		}
+		if ws.Status == "active" {
+			active++
+		}
+		gateways := strings.Join(ws.Gateways, ", ")
+		if gateways == "" {
+			gateways = "all"
+		}
+		table = append(table, []string{
+			ws.Name,
+			ws.Status,
+			ws.Start.Format(time.RFC3339),
+			ws.End.Format(time.RFC3339),
+			string(ws.Repeat),
+			gateways,
+			fmt.Sprint(ws.Targets),
+			fmt.Sprint(ws.Snoozed),
+			ws.Reason,
+		})
+	}
+	if err = dv.UpdateDataview(dv.Entity, dv.Sampler, dv.Name, table); err != nil {
+		// recreate the dataview on the next check
+		s.dataview = nil
+		return
+	}
+
+	for _, h := range [][2]string{
+		{"activeWindows", fmt.Sprint(active)},
+		{"snoozedItems", fmt.Sprint(len(s.snoozed))},
+		{"lastCheck", now.Format(time.RFC3339)},
+	} {
+		if !s.headlines[h[0]] {
+			if exists, _ := dv.HeadlineExists(dv.Entity, dv.Sampler, dv.Name, h[0]); !exists {
+				if err = dv.CreateHeadline(dv.Entity, dv.Sampler, dv.Name, h[0]); err != nil {
+					return
+				}
+			}
+			s.headlines[h[0]] = true
+		}
+		if err = dv.UpdateHeadline(dv.Entity, dv.Sampler, dv.Name, h[0], h[1]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// newClient returns an XML-RPC client if the URL path ends in
+// `/xmlrpc`, otherwise a REST client
+func newClient(np Netprobe) (c api.APIClient, err error) {
+	if strings.HasSuffix(strings.TrimSuffix(np.URL, "/"), "/xmlrpc") {
+		var opts []api.Option
+		if np.AllowInsecure {
+			opts = append(opts, api.InsecureSkipVerify())
+		}
+		return api.NewXMLRPCClient(np.URL, opts...)
+	}
+	var opts []rest.Option
+	if np.AllowInsecure {
+		opts = append(opts, rest.HTTPClient(&http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}))
+	}
+	return api.NewRESTClient(np.URL, opts...)
+}
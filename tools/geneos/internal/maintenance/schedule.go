@@ -0,0 +1,327 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/geneos/xpath"
+)
+
+// Repeat is how often a maintenance window recurs
+type Repeat string
+
+const (
+	RepeatNone     Repeat = ""
+	RepeatDaily    Repeat = "daily"
+	RepeatWeekdays Repeat = "weekdays"
+	RepeatWeekly   Repeat = "weekly"
+	RepeatMonthly  Repeat = "monthly"
+)
+
+// Window is a maintenance window. The first occurrence runs from Start
+// to End and later occurrences, if Repeat is set, start at the same
+// time of day and last as long, until the optional Until time.
+type Window struct {
+	Name     string         `json:"name"`
+	Gateways []string       `json:"gateways,omitempty"`
+	Targets  []*xpath.XPath `json:"targets"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Repeat   Repeat         `json:"repeat,omitempty"`
+	Until    time.Time      `json:"until,omitzero"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+// Occurrence is a single occurrence of a maintenance window
+type Occurrence struct {
+	Window *Window
+	Start  time.Time
+	End    time.Time
+}
+
+// Active returns true if the occurrence includes time t
+func (o Occurrence) Active(t time.Time) bool {
+	return !t.Before(o.Start) && t.Before(o.End)
+}
+
+// windowConfig is the layout of a window in the schedule file. Times
+// and durations are strings so that they can be parsed with useful
+// errors.
+type windowConfig struct {
+	Name     string   `mapstructure:"name"`
+	Gateways []string `mapstructure:"gateways"`
+	Targets  []string `mapstructure:"targets"`
+	Start    string   `mapstructure:"start"`
+	End      string   `mapstructure:"end"`
+	Duration string   `mapstructure:"duration"`
+	Repeat   string   `mapstructure:"repeat"`
+	Until    string   `mapstructure:"until"`
+	Reason   string   `mapstructure:"reason"`
+}
+
+// Schedule is a set of maintenance windows and the optional Netprobe
+// settings used to publish them
+type Schedule struct {
+	Windows  []*Window
+	Netprobe Netprobe
+}
+
+// Netprobe holds the settings to publish the windows as a dataview
+type Netprobe struct {
+	URL           string
+	AllowInsecure bool
+	Entity        string
+	Sampler       string
+	Type          string
+	Dataview      string
+	Upcoming      time.Duration
+}
+
+// ReadSchedule reads the maintenance windows from the YAML file
+func ReadSchedule(file string) (s *Schedule, err error) {
+	cf, err := config.Read("maintenance",
+		config.FilePath(file),
+		config.Format("yaml"),
+		config.MustExist(),
+	)
+	if err != nil {
+		return
+	}
+
+	s = &Schedule{
+		Netprobe: Netprobe{
+			URL:           config.Get[string](cf, cf.Join("netprobe", "url")),
+			AllowInsecure: config.Get[bool](cf, cf.Join("netprobe", "allow-insecure")),
+			Entity:        config.Get[string](cf, cf.Join("netprobe", "entity")),
+			Sampler:       config.Get[string](cf, cf.Join("netprobe", "sampler")),
+			Type:          config.Get[string](cf, cf.Join("netprobe", "type")),
+			Dataview:      config.Get[string](cf, cf.Join("netprobe", "dataview"), config.DefaultValue("maintenance")),
+			Upcoming:      config.Get[time.Duration](cf, cf.Join("netprobe", "upcoming"), config.DefaultValue(7*24*time.Hour)),
+		},
+	}
+	if s.Netprobe.URL != "" && (s.Netprobe.Entity == "" || s.Netprobe.Sampler == "") {
+		return nil, fmt.Errorf("%s: netprobe entity and sampler must be set", file)
+	}
+
+	var windows []windowConfig
+	if err = cf.UnmarshalKey("windows", &windows); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	names := map[string]bool{}
+	for n, wc := range windows {
+		w, err := wc.window()
+		if err != nil {
+			if wc.Name == "" {
+				return nil, fmt.Errorf("%s: window %d: %w", file, n+1, err)
+			}
+			return nil, fmt.Errorf("%s: window %q: %w", file, wc.Name, err)
+		}
+		if names[w.Name] {
+			return nil, fmt.Errorf("%s: duplicate window name %q", file, w.Name)
+		}
+		names[w.Name] = true
+		s.Windows = append(s.Windows, w)
+	}
+	return
+}
+
+// the layouts accepted for times in the schedule file. Times without a
+// zone are local.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseTime(s string) (t time.Time, err error) {
+	for _, layout := range timeLayouts {
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return
+		}
+	}
+	return t, fmt.Errorf("cannot parse time %q", s)
+}
+
+// window validates wc and returns the Window
+func (wc windowConfig) window() (w *Window, err error) {
+	if wc.Name == "" {
+		return nil, fmt.Errorf("name must be set")
+	}
+	if len(wc.Targets) == 0 {
+		return nil, fmt.Errorf("no targets")
+	}
+	w = &Window{
+		Name:     wc.Name,
+		Gateways: wc.Gateways,
+		Repeat:   Repeat(strings.ToLower(wc.Repeat)),
+		Reason:   wc.Reason,
+	}
+	for _, t := range wc.Targets {
+		x, err := xpath.Parse(t)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", t, err)
+		}
+		w.Targets = append(w.Targets, x)
+	}
+	if !slices.Contains([]Repeat{RepeatNone, RepeatDaily, RepeatWeekdays, RepeatWeekly, RepeatMonthly}, w.Repeat) {
+		return nil, fmt.Errorf("unknown repeat %q", wc.Repeat)
+	}
+
+	if w.Start, err = parseTime(wc.Start); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	switch {
+	case wc.End != "" && wc.Duration != "":
+		return nil, fmt.Errorf("only one of end or duration can be set")
+	case wc.End != "":
+		if w.End, err = parseTime(wc.End); err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+	case wc.Duration != "":
+		d, err := time.ParseDuration(wc.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("duration: %w", err)
+		}
+		w.End = w.Start.Add(d)
+	default:
+		return nil, fmt.Errorf("one of end or duration must be set")
+	}
+	if !w.End.After(w.Start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	if w.Repeat != RepeatNone && w.End.Sub(w.Start) > w.period() {
+		return nil, fmt.Errorf("window is longer than the %s repeat", w.Repeat)
+	}
+	if wc.Until != "" {
+		if w.Until, err = parseTime(wc.Until); err != nil {
+			return nil, fmt.Errorf("until: %w", err)
+		}
+	}
+	return
+}
+
+// period returns the shortest time between the starts of occurrences
+func (w *Window) period() time.Duration {
+	switch w.Repeat {
+	case RepeatDaily, RepeatWeekdays:
+		return 24 * time.Hour
+	case RepeatWeekly:
+		return 7 * 24 * time.Hour
+	case RepeatMonthly:
+		return 28 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// next returns the start of the occurrence after the one at start,
+// skipping weekends for RepeatWeekdays. Monthly windows are handled in
+// Occurrences.
+func (w *Window) next(start time.Time) time.Time {
+	switch w.Repeat {
+	case RepeatWeekdays:
+		start = start.AddDate(0, 0, 1)
+		for start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+			start = start.AddDate(0, 0, 1)
+		}
+		return start
+	case RepeatWeekly:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Occurrences returns the occurrences of the window that are active at
+// any time between from and to, in order
+func (w *Window) Occurrences(from, to time.Time) (occurrences []Occurrence) {
+	length := w.End.Sub(w.Start)
+	start := w.Start
+
+	if w.Repeat == RepeatNone {
+		if w.End.After(from) && w.Start.Before(to) {
+			occurrences = append(occurrences, Occurrence{Window: w, Start: w.Start, End: w.End})
+		}
+		return
+	}
+
+	// skip whole weeks or months to close to from, keeping the
+	// weekday or day of the month. monthly occurrences are always
+	// calculated from the first to avoid drifting at month ends.
+	var months int
+	if skip := from.Sub(start) - length; skip > 0 {
+		switch w.Repeat {
+		case RepeatMonthly:
+			if months = int(skip/(31*24*time.Hour)) - 1; months > 0 {
+				start = w.Start.AddDate(0, months, 0)
+			} else {
+				months = 0
+			}
+		default:
+			if weeks := int(skip/(7*24*time.Hour)) - 1; weeks > 0 {
+				start = start.AddDate(0, 0, 7*weeks)
+			}
+		}
+	}
+	if w.Repeat == RepeatWeekdays && (start.Weekday() == time.Saturday || start.Weekday() == time.Sunday) {
+		start = w.next(start)
+	}
+
+	for start.Before(to) {
+		if !w.Until.IsZero() && start.After(w.Until) {
+			break
+		}
+		if end := start.Add(length); end.After(from) {
+			occurrences = append(occurrences, Occurrence{Window: w, Start: start, End: end})
+		}
+		if w.Repeat == RepeatMonthly {
+			months++
+			start = w.Start.AddDate(0, months, 0)
+		} else {
+			start = w.next(start)
+		}
+	}
+	return
+}
+
+// Current returns the occurrence of the window active at t, if any
+func (w *Window) Current(t time.Time) (o Occurrence, ok bool) {
+	occurrences := w.Occurrences(t, t.Add(time.Nanosecond))
+	if len(occurrences) == 0 {
+		return
+	}
+	return occurrences[0], occurrences[0].Active(t)
+}
+
+// Next returns the current occurrence of the window at t or, if none,
+// the next occurrence to start within d of t
+func (w *Window) Next(t time.Time, d time.Duration) (o Occurrence, ok bool) {
+	occurrences := w.Occurrences(t, t.Add(d))
+	if len(occurrences) == 0 {
+		return
+	}
+	return occurrences[0], true
+}
@@ -15,42 +15,259 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package profiles provides functionality to manage and manipulate profiles.
-
+// Package profiles provides deployment profiles, which are named sets
+// of instances to create with `geneos init --profile` and `geneos
+// deploy --profile`.
+//
+// The built-in profiles are merged with any user-defined profiles in a
+// `profiles.yaml` file in the same directories as the other
+// configuration files, e.g. `${HOME}/.config/geneos/profiles.yaml`.
+// Settings in a user-defined profile override those of a built-in
+// profile with the same name.
 package profiles
 
 import (
 	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/values"
 )
 
 //go:embed "profiles.defaults.yaml"
 var profilesDefault []byte
 
-// LoadProfiles initializes the profiles by reading from the specified configuration file.
-// If the file does not exist, it creates a new one with default values.
+// None is the name of the profile that creates nothing
+const None = "none"
+
+// Profile is a named set of instances, grouped by component type, plus
+// the variables used to expand the instance settings
+type Profile struct {
+	Name        string
+	Description string
+	Variables   map[string]string
+
+	// Components lists the component types in the order that instances
+	// should be created and Instances holds the instances of each
+	Components []*geneos.Component
+	Instances  map[*geneos.Component][]Instance
+}
+
+// Instance is the definition of one instance in a profile. All string
+// values are expanded before use.
+type Instance struct {
+	Name       string    `mapstructure:"name"`
+	Port       uint16    `mapstructure:"port"`
+	Version    string    `mapstructure:"version"`
+	Template   string    `mapstructure:"template"`
+	Keyfile    string    `mapstructure:"keyfile"`
+	KeyfileCRC string    `mapstructure:"keycrc"`
+	Insecure   bool      `mapstructure:"insecure"`
+	CertBundle string    `mapstructure:"certs-bundle"`
+	Options    string    `mapstructure:"options"`
+	Params     []string  `mapstructure:"params"`
+	Env        []string  `mapstructure:"env"`
+	Imports    []string  `mapstructure:"import"`
+	Includes   []Include `mapstructure:"includes"`
+	Gateways   []string  `mapstructure:"gateways"`
+	Types      []string  `mapstructure:"types"`
+	Attributes []string  `mapstructure:"attributes"`
+	Variables  []string  `mapstructure:"variables"`
+}
+
+// Include is a Gateway include file. Location is a path, relative to
+// the Gateway instance directory, or a URL.
+type Include struct {
+	Priority int    `mapstructure:"priority"`
+	Location string `mapstructure:"location"`
+}
+
+// creation order for well known component types, so that licds and
+// gateways exist before the instances that connect to them. Any other
+// types follow in alphabetical order.
+var order = []string{"licd", "gateway", "netprobe", "san", "floating", "fa2", "fileagent", "webserver"}
+
+// Load reads the built-in and user-defined profiles
 func Load() (pf *config.Config, err error) {
-	pf, err = config.Read("profiles",
+	return config.Read("profiles",
 		config.AppName(cordial.ExecutableName()),
 		config.Format("yaml"),
 		config.WithDefaults(profilesDefault, "yaml"),
 	)
+}
+
+// Names returns the sorted names of all available profiles and the
+// name of the default profile
+func Names() (names []string, def string, err error) {
+	pf, err := Load()
 	if err != nil {
 		return
 	}
+	names = slices.Sorted(maps.Keys(config.Get[map[string]any](pf, "profiles")))
+	def = config.Get[string](pf, "default", config.NoExpand())
+	return
+}
 
-	if config.Path("profiles",
-		config.AppName(cordial.ExecutableName()),
-		config.Format("yaml"),
-		config.WithDefaults(profilesDefault, "yaml"),
-		config.MustExist(), // Ensure the file exists, checking if creation is required
-	) == "internal defaults" {
-		if err = pf.Write("profiles",
-			config.AppName(cordial.ExecutableName()),
-			config.Format("yaml"),
-		); err != nil {
+// Get returns the profile name, or the default profile if name is
+// empty, with all settings expanded. Values in `${name}` format are
+// looked up first in vars, then in the profile variables and finally
+// in the environment. The profile variables are themselves expanded
+// using vars and the environment. Prefixed expansions such as
+// `${file:...}` or `${enc:...}` work as for other configuration
+// values.
+func Get(name string, vars map[string]string) (p *Profile, err error) {
+	pf, err := Load()
+	if err != nil {
+		return
+	}
+
+	if name == "" {
+		name = config.Get[string](pf, "default", config.NoExpand())
+		if name == "" {
+			name = None
+		}
+	}
+
+	key := pf.Join("profiles", name)
+	if !pf.IsSet(key) && name != None {
+		return nil, fmt.Errorf("%w: profile %q not found", geneos.ErrNotExist, name)
+	}
+
+	var raw map[string]any
+	if err = pf.UnmarshalKey(key, &raw, config.NoExpand()); err != nil {
+		return
+	}
+
+	p = &Profile{
+		Name:      name,
+		Variables: map[string]string{},
+		Instances: map[*geneos.Component][]Instance{},
+	}
+
+	env := map[string]string{}
+	for _, e := range os.Environ() {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			env[k] = v
+		}
+	}
+
+	// expand the profile variables first, so that they can be used in
+	// the instance settings
+	if pv, ok := raw["variables"].(map[string]any); ok {
+		for k, v := range pv {
+			p.Variables[k] = config.Expand[string](pf, fmt.Sprint(v), config.LookupTable(vars, env))
+		}
+	}
+	lookup := config.LookupTable(vars, p.Variables, env)
+	expand := func(s string) string {
+		return config.Expand[string](pf, s, lookup)
+	}
+
+	if d, ok := raw["description"].(string); ok {
+		p.Description = expand(d)
+	}
+
+	for k := range raw {
+		if k == "description" || k == "variables" {
+			continue
+		}
+		ct := geneos.ParseComponent(k)
+		if ct == nil {
+			return nil, fmt.Errorf("%w: profile %q: unknown component type %q", geneos.ErrInvalidArgs, name, k)
+		}
+
+		var instances []Instance
+		if err = pf.UnmarshalKey(pf.Join(key, k), &instances, config.NoExpand()); err != nil {
+			return nil, fmt.Errorf("profile %q: %s: %w", name, k, err)
+		}
+		for i := range instances {
+			instances[i].expand(expand)
+			if instances[i].Name == "" {
+				return nil, fmt.Errorf("%w: profile %q: %s instance %d has no name", geneos.ErrInvalidArgs, name, k, i)
+			}
+		}
+		p.Components = append(p.Components, ct)
+		p.Instances[ct] = instances
+	}
+
+	slices.SortFunc(p.Components, func(a, b *geneos.Component) int {
+		ai, bi := slices.Index(order, a.Name), slices.Index(order, b.Name)
+		switch {
+		case ai == bi:
+			return strings.Compare(a.Name, b.Name)
+		case ai == -1:
+			return 1
+		case bi == -1:
+			return -1
+		default:
+			return ai - bi
+		}
+	})
+
+	return
+}
+
+// expand applies fn to all the string values in the instance
+func (i *Instance) expand(fn func(string) string) {
+	for _, s := range []*string{&i.Name, &i.Version, &i.Template, &i.Keyfile, &i.KeyfileCRC, &i.CertBundle, &i.Options} {
+		*s = fn(*s)
+	}
+	for _, sl := range []*[]string{&i.Params, &i.Env, &i.Imports, &i.Gateways, &i.Types, &i.Attributes, &i.Variables} {
+		for n := range *sl {
+			(*sl)[n] = fn((*sl)[n])
+		}
+	}
+	for n := range i.Includes {
+		i.Includes[n].Location = fn(i.Includes[n].Location)
+	}
+}
+
+// Values returns the instance settings as values.Values, as used by
+// the `add` and `deploy` commands
+func (i Instance) Values() (v values.Values, err error) {
+	v.Params = slices.Clone(i.Params)
+	if i.Options != "" {
+		v.Params = append(v.Params, "options="+i.Options)
+	}
+
+	for _, e := range i.Env {
+		if err = v.Envs.Set(e); err != nil {
+			return
+		}
+	}
+	for _, inc := range i.Includes {
+		priority := inc.Priority
+		if priority == 0 {
+			priority = 100
+		}
+		if err = v.Includes.Set(strconv.Itoa(priority) + ":" + inc.Location); err != nil {
+			return
+		}
+	}
+	for _, g := range i.Gateways {
+		if err = v.Gateways.Set(g); err != nil {
+			return
+		}
+	}
+	for _, t := range i.Types {
+		if err = v.Types.Set(t); err != nil {
+			return
+		}
+	}
+	for _, a := range i.Attributes {
+		if err = v.Attributes.Set(a); err != nil {
+			return
+		}
+	}
+	for _, va := range i.Variables {
+		if err = v.Variables.Set(va); err != nil {
 			return
 		}
 	}
@@ -42,8 +42,8 @@ import (
 //
 // If an error writer is set with responses.WriteStderr() then all
 // non-ignored errors are written out, prefixed with the
-// Instance.String() and a colon. Note that this format may change if
-// and when structured logging is introduced.
+// Instance.String() and a colon unless Instance is nil. Note that this
+// format may change if and when structured logging is introduced.
 //
 // Report calls Flush() after writing to CSV or Tab writers.
 func (responses GeneralResponses) Report(writer any, options ...Option) {
@@ -154,7 +154,9 @@ OUTER:
 			}
 
 			if len(resp.Completed) > 0 && (opts.outputFields == 0 || opts.outputFields&outputFieldCompleted != 0) {
-				fmt.Fprintf(w, opts.prefixformat, resp.Instance)
+				if resp.Instance != nil {
+					fmt.Fprintf(w, opts.prefixformat, resp.Instance)
+				}
 				fmt.Fprint(w, joinNatural(resp.Completed...))
 				fmt.Fprint(w, opts.suffix)
 			}
@@ -201,7 +203,11 @@ OUTER:
 					}
 				}
 				if !ignored {
-					fmt.Fprintf(opts.stderr, "%s: %s\n", r.Instance, r.Err)
+					if r.Instance != nil {
+						fmt.Fprintf(opts.stderr, "%s: %s\n", r.Instance, r.Err)
+					} else {
+						fmt.Fprintln(opts.stderr, r.Err)
+					}
 					errored = true
 				}
 			}
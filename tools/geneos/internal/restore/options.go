@@ -17,9 +17,8 @@ type restoreOptions struct {
 }
 
 var globalRestoreOptions = restoreOptions{
-	compression: "gzip",
-	names:       []string{"all"},
-	progress:    io.Discard,
+	names:    []string{"all"},
+	progress: io.Discard,
 }
 
 // RestoreOption controls the behaviour of the Restore function
@@ -34,6 +33,8 @@ func evalRestoreOptions(options ...RestoreOption) *restoreOptions {
 }
 
 // Compression sets the compression type to use for backup and restore. Valid values are "gzip", "bzip2", "xz" and "none"
+//
+// If not set then the type is taken from the archive file name
 func Compression(compression string) RestoreOption {
 	return func(ro *restoreOptions) {
 		ro.compression = compression
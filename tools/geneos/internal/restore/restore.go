@@ -2,7 +2,6 @@ package restore
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -14,11 +13,10 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/dsnet/compress/bzip2"
-
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/certs"
 	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/tools/geneos/internal/backup"
 	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
 	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
 )
@@ -28,7 +26,7 @@ var log = cordial.Logger
 var fileTypes = map[string]string{
 	".tar.gz":  "gzip",
 	".tgz":     "gzip",
-	".tar.bz2": "bz2",
+	".tar.bz2": "bzip2",
 	".tar":     "none",
 }
 
@@ -46,66 +44,84 @@ func Restore(archive string, options ...RestoreOption) (err error) {
 	instancesRestored := map[string]geneos.Instance{}
 
 	var tin io.ReadCloser
-	var fileType string
 
+	// the compression option is used if given, otherwise it is
+	// taken from the file name
 	opts := evalRestoreOptions(options...)
+	compression := opts.compression
 	if archive == "-" {
-		fileType = opts.compression
 		archive = ""
-	} else {
+	} else if compression == "" {
 		for s, t := range fileTypes {
 			if strings.HasSuffix(archive, s) {
-				fileType = t
+				compression = t
 				break
 			}
 		}
 	}
 
-	if fileType == "" {
+	if compression == "" {
 		return
 	}
 
 	names := opts.names
 	ct := opts.component
 
-	// default source is STDIN
-	in := os.Stdin
+	// the archive can be local, on a remote host or in an object store
+	var store backup.Store
+	var name string
 	if archive != "" {
-		if in, err = os.Open(archive); err != nil {
+		if store, name, err = backup.Location(archive); err != nil {
 			return
 		}
-		defer in.Close()
+		if name == "" {
+			return fmt.Errorf("%s: %w", archive, geneos.ErrIsADirectory)
+		}
+		// remote archives are fetched once and then read locally
+		if !backup.IsLocal(store) {
+			spool := backup.Spool(store)
+			defer spool.Close()
+			store = spool
+		}
 	}
 
-	switch fileType {
-	case "", "none":
+	// archives written with a manifest are verified, and incremental
+	// archives are reassembled with their full archive, before anything
+	// is restored. Older archives and STDIN are read as-is.
+	if archive != "" {
+		m, err := backup.Verify(store, name, compression)
 		switch {
-		case strings.HasSuffix(archive, ".gz") || strings.HasSuffix(archive, ".tgz"):
-			if tin, err = gzip.NewReader(in); err != nil {
-				return
+		case err == nil:
+			if m.Kind == backup.Incremental {
+				fmt.Fprintf(opts.progress, "archive %s verified, with full archive %s\n", archive, m.Base)
+			} else {
+				fmt.Fprintf(opts.progress, "archive %s verified\n", archive)
 			}
-			defer tin.Close()
-		case strings.HasSuffix(archive, ".bz2"):
-			if tin, err = bzip2.NewReader(in, nil); err != nil {
-				return
+			if tin, err = backup.OpenChain(store, name, m, compression); err != nil {
+				return err
 			}
 			defer tin.Close()
+		case errors.Is(err, backup.ErrNoManifest) && !errors.Is(err, backup.ErrChain):
+			log.Debug("archive has no manifest, not verified", slog.String("archive", archive))
 		default:
-			tin = in
+			return err
 		}
-	case "gzip":
-		if tin, err = gzip.NewReader(in); err != nil {
-			return
+	}
+
+	if tin == nil {
+		// default source is STDIN
+		var in io.ReadCloser = os.Stdin
+		if archive != "" {
+			if in, err = store.Open(name); err != nil {
+				return
+			}
+			defer in.Close()
 		}
-		defer tin.Close()
-	case "bzip2":
-		if tin, err = bzip2.NewReader(in, nil); err != nil {
+
+		if tin, err = backup.Decompress(in, compression, archive); err != nil {
 			return
 		}
 		defer tin.Close()
-	default:
-		err = fmt.Errorf("unknown decompression type %s", opts.compression)
-		return
 	}
 
 	// store paths processed mapped to types and instances, with record
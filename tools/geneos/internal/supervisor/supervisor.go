@@ -0,0 +1,443 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supervisor watches Geneos instances and restarts them
+// according to their restart policies.
+//
+// Each instance can have a restart policy set in its configuration,
+// using the keys `restart`, `restart-retries`, `restart-delay` and
+// `restart-max-delay`. Instances without a policy use the defaults
+// given to the supervisor.
+//
+// Instances are checked for a live process and, where the instance has
+// a port, that the process is listening on it. Disabled instances are
+// ignored and protected instances are never stopped, so a protected
+// instance that is running but not listening is only reported.
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/process"
+	"github.com/itrs-group/cordial/tools/geneos/internal/geneos"
+	"github.com/itrs-group/cordial/tools/geneos/internal/instance"
+)
+
+var log = cordial.Logger
+
+// Policy is an instance restart policy
+type Policy string
+
+const (
+	// PolicyNo means the instance is not supervised
+	PolicyNo Policy = "no"
+	// PolicyOnFailure restarts an instance that stops without being
+	// stopped by a user, or that is not listening on its port
+	PolicyOnFailure Policy = "on-failure"
+	// PolicyAlways starts an instance whenever it is not running,
+	// unless it has been stopped by a user
+	PolicyAlways Policy = "always"
+)
+
+// ParsePolicy returns the Policy for s. An empty string returns an
+// empty Policy, meaning the default should be used.
+func ParsePolicy(s string) (p Policy, err error) {
+	switch p = Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "", PolicyNo, PolicyOnFailure, PolicyAlways:
+		return
+	case "never", "none", "false":
+		return PolicyNo, nil
+	default:
+		return "", fmt.Errorf("%w: unknown restart policy %q", geneos.ErrInvalidArgs, s)
+	}
+}
+
+// State is the supervised state of an instance
+type State string
+
+const (
+	StateRunning      State = "running"
+	StateStarting     State = "starting"
+	StateStopped      State = "stopped"
+	StateUnhealthy    State = "unhealthy"
+	StateBackoff      State = "backoff"
+	StateFailed       State = "failed"
+	StateDisabled     State = "disabled"
+	StateUnsupervised State = "unsupervised"
+)
+
+// instance configuration keys
+const (
+	KeyPolicy   = "restart"
+	KeyRetries  = "restart-retries"
+	KeyDelay    = "restart-delay"
+	KeyMaxDelay = "restart-max-delay"
+)
+
+// Options control the supervisor. Policy, Retries, Delay and MaxDelay
+// are the defaults for instances without their own settings.
+type Options struct {
+	Policy   Policy
+	Retries  int
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// Interval is the time between checks
+	Interval time.Duration
+	// Grace is how long after starting an instance before its port is
+	// checked
+	Grace time.Duration
+	// Reset is how long an instance must be healthy before its retry
+	// count is reset
+	Reset time.Duration
+	// PortCheck enables checking that instances are listening on their
+	// configured ports
+	PortCheck bool
+	// StatusFile is the path, on the local host, to write the status
+	// report to after each check
+	StatusFile string
+}
+
+// Status is the supervised status of one instance
+type Status struct {
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Host        string    `json:"host"`
+	Policy      Policy    `json:"policy"`
+	State       State     `json:"state"`
+	PID         int       `json:"pid,omitempty"`
+	Port        uint16    `json:"port,omitempty"`
+	Listening   bool      `json:"listening"`
+	Restarts    int       `json:"restarts"`
+	Retries     int       `json:"retries"`
+	LastCheck   time.Time `json:"lastCheck,omitzero"`
+	LastStart   time.Time `json:"lastStart,omitzero"`
+	NextAttempt time.Time `json:"nextAttempt,omitzero"`
+	LastError   string    `json:"lastError,omitempty"`
+
+	// seen is true once the instance has been seen running, so that an
+	// instance that was never started is not restarted by on-failure
+	seen bool
+	// since is when the process was first seen running, or started
+	since time.Time
+}
+
+// Report is the status report written by the supervisor
+type Report struct {
+	PID       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+	Updated   time.Time `json:"updated"`
+	Interval  string    `json:"interval"`
+	Instances []Status  `json:"instances"`
+}
+
+// Supervisor watches a set of instances
+type Supervisor struct {
+	h       *geneos.Host
+	ct      *geneos.Component
+	names   []string
+	opts    Options
+	started time.Time
+
+	mu     sync.Mutex
+	status map[string]*Status
+}
+
+// New returns a supervisor for the instances of type ct on host h
+// matching names. As for other commands, a nil ct means all types and
+// no names means all instances. The instances are re-evaluated on each
+// check, so instances that are added or removed later are handled.
+func New(h *geneos.Host, ct *geneos.Component, names []string, opts Options) *Supervisor {
+	if opts.Policy == "" {
+		opts.Policy = PolicyOnFailure
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	return &Supervisor{
+		h:      h,
+		ct:     ct,
+		names:  names,
+		opts:   opts,
+		status: map[string]*Status{},
+	}
+}
+
+// Run checks the instances every interval until ctx is cancelled
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.started = time.Now()
+	log.Info("supervisor started", slog.Int("pid", os.Getpid()), slog.Duration("interval", s.opts.Interval), slog.String("policy", string(s.opts.Policy)))
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Check()
+		if err := s.writeReport(); err != nil {
+			log.Error("cannot write status report", slog.String("file", s.opts.StatusFile), slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			log.Info("supervisor stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs one round of checks over all the matching instances,
+// restarting them as required
+func (s *Supervisor) Check() {
+	instances := instance.Instances(s.h, s.ct, instance.MatchNames(s.names...))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := map[string]bool{}
+	var wg sync.WaitGroup
+	for _, i := range instances {
+		id := instance.IDString(i)
+		current[id] = true
+		st, ok := s.status[id]
+		if !ok {
+			st = &Status{
+				Type: i.Type().String(),
+				Name: i.Name(),
+				Host: i.Host().String(),
+			}
+			s.status[id] = st
+		}
+		wg.Go(func() {
+			s.check(i, st)
+		})
+	}
+	wg.Wait()
+
+	// forget instances that no longer exist
+	for id := range s.status {
+		if !current[id] {
+			delete(s.status, id)
+		}
+	}
+}
+
+// check one instance and update st
+func (s *Supervisor) check(i geneos.Instance, st *Status) {
+	now := time.Now()
+	st.LastCheck = now
+	st.Port = config.Get[uint16](i.Config(), "port")
+
+	policy, err := ParsePolicy(config.Get[string](i.Config(), KeyPolicy))
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	if policy == "" {
+		policy = s.opts.Policy
+	}
+	st.Policy = policy
+
+	if policy == PolicyNo {
+		st.State, st.PID, st.Retries = StateUnsupervised, 0, 0
+		return
+	}
+
+	if instance.IsDisabled(i) {
+		st.State, st.PID, st.Retries, st.NextAttempt = StateDisabled, 0, 0, time.Time{}
+		st.seen = false
+		return
+	}
+
+	pid, err := instance.GetLivePID(i)
+	if err != nil {
+		st.PID, st.Listening, st.since = 0, false, time.Time{}
+		if instance.WasStopped(i) || (policy == PolicyOnFailure && !st.seen) {
+			// stopped by a user or, for on-failure, never seen running
+			st.State, st.Retries, st.NextAttempt = StateStopped, 0, time.Time{}
+			st.seen = false
+			return
+		}
+		if st.seen {
+			i.Log().Warn("instance not running", slog.String("policy", string(policy)))
+		}
+		s.restart(i, st, false)
+		return
+	}
+
+	st.PID = pid
+	st.seen = true
+	if st.since.IsZero() {
+		st.since = now
+	}
+
+	healthy := true
+	st.Listening = false
+	if s.opts.PortCheck && st.Port != 0 && now.Sub(st.since) >= s.opts.Grace {
+		st.Listening = slices.Contains(process.ListeningPorts(i.Host(), pid), int(st.Port))
+		healthy = st.Listening
+	}
+
+	if healthy {
+		st.State = StateRunning
+		if st.Retries > 0 && now.Sub(st.since) >= s.reset(i) {
+			st.Retries, st.NextAttempt = 0, time.Time{}
+		}
+		return
+	}
+
+	st.State = StateUnhealthy
+	st.LastError = fmt.Sprintf("not listening on port %d", st.Port)
+	if instance.IsProtected(i) {
+		st.LastError += ", protected so not restarted"
+		return
+	}
+	i.Log().Warn("instance not listening on port", slog.Uint64("port", uint64(st.Port)))
+	s.restart(i, st, true)
+}
+
+// restart (re)starts instance i, stopping it first if stop is true,
+// subject to the retry limits and back-off delay
+func (s *Supervisor) restart(i geneos.Instance, st *Status, stop bool) {
+	now := time.Now()
+
+	retries := config.Get[int](i.Config(), KeyRetries, config.DefaultValue(s.opts.Retries))
+	if retries > 0 && st.Retries >= retries {
+		if st.State != StateFailed {
+			i.Log().Error("restart retries exhausted, giving up", slog.Int("retries", st.Retries))
+		}
+		st.State = StateFailed
+		return
+	}
+
+	if st.NextAttempt.After(now) {
+		st.State = StateBackoff
+		return
+	}
+
+	if stop {
+		if err := instance.Stop(i, false, false); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			st.LastError = err.Error()
+			st.State = StateUnhealthy
+			return
+		}
+		// not a user stop
+		i.Host().Remove(instance.ComponentFilepath(i, geneos.StoppedExtension))
+	}
+
+	st.Retries++
+	st.Restarts++
+	st.LastStart = now
+	st.NextAttempt = now.Add(s.delay(i, st.Retries))
+
+	i.Log().Info("starting instance", slog.Int("retry", st.Retries), slog.Time("next", st.NextAttempt))
+	if err := instance.Start(i); err != nil && !errors.Is(err, geneos.ErrRunning) {
+		i.Log().Error("cannot start instance", slog.Any("error", err))
+		st.LastError = err.Error()
+		st.State = StateBackoff
+		return
+	}
+
+	st.State = StateStarting
+	st.seen = true
+	st.since = now
+}
+
+// delay returns the back-off delay after retry n, doubling from the
+// initial delay up to the maximum
+func (s *Supervisor) delay(i geneos.Instance, n int) time.Duration {
+	d := config.Get[time.Duration](i.Config(), KeyDelay, config.DefaultValue(s.opts.Delay))
+	m := config.Get[time.Duration](i.Config(), KeyMaxDelay, config.DefaultValue(s.opts.MaxDelay))
+	for ; n > 1 && (m <= 0 || d < m); n-- {
+		d *= 2
+	}
+	if m > 0 && d > m {
+		d = m
+	}
+	return d
+}
+
+// reset returns how long an instance must be running before the retry
+// count is reset, defaulting to the maximum back-off delay
+func (s *Supervisor) reset(i geneos.Instance) time.Duration {
+	if s.opts.Reset > 0 {
+		return s.opts.Reset
+	}
+	return config.Get[time.Duration](i.Config(), KeyMaxDelay, config.DefaultValue(s.opts.MaxDelay))
+}
+
+// Report returns the current status of the supervisor and the
+// instances it is watching, sorted by type, name and host
+func (s *Supervisor) Report() (r Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r = Report{
+		PID:      os.Getpid(),
+		Started:  s.started,
+		Updated:  time.Now(),
+		Interval: s.opts.Interval.String(),
+	}
+	for _, st := range s.status {
+		r.Instances = append(r.Instances, *st)
+	}
+	slices.SortFunc(r.Instances, func(a, b Status) int {
+		return strings.Compare(a.Type+":"+a.Name+"@"+a.Host, b.Type+":"+b.Name+"@"+b.Host)
+	})
+	return
+}
+
+// writeReport writes the status report to the status file, if set,
+// replacing the file so readers never see a partial report
+func (s *Supervisor) writeReport() (err error) {
+	if s.opts.StatusFile == "" {
+		return
+	}
+	b, err := json.MarshalIndent(s.Report(), "", "    ")
+	if err != nil {
+		return
+	}
+	if err = geneos.LOCAL.MkdirAll(path.Dir(s.opts.StatusFile), 0775); err != nil {
+		return
+	}
+	tmp := s.opts.StatusFile + ".tmp"
+	if err = geneos.LOCAL.WriteFile(tmp, b, 0664); err != nil {
+		return
+	}
+	return geneos.LOCAL.Rename(tmp, s.opts.StatusFile)
+}
+
+// ReadReport reads a status report written by a supervisor from file
+// on the local host
+func ReadReport(file string) (r Report, err error) {
+	b, err := geneos.LOCAL.ReadFile(file)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(b, &r)
+	return
+}
@@ -30,11 +30,15 @@ import (
 	// import subsystems here for command registration
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/aescmd"
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/cfgcmd"
+	_ "github.com/itrs-group/cordial/tools/geneos/cmd/gatewaycmd"
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/hostcmd"
 
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/imscmd"
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/initcmd"
+	_ "github.com/itrs-group/cordial/tools/geneos/cmd/maintenancecmd"
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/pkgcmd"
+	_ "github.com/itrs-group/cordial/tools/geneos/cmd/servicecmd"
+	_ "github.com/itrs-group/cordial/tools/geneos/cmd/supervisecmd"
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/tlscmd"
 
 	_ "github.com/itrs-group/cordial/tools/geneos/cmd/pscmd"
@@ -0,0 +1,341 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+// The types in this file are the subset of the OTLP/JSON encoding of
+// the metrics and logs export requests used by the receiver. They
+// follow the field names of the protobuf JSON mapping, where 64 bit
+// integers are sent as strings, and unknown fields are ignored.
+//
+// See https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// int64String is a 64 bit integer that may be encoded as either a
+// JSON number or a string
+type int64String int64
+
+func (i *int64String) UnmarshalJSON(data []byte) (err error) {
+	s := string(bytes.Trim(data, `"`))
+	if s == "" || s == "null" {
+		*i = 0
+		return
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		// fixed64 fields such as counts may exceed an int64
+		u, err2 := strconv.ParseUint(s, 10, 64)
+		if err2 != nil {
+			return err
+		}
+		n, err = int64(u), nil
+	}
+	*i = int64String(n)
+	return
+}
+
+// float64Value is a double that may also be encoded as the strings
+// "NaN", "Infinity" or "-Infinity"
+type float64Value float64
+
+func (f *float64Value) UnmarshalJSON(data []byte) (err error) {
+	s := string(bytes.Trim(data, `"`))
+	switch s {
+	case "", "null":
+		*f = 0
+		return
+	case "Infinity":
+		s = "+Inf"
+	case "-Infinity":
+		s = "-Inf"
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	*f = float64Value(v)
+	return
+}
+
+type anyValue struct {
+	StringValue *string       `json:"stringValue,omitempty"`
+	BoolValue   *bool         `json:"boolValue,omitempty"`
+	IntValue    *int64String  `json:"intValue,omitempty"`
+	DoubleValue *float64Value `json:"doubleValue,omitempty"`
+	BytesValue  *string       `json:"bytesValue,omitempty"`
+	ArrayValue  *arrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *keyValueList `json:"kvlistValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+type keyValueList struct {
+	Values []keyValue `json:"values"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// String renders the value as text for a dataview cell or stream
+// message. Arrays and key/value lists are rendered as JSON.
+func (v anyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(float64(*v.DoubleValue), 'g', -1, 64)
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil, v.KvlistValue != nil:
+		b, _ := json.Marshal(v.native())
+		return string(b)
+	default:
+		return ""
+	}
+}
+
+// native returns the value as plain Go types, for rendering as JSON
+func (v anyValue) native() any {
+	switch {
+	case v.ArrayValue != nil:
+		a := make([]any, 0, len(v.ArrayValue.Values))
+		for _, e := range v.ArrayValue.Values {
+			a = append(a, e.native())
+		}
+		return a
+	case v.KvlistValue != nil:
+		return attributeMap(v.KvlistValue.Values)
+	default:
+		return v.String()
+	}
+}
+
+// attributeMap converts a list of key/value pairs to a map of strings,
+// or of native values for nested lists
+func attributeMap(kvs []keyValue) map[string]any {
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.native()
+	}
+	return m
+}
+
+// attributes returns the attributes as a map of strings
+func attributes(kvs []keyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.String()
+	}
+	return m
+}
+
+// formatAttributes renders attributes as `k=v` pairs, sorted by key,
+// separated by sep
+func formatAttributes(attrs map[string]string, sep string) string {
+	var s []string
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		s = append(s, k+"="+attrs[k])
+	}
+	return strings.Join(s, sep)
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type instrumentationScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// unixNano converts an OTLP timestamp to a time.Time. A zero timestamp
+// is returned as the zero time.
+func unixNano(t int64String) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(t))
+}
+
+// metrics
+
+type exportMetricsServiceRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type scopeMetrics struct {
+	Scope   instrumentationScope `json:"scope"`
+	Metrics []metric             `json:"metrics"`
+}
+
+type metric struct {
+	Name                 string     `json:"name"`
+	Description          string     `json:"description"`
+	Unit                 string     `json:"unit"`
+	Gauge                *gauge     `json:"gauge,omitempty"`
+	Sum                  *sum       `json:"sum,omitempty"`
+	Histogram            *histogram `json:"histogram,omitempty"`
+	ExponentialHistogram *histogram `json:"exponentialHistogram,omitempty"`
+	Summary              *histogram `json:"summary,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+// histogram covers the fields used from histogram, exponential
+// histogram and summary data points. Buckets and quantiles are not
+// used.
+type histogram struct {
+	DataPoints []histogramDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes   []keyValue    `json:"attributes"`
+	TimeUnixNano int64String   `json:"timeUnixNano"`
+	AsDouble     *float64Value `json:"asDouble,omitempty"`
+	AsInt        *int64String  `json:"asInt,omitempty"`
+}
+
+type histogramDataPoint struct {
+	Attributes   []keyValue    `json:"attributes"`
+	TimeUnixNano int64String   `json:"timeUnixNano"`
+	Count        int64String   `json:"count"`
+	Sum          *float64Value `json:"sum,omitempty"`
+	Min          *float64Value `json:"min,omitempty"`
+	Max          *float64Value `json:"max,omitempty"`
+}
+
+func (p numberDataPoint) value() string {
+	switch {
+	case p.AsInt != nil:
+		return strconv.FormatInt(int64(*p.AsInt), 10)
+	case p.AsDouble != nil:
+		return formatFloat(p.AsDouble)
+	default:
+		return ""
+	}
+}
+
+func formatFloat(f *float64Value) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*f), 'g', -1, 64)
+}
+
+type exportMetricsServiceResponse struct {
+	PartialSuccess *exportPartialSuccess `json:"partialSuccess,omitempty"`
+}
+
+type exportPartialSuccess struct {
+	RejectedDataPoints int64  `json:"rejectedDataPoints,omitempty,string"`
+	RejectedLogRecords int64  `json:"rejectedLogRecords,omitempty,string"`
+	ErrorMessage       string `json:"errorMessage,omitempty"`
+}
+
+// logs
+
+type exportLogsServiceRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	Scope      instrumentationScope `json:"scope"`
+	LogRecords []logRecord          `json:"logRecords"`
+}
+
+type logRecord struct {
+	TimeUnixNano         int64String `json:"timeUnixNano"`
+	ObservedTimeUnixNano int64String `json:"observedTimeUnixNano"`
+	SeverityNumber       int         `json:"severityNumber"`
+	SeverityText         string      `json:"severityText"`
+	Body                 anyValue    `json:"body"`
+	Attributes           []keyValue  `json:"attributes"`
+	TraceID              string      `json:"traceId"`
+	SpanID               string      `json:"spanId"`
+}
+
+type exportLogsServiceResponse struct {
+	PartialSuccess *exportPartialSuccess `json:"partialSuccess,omitempty"`
+}
+
+// severity returns the severity text of the log record or, if not set,
+// the short name of the severity number range
+func (l logRecord) severity() string {
+	if l.SeverityText != "" {
+		return l.SeverityText
+	}
+	switch {
+	case l.SeverityNumber <= 0:
+		return "UNSPECIFIED"
+	case l.SeverityNumber <= 4:
+		return "TRACE"
+	case l.SeverityNumber <= 8:
+		return "DEBUG"
+	case l.SeverityNumber <= 12:
+		return "INFO"
+	case l.SeverityNumber <= 16:
+		return "WARN"
+	case l.SeverityNumber <= 20:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+// timestamp returns the time of the log record, falling back to the
+// observed time and then the current time
+func (l logRecord) timestamp() time.Time {
+	if t := unixNano(l.TimeUnixNano); !t.IsZero() {
+		return t
+	}
+	if t := unixNano(l.ObservedTimeUnixNano); !t.IsZero() {
+		return t
+	}
+	return time.Now()
+}
@@ -0,0 +1,466 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/geneos/api"
+	"github.com/itrs-group/cordial/pkg/rest"
+)
+
+// metricColumns are the fixed columns of each metrics dataview, after
+// the row name. Columns for data point attributes follow, sorted by
+// name.
+var metricColumns = []string{"metric", "value", "unit", "type", "count", "sum", "min", "max", "updated"}
+
+// timeFormat is used for the `updated` column and stream messages
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// receiver maps OTLP resources to Geneos managed entities and samplers
+// and holds the current contents of each metrics dataview between
+// updates to the Netprobe
+type receiver struct {
+	client api.APIClient
+
+	entityAttrs    []string
+	samplerAttrs   []string
+	defaultEntity  string
+	defaultSampler string
+	samplerType    string
+
+	metrics  bool
+	dataview string
+	perScope bool
+	expire   time.Duration
+
+	logs        bool
+	logSampler  string
+	stream      string
+	streamAttrs []string
+	logAttrs    bool
+
+	mu      sync.Mutex
+	views   map[viewKey]*view
+	streams map[viewKey]*api.Stream
+}
+
+// viewKey identifies a dataview, or a stream, on a Netprobe
+type viewKey struct {
+	entity, sampler, name string
+}
+
+// view is the current contents of one metrics dataview. dataview is
+// nil until the dataview has been created on the Netprobe.
+type view struct {
+	dataview *api.Dataview
+	rows     map[string]row
+	dirty    bool
+}
+
+// row is one data point, with cells keyed by column name. received is
+// used to expire rows, as the data point time is set by the sender.
+type row struct {
+	cells    map[string]string
+	attrs    []string
+	received time.Time
+}
+
+// newReceiver returns a receiver configured from cf, with a client for
+// the Netprobe API selected by the URL: XML-RPC if the path ends in
+// `/xmlrpc` and REST otherwise
+func newReceiver(cf *config.Config) (r *receiver, err error) {
+	r = &receiver{
+		entityAttrs:    config.Get[[]string](cf, cf.Join("entity", "attributes")),
+		defaultEntity:  config.Get[string](cf, cf.Join("entity", "default")),
+		samplerAttrs:   config.Get[[]string](cf, cf.Join("sampler", "attributes")),
+		defaultSampler: config.Get[string](cf, cf.Join("sampler", "default")),
+		samplerType:    config.Get[string](cf, cf.Join("sampler", "type")),
+
+		metrics:  config.Get[bool](cf, cf.Join("metrics", "enabled")),
+		dataview: config.Get[string](cf, cf.Join("metrics", "dataview")),
+		perScope: config.Get[bool](cf, cf.Join("metrics", "dataview-per-scope")),
+		expire:   config.Get[time.Duration](cf, cf.Join("metrics", "expire")),
+
+		logs:        config.Get[bool](cf, cf.Join("logs", "enabled")),
+		logSampler:  config.Get[string](cf, cf.Join("logs", "sampler")),
+		stream:      config.Get[string](cf, cf.Join("logs", "stream")),
+		streamAttrs: config.Get[[]string](cf, cf.Join("logs", "stream-attributes")),
+		logAttrs:    config.Get[bool](cf, cf.Join("logs", "attributes")),
+
+		views:   map[viewKey]*view{},
+		streams: map[viewKey]*api.Stream{},
+	}
+
+	if r.defaultEntity == "" || r.defaultSampler == "" {
+		return nil, fmt.Errorf("entity and sampler defaults must be set")
+	}
+	if r.metrics && r.dataview == "" {
+		return nil, fmt.Errorf("metrics dataview name must be set")
+	}
+	if r.logs && r.stream == "" {
+		return nil, fmt.Errorf("logs stream name must be set")
+	}
+
+	u := config.Get[string](cf, cf.Join("netprobe", "url"))
+	insecure := config.Get[bool](cf, cf.Join("netprobe", "allow-insecure"))
+	if strings.HasSuffix(strings.TrimSuffix(u, "/"), "/xmlrpc") {
+		var opts []api.Option
+		if insecure {
+			opts = append(opts, api.InsecureSkipVerify())
+		}
+		r.client, err = api.NewXMLRPCClient(u, opts...)
+	} else {
+		var opts []rest.Option
+		if insecure {
+			opts = append(opts, rest.HTTPClient(&http.Client{
+				Transport: &http.Transport{
+					Proxy:           http.ProxyFromEnvironment,
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			}))
+		}
+		r.client, err = api.NewRESTClient(u, opts...)
+	}
+	return
+}
+
+// resourceNames returns the managed entity and sampler names for the
+// resource attributes. The first non-empty attribute in each
+// configured list is used, otherwise the default.
+func (r *receiver) resourceNames(attrs map[string]string) (entity, sampler string) {
+	return firstAttribute(attrs, r.entityAttrs, r.defaultEntity), firstAttribute(attrs, r.samplerAttrs, r.defaultSampler)
+}
+
+func firstAttribute(attrs map[string]string, names []string, def string) string {
+	for _, n := range names {
+		if v := attrs[n]; v != "" {
+			return v
+		}
+	}
+	return def
+}
+
+// samplerName returns the sampler name as used by the API, including
+// the sampler type if configured
+func (r *receiver) samplerName(sampler string) string {
+	if r.samplerType != "" {
+		return sampler + "(" + r.samplerType + ")"
+	}
+	return sampler
+}
+
+// addMetrics adds the data points in req to the dataviews and returns
+// the number of data points rejected
+func (r *receiver) addMetrics(req *exportMetricsServiceRequest) (rejected int64) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rm := range req.ResourceMetrics {
+		entity, sampler := r.resourceNames(attributes(rm.Resource.Attributes))
+		for _, sm := range rm.ScopeMetrics {
+			name := r.dataview
+			if r.perScope && sm.Scope.Name != "" {
+				name = sm.Scope.Name
+			}
+			key := viewKey{entity: entity, sampler: sampler, name: name}
+			v, ok := r.views[key]
+			if !ok {
+				v = &view{rows: map[string]row{}}
+				r.views[key] = v
+			}
+
+			for _, m := range sm.Metrics {
+				for _, p := range m.points() {
+					if p.cells["value"] == "" && p.cells["count"] == "" {
+						rejected++
+						continue
+					}
+					p.cells["metric"] = m.Name
+					p.cells["unit"] = m.Unit
+					p.received = now
+					if t := unixNano(p.time); !t.IsZero() {
+						p.cells["updated"] = t.Format(timeFormat)
+					} else {
+						p.cells["updated"] = now.Format(timeFormat)
+					}
+					v.rows[rowName(m.Name, p.attrMap)] = p.row
+					v.dirty = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// point is a data point converted to a row
+type point struct {
+	row
+	attrMap map[string]string
+	time    int64String
+}
+
+// points returns the data points of m as rows. Histograms and
+// summaries are shown as the count, sum, min and max of the data point
+// with the mean as the value. Buckets and quantiles are not shown.
+func (m metric) points() (points []point) {
+	newPoint := func(kind string, kvs []keyValue, t int64String) point {
+		attrs := attributes(kvs)
+		p := point{attrMap: attrs, time: t}
+		p.cells = map[string]string{"type": kind}
+		for k, v := range attrs {
+			if slices.Contains(metricColumns, k) {
+				k = "attribute." + k
+			}
+			p.cells[k] = v
+			p.attrs = append(p.attrs, k)
+		}
+		return p
+	}
+
+	switch {
+	case m.Gauge != nil:
+		for _, d := range m.Gauge.DataPoints {
+			p := newPoint("gauge", d.Attributes, d.TimeUnixNano)
+			p.cells["value"] = d.value()
+			points = append(points, p)
+		}
+	case m.Sum != nil:
+		kind := "sum"
+		if m.Sum.AggregationTemporality == 1 {
+			kind = "sum (delta)"
+		}
+		for _, d := range m.Sum.DataPoints {
+			p := newPoint(kind, d.Attributes, d.TimeUnixNano)
+			p.cells["value"] = d.value()
+			points = append(points, p)
+		}
+	default:
+		var kind string
+		var h *histogram
+		switch {
+		case m.Histogram != nil:
+			kind, h = "histogram", m.Histogram
+		case m.ExponentialHistogram != nil:
+			kind, h = "exponentialHistogram", m.ExponentialHistogram
+		case m.Summary != nil:
+			kind, h = "summary", m.Summary
+		default:
+			return
+		}
+		for _, d := range h.DataPoints {
+			p := newPoint(kind, d.Attributes, d.TimeUnixNano)
+			p.cells["count"] = strconv.FormatInt(int64(d.Count), 10)
+			p.cells["sum"] = formatFloat(d.Sum)
+			p.cells["min"] = formatFloat(d.Min)
+			p.cells["max"] = formatFloat(d.Max)
+			if d.Sum != nil && d.Count > 0 {
+				p.cells["value"] = strconv.FormatFloat(float64(*d.Sum)/float64(d.Count), 'g', -1, 64)
+			}
+			points = append(points, p)
+		}
+	}
+	return
+}
+
+// rowName returns the row name for a metric and data point attributes,
+// in the same form as a Prometheus series, e.g. `name{k1=v1,k2=v2}`
+func rowName(name string, attrs map[string]string) string {
+	if len(attrs) == 0 {
+		return name
+	}
+	return name + "{" + formatAttributes(attrs, ",") + "}"
+}
+
+// run publishes changed dataviews every interval until ctx is
+// cancelled
+func (r *receiver) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.publish()
+		}
+	}
+}
+
+// publish removes expired rows and sends the contents of all changed
+// dataviews to the Netprobe. The tables are built while holding the
+// lock but sent after it is released, so that the receiver continues
+// to accept data while the Netprobe is slow or unavailable.
+func (r *receiver) publish() {
+	now := time.Now()
+	tables := map[viewKey][][]string{}
+
+	r.mu.Lock()
+	for key, v := range r.views {
+		if r.expire > 0 {
+			for name, row := range v.rows {
+				if now.Sub(row.received) > r.expire {
+					delete(v.rows, name)
+					v.dirty = true
+				}
+			}
+		}
+		if v.dirty {
+			tables[key] = v.table()
+			v.dirty = false
+		}
+	}
+	r.mu.Unlock()
+
+	for key, table := range tables {
+		if err := r.update(key, table); err != nil {
+			log.Error("updating dataview failed", slog.String("entity", key.entity), slog.String("sampler", key.sampler), slog.String("dataview", key.name), slog.Any("error", err))
+			r.mu.Lock()
+			if v, ok := r.views[key]; ok {
+				// try again next time, creating the dataview in case
+				// the Netprobe has been restarted
+				v.dirty = true
+				v.dataview = nil
+			}
+			r.mu.Unlock()
+			continue
+		}
+		log.Debug("updated dataview", slog.String("entity", key.entity), slog.String("sampler", key.sampler), slog.String("dataview", key.name), slog.Int("rows", len(table)-1))
+	}
+}
+
+// update sends table to the dataview, creating it first if required
+func (r *receiver) update(key viewKey, table [][]string) (err error) {
+	r.mu.Lock()
+	dv := r.views[key].dataview
+	r.mu.Unlock()
+
+	if dv == nil {
+		if dv, err = api.NewDataview(r.client, key.entity, key.sampler, r.samplerType, "", key.name); err != nil {
+			return
+		}
+		r.mu.Lock()
+		r.views[key].dataview = dv
+		r.mu.Unlock()
+	}
+	return dv.UpdateDataview(dv.Entity, dv.Sampler, dv.Name, table)
+}
+
+// table returns the contents of the view, with the column headings as
+// the first row and rows sorted by name
+func (v *view) table() (table [][]string) {
+	attrs := map[string]bool{}
+	for _, row := range v.rows {
+		for _, a := range row.attrs {
+			attrs[a] = true
+		}
+	}
+	columns := append(slices.Clone(metricColumns), slices.Sorted(maps.Keys(attrs))...)
+
+	table = append(table, append([]string{"name"}, columns...))
+	for _, name := range slices.Sorted(maps.Keys(v.rows)) {
+		cells := []string{name}
+		for _, c := range columns {
+			cells = append(cells, v.rows[name].cells[c])
+		}
+		table = append(table, cells)
+	}
+	return
+}
+
+// addLogs writes the log records in req to streams and returns the
+// number of records that could not be written and the first error
+func (r *receiver) addLogs(req *exportLogsServiceRequest) (rejected int64, err error) {
+	for _, rl := range req.ResourceLogs {
+		resAttrs := attributes(rl.Resource.Attributes)
+		entity, sampler := r.resourceNames(resAttrs)
+		if r.logSampler != "" {
+			sampler = r.logSampler
+		} else {
+			sampler = r.samplerName(sampler)
+		}
+
+		for _, sl := range rl.ScopeLogs {
+			for _, l := range sl.LogRecords {
+				attrs := attributes(l.Attributes)
+				name := firstAttribute(attrs, r.streamAttrs, firstAttribute(resAttrs, r.streamAttrs, r.stream))
+
+				s, e := r.openStream(viewKey{entity: entity, sampler: sampler, name: name})
+				if e == nil {
+					_, e = s.Write([]byte(r.logMessage(l, attrs)))
+				}
+				if e != nil {
+					rejected++
+					if err == nil {
+						err = fmt.Errorf("stream %s/%s/%s: %w", entity, sampler, name, e)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// openStream returns the stream for key, opening it if required
+func (r *receiver) openStream(key viewKey) (s *api.Stream, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.streams[key]; ok {
+		return s, nil
+	}
+	if s, err = api.OpenStream(r.client, key.entity, key.sampler, key.name); err != nil {
+		return
+	}
+	r.streams[key] = s
+	return
+}
+
+// logMessage formats a log record as a single line stream message:
+//
+//	TIMESTAMP SEVERITY BODY [k=v ...]
+//
+// with the trace and span IDs, if set, added to the attributes
+func (r *receiver) logMessage(l logRecord, attrs map[string]string) string {
+	msg := l.timestamp().Format(timeFormat) + " " + l.severity() + " " + strings.ReplaceAll(l.Body.String(), "\n", " ")
+	if !r.logAttrs {
+		return msg
+	}
+	if l.TraceID != "" {
+		attrs["trace_id"] = l.TraceID
+	}
+	if l.SpanID != "" {
+		attrs["span_id"] = l.SpanID
+	}
+	if len(attrs) > 0 {
+		msg += " " + formatAttributes(attrs, " ")
+	}
+	return msg
+}
@@ -0,0 +1,246 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/itrs-group/cordial"
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/process"
+)
+
+var daemon bool
+
+func init() {
+	Cmd.AddCommand(startCmd)
+
+	startCmd.Flags().BoolVarP(&daemon, "daemon", "D", false, "Daemonise the receiver process")
+	startCmd.PersistentFlags().StringVarP(&logFile, "logfile", "l", "-", "Write logs to `file`. Use '-' for console or "+os.DevNull+" for none")
+
+	startCmd.Flags().SortFlags = false
+}
+
+//go:embed _docs/start.md
+var startDescription string
+
+var startCmd = &cobra.Command{
+	Use:          "start",
+	Short:        "Run the receiver",
+	Long:         startDescription,
+	SilenceUsage: true,
+	Run: func(command *cobra.Command, args []string) {
+		if daemon {
+			var logArgs []string
+
+			if logFile == "-" {
+				logArgs = append(logArgs, "--logfile", cordial.ExecutableName()+".log")
+			}
+
+			if err := process.Daemon(os.Stdout, logArgs, nil, "-D", "--daemon"); err != nil {
+				log.Error("failed to daemonise process", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}
+
+		var l slog.Level = slog.LevelInfo
+		if Debug {
+			l = slog.LevelDebug
+		}
+
+		cf := LoadConfigFile()
+
+		log = cordial.LogInit(cordial.ExecutableName(),
+			cordial.SetLogLevel(l),
+			cordial.SetLogfile(logFile),
+			cordial.LumberjackOptions(&lumberjack.Logger{
+				Filename:   logFile,
+				MaxSize:    config.Get[int](cf, cf.Join("server", "logs", "max-size")),
+				MaxBackups: config.Get[int](cf, cf.Join("server", "logs", "max-backups")),
+				MaxAge:     config.Get[int](cf, cf.Join("server", "logs", "stale-after")),
+				Compress:   config.Get[bool](cf, cf.Join("server", "logs", "compress")),
+			}),
+			cordial.RotateOnStart(config.Get[bool](cf, cf.Join("server", "logs", "rotate-on-start"))),
+		)
+
+		r, err := newReceiver(cf)
+		if err != nil {
+			log.Error("invalid configuration", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		interval := config.Get[time.Duration](cf, cf.Join("metrics", "interval"))
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+		go r.run(context.Background(), interval)
+
+		if err = startReceiver(cf, r); err != nil {
+			log.Error("failed to start server", slog.Any("error", err))
+			os.Exit(1)
+		}
+	},
+}
+
+// OTLP/HTTP paths
+const (
+	metricsPath = "/v1/metrics"
+	logsPath    = "/v1/logs"
+)
+
+func startReceiver(cf *config.Config, r *receiver) error {
+	listen := config.Get[string](cf, cf.Join("server", "listen"))
+	maxBody := config.Get[int64](cf, cf.Join("server", "max-body"))
+
+	mux := http.NewServeMux()
+	if r.metrics {
+		mux.HandleFunc("POST "+metricsPath, func(w http.ResponseWriter, req *http.Request) {
+			var m exportMetricsServiceRequest
+			if !decodeRequest(w, req, maxBody, &m) {
+				return
+			}
+			var resp exportMetricsServiceResponse
+			if rejected := r.addMetrics(&m); rejected > 0 {
+				resp.PartialSuccess = &exportPartialSuccess{
+					RejectedDataPoints: rejected,
+					ErrorMessage:       "data points without a value",
+				}
+			}
+			writeJSON(w, http.StatusOK, resp)
+		})
+	}
+	if r.logs {
+		mux.HandleFunc("POST "+logsPath, func(w http.ResponseWriter, req *http.Request) {
+			var l exportLogsServiceRequest
+			if !decodeRequest(w, req, maxBody, &l) {
+				return
+			}
+			var resp exportLogsServiceResponse
+			if rejected, err := r.addLogs(&l); rejected > 0 {
+				log.Error("writing log records to streams failed", slog.Int64("rejected", rejected), slog.Any("error", err))
+				resp.PartialSuccess = &exportPartialSuccess{
+					RejectedLogRecords: rejected,
+					ErrorMessage:       err.Error(),
+				}
+			}
+			writeJSON(w, http.StatusOK, resp)
+		})
+	}
+
+	log.Info("starting receiver", slog.String("listen", listen), slog.Bool("metrics", r.metrics), slog.Bool("logs", r.logs))
+
+	if !config.Get[bool](cf, cf.Join("server", "tls", "enabled")) {
+		return http.ListenAndServe(listen, mux)
+	}
+
+	certPEM := config.Get[[]byte](cf, cf.Join("server", "tls", "certificate"))
+	keyPEM := config.Get[[]byte](cf, cf.Join("server", "tls", "private-key"))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:    listen,
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		},
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	return srv.Serve(tls.NewListener(ln, srv.TLSConfig))
+}
+
+// status is the JSON encoding of a google.rpc.Status, returned in the
+// body of OTLP/HTTP error responses
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// gRPC status codes used in error responses
+const (
+	codeInvalidArgument = 3
+	codeUnimplemented   = 12
+)
+
+// decodeRequest decodes the JSON body of req into v. Only the JSON
+// encoding of OTLP/HTTP is supported, protobuf requests are rejected
+// with 415 Unsupported Media Type. On error the response has been
+// written and false is returned.
+func decodeRequest(w http.ResponseWriter, req *http.Request, maxBody int64, v any) bool {
+	mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mt != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, status{
+			Code:    codeUnimplemented,
+			Message: "unsupported content type " + mt + ", only OTLP/HTTP JSON (application/json) is accepted",
+		})
+		return false
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, req.Body, maxBody)
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, status{Code: codeInvalidArgument, Message: err.Error()})
+			return false
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		writeJSON(w, http.StatusUnsupportedMediaType, status{Code: codeUnimplemented, Message: "unsupported content encoding"})
+		return false
+	}
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		log.Debug("invalid request", slog.String("path", req.URL.Path), slog.Any("error", err))
+		writeJSON(w, http.StatusBadRequest, status{Code: codeInvalidArgument, Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debug("writing response failed", slog.Any("error", err))
+	}
+}
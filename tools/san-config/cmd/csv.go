@@ -0,0 +1,70 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"log/slog"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// ParseInventoryCSV loads a CSV inventory with a header row of column
+// names. Each following row is a host, mapped to a hostname and type
+// using `inventory.fields` with the column names as the lookup keys.
+// Lines starting with `#` are ignored.
+func ParseInventoryCSV(cf *config.Config, cacheFile string, in io.Reader) (inv *Inventory, contents []byte, err error) {
+	buf := &bytes.Buffer{}
+
+	if cacheFile != "" {
+		buf = bytes.NewBuffer(make([]byte, 0, bufSize))
+		in = io.TeeReader(in, buf)
+	}
+
+	c := csv.NewReader(in)
+	c.Comment = '#'
+	c.TrimLeadingSpace = true
+
+	rows, err := c.ReadAll()
+	if err != nil {
+		log.Error("loading inventory", slog.Any("error", err))
+		return
+	}
+	contents = buf.Bytes()
+
+	var records []map[string]string
+	if len(rows) > 0 {
+		columns := rows[0]
+		for _, row := range rows[1:] {
+			r := make(map[string]string, len(columns))
+			for i, col := range columns {
+				if i < len(row) {
+					r[col] = row[i]
+				}
+			}
+			records = append(records, r)
+		}
+	}
+
+	inv = &Inventory{
+		hosts: recordHosts(cf, records, config.Get[map[string]string](cf, "inventory.mappings")),
+	}
+	return
+}
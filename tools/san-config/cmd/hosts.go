@@ -18,6 +18,9 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"log/slog"
+	"maps"
 	"sync"
 
 	"github.com/itrs-group/cordial/pkg/config"
@@ -29,12 +32,64 @@ type HostMappings map[string]string
 // Inventories is a map of source to inventory, for "if modified" checks
 var Inventories sync.Map
 
-// LoadHosts reads the inventories and extracts the hosts and their
-// types, returning them as a map of HostMappings
+// LoadHosts reads the inventories using the provider for
+// `inventory.type` and extracts the hosts and their types, returning
+// them as a map of HostMappings. The provider is called for each value
+// in `inventory.indices`, with `index` added to the mappings, and hosts
+// in later inventories replace those in earlier ones. Inventories that
+// cannot be loaded are logged and skipped.
 func LoadHosts(cf *config.Config) (hosts map[string]HostMappings, err error) {
-	switch config.Get[string](cf, "inventory.type") {
-	case "yaml":
-		hosts, err = ReadHostsYAML(cf)
+	inventoryType := config.Get[string](cf, "inventory.type")
+	provider, ok := inventoryProviders[inventoryType]
+	if !ok {
+		err = fmt.Errorf("unknown inventory type %q", inventoryType)
+		return
 	}
+
+	hosts = make(map[string]HostMappings)
+	lookup := config.Get[map[string]string](cf, "inventory.mappings")
+	checkModified := config.Get[bool](cf, "inventory.check-modified")
+
+	for _, index := range config.Get[[]string](cf, "inventory.indices") {
+		src := InventorySource{
+			Lookup: maps.Clone(lookup),
+		}
+		if src.Lookup == nil {
+			src.Lookup = make(map[string]string)
+		}
+		src.Lookup["index"] = index
+
+		src.Source = config.Get[string](cf, "inventory.source", config.LookupTable(src.Lookup))
+		src.CacheFile = config.ResolveHome(config.Get[string](cf, "inventory.cache", config.LookupTable(src.Lookup)))
+
+		if checkModified {
+			if pi, ok := Inventories.Load(src.Source); ok {
+				if pinv, ok := pi.(*Inventory); ok {
+					src.Previous = pinv
+				}
+			}
+		}
+
+		inv, err := provider.LoadInventory(cf, src)
+		if err != nil {
+			log.Error("failed to read inventory", slog.String("type", inventoryType), slog.String("src", src.Source), slog.Any("error", err))
+			continue
+		}
+
+		if checkModified {
+			log.Debug("storing inventory", slog.String("src", src.Source), slog.Int64("size", inv.size), slog.Time("last_modified", inv.lastModified))
+			Inventories.Store(src.Source, inv)
+		}
+
+		for h, t := range inv.hosts {
+			hosts[h] = maps.Clone(lookup)
+			if hosts[h] == nil {
+				hosts[h] = make(HostMappings)
+			}
+			hosts[h]["hostname"] = h
+			hosts[h]["hosttype"] = t
+		}
+	}
+
 	return
 }
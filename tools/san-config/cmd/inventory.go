@@ -27,7 +27,6 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 
@@ -64,9 +63,8 @@ func ReadInventory(cf *config.Config, file string, options ...FetchOption) (inv
 		return
 	}
 	defer in.Close()
-	switch fo.inventoryType {
-	case "yaml":
-		inv, _, err = ParseInventoryYAML(cf, "", in)
+	if inv, _, err = parseInventory(cf, fo.inventoryType, "", in); err != nil {
+		return
 	}
 	inv.source = file
 	if st, err := os.Stat(file); err == nil { // stat succeeds
@@ -76,8 +74,9 @@ func ReadInventory(cf *config.Config, file string, options ...FetchOption) (inv
 	return
 }
 
-// FetchInventory fetches an inventory file in JSON format from the
-// source URL with optional method (default GET), client and requests.
+// FetchInventory fetches an inventory file from the source URL with
+// optional method (default GET), client and requests. The format is set
+// with the InventoryType option.
 func FetchInventory(cf *config.Config, source string, cacheFile string, options ...FetchOption) (inv *Inventory, err error) {
 	var cache []byte
 
@@ -124,9 +123,8 @@ func FetchInventory(cf *config.Config, source string, cacheFile string, options
 		}
 	}
 
-	switch fo.inventoryType {
-	case "yaml":
-		inv, cache, err = ParseInventoryYAML(cf, cacheFile, resp.Body)
+	if inv, cache, err = parseInventory(cf, fo.inventoryType, cacheFile, resp.Body); err != nil {
+		return
 	}
 
 	// set size and last modified if available, ignore errors as zero values are valid
@@ -137,34 +135,27 @@ func FetchInventory(cf *config.Config, source string, cacheFile string, options
 		inv.lastModified, _ = http.ParseTime(lm)
 	}
 
-	// write cache if non-zero len. any error results in clean-up and no write.
-	if len(cache) > 0 && cacheFile != "" {
-		dir, file := filepath.Split(cacheFile)
-		if err = os.MkdirAll(dir, 0775); err != nil {
-			log.Warn("making directories", slog.Any("error", err))
-			return inv, nil
-		}
-		f, err := os.CreateTemp(dir, file+"-*")
-		if err != nil {
-			log.Warn("creating temp file", slog.Any("error", err))
-			return inv, nil
-		}
-		// defer clean-up (which fails once file is renamed)
-		defer f.Close()
-		defer os.Remove(f.Name())
-		if _, err = f.Write(cache); err != nil {
-			log.Warn("writing inventory to temp file", slog.Any("error", err))
-			return inv, nil
-		}
-		if err = os.Rename(f.Name(), cacheFile); err != nil {
-			log.Warn("renaming temp file", slog.Any("error", err))
-			return inv, nil
-		}
-	}
+	writeInventoryCache(cacheFile, cache)
 
 	return
 }
 
+// parseInventory parses the inventory from in using the parser for
+// inventoryType, returning the contents for caching if cacheFile is set
+func parseInventory(cf *config.Config, inventoryType string, cacheFile string, in io.Reader) (inv *Inventory, contents []byte, err error) {
+	switch inventoryType {
+	case "yaml":
+		return ParseInventoryYAML(cf, cacheFile, in)
+	case "json":
+		return ParseInventoryJSON(cf, cacheFile, in)
+	case "csv":
+		return ParseInventoryCSV(cf, cacheFile, in)
+	default:
+		err = fmt.Errorf("unsupported inventory file type %q", inventoryType)
+		return
+	}
+}
+
 type fetchOptions struct {
 	inventoryType string
 	method        string
@@ -199,7 +190,8 @@ func evalFetchOptions(options ...FetchOption) (f *fetchOptions) {
 // FetchOption for FetchInventory options
 type FetchOption func(*fetchOptions)
 
-// InventoryType sets the inventory format. The default is YAML.
+// InventoryType sets the inventory format, one of `yaml`, `json` or
+// `csv`. The default is YAML.
 func InventoryType(t string) FetchOption {
 	return func(fo *fetchOptions) {
 		fo.inventoryType = t
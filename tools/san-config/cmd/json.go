@@ -0,0 +1,92 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// ParseInventoryJSON loads a JSON inventory, which is either a flat
+// object of:
+//
+//	{ "name": "type", "name": "type" }
+//
+// or an array of objects, one per host, which are mapped to hostnames
+// and types using `inventory.fields`.
+func ParseInventoryJSON(cf *config.Config, cacheFile string, in io.Reader) (inv *Inventory, contents []byte, err error) {
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return
+	}
+
+	var v any
+	if err = json.Unmarshal(b, &v); err != nil {
+		log.Error("loading inventory", slog.Any("error", err))
+		return
+	}
+
+	hosts, err := jsonHosts(cf, v, config.Get[map[string]string](cf, "inventory.mappings"))
+	if err != nil {
+		return
+	}
+
+	inv = &Inventory{
+		hosts: hosts,
+	}
+	if cacheFile != "" {
+		contents = b
+	}
+	return
+}
+
+// jsonHosts returns a map of hostname to type from the decoded JSON
+// value v, which must be an object of hostname to type or an array of
+// records. Record values are converted to strings for
+// `inventory.fields`, with any objects or arrays left as JSON.
+func jsonHosts(cf *config.Config, v any, lookup map[string]string) (hosts map[string]string, err error) {
+	switch t := v.(type) {
+	case map[string]any:
+		hosts = make(map[string]string, len(t))
+		for h, ht := range t {
+			hosts[h] = jsonString(ht)
+		}
+	case []any:
+		records := make([]map[string]string, 0, len(t))
+		for _, e := range t {
+			o, ok := e.(map[string]any)
+			if !ok {
+				err = errors.New("inventory array entries must be objects")
+				return
+			}
+			r := make(map[string]string, len(o))
+			for k, x := range o {
+				r[k] = jsonString(x)
+			}
+			records = append(records, r)
+		}
+		hosts = recordHosts(cf, records, lookup)
+	default:
+		err = errors.New("inventory must be an object or an array of objects")
+	}
+	return
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+func init() {
+	RegisterInventoryProvider("ldap", InventoryProviderFunc(loadLDAPInventory))
+}
+
+// loadLDAPInventory loads an inventory from a search of the LDAP
+// directory at the source URL, which must be an `ldap://` or `ldaps://`
+// URL. Each entry found is mapped to a host using `inventory.fields`
+// with the first value of each of the `inventory.ldap.attributes`, plus
+// `dn` for the entry DN, as lookup keys.
+//
+// If `inventory.authentication.type` is `basic` then the username (a
+// DN) and password are used to bind to the directory, otherwise the
+// search is anonymous. LDAP has no way to check for changes, so the
+// checksum of the results is used instead.
+func loadLDAPInventory(cf *config.Config, src InventorySource) (inv *Inventory, err error) {
+	timeout := inventoryTimeout(cf)
+
+	l, err := ldap.DialURL(src.Source,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(&tls.Config{
+			InsecureSkipVerify: config.Get[bool](cf, "inventory.insecure"),
+		}),
+	)
+	if err != nil {
+		return
+	}
+	defer l.Close()
+	l.SetTimeout(timeout)
+
+	if config.Get[string](cf, "inventory.authentication.type") == "basic" {
+		if err = l.Bind(config.Get[string](cf, "inventory.authentication.username"),
+			string(config.Get[config.Secret](cf, "inventory.authentication.password"))); err != nil {
+			return
+		}
+	}
+
+	filter := config.Get[string](cf, "inventory.ldap.filter", config.LookupTable(src.Lookup))
+	if filter == "" {
+		filter = "(objectClass=computer)"
+	}
+	attributes := config.Get[[]string](cf, "inventory.ldap.attributes")
+
+	search := ldap.NewSearchRequest(config.Get[string](cf, "inventory.ldap.base", config.LookupTable(src.Lookup)),
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(timeout.Seconds()), false,
+		filter, attributes, nil)
+
+	result, err := l.SearchWithPaging(search, 500)
+	if err != nil {
+		return
+	}
+
+	records := make([]map[string]string, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		r := map[string]string{"dn": e.DN}
+		for _, a := range attributes {
+			r[a] = e.GetAttributeValue(a)
+		}
+		records = append(records, r)
+	}
+
+	return remoteInventory(cf, src, records)
+}
@@ -0,0 +1,246 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// InventoryProvider loads an inventory of hosts and their types from a
+// source. Providers are selected by `inventory.type` and are called
+// once for each value in `inventory.indices`.
+type InventoryProvider interface {
+	LoadInventory(cf *config.Config, src InventorySource) (inv *Inventory, err error)
+}
+
+// InventoryProviderFunc adapts a function to an InventoryProvider
+type InventoryProviderFunc func(cf *config.Config, src InventorySource) (inv *Inventory, err error)
+
+// LoadInventory calls f(cf, src)
+func (f InventoryProviderFunc) LoadInventory(cf *config.Config, src InventorySource) (*Inventory, error) {
+	return f(cf, src)
+}
+
+// InventorySource is the request passed to an InventoryProvider
+type InventorySource struct {
+	Source    string            // `inventory.source`, expanded
+	CacheFile string            // `inventory.cache`, expanded, empty for no cache
+	Lookup    map[string]string // `inventory.mappings` plus `index`
+	Previous  *Inventory        // the last inventory from Source, only if `inventory.check-modified` is set
+}
+
+var inventoryProviders = make(map[string]InventoryProvider)
+
+// RegisterInventoryProvider registers provider for the inventory type
+// name, replacing any existing provider of the same name. It should be
+// called from init() functions.
+func RegisterInventoryProvider(name string, provider InventoryProvider) {
+	inventoryProviders[name] = provider
+}
+
+func init() {
+	RegisterInventoryProvider("yaml", fileInventory("yaml"))
+	RegisterInventoryProvider("json", fileInventory("json"))
+	RegisterInventoryProvider("csv", fileInventory("csv"))
+}
+
+// fileInventory returns a provider for inventory files of type
+// inventoryType, which can be local files or fetched from http or
+// https URLs. Only remote inventories are written to the cache file.
+func fileInventory(inventoryType string) InventoryProviderFunc {
+	return func(cf *config.Config, src InventorySource) (inv *Inventory, err error) {
+		source := src.Source
+		fetchopts := []FetchOption{InventoryType(inventoryType)}
+
+		switch config.Get[string](cf, "inventory.authentication.type") {
+		case "header":
+			fetchopts = append(fetchopts,
+				AddHeader(config.Get[string](cf, "inventory.authentication.header"),
+					config.Get[[]string](cf, "inventory.authentication.value"),
+				))
+		case "basic":
+			fetchopts = append(fetchopts,
+				BasicAuth(config.Get[string](cf, "inventory.authentication.username"),
+					config.Get[config.Secret](cf, "inventory.authentication.password"),
+				))
+		}
+
+		if src.Previous != nil {
+			log.Debug("checking inventory", slog.String("src", source), slog.Int64("size", src.Previous.size), slog.Time("last_modified", src.Previous.lastModified))
+			fetchopts = append(fetchopts, IfModified(src.Previous))
+		}
+
+		switch {
+		case strings.HasPrefix(source, "http:"), strings.HasPrefix(source, "https:"):
+			client := inventoryHTTPClient(cf)
+			defer client.CloseIdleConnections()
+			fetchopts = append(fetchopts, Client(client))
+			return FetchInventory(cf, source, src.CacheFile, fetchopts...)
+		case strings.HasPrefix(source, "file"):
+			// remove file: scheme and drop through
+			source = strings.TrimPrefix(source, "file:")
+			fallthrough
+		default:
+			return ReadInventory(cf, config.ResolveHome(source), fetchopts...)
+		}
+	}
+}
+
+// inventoryHTTPClient returns an HTTP client using the
+// `inventory.timeout` and `inventory.insecure` settings
+func inventoryHTTPClient(cf *config.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.Get[bool](cf, "inventory.insecure"),
+			},
+		},
+		Timeout: inventoryTimeout(cf),
+	}
+}
+
+func inventoryTimeout(cf *config.Config) (timeout time.Duration) {
+	timeout = config.Get[time.Duration](cf, "inventory.timeout")
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return
+}
+
+// recordHosts returns a map of hostname to host type from records,
+// using the `inventory.fields.hostname` and `inventory.fields.hosttype`
+// values expanded with each record and then lookup. Records without a
+// hostname are skipped.
+func recordHosts(cf *config.Config, records []map[string]string, lookup map[string]string) (hosts map[string]string) {
+	hosts = make(map[string]string, len(records))
+
+	hostname := config.Get[string](cf, "inventory.fields.hostname", config.NoExpand())
+	hosttype := config.Get[string](cf, "inventory.fields.hosttype", config.NoExpand())
+
+	for _, r := range records {
+		h := config.Expand[string](cf, hostname, config.LookupTable(r, lookup))
+		if h == "" {
+			log.Debug("skipping inventory record without a hostname", slog.Any("record", r))
+			continue
+		}
+		hosts[h] = config.Expand[string](cf, hosttype, config.LookupTable(r, lookup))
+	}
+	return
+}
+
+// remoteInventory returns an inventory from v, the results of a remote
+// provider, which must encode to JSON as either an object of hostnames
+// to types or an array of records. A checksum of the results is used
+// to return the previous inventory unchanged when there is no other way
+// to tell if the source has been modified. The results are written to
+// the cache file, if any, in JSON so that it can be used as a `json`
+// inventory.
+func remoteInventory(cf *config.Config, src InventorySource, v any) (inv *Inventory, err error) {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(contents)
+	cksum := hex.EncodeToString(sum[:])
+
+	if src.Previous != nil && src.Previous.cksum == cksum {
+		log.Info("inventory not modified (cksum)", slog.String("src", src.Source))
+		return src.Previous, nil
+	}
+
+	log.Info("loading inventory", slog.String("src", src.Source))
+
+	// decode again to get the plain JSON types
+	var results any
+	if err = json.Unmarshal(contents, &results); err != nil {
+		return
+	}
+	hosts, err := jsonHosts(cf, results, src.Lookup)
+	if err != nil {
+		return
+	}
+
+	inv = &Inventory{
+		source: src.Source,
+		hosts:  hosts,
+		size:   int64(len(contents)),
+		cksum:  cksum,
+	}
+
+	writeInventoryCache(src.CacheFile, contents)
+	return
+}
+
+// writeInventoryCache atomically writes contents to cacheFile, if
+// cacheFile is set and contents is not empty. Intermediate directories
+// are created. Errors are logged and any partial file removed.
+func writeInventoryCache(cacheFile string, contents []byte) {
+	if len(contents) == 0 || cacheFile == "" {
+		return
+	}
+	dir, file := filepath.Split(cacheFile)
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		log.Warn("making directories", slog.Any("error", err))
+		return
+	}
+	f, err := os.CreateTemp(dir, file+"-*")
+	if err != nil {
+		log.Warn("creating temp file", slog.Any("error", err))
+		return
+	}
+	// defer clean-up (which fails once file is renamed)
+	defer f.Close()
+	defer os.Remove(f.Name())
+	if _, err = f.Write(contents); err != nil {
+		log.Warn("writing inventory to temp file", slog.Any("error", err))
+		return
+	}
+	if err = os.Rename(f.Name(), cacheFile); err != nil {
+		log.Warn("renaming temp file", slog.Any("error", err))
+		return
+	}
+}
+
+// jsonString returns the JSON value v as a string. Other than strings
+// and nulls, values are returned as JSON.
+func jsonString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
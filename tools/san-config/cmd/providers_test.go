@@ -0,0 +1,480 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+// testInventoryConfig returns a configuration with check-modified set,
+// a single `main` index, a `company` mapping and the settings in values.
+// Any previously loaded inventories are forgotten.
+func testInventoryConfig(t *testing.T, values map[string]any) *config.Config {
+	t.Helper()
+	Inventories.Clear()
+	cf := config.New()
+	config.Set(cf, "inventory.check-modified", true)
+	config.Set(cf, "inventory.indices", []string{"main"})
+	config.Set(cf, "inventory.mappings", map[string]string{"company": "ITRS"})
+	config.Set(cf, "inventory.fields.hostname", "${name}")
+	config.Set(cf, "inventory.fields.hosttype", "${type}")
+	for k, v := range values {
+		config.Set(cf, k, v)
+	}
+	return cf
+}
+
+// loadHosts calls LoadHosts and returns the hosts as a map of hostname
+// to type, checking the other mappings, and the inventory stored for
+// source
+func loadHosts(t *testing.T, cf *config.Config, source string) (hosts map[string]string, inv *Inventory) {
+	t.Helper()
+	h, err := LoadHosts(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts = make(map[string]string, len(h))
+	for name, m := range h {
+		if m["hostname"] != name || m["company"] != "ITRS" {
+			t.Errorf("host %q mappings = %v", name, m)
+		}
+		hosts[name] = m["hosttype"]
+	}
+	if i, ok := Inventories.Load(source); ok {
+		inv = i.(*Inventory)
+	}
+	return
+}
+
+// fakeSource is an HTTP inventory source that returns body, with a
+// Last-Modified header if lastModified is set, and records the requests
+type fakeSource struct {
+	*httptest.Server
+	mutex        sync.Mutex
+	body         string
+	lastModified time.Time
+	requests     []*http.Request
+}
+
+func newFakeSource(t *testing.T, body string, lastModified time.Time) *fakeSource {
+	f := &fakeSource{body: body, lastModified: lastModified.Truncate(time.Second)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.requests = append(f.requests, r.Clone(r.Context()))
+		if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !f.lastModified.IsZero() && !f.lastModified.After(ims) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if !f.lastModified.IsZero() {
+			w.Header().Set("Last-Modified", f.lastModified.UTC().Format(http.TimeFormat))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(f.body))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeSource) setBody(body string, lastModified time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.body = body
+	f.lastModified = lastModified.Truncate(time.Second)
+}
+
+// last returns the most recent request
+func (f *fakeSource) last(t *testing.T) *http.Request {
+	t.Helper()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.requests) == 0 {
+		t.Fatal("no requests to inventory source")
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// TestYAMLFileInventory checks that local YAML inventories are loaded as
+// they were before inventory providers, with hostnames lowercased,
+// dotted names kept whole and later indices replacing earlier hosts
+func TestYAMLFileInventory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"inv-a.yaml": "Host1.Example.com: gateway\nhost2: netprobe\n",
+		"inv-b.yaml": "host2: gateway\nhost3: netprobe\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cf := testInventoryConfig(t, map[string]any{
+		"inventory.type":    "yaml",
+		"inventory.indices": []string{"a", "b"},
+		"inventory.source":  "file:" + filepath.Join(dir, "inv-${index}.yaml"),
+	})
+
+	hosts, inv := loadHosts(t, cf, "file:"+filepath.Join(dir, "inv-a.yaml"))
+	want := map[string]string{"host1.example.com": "gateway", "host2": "gateway", "host3": "netprobe"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+	if inv == nil {
+		t.Fatal("inventory not stored for check-modified")
+	}
+
+	// unchanged files are not read again
+	if _, again := loadHosts(t, cf, "file:"+filepath.Join(dir, "inv-a.yaml")); again != inv {
+		t.Error("unchanged inventory file loaded again")
+	}
+
+	// but changed ones are
+	if err := os.WriteFile(filepath.Join(dir, "inv-a.yaml"), []byte("host4: gateway\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hosts, again := loadHosts(t, cf, "file:"+filepath.Join(dir, "inv-a.yaml"))
+	if again == inv {
+		t.Error("changed inventory file not loaded again")
+	}
+	want = map[string]string{"host4": "gateway", "host2": "gateway", "host3": "netprobe"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts after change = %v, want %v", hosts, want)
+	}
+
+	// a missing inventory is skipped
+	config.Set(cf, "inventory.indices", []string{"a", "missing"})
+	if hosts, _ = loadHosts(t, cf, ""); len(hosts) != 1 {
+		t.Errorf("hosts with a missing inventory = %v, want only host4", hosts)
+	}
+}
+
+func TestYAMLHTTPInventory(t *testing.T) {
+	body := "host1: gateway\nhost2: netprobe\n"
+	src := newFakeSource(t, body, time.Now().Add(-time.Hour))
+	cache := filepath.Join(t.TempDir(), "cache", "inventory.yaml")
+	cf := testInventoryConfig(t, map[string]any{
+		"inventory.type":                    "yaml",
+		"inventory.source":                  src.URL + "/${index}.yaml",
+		"inventory.cache":                   cache,
+		"inventory.authentication.type":     "basic",
+		"inventory.authentication.username": "user",
+		"inventory.authentication.password": "pass",
+	})
+
+	hosts, inv := loadHosts(t, cf, src.URL+"/main.yaml")
+	if want := map[string]string{"host1": "gateway", "host2": "netprobe"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+	r := src.last(t)
+	if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" || r.URL.Path != "/main.yaml" {
+		t.Errorf("request %s with basic auth %q, %q, %v", r.URL.Path, u, p, ok)
+	}
+	if b, err := os.ReadFile(cache); err != nil || string(b) != body {
+		t.Errorf("cache file = %q, %v, want %q", b, err, body)
+	}
+
+	// not modified since the last load
+	hosts, again := loadHosts(t, cf, src.URL+"/main.yaml")
+	if src.last(t).Header.Get("If-Modified-Since") == "" {
+		t.Error("no If-Modified-Since header with check-modified")
+	}
+	if again != inv || len(hosts) != 2 {
+		t.Errorf("not modified inventory reloaded, or hosts %v lost", hosts)
+	}
+}
+
+func TestRESTInventory(t *testing.T) {
+	body := `{"data": {"hosts": [
+		{"name": "r1", "type": "gateway", "tags": ["a", "b"]},
+		{"name": "r2", "type": "netprobe"},
+		{"type": "netprobe"}
+	]}}`
+	src := newFakeSource(t, body, time.Now().Add(-time.Hour))
+	cache := filepath.Join(t.TempDir(), "inventory.json")
+	cf := testInventoryConfig(t, map[string]any{
+		"inventory.type":                 "rest",
+		"inventory.source":               src.URL + "/api/hosts?env=${index}",
+		"inventory.cache":                cache,
+		"inventory.rest.results":         "data.hosts",
+		"inventory.fields.hosttype":      "${type}-${company}",
+		"inventory.authentication.type":  "token",
+		"inventory.authentication.token": "tok",
+	})
+
+	hosts, inv := loadHosts(t, cf, src.URL+"/api/hosts?env=main")
+	want := map[string]string{"r1": "gateway-ITRS", "r2": "netprobe-ITRS"}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+	r := src.last(t)
+	if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("Accept") != "application/json" || r.URL.Query().Get("env") != "main" {
+		t.Errorf("request %s with headers %v", r.URL, r.Header)
+	}
+
+	// the cache can be loaded as a json inventory with the same fields
+	f, err := os.Open(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cached, _, err := ParseInventoryJSON(cf, "", f)
+	if err != nil || !reflect.DeepEqual(cached.hosts, want) {
+		t.Errorf("cached inventory hosts = %v, %v, want %v", cached, err, want)
+	}
+
+	// 304 Not Modified returns the previous inventory
+	if _, again := loadHosts(t, cf, src.URL+"/api/hosts?env=main"); again != inv {
+		t.Error("not modified inventory reloaded")
+	}
+	if src.last(t).Header.Get("If-Modified-Since") == "" {
+		t.Error("no If-Modified-Since header with check-modified")
+	}
+
+	// and an object of hostname to type is also accepted
+	src.setBody(`{"data": {"hosts": {"o1": "gateway"}}}`, time.Now().Add(time.Second))
+	if hosts, _ = loadHosts(t, cf, src.URL+"/api/hosts?env=main"); !reflect.DeepEqual(hosts, map[string]string{"o1": "gateway"}) {
+		t.Errorf("hosts from object = %v", hosts)
+	}
+}
+
+func TestServiceNowInventory(t *testing.T) {
+	src := newFakeSource(t, `{"result": [{"name": "sn1", "u_role": "gateway"}, {"name": "sn2", "u_role": "netprobe"}]}`, time.Time{})
+	cf := testInventoryConfig(t, map[string]any{
+		"inventory.type":                    "snow",
+		"inventory.source":                  src.URL,
+		"inventory.fields.hosttype":         "${u_role}",
+		"inventory.snow.table":              "cmdb_ci_linux_server",
+		"inventory.snow.query":              "environment=${index}",
+		"inventory.snow.columns":            []string{"name", "u_role"},
+		"inventory.snow.limit":              100,
+		"inventory.authentication.type":     "basic",
+		"inventory.authentication.username": "user",
+		"inventory.authentication.password": "pass",
+	})
+
+	hosts, inv := loadHosts(t, cf, src.URL)
+	if want := map[string]string{"sn1": "gateway", "sn2": "netprobe"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+	r := src.last(t)
+	if r.URL.Path != "/api/now/table/cmdb_ci_linux_server" {
+		t.Errorf("request path = %s", r.URL.Path)
+	}
+	wantQuery := url.Values{
+		"sysparm_exclude_reference_link": {"true"},
+		"sysparm_query":                  {"environment=main"},
+		"sysparm_fields":                 {"name,u_role"},
+		"sysparm_limit":                  {"100"},
+	}
+	if q := r.URL.Query(); !reflect.DeepEqual(q, wantQuery) {
+		t.Errorf("request query = %v, want %v", q, wantQuery)
+	}
+	if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+		t.Errorf("basic auth = %q, %q, %v", u, p, ok)
+	}
+
+	// the same results return the previous inventory by checksum
+	if _, again := loadHosts(t, cf, src.URL); again != inv {
+		t.Error("unchanged results loaded again")
+	}
+	if src.last(t).Header.Get("If-Modified-Since") != "" {
+		t.Error("If-Modified-Since sent without a last modified time")
+	}
+
+	src.setBody(`{"result": [{"name": "sn3", "u_role": "gateway"}]}`, time.Time{})
+	hosts, again := loadHosts(t, cf, src.URL)
+	if again == inv || !reflect.DeepEqual(hosts, map[string]string{"sn3": "gateway"}) {
+		t.Errorf("changed results not loaded, hosts %v", hosts)
+	}
+}
+
+// ldapStub is a minimal LDAP server that accepts simple binds with the
+// password `secret` and returns entries for any search, recording the
+// bind DN and the search base and filter
+type ldapStub struct {
+	net.Listener
+	mutex   sync.Mutex
+	entries map[string]map[string]string
+	binds   []string
+	bases   []string
+	filters []string
+}
+
+func newLDAPStub(t *testing.T, entries map[string]map[string]string) *ldapStub {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ldapStub{Listener: l, entries: entries}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *ldapStub) setEntries(entries map[string]map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = entries
+}
+
+func (s *ldapStub) serve(c net.Conn) {
+	defer c.Close()
+	for {
+		p, err := ber.ReadPacket(c)
+		if err != nil || len(p.Children) < 2 {
+			return
+		}
+		id, _ := p.Children[0].Value.(int64)
+		op := p.Children[1]
+		var out bytes.Buffer
+		s.mutex.Lock()
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, _ := op.Children[1].Value.(string)
+			s.binds = append(s.binds, dn)
+			code := int64(ldap.LDAPResultSuccess)
+			if op.Children[2].Data.String() != "secret" {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			out.Write(ldapResult(id, ldap.ApplicationBindResponse, code).Bytes())
+		case ldap.ApplicationSearchRequest:
+			base, _ := op.Children[0].Value.(string)
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			s.bases = append(s.bases, base)
+			s.filters = append(s.filters, filter)
+			for dn, attrs := range s.entries {
+				out.Write(ldapEntry(id, dn, attrs).Bytes())
+			}
+			out.Write(ldapResult(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			s.mutex.Unlock()
+			return
+		}
+		s.mutex.Unlock()
+		c.Write(out.Bytes())
+	}
+}
+
+func ldapMessage(id int64, op *ber.Packet) *ber.Packet {
+	p := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	p.AppendChild(op)
+	return p
+}
+
+func ldapResult(id int64, tag ber.Tag, code int64) *ber.Packet {
+	r := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	r.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	r.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	r.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return ldapMessage(id, r)
+}
+
+func ldapEntry(id int64, dn string, attrs map[string]string) *ber.Packet {
+	e := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	e.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+	as := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for k, v := range attrs {
+		a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, k, ""))
+		vs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		vs.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+		a.AppendChild(vs)
+		as.AppendChild(a)
+	}
+	e.AppendChild(as)
+	return ldapMessage(id, e)
+}
+
+func TestLDAPInventory(t *testing.T) {
+	stub := newLDAPStub(t, map[string]map[string]string{
+		"cn=ld1,dc=example,dc=com": {"cn": "ld1", "description": "gateway"},
+		"cn=ld2,dc=example,dc=com": {"cn": "ld2", "description": "netprobe"},
+	})
+	source := "ldap://" + stub.Addr().String()
+	cache := filepath.Join(t.TempDir(), "inventory.json")
+	cf := testInventoryConfig(t, map[string]any{
+		"inventory.type":                    "ldap",
+		"inventory.source":                  source,
+		"inventory.cache":                   cache,
+		"inventory.fields.hostname":         "${cn}",
+		"inventory.fields.hosttype":         "${description}",
+		"inventory.ldap.base":               "ou=${index},dc=example,dc=com",
+		"inventory.ldap.filter":             "(&(objectClass=computer)(ou=${index}))",
+		"inventory.ldap.attributes":         []string{"cn", "description"},
+		"inventory.authentication.type":     "basic",
+		"inventory.authentication.username": "cn=admin,dc=example,dc=com",
+		"inventory.authentication.password": "secret",
+	})
+
+	hosts, inv := loadHosts(t, cf, source)
+	if want := map[string]string{"ld1": "gateway", "ld2": "netprobe"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+	stub.mutex.Lock()
+	if !reflect.DeepEqual(stub.binds, []string{"cn=admin,dc=example,dc=com"}) ||
+		!reflect.DeepEqual(stub.bases, []string{"ou=main,dc=example,dc=com"}) ||
+		!reflect.DeepEqual(stub.filters, []string{"(&(objectClass=computer)(ou=main))"}) {
+		t.Errorf("binds %q, search bases %q and filters %q", stub.binds, stub.bases, stub.filters)
+	}
+	stub.mutex.Unlock()
+	if _, err := os.Stat(cache); err != nil {
+		t.Errorf("cache file not written: %v", err)
+	}
+
+	// the same entries return the previous inventory by checksum
+	if _, again := loadHosts(t, cf, source); again != inv {
+		t.Error("unchanged entries loaded again")
+	}
+
+	stub.setEntries(map[string]map[string]string{
+		"cn=ld3,dc=example,dc=com": {"cn": "ld3", "description": "gateway"},
+	})
+	if hosts, _ = loadHosts(t, cf, source); !reflect.DeepEqual(hosts, map[string]string{"ld3": "gateway"}) {
+		t.Errorf("hosts after change = %v", hosts)
+	}
+
+	// a failed bind skips the inventory
+	config.Set(cf, "inventory.authentication.password", "wrong")
+	if hosts, _ = loadHosts(t, cf, source); len(hosts) != 0 {
+		t.Errorf("hosts after failed bind = %v, want none", hosts)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/itrs-group/cordial/pkg/config"
+	"github.com/itrs-group/cordial/pkg/rest"
+)
+
+func init() {
+	RegisterInventoryProvider("rest", InventoryProviderFunc(loadRESTInventory))
+}
+
+// loadRESTInventory loads an inventory from a JSON REST API at the
+// source URL. The hosts are taken from the value at the dotted path
+// `inventory.rest.results`, or the whole response if not set, which
+// can be an object of hostname to type or an array of records.
+func loadRESTInventory(cf *config.Config, src InventorySource) (inv *Inventory, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryTimeout(cf))
+	defer cancel()
+
+	c, err := inventoryRESTClient(ctx, cf, src)
+	if err != nil {
+		return
+	}
+	defer c.HTTPClient.CloseIdleConnections()
+
+	return fetchRESTInventory(ctx, cf, c, src, "", nil, config.Get[string](cf, "inventory.rest.results"))
+}
+
+// inventoryRESTClient returns a REST client for the source URL using
+// the `inventory.authentication` settings. Requests include an
+// If-Modified-Since header when there is a previous inventory with a
+// last modified time.
+func inventoryRESTClient(ctx context.Context, cf *config.Config, src InventorySource) (c *rest.Client, err error) {
+	u, err := url.Parse(src.Source)
+	if err != nil {
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		err = fmt.Errorf("unsupported scheme %q for inventory source", u.Scheme)
+		return
+	}
+
+	authType := config.Get[string](cf, "inventory.authentication.type")
+	header := config.Get[string](cf, "inventory.authentication.header")
+	values := config.Get[[]string](cf, "inventory.authentication.value")
+	username := config.Get[string](cf, "inventory.authentication.username")
+	password := config.Get[config.Secret](cf, "inventory.authentication.password")
+
+	c = rest.NewClient(
+		rest.BaseURL(u),
+		rest.HTTPClient(inventoryHTTPClient(cf)),
+		rest.SetupRequestFunc(func(req *http.Request, _ *rest.Client, _ []byte) {
+			req.Header.Set("accept", "application/json")
+			switch authType {
+			case "header":
+				req.Header[http.CanonicalHeaderKey(header)] = values
+			case "basic":
+				req.SetBasicAuth(username, string(password))
+			}
+			if src.Previous != nil && !src.Previous.lastModified.IsZero() {
+				req.Header.Set("if-modified-since", src.Previous.lastModified.Format(http.TimeFormat))
+			}
+		}),
+	)
+
+	switch authType {
+	case "token":
+		c.SetAuth("Authorization", "Bearer "+config.Get[string](cf, "inventory.authentication.token"))
+	case "oauth":
+		// the token endpoint is `/oauth2/token` under the
+		// authentication URL, defaulting to the root of the source
+		authURL := config.Get[string](cf, "inventory.authentication.url", config.LookupTable(src.Lookup))
+		if authURL == "" {
+			authURL = u.Scheme + "://" + u.Host
+		}
+		if c.BaseURL, err = url.Parse(authURL); err != nil {
+			return
+		}
+		c.Auth(ctx,
+			config.Get[string](cf, "inventory.authentication.client-id"),
+			config.Get[config.Secret](cf, "inventory.authentication.client-secret"),
+		)
+		c.BaseURL = u
+	}
+	return
+}
+
+// fetchRESTInventory GETs endpoint, relative to the client base URL,
+// with the optional query and returns the inventory from the JSON value
+// at the dotted path results. A 304 Not Modified response returns the
+// previous inventory.
+func fetchRESTInventory(ctx context.Context, cf *config.Config, c *rest.Client, src InventorySource, endpoint string, query any, results string) (inv *Inventory, err error) {
+	var body any
+	resp, err := c.Get(ctx, endpoint, query, &body)
+	if resp != nil && resp.StatusCode == http.StatusNotModified && src.Previous != nil {
+		log.Info("inventory not modified (header)", slog.String("src", src.Source))
+		return src.Previous, nil
+	}
+	if err != nil {
+		return
+	}
+
+	if results != "" {
+		for _, p := range strings.Split(results, ".") {
+			o, ok := body.(map[string]any)
+			if !ok {
+				err = fmt.Errorf("inventory results %q not found", results)
+				return
+			}
+			body = o[p]
+		}
+	}
+
+	if inv, err = remoteInventory(cf, src, body); err != nil || inv == src.Previous {
+		return
+	}
+	if lm := resp.Header.Get("last-modified"); lm != "" {
+		inv.lastModified, _ = http.ParseTime(lm)
+	}
+	return
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2026 ITRS Group
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/itrs-group/cordial/pkg/config"
+)
+
+func init() {
+	RegisterInventoryProvider("snow", InventoryProviderFunc(loadServiceNowInventory))
+}
+
+// loadServiceNowInventory loads an inventory from a ServiceNow CMDB
+// table using the Table API of the instance at the source URL. Each
+// record returned is mapped to a host using `inventory.fields`, with
+// reference fields returned as their values and not links.
+//
+// ServiceNow does not support If-Modified-Since, so the checksum of
+// the results is used to check for changes.
+func loadServiceNowInventory(cf *config.Config, src InventorySource) (inv *Inventory, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryTimeout(cf))
+	defer cancel()
+
+	c, err := inventoryRESTClient(ctx, cf, src)
+	if err != nil {
+		return
+	}
+	defer c.HTTPClient.CloseIdleConnections()
+
+	table := config.Get[string](cf, "inventory.snow.table")
+	if table == "" {
+		table = "cmdb_ci_server"
+	}
+
+	query := url.Values{}
+	query.Set("sysparm_exclude_reference_link", "true")
+	if q := config.Get[string](cf, "inventory.snow.query", config.LookupTable(src.Lookup)); q != "" {
+		query.Set("sysparm_query", q)
+	}
+	if columns := config.Get[[]string](cf, "inventory.snow.columns"); len(columns) > 0 {
+		query.Set("sysparm_fields", strings.Join(columns, ","))
+	}
+	if limit := config.Get[int](cf, "inventory.snow.limit"); limit > 0 {
+		query.Set("sysparm_limit", strconv.Itoa(limit))
+	}
+
+	return fetchRESTInventory(ctx, cf, c, src, "api/now/table/"+table, query.Encode(), "result")
+}
@@ -19,14 +19,8 @@ package cmd
 
 import (
 	"bytes"
-	"crypto/tls"
 	"io"
 	"log/slog"
-	"maps"
-	"net/http"
-	"slices"
-	"strings"
-	"time"
 
 	"github.com/itrs-group/cordial"
 	"github.com/itrs-group/cordial/pkg/config"
@@ -67,101 +61,3 @@ func ParseInventoryYAML(cf *config.Config, cacheFile string, in io.Reader) (inv
 	}
 	return
 }
-
-// ReadHostsYAML read all the inventories referenced in the YAML
-// configuration and returns a consolidated map of hostname to mappings.
-// If the slice "loop" exists it runs for each value, setting the
-// mapping "index" to the loop value.
-func ReadHostsYAML(cf *config.Config) (hosts map[string]HostMappings, err error) {
-	hosts = make(map[string]HostMappings)
-
-	timeout := config.Get[time.Duration](cf, "inventory.timeout")
-	if timeout == 0 {
-		timeout = 10 * time.Second
-	}
-
-	httpClient := &http.Client{
-		Timeout: timeout,
-	}
-	httpsClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: config.Get[bool](cf, "inventory.insecure"),
-			},
-		},
-		Timeout: timeout,
-	}
-
-	lookup := config.Get[map[string]string](cf, "inventory.mappings")
-
-	defFetchopts := []FetchOption{InventoryType("yaml")}
-
-	switch config.Get[string](cf, "inventory.authentication.type") {
-	case "header":
-		defFetchopts = append(defFetchopts,
-			AddHeader(config.Get[string](cf, "inventory.authentication.header"),
-				config.Get[[]string](cf, "inventory.authentication.value"),
-			))
-	case "basic":
-		defFetchopts = append(defFetchopts,
-			BasicAuth(config.Get[string](cf, "inventory.authentication.username"),
-				config.Get[config.Secret](cf, "inventory.authentication.password"),
-			))
-	}
-
-	for _, index := range config.Get[[]string](cf, "inventory.indices") {
-		var inv *Inventory
-		var err error
-
-		source := config.Get[string](cf, "inventory.source",
-			config.LookupTable(lookup),
-			config.LookupTable(map[string]string{"index": index}),
-		)
-
-		fetchopts := slices.Clone(defFetchopts)
-
-		if config.Get[bool](cf, "inventory.check-modified") {
-			if pi, ok := Inventories.Load(source); ok {
-				if pinv, ok := pi.(*Inventory); ok {
-					log.Debug("checking inventory", slog.String("src", source), slog.Int64("size", pinv.size), slog.Time("last_modified", pinv.lastModified))
-					fetchopts = append(fetchopts, IfModified(pinv))
-				}
-			}
-		}
-
-		switch {
-		case strings.HasPrefix(source, "http:"):
-			fetchopts = append(fetchopts, Client(httpClient))
-			inv, err = FetchInventory(cf, source, "", fetchopts...)
-		case strings.HasPrefix(source, "https:"):
-			fetchopts = append(fetchopts, Client(httpsClient))
-			inv, err = FetchInventory(cf, source, "", fetchopts...)
-		case strings.HasPrefix(source, "file"):
-			// remove file: scheme and drop through
-			source = strings.TrimPrefix(source, "file:")
-			fallthrough
-		default:
-			source = config.ResolveHome(source)
-			inv, err = ReadInventory(cf, source)
-		}
-
-		if err != nil {
-			log.Error("failed to read inventory", slog.String("src", source), slog.Any("error", err))
-			continue
-		}
-
-		if config.Get[bool](cf, "inventory.check-modified") {
-			log.Debug("storing inventory", slog.String("src", source), slog.Int64("size", inv.size), slog.Time("last_modified", inv.lastModified))
-			Inventories.Store(source, inv)
-		}
-
-		for h, t := range inv.hosts {
-			hosts[h] = maps.Clone(lookup)
-			hosts[h]["hostname"] = h
-			hosts[h]["hosttype"] = t
-		}
-	}
-
-	return
-}
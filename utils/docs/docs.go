@@ -31,7 +31,9 @@ import (
 
 	dv2email "github.com/itrs-group/cordial/tools/dv2email/cmd"
 	gatewayReporter "github.com/itrs-group/cordial/tools/gateway-reporter/cmd"
+	geneosExporter "github.com/itrs-group/cordial/tools/geneos-exporter/cmd"
 	imsGatewayCmd "github.com/itrs-group/cordial/tools/ims-gateway/cmd"
+	otelReceiver "github.com/itrs-group/cordial/tools/otel-receiver/cmd"
 	sanCfgCmd "github.com/itrs-group/cordial/tools/san-config/cmd"
 
 	"github.com/spf13/cobra"
@@ -48,6 +50,8 @@ var doclist = []docs{
 	{dv2email.Cmd, "../../tools/dv2email/docs"},
 	{imsGatewayCmd.Cmd, "../../tools/ims-gateway/docs"},
 	{gatewayReporter.Cmd, "../../tools/gateway-reporter/docs"},
+	{geneosExporter.Cmd, "../../tools/geneos-exporter/docs"},
+	{otelReceiver.Cmd, "../../tools/otel-receiver/docs"},
 	{sanCfgCmd.Cmd, "../../tools/san-config/docs"},
 
 	{snowCmd.Cmd, "../../integrations/servicenow/docs"},